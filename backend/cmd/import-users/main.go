@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/bootstrap"
+	"gosveltekit/internal/config"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/validation"
+
+	"gorm.io/gorm"
+)
+
+// importedUser is one row of the import file. PasswordHash is stored as it
+// is; legacy formats are re-hashed on the user's first successful login.
+type importedUser struct {
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	DisplayName   string `json:"display_name"`
+	PasswordHash  string `json:"password_hash"`
+	Role          string `json:"role"`
+	Active        *bool  `json:"active"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func main() {
+	file := flag.String("file", "", "CSV or JSON file with the users to import")
+	format := flag.String("format", "", "file format: csv or json (default: from the file extension)")
+	dryRun := flag.Bool("dry-run", false, "validate the file without writing to the database")
+	flag.Parse()
+
+	if *file == "" {
+		exitf("usage: import-users -file users.csv [-format csv|json] [-dry-run]")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	users, err := readUsers(*file, *format)
+	if err != nil {
+		exitf("failed to read %s: %v", *file, err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		exitf("failed to load config: %v", err)
+	}
+
+	hasher, err := bootstrap.NewPasswordHasher(cfg)
+	if err != nil {
+		exitf("invalid password hashing config: %v", err)
+	}
+
+	formats := make(map[string]int)
+	for i := range users {
+		if err := validateUser(&users[i], hasher); err != nil {
+			exitf("row %d (%s): %v", i+1, users[i].Username, err)
+		}
+		formats[hashFormat(hasher, users[i].PasswordHash)]++
+	}
+
+	if *dryRun {
+		fmt.Printf("%d users are valid\n", len(users))
+		printFormats(formats)
+		return
+	}
+
+	db, err := bootstrap.OpenGorm(cfg)
+	if err != nil {
+		exitf("failed to open database: %v", err)
+	}
+
+	created, skipped, err := importUsers(db, users)
+	if err != nil {
+		exitf("failed to import users: %v", err)
+	}
+
+	fmt.Printf("imported %d users, skipped %d already present\n", created, skipped)
+	printFormats(formats)
+}
+
+func readUsers(path, format string) ([]importedUser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch format {
+	case "csv":
+		return readCSV(f)
+	case "json":
+		var users []importedUser
+		if err := json.NewDecoder(f).Decode(&users); err != nil {
+			return nil, err
+		}
+		return users, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// readCSV reads a file whose header names the importedUser JSON fields;
+// username, email and password_hash are required
+func readCSV(r io.Reader) ([]importedUser, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"username", "email", "password_hash"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	var users []importedUser
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return users, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		user := importedUser{
+			Username:     field("username"),
+			Email:        field("email"),
+			DisplayName:  field("display_name"),
+			PasswordHash: field("password_hash"),
+			Role:         field("role"),
+		}
+		if value := field("active"); value != "" {
+			active, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid active value %q", len(users)+2, value)
+			}
+			user.Active = &active
+		}
+		if value := field("email_verified"); value != "" {
+			if user.EmailVerified, err = strconv.ParseBool(value); err != nil {
+				return nil, fmt.Errorf("line %d: invalid email_verified value %q", len(users)+2, value)
+			}
+		}
+		users = append(users, user)
+	}
+}
+
+func validateUser(user *importedUser, hasher auth.PasswordHasher) error {
+	if user.DisplayName == "" {
+		user.DisplayName = user.Username
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+
+	if err := validation.ValidateUsername(user.Username); err != nil {
+		return err
+	}
+	if err := validation.ValidateEmail(user.Email); err != nil {
+		return err
+	}
+	if err := validation.ValidateDisplayName(user.DisplayName); err != nil {
+		return err
+	}
+	if user.Role != "user" && user.Role != "admin" {
+		return fmt.Errorf("invalid role %q", user.Role)
+	}
+	// Decode the hash now: one that cannot be verified, or whose parameters
+	// would exhaust the server, would otherwise only fail at first login
+	if err := hasher.CheckHash(user.PasswordHash); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// hashFormat names the format of encoded for the import summary
+func hashFormat(hasher auth.PasswordHasher, encoded string) string {
+	if name, ok := auth.NewLegacyHasher().Format(encoded); ok {
+		return name + " (legacy)"
+	}
+	if hasher.NeedsRehash(encoded) {
+		return "outdated"
+	}
+	return "current"
+}
+
+// importUsers creates the users in one transaction, skipping those whose
+// username or email is already taken
+func importUsers(db *gorm.DB, users []importedUser) (int, int, error) {
+	created, skipped := 0, 0
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, user := range users {
+			var count int64
+			if err := tx.Model(&models.User{}).
+				Where("username = ? OR email = ?", user.Username, user.Email).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				skipped++
+				continue
+			}
+
+			record := models.User{
+				Username:      user.Username,
+				Email:         user.Email,
+				DisplayName:   user.DisplayName,
+				PasswordHash:  user.PasswordHash,
+				Role:          user.Role,
+				Active:        true,
+				EmailVerified: user.EmailVerified,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return fmt.Errorf("%s: %w", user.Username, err)
+			}
+
+			// GORM skips a false Active on create and the column defaults to true
+			if user.Active != nil && !*user.Active {
+				if err := tx.Model(&record).Update("active", false).Error; err != nil {
+					return fmt.Errorf("%s: %w", user.Username, err)
+				}
+			}
+			created++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return created, skipped, nil
+}
+
+func printFormats(formats map[string]int) {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("  %-32s %d\n", name, formats[name])
+	}
+}
+
+func exitf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -10,7 +10,6 @@ import (
 	"gosveltekit/internal/models"
 	"gosveltekit/internal/validation"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -37,12 +36,17 @@ func main() {
 		exitf("failed to open database: %v", err)
 	}
 
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hasher, err := bootstrap.NewPasswordHasher(cfg)
+	if err != nil {
+		exitf("invalid password hashing config: %v", err)
+	}
+
+	passwordHash, err := hasher.Hash(password)
 	if err != nil {
 		exitf("failed to hash password: %v", err)
 	}
 
-	user, created, err := upsertAdmin(db, identifier, email, displayName, string(passwordHash))
+	user, created, err := upsertAdmin(db, identifier, email, displayName, passwordHash)
 	if err != nil {
 		exitf("failed to seed admin: %v", err)
 	}
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing sessions keep working: their tokens are replaced by the token hash
+-- and each gets a random public ID.
+ALTER TABLE sessions ADD COLUMN public_id VARCHAR(32);
+
+UPDATE sessions
+SET id = encode(sha256(convert_to(id, 'UTF8')), 'hex'),
+    public_id = md5(random()::text || clock_timestamp()::text || id);
+
+ALTER TABLE sessions ALTER COLUMN public_id SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_sessions_public_id ON sessions (public_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Token hashes cannot be turned back into tokens, so every session ends.
+DELETE FROM sessions;
+
+DROP INDEX IF EXISTS idx_sessions_public_id;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS public_id;
+-- +goose StatementEnd
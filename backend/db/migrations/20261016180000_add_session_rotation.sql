@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- previous_id keeps the hash of the token replaced by the last rotation, which
+-- may be accepted until previous_expires_at so that requests already in flight
+-- do not fail. rotation_pending asks for a new token on the session's next use.
+ALTER TABLE sessions ADD COLUMN previous_id VARCHAR(64);
+ALTER TABLE sessions ADD COLUMN previous_expires_at TIMESTAMPTZ;
+ALTER TABLE sessions ADD COLUMN rotation_pending BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX idx_sessions_previous_id ON sessions (previous_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_previous_id;
+
+ALTER TABLE sessions DROP COLUMN IF EXISTS rotation_pending;
+ALTER TABLE sessions DROP COLUMN IF EXISTS previous_expires_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS previous_id;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- reauthenticated_at is when the user last confirmed their password within the
+-- session; sensitive account actions require it to be recent.
+ALTER TABLE sessions ADD COLUMN reauthenticated_at TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN IF EXISTS reauthenticated_at;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Personal access tokens for scripts. Only the SHA-256 of each token is
+-- stored; hint keeps its last characters so the owner can recognise it.
+CREATE TABLE api_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL,
+    hint VARCHAR(8) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    last_used_ip VARCHAR(45),
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_api_tokens_token_hash ON api_tokens (token_hash);
+CREATE INDEX idx_api_tokens_user_id ON api_tokens (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Machine clients using the OAuth 2.0 client credentials grant, and the
+-- access tokens issued to them. Only SHA-256 hashes of secrets and tokens
+-- are stored.
+CREATE TABLE oauth_clients (
+    id BIGSERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_oauth_clients_client_id ON oauth_clients (client_id);
+
+CREATE TABLE oauth_client_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    client_id VARCHAR(64) NOT NULL REFERENCES oauth_clients(client_id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_oauth_client_tokens_token_hash ON oauth_client_tokens (token_hash);
+CREATE INDEX idx_oauth_client_tokens_client_id ON oauth_client_tokens (client_id);
+CREATE INDEX idx_oauth_client_tokens_expires_at ON oauth_client_tokens (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS oauth_client_tokens;
+DROP TABLE IF EXISTS oauth_clients;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Security-relevant actions, such as an admin impersonating a user. actor_id
+-- is who acted and user_id whose account it concerns; details is a JSON
+-- object of strings.
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    actor_id BIGINT,
+    user_id BIGINT,
+    ip VARCHAR(45),
+    user_agent VARCHAR(500),
+    details TEXT,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_audit_events_type ON audit_events (type);
+CREATE INDEX idx_audit_events_actor_id ON audit_events (actor_id);
+CREATE INDEX idx_audit_events_user_id ON audit_events (user_id);
+CREATE INDEX idx_audit_events_created_at ON audit_events (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS audit_events;
+-- +goose StatementEnd
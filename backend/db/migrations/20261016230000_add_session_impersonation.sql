@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- impersonator_id is set on sessions an admin created to act as the user, with
+-- the reason they gave; deleting the admin ends those sessions.
+ALTER TABLE sessions ADD COLUMN impersonator_id BIGINT REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE sessions ADD COLUMN impersonation_reason VARCHAR(255);
+
+CREATE INDEX idx_sessions_impersonator_id ON sessions (impersonator_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_sessions_impersonator_id;
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonation_reason;
+ALTER TABLE sessions DROP COLUMN IF EXISTS impersonator_id;
+-- +goose StatementEnd
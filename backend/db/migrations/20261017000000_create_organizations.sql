@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Organizations group users under per-organization roles (owner, admin,
+-- member). A session remembers the organization its user is working in.
+CREATE TABLE organizations (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_organizations_created_at ON organizations (created_at);
+CREATE INDEX idx_organizations_name ON organizations (name);
+
+CREATE TABLE organization_memberships (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_organization_memberships_org_user ON organization_memberships (organization_id, user_id);
+CREATE INDEX idx_organization_memberships_user_id ON organization_memberships (user_id);
+
+ALTER TABLE sessions ADD COLUMN active_organization_id BIGINT REFERENCES organizations(id) ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN IF EXISTS active_organization_id;
+DROP TABLE IF EXISTS organization_memberships;
+DROP TABLE IF EXISTS organizations;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Pending invitations to join an organization. Only a hash of the emailed
+-- token is stored; accepting an invitation deletes it.
+CREATE TABLE organization_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    organization_id BIGINT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    inviter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_organization_invitations_token_hash ON organization_invitations (token_hash);
+CREATE UNIQUE INDEX idx_organization_invitations_org_email ON organization_invitations (organization_id, email);
+CREATE INDEX idx_organization_invitations_expires_at ON organization_invitations (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS organization_invitations;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The audit log is only ever appended to: a trigger rejects updates and
+-- deletes. Composite indexes serve the admin
+-- filters and each user's security activity, newest first.
+CREATE INDEX idx_audit_events_user_id_created_at ON audit_events (user_id, created_at, id);
+CREATE INDEX idx_audit_events_actor_id_created_at ON audit_events (actor_id, created_at, id);
+
+CREATE FUNCTION audit_events_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+DROP FUNCTION IF EXISTS audit_events_append_only();
+DROP INDEX IF EXISTS idx_audit_events_actor_id_created_at;
+DROP INDEX IF EXISTS idx_audit_events_user_id_created_at;
+-- +goose StatementEnd
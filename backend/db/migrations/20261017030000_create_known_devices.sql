@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Devices (user agent family and IP network) each user has signed in from,
+-- to tell users about sign-ins from new ones. Only a hash of the emailed
+-- token that revokes the reported session is stored.
+CREATE TABLE known_devices (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent_family VARCHAR(255) NOT NULL,
+    ip_network VARCHAR(64) NOT NULL,
+    last_ip VARCHAR(45),
+    created_at TIMESTAMPTZ,
+    last_seen_at TIMESTAMPTZ NOT NULL,
+    session_public_id VARCHAR(32),
+    revoke_token_hash VARCHAR(64),
+    revoke_expires_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX idx_known_devices_user_device ON known_devices (user_id, user_agent_family, ip_network);
+CREATE INDEX idx_known_devices_last_seen_at ON known_devices (last_seen_at);
+CREATE INDEX idx_known_devices_revoke_token_hash ON known_devices (revoke_token_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS known_devices;
+-- +goose StatementEnd
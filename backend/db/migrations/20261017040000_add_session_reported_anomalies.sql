@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Space-separated "<kind>=<actual>" client mismatches already reported for
+-- the session, so that each one is reported once rather than per request.
+ALTER TABLE sessions ADD COLUMN reported_anomalies TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions DROP COLUMN IF EXISTS reported_anomalies;
+-- +goose StatementEnd
@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// AccessTokenType is the "typ" header of access tokens (RFC 9068)
+const AccessTokenType = "at+jwt"
+
+// AccessTokenClaims are the claims of a signed access token. Subject is the
+// user ID; Scope lists the granted scopes separated by spaces, as in OAuth.
+type AccessTokenClaims struct {
+	jwt.Claims
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions,omitempty"` // the user's effective permissions when issued
+	Scope       string   `json:"scope"`
+	SessionID   string   `json:"sid,omitempty"` // public ID of the session the token was issued for
+}
+
+// HasScope reports whether the token was granted the scope
+func (c *AccessTokenClaims) HasScope(scope string) bool {
+	return slices.Contains(strings.Fields(c.Scope), scope)
+}
+
+// AccessToken is a freshly signed access token
+type AccessToken struct {
+	Token     string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// AccessTokenSignerConfig describes the tokens an AccessTokenSigner issues
+type AccessTokenSignerConfig struct {
+	Issuer   string        // "iss", usually the backend's public URL
+	Audience string        // "aud", optional
+	TTL      time.Duration // how long a token is valid
+}
+
+// AccessTokenSigner issues short-lived JWTs that other services verify with
+// the public keys it publishes, without calling back to this backend. Ed25519
+// keys sign with EdDSA and P-256 keys with ES256.
+//
+// Keys are rotated by making the new key current and keeping the old one
+// among the previous keys for at least one TTL, so tokens it signed still
+// verify; previous keys are only published, never used to sign.
+type AccessTokenSigner struct {
+	signer jose.Signer
+	keys   jose.JSONWebKeySet
+	config AccessTokenSignerConfig
+}
+
+// NewAccessTokenSigner creates a signer that signs with current and publishes
+// its public key along with the previous ones
+func NewAccessTokenSigner(current crypto.Signer, previous []crypto.PublicKey, config AccessTokenSignerConfig) (*AccessTokenSigner, error) {
+	if config.TTL <= 0 {
+		return nil, errors.New("access token TTL must be positive")
+	}
+
+	currentKey, err := publicJSONWebKey(current.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{
+			Algorithm: jose.SignatureAlgorithm(currentKey.Algorithm),
+			Key:       jose.JSONWebKey{Key: current, KeyID: currentKey.KeyID},
+		},
+		(&jose.SignerOptions{}).WithType(AccessTokenType),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{*currentKey}}
+	for _, publicKey := range previous {
+		key, err := publicJSONWebKey(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys.Key(key.KeyID)) == 0 {
+			keys.Keys = append(keys.Keys, *key)
+		}
+	}
+
+	return &AccessTokenSigner{signer: signer, keys: keys, config: config}, nil
+}
+
+// publicJSONWebKey describes a public key for the JWKS. Its key ID is the
+// RFC 7638 thumbprint, so the same key always gets the same ID.
+func publicJSONWebKey(publicKey crypto.PublicKey) (*jose.JSONWebKey, error) {
+	key := &jose.JSONWebKey{Key: publicKey, Use: "sig"}
+	switch k := publicKey.(type) {
+	case ed25519.PublicKey:
+		key.Algorithm = string(jose.EdDSA)
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("access token keys must use the P-256 curve")
+		}
+		key.Algorithm = string(jose.ES256)
+	default:
+		return nil, fmt.Errorf("unsupported access token key type %T", publicKey)
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	key.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+	return key, nil
+}
+
+// JWKS returns the public keys that verify the signer's tokens
+func (s *AccessTokenSigner) JWKS() jose.JSONWebKeySet {
+	return s.keys
+}
+
+// Sign issues a token for the user carrying the given scopes
+func (s *AccessTokenSigner) Sign(user *UserData, scopes []string, sessionPublicID string) (*AccessToken, error) {
+	jti := make([]byte, 16)
+	if _, err := GenerateRandomBytes(jti); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.TTL)
+	claims := AccessTokenClaims{
+		Claims: jwt.Claims{
+			Issuer:    s.config.Issuer,
+			Subject:   user.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(expiresAt),
+			ID:        base64.RawURLEncoding.EncodeToString(jti),
+		},
+		Role:        user.Role,
+		Permissions: user.Permissions,
+		Scope:       strings.Join(scopes, " "),
+		SessionID:   sessionPublicID,
+	}
+	if s.config.Audience != "" {
+		claims.Audience = jwt.Audience{s.config.Audience}
+	}
+
+	token, err := jwt.Signed(s.signer).Claims(claims).Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{Token: token, Scopes: scopes, ExpiresAt: expiresAt}, nil
+}
+
+// ParseAccessTokenSigningKey reads a PEM-encoded Ed25519 or P-256 private key
+// in PKCS #8 or, for P-256, SEC 1 form
+func ParseAccessTokenSigningKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch k := key.(type) {
+		case ed25519.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("unsupported access token key type %T", key)
+		}
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+// ParseAccessTokenVerificationKey reads a PEM-encoded public key, or the
+// public half of a private key accepted by ParseAccessTokenSigningKey, so a
+// retired signing key can be kept as a previous key as is
+func ParseAccessTokenVerificationKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if block.Type == "PUBLIC KEY" {
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	key, err := ParseAccessTokenSigningKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return key.Public(), nil
+}
+
+// SetAccessTokenSigner enables signed access tokens
+func (m *AuthManager) SetAccessTokenSigner(signer *AccessTokenSigner) {
+	m.accessTokenSigner = signer
+}
+
+// AccessTokenJWKS returns the keys that verify access tokens
+func (m *AuthManager) AccessTokenJWKS() (*jose.JSONWebKeySet, error) {
+	if m.accessTokenSigner == nil {
+		return nil, ErrAccessTokensNotSupported
+	}
+	keys := m.accessTokenSigner.JWKS()
+	return &keys, nil
+}
+
+// IssueAccessToken signs an access token for a signed-in user. The token
+// carries the requested scopes, or every scope the user may have if none are
+// requested; the scopes are the same as for personal access tokens.
+func (m *AuthManager) IssueAccessToken(user *UserData, sessionPublicID string, scopes []string) (*AccessToken, error) {
+	if m.accessTokenSigner == nil {
+		return nil, ErrAccessTokensNotSupported
+	}
+	if !user.Active {
+		return nil, ErrUserNotActive
+	}
+
+	if len(scopes) == 0 {
+		scopes = AllowedAPITokenScopes(user.Role)
+	}
+	scopes, err := normalizeAPITokenScopes(user.Role, scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.accessTokenSigner.Sign(user, scopes, sessionPublicID)
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccessTokenSigner(t *testing.T, previous ...crypto.PublicKey) (*AccessTokenSigner, ed25519.PublicKey) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := NewAccessTokenSigner(privateKey, previous, AccessTokenSignerConfig{
+		Issuer:   "https://api.example.com",
+		Audience: "orders",
+		TTL:      5 * time.Minute,
+	})
+	require.NoError(t, err)
+	return signer, publicKey
+}
+
+func TestAccessTokenSigner_SignsVerifiableTokens(t *testing.T) {
+	signer, publicKey := newTestAccessTokenSigner(t)
+	user := &UserData{ID: "42", Role: "admin"}
+
+	token, err := signer.Sign(user, []string{APITokenScopeRead, APITokenScopeAdmin}, "session-1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), token.ExpiresAt, time.Second)
+
+	verifier := oidc.NewVerifier("https://api.example.com",
+		&oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{publicKey}},
+		&oidc.Config{ClientID: "orders", SupportedSigningAlgs: []string{oidc.EdDSA}})
+	idToken, err := verifier.Verify(context.Background(), token.Token)
+	require.NoError(t, err)
+
+	var claims AccessTokenClaims
+	require.NoError(t, idToken.Claims(&claims))
+	assert.Equal(t, "42", claims.Subject)
+	assert.Equal(t, "admin", claims.Role)
+	assert.Equal(t, "session-1", claims.SessionID)
+	assert.NotEmpty(t, claims.ID)
+	assert.True(t, claims.HasScope(APITokenScopeAdmin))
+	assert.False(t, claims.HasScope(APITokenScopeWrite))
+}
+
+func TestAccessTokenSigner_ES256(t *testing.T) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := NewAccessTokenSigner(privateKey, nil, AccessTokenSignerConfig{TTL: time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, "ES256", signer.JWKS().Keys[0].Algorithm)
+
+	_, err = signer.Sign(&UserData{ID: "1"}, []string{APITokenScopeRead}, "")
+	require.NoError(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = NewAccessTokenSigner(p384, nil, AccessTokenSignerConfig{TTL: time.Minute})
+	assert.Error(t, err)
+}
+
+func TestAccessTokenSigner_JWKSKeepsPreviousKeys(t *testing.T) {
+	oldPublicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, currentPublicKey := newTestAccessTokenSigner(t, oldPublicKey, oldPublicKey)
+	keys := signer.JWKS()
+	require.Len(t, keys.Keys, 2)
+
+	assert.Equal(t, currentPublicKey, keys.Keys[0].Key)
+	assert.Equal(t, oldPublicKey, keys.Keys[1].Key)
+	for _, key := range keys.Keys {
+		assert.True(t, key.IsPublic())
+		assert.NotEmpty(t, key.KeyID)
+		assert.Equal(t, "sig", key.Use)
+	}
+}
+
+func TestParseAccessTokenKeys(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	parsed, err := ParseAccessTokenSigningKey(privatePEM)
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, parsed)
+
+	verificationKey, err := ParseAccessTokenVerificationKey(privatePEM)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, verificationKey)
+
+	der, err = x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	verificationKey, err = ParseAccessTokenVerificationKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, verificationKey)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err = x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	_, err = ParseAccessTokenSigningKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+
+	_, err = ParseAccessTokenSigningKey([]byte("not a key"))
+	assert.Error(t, err)
+}
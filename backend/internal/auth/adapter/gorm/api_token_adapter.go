@@ -0,0 +1,137 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// APITokenAdapter implements auth.APITokenAdapter using GORM
+type APITokenAdapter struct {
+	db *gorm.DB
+}
+
+// NewAPITokenAdapter creates a new GORM-based personal access token adapter
+func NewAPITokenAdapter(db *gorm.DB) *APITokenAdapter {
+	return &APITokenAdapter{db: db}
+}
+
+// CreateAPIToken stores a new token and sets its ID
+func (a *APITokenAdapter) CreateAPIToken(token *auth.APIToken) error {
+	uid, err := strconv.ParseUint(token.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	record := &models.APIToken{
+		UserID:    uint(uid),
+		Name:      token.Name,
+		TokenHash: token.TokenHash,
+		Hint:      token.Hint,
+		Scopes:    strings.Join(token.Scopes, " "),
+		CreatedAt: token.CreatedAt,
+	}
+	if !token.ExpiresAt.IsZero() {
+		record.ExpiresAt = &token.ExpiresAt
+	}
+	if err := a.db.Create(record).Error; err != nil {
+		return err
+	}
+
+	token.ID = strconv.FormatUint(uint64(record.ID), 10)
+	return nil
+}
+
+// GetAPITokenByHash finds a token by the hash of its value
+func (a *APITokenAdapter) GetAPITokenByHash(tokenHash string) (*auth.APIToken, error) {
+	var record models.APIToken
+	if err := a.db.Where("token_hash = ?", tokenHash).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrAPITokenNotFound
+		}
+		return nil, err
+	}
+
+	return toAuthAPIToken(&record), nil
+}
+
+// ListAPITokens returns the user's tokens, newest first
+func (a *APITokenAdapter) ListAPITokens(userID string) ([]auth.APIToken, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.APIToken
+	if err := a.db.Where("user_id = ?", uid).Order("created_at DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	tokens := make([]auth.APIToken, 0, len(records))
+	for i := range records {
+		tokens = append(tokens, *toAuthAPIToken(&records[i]))
+	}
+	return tokens, nil
+}
+
+// TouchAPIToken records when and from where the token was last used
+func (a *APITokenAdapter) TouchAPIToken(id string, lastUsedAt time.Time, ip string) error {
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Model(&models.APIToken{}).
+		Where("id = ?", tid).
+		Updates(map[string]any{
+			"last_used_at": lastUsedAt,
+			"last_used_ip": ip,
+		}).Error
+}
+
+// DeleteAPIToken removes a token owned by the user
+func (a *APITokenAdapter) DeleteAPIToken(userID, id string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	tid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return auth.ErrAPITokenNotFound
+	}
+
+	result := a.db.Where("id = ? AND user_id = ?", tid, uid).Delete(&models.APIToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+func toAuthAPIToken(record *models.APIToken) *auth.APIToken {
+	token := &auth.APIToken{
+		ID:         strconv.FormatUint(uint64(record.ID), 10),
+		UserID:     strconv.FormatUint(uint64(record.UserID), 10),
+		Name:       record.Name,
+		TokenHash:  record.TokenHash,
+		Hint:       record.Hint,
+		Scopes:     strings.Fields(record.Scopes),
+		LastUsedIP: record.LastUsedIP,
+		CreatedAt:  record.CreatedAt,
+	}
+	if record.ExpiresAt != nil {
+		token.ExpiresAt = *record.ExpiresAt
+	}
+	if record.LastUsedAt != nil {
+		token.LastUsedAt = *record.LastUsedAt
+	}
+	return token
+}
@@ -0,0 +1,159 @@
+package gorm
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// AuditAdapter implements auth.AuditAdapter using GORM
+type AuditAdapter struct {
+	db *gorm.DB
+}
+
+// NewAuditAdapter creates a new GORM-based audit log adapter
+func NewAuditAdapter(db *gorm.DB) *AuditAdapter {
+	return &AuditAdapter{db: db}
+}
+
+// RecordAuditEvent stores an event and sets its ID
+func (a *AuditAdapter) RecordAuditEvent(event *auth.AuditEvent) error {
+	record := &models.AuditEvent{
+		Type:      event.Type,
+		IP:        event.IP,
+		UserAgent: event.UserAgent,
+		CreatedAt: event.CreatedAt,
+	}
+
+	var err error
+	if record.ActorID, err = optionalUserID(event.ActorID); err != nil {
+		return err
+	}
+	if record.UserID, err = optionalUserID(event.UserID); err != nil {
+		return err
+	}
+	if len(event.Details) > 0 {
+		details, err := json.Marshal(event.Details)
+		if err != nil {
+			return err
+		}
+		record.Details = string(details)
+	}
+
+	if err := a.db.Create(record).Error; err != nil {
+		return err
+	}
+
+	event.ID = strconv.FormatUint(uint64(record.ID), 10)
+	return nil
+}
+
+// AuditEventFilter narrows an audit log listing; zero fields match every
+// event. From is inclusive and To exclusive.
+type AuditEventFilter struct {
+	ActorID string
+	UserID  string
+	Type    string
+	From    time.Time
+	To      time.Time
+}
+
+type AuditCursorPageResult struct {
+	Events     []*models.AuditEvent
+	NextCursor *string
+	PrevCursor *string
+	HasNext    bool
+	HasPrev    bool
+}
+
+// ListAuditEventsCursor returns cursor-based paginated audit events sorted by
+// creation time.
+func (a *AuditAdapter) ListAuditEventsCursor(
+	filter AuditEventFilter,
+	input pagination.CursorQuery,
+) (*AuditCursorPageResult, error) {
+	query, err := a.auditEventsQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := listCursorPage(query, "created_at", input, encodeAuditEventCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuditCursorPageResult{
+		Events:     page.items,
+		NextCursor: page.nextCursor,
+		PrevCursor: page.prevCursor,
+		HasNext:    page.hasNext,
+		HasPrev:    page.hasPrev,
+	}, nil
+}
+
+func (a *AuditAdapter) auditEventsQuery(filter AuditEventFilter) (*gorm.DB, error) {
+	query := a.db.Model(&models.AuditEvent{})
+
+	actorID, err := optionalUserID(filter.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if actorID != nil {
+		query = query.Where("actor_id = ?", *actorID)
+	}
+
+	userID, err := optionalUserID(filter.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	return query, nil
+}
+
+func encodeAuditEventCursor(
+	event *models.AuditEvent,
+	sortField string,
+	direction pagination.SortDirection,
+) (string, error) {
+	if sortField != "created_at" {
+		return "", pagination.ErrInvalidCursor
+	}
+
+	return pagination.EncodeCursor(pagination.CursorToken{
+		Sort:      sortField,
+		Direction: direction,
+		Value:     event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        event.ID,
+	})
+}
+
+// optionalUserID parses a user ID that may be empty
+func optionalUserID(userID string) (*uint, error) {
+	if userID == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	uid := uint(id)
+	return &uid, nil
+}
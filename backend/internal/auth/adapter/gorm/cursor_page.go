@@ -0,0 +1,193 @@
+package gorm
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"gosveltekit/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+// cursorPage is one page of a cursor-paginated listing
+type cursorPage[T any] struct {
+	items      []T
+	nextCursor *string
+	prevCursor *string
+	hasNext    bool
+	hasPrev    bool
+}
+
+// listCursorPage loads the page of query that input asks for, sorted by
+// orderColumn with id breaking ties, and encodes the cursors pointing before
+// its first and after its last item with encode.
+func listCursorPage[T any](
+	query *gorm.DB,
+	orderColumn string,
+	input pagination.CursorQuery,
+	encode func(item T, sortField string, direction pagination.SortDirection) (string, error),
+) (*cursorPage[T], error) {
+	var (
+		items   []T
+		hasNext bool
+		hasPrev bool
+	)
+
+	limit := input.PageSize + 1
+	isBefore := input.Before != ""
+	cursorValue := input.After
+	if isBefore {
+		cursorValue = input.Before
+	}
+
+	if cursorValue != "" {
+		token, err := pagination.DecodeCursor(cursorValue)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Sort != input.Sort || token.Direction != input.Order {
+			return nil, pagination.ErrInvalidCursor
+		}
+
+		cursorQuery, reverseOrder, err := buildCursorQuery(orderColumn, input.Order, token, isBefore)
+		if err != nil {
+			return nil, err
+		}
+
+		query = query.Where(cursorQuery.sql, cursorQuery.args...)
+		if err := query.
+			Order(fmt.Sprintf("%s %s", orderColumn, reverseOrder.primary)).
+			Order(fmt.Sprintf("id %s", reverseOrder.tieBreaker)).
+			Limit(limit).
+			Find(&items).Error; err != nil {
+			return nil, err
+		}
+
+		if len(items) > input.PageSize {
+			if isBefore {
+				hasPrev = true
+			} else {
+				hasNext = true
+			}
+			items = items[:input.PageSize]
+		}
+
+		if isBefore {
+			slices.Reverse(items)
+			hasNext = true
+		} else {
+			hasPrev = true
+		}
+	} else {
+		order := buildOrder(input.Order, false)
+		if err := query.
+			Order(fmt.Sprintf("%s %s", orderColumn, order.primary)).
+			Order(fmt.Sprintf("id %s", order.tieBreaker)).
+			Limit(limit).
+			Find(&items).Error; err != nil {
+			return nil, err
+		}
+
+		if len(items) > input.PageSize {
+			hasNext = true
+			items = items[:input.PageSize]
+		}
+	}
+
+	page := &cursorPage[T]{items: items, hasNext: hasNext, hasPrev: hasPrev}
+	if len(items) > 0 {
+		if hasPrev {
+			cursor, err := encode(items[0], input.Sort, input.Order)
+			if err != nil {
+				return nil, err
+			}
+			page.prevCursor = &cursor
+		}
+		if hasNext {
+			cursor, err := encode(items[len(items)-1], input.Sort, input.Order)
+			if err != nil {
+				return nil, err
+			}
+			page.nextCursor = &cursor
+		}
+	}
+	return page, nil
+}
+
+type cursorWhereClause struct {
+	sql  string
+	args []any
+}
+
+type orderParts struct {
+	primary    string
+	tieBreaker string
+}
+
+func buildCursorQuery(
+	orderColumn string,
+	direction pagination.SortDirection,
+	token *pagination.CursorToken,
+	isBefore bool,
+) (*cursorWhereClause, orderParts, error) {
+	value, err := parseCursorValue(token.Sort, token.Value)
+	if err != nil {
+		return nil, orderParts{}, pagination.ErrInvalidCursor
+	}
+
+	order := buildOrder(direction, isBefore)
+	comparator := ">"
+	tieComparator := ">"
+	if direction == pagination.SortDesc {
+		comparator = "<"
+		tieComparator = "<"
+	}
+	if isBefore {
+		if comparator == ">" {
+			comparator = "<"
+			tieComparator = "<"
+		} else {
+			comparator = ">"
+			tieComparator = ">"
+		}
+	}
+
+	return &cursorWhereClause{
+		sql: fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", orderColumn, comparator, orderColumn, tieComparator),
+		args: []any{
+			value,
+			value,
+			token.ID,
+		},
+	}, order, nil
+}
+
+func buildOrder(direction pagination.SortDirection, reverse bool) orderParts {
+	primary := string(direction)
+	tieBreaker := string(direction)
+	if reverse {
+		if direction == pagination.SortAsc {
+			primary = string(pagination.SortDesc)
+			tieBreaker = string(pagination.SortDesc)
+		} else {
+			primary = string(pagination.SortAsc)
+			tieBreaker = string(pagination.SortAsc)
+		}
+	}
+
+	return orderParts{
+		primary:    primary,
+		tieBreaker: tieBreaker,
+	}
+}
+
+func parseCursorValue(sortField string, raw string) (any, error) {
+	switch sortField {
+	case "created_at":
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
@@ -0,0 +1,125 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// EmailVerificationAdapter implements auth.EmailVerificationAdapter using GORM
+type EmailVerificationAdapter struct {
+	db *gorm.DB
+}
+
+// NewEmailVerificationAdapter creates a new GORM-based email verification adapter
+func NewEmailVerificationAdapter(db *gorm.DB) *EmailVerificationAdapter {
+	return &EmailVerificationAdapter{db: db}
+}
+
+// ReplaceEmailVerificationToken discards the user's pending tokens and stores the given one
+func (a *EmailVerificationAdapter) ReplaceEmailVerificationToken(token *auth.EmailVerificationToken) error {
+	uid, err := strconv.ParseUint(token.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", uid).Delete(&models.EmailVerificationToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.EmailVerificationToken{
+			ID:        token.TokenHash,
+			UserID:    uint(uid),
+			Email:     token.Email,
+			ExpiresAt: token.ExpiresAt,
+			CreatedAt: token.CreatedAt,
+		}).Error
+	})
+}
+
+// FindEmailVerificationTokenByUser returns the user's pending token
+func (a *EmailVerificationAdapter) FindEmailVerificationTokenByUser(userID string) (*auth.EmailVerificationToken, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var record models.EmailVerificationToken
+	if err := a.db.Where("user_id = ?", uid).Order("created_at DESC").First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrEmailVerificationInvalid
+		}
+		return nil, err
+	}
+
+	return toAuthEmailVerificationToken(&record), nil
+}
+
+// ConsumeEmailVerificationToken deletes and returns a pending token. Only the
+// caller whose delete removed the row gets the token.
+func (a *EmailVerificationAdapter) ConsumeEmailVerificationToken(tokenHash string) (*auth.EmailVerificationToken, error) {
+	var record models.EmailVerificationToken
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", tokenHash).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return auth.ErrEmailVerificationInvalid
+			}
+			return err
+		}
+
+		result := tx.Where("id = ?", tokenHash).Delete(&models.EmailVerificationToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrEmailVerificationInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthEmailVerificationToken(&record), nil
+}
+
+// MarkEmailVerified flags the user's email as verified if it still matches
+func (a *EmailVerificationAdapter) MarkEmailVerified(userID, email string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	result := a.db.Model(&models.User{}).
+		Where("id = ? AND email = ?", uid, email).
+		Update("email_verified", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrEmailVerificationInvalid
+	}
+	return nil
+}
+
+// DeleteExpiredEmailVerificationTokens cleans up tokens that were never used
+func (a *EmailVerificationAdapter) DeleteExpiredEmailVerificationTokens() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.EmailVerificationToken{})
+	return result.RowsAffected, result.Error
+}
+
+func toAuthEmailVerificationToken(record *models.EmailVerificationToken) *auth.EmailVerificationToken {
+	return &auth.EmailVerificationToken{
+		TokenHash: record.ID,
+		UserID:    strconv.FormatUint(uint64(record.UserID), 10),
+		Email:     record.Email,
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+	}
+}
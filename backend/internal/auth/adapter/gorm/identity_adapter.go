@@ -0,0 +1,131 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// IdentityAdapter implements auth.IdentityAdapter using GORM
+type IdentityAdapter struct {
+	db *gorm.DB
+}
+
+// NewIdentityAdapter creates a new GORM-based identity adapter
+func NewIdentityAdapter(db *gorm.DB) *IdentityAdapter {
+	return &IdentityAdapter{db: db}
+}
+
+// FindIdentity looks up a linked identity by provider and subject
+func (a *IdentityAdapter) FindIdentity(provider, subject string) (*auth.UserIdentity, error) {
+	var record models.UserIdentity
+	err := a.db.Where("provider = ? AND subject = ?", provider, subject).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return toAuthIdentity(&record), nil
+}
+
+// CreateIdentity links an external identity to a user and sets its ID
+func (a *IdentityAdapter) CreateIdentity(identity *auth.UserIdentity) error {
+	uid, err := strconv.ParseUint(identity.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	record := &models.UserIdentity{
+		UserID:    uint(uid),
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: identity.CreatedAt,
+	}
+	if err := a.db.Create(record).Error; err != nil {
+		return err
+	}
+
+	identity.ID = strconv.FormatUint(uint64(record.ID), 10)
+	return nil
+}
+
+// ListIdentities returns the identities linked to a user
+func (a *IdentityAdapter) ListIdentities(userID string) ([]auth.UserIdentity, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.UserIdentity
+	if err := a.db.Where("user_id = ?", uid).Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	identities := make([]auth.UserIdentity, 0, len(records))
+	for i := range records {
+		identities = append(identities, *toAuthIdentity(&records[i]))
+	}
+	return identities, nil
+}
+
+// SaveOAuthState stores a pending authorization request
+func (a *IdentityAdapter) SaveOAuthState(state *auth.OAuthState) error {
+	return a.db.Create(&models.OAuthState{
+		ID:           state.StateHash,
+		Provider:     state.Provider,
+		CodeVerifier: state.CodeVerifier,
+		Nonce:        state.Nonce,
+		ExpiresAt:    state.ExpiresAt,
+		CreatedAt:    state.CreatedAt,
+	}).Error
+}
+
+// GetOAuthState finds a pending authorization request by state hash
+func (a *IdentityAdapter) GetOAuthState(stateHash string) (*auth.OAuthState, error) {
+	var record models.OAuthState
+	if err := a.db.Where("id = ?", stateHash).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrOAuthStateInvalid
+		}
+		return nil, err
+	}
+
+	return &auth.OAuthState{
+		StateHash:    record.ID,
+		Provider:     record.Provider,
+		CodeVerifier: record.CodeVerifier,
+		Nonce:        record.Nonce,
+		ExpiresAt:    record.ExpiresAt,
+		CreatedAt:    record.CreatedAt,
+	}, nil
+}
+
+// DeleteOAuthState removes a pending authorization request
+func (a *IdentityAdapter) DeleteOAuthState(stateHash string) error {
+	return a.db.Where("id = ?", stateHash).Delete(&models.OAuthState{}).Error
+}
+
+// DeleteExpiredOAuthStates cleans up authorization requests that never came back
+func (a *IdentityAdapter) DeleteExpiredOAuthStates() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.OAuthState{})
+	return result.RowsAffected, result.Error
+}
+
+func toAuthIdentity(record *models.UserIdentity) *auth.UserIdentity {
+	return &auth.UserIdentity{
+		ID:        strconv.FormatUint(uint64(record.ID), 10),
+		UserID:    strconv.FormatUint(uint64(record.UserID), 10),
+		Provider:  record.Provider,
+		Subject:   record.Subject,
+		Email:     record.Email,
+		CreatedAt: record.CreatedAt,
+	}
+}
@@ -0,0 +1,207 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InvitationAdapter implements auth.InvitationAdapter using GORM
+type InvitationAdapter struct {
+	db *gorm.DB
+}
+
+// NewInvitationAdapter creates a new GORM-based invitation adapter
+func NewInvitationAdapter(db *gorm.DB) *InvitationAdapter {
+	return &InvitationAdapter{db: db}
+}
+
+// invitationRow is an invitation joined with its organization and inviter
+type invitationRow struct {
+	models.OrganizationInvitation
+	OrganizationName string
+	InviterName      string
+}
+
+// ReplaceInvitation discards any pending invitation for the same email in the
+// organization and stores the given one
+func (a *InvitationAdapter) ReplaceInvitation(invitation *auth.Invitation) error {
+	oid, err := strconv.ParseUint(invitation.OrganizationID, 10, 64)
+	if err != nil {
+		return auth.ErrOrganizationNotFound
+	}
+	inviterID, err := strconv.ParseUint(invitation.InviterID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organization_id = ? AND email = ?", oid, invitation.Email).
+			Delete(&models.OrganizationInvitation{}).Error; err != nil {
+			return err
+		}
+
+		record := &models.OrganizationInvitation{
+			OrganizationID: uint(oid),
+			Email:          invitation.Email,
+			Role:           invitation.Role,
+			InviterID:      uint(inviterID),
+			TokenHash:      invitation.TokenHash,
+			ExpiresAt:      invitation.ExpiresAt,
+			CreatedAt:      invitation.CreatedAt,
+		}
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+
+		invitation.ID = strconv.FormatUint(uint64(record.ID), 10)
+		return nil
+	})
+}
+
+// GetInvitation finds a pending invitation in an organization
+func (a *InvitationAdapter) GetInvitation(orgID, invitationID string) (*auth.Invitation, error) {
+	oid, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrInvitationNotFound
+	}
+	id, err := strconv.ParseUint(invitationID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrInvitationNotFound
+	}
+
+	return a.findInvitation(auth.ErrInvitationNotFound,
+		"organization_invitations.organization_id = ? AND organization_invitations.id = ?", oid, id)
+}
+
+// GetInvitationByTokenHash finds a pending invitation by the hash of its token
+func (a *InvitationAdapter) GetInvitationByTokenHash(tokenHash string) (*auth.Invitation, error) {
+	return a.findInvitation(auth.ErrInvitationInvalid, "organization_invitations.token_hash = ?", tokenHash)
+}
+
+// ListInvitations returns an organization's pending invitations, newest first
+func (a *InvitationAdapter) ListInvitations(orgID string) ([]auth.Invitation, error) {
+	oid, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrOrganizationNotFound
+	}
+
+	var rows []invitationRow
+	if err := a.invitationQuery().
+		Where("organization_invitations.organization_id = ?", oid).
+		Order("organization_invitations.created_at DESC").
+		Order("organization_invitations.id DESC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	invitations := make([]auth.Invitation, 0, len(rows))
+	for i := range rows {
+		invitations = append(invitations, *toAuthInvitation(&rows[i]))
+	}
+	return invitations, nil
+}
+
+// RenewInvitation gives an invitation a new token and expiry
+func (a *InvitationAdapter) RenewInvitation(invitationID, tokenHash string, expiresAt time.Time) error {
+	result := a.db.Model(&models.OrganizationInvitation{}).
+		Where("id = ?", invitationID).
+		Updates(map[string]any{"token_hash": tokenHash, "expires_at": expiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// DeleteInvitation removes a pending invitation
+func (a *InvitationAdapter) DeleteInvitation(orgID, invitationID string) error {
+	result := a.db.Where("organization_id = ? AND id = ?", orgID, invitationID).
+		Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvitationNotFound
+	}
+	return nil
+}
+
+// ConsumeInvitation deletes and returns a pending invitation. Only the caller
+// whose delete removed the row gets it, so it cannot be accepted twice.
+func (a *InvitationAdapter) ConsumeInvitation(tokenHash string) (*auth.Invitation, error) {
+	var invitation *auth.Invitation
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		var record models.OrganizationInvitation
+		if err := tx.Where("token_hash = ?", tokenHash).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return auth.ErrInvitationInvalid
+			}
+			return err
+		}
+
+		result := tx.Where("id = ?", record.ID).Delete(&models.OrganizationInvitation{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrInvitationInvalid
+		}
+
+		invitation = toAuthInvitation(&invitationRow{OrganizationInvitation: record})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+// DeleteExpiredInvitations cleans up invitations that were never accepted
+func (a *InvitationAdapter) DeleteExpiredInvitations() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.OrganizationInvitation{})
+	return result.RowsAffected, result.Error
+}
+
+func (a *InvitationAdapter) invitationQuery() *gorm.DB {
+	return a.db.Table("organization_invitations").
+		Select(
+			"organization_invitations.*, organizations.name AS organization_name, " +
+				"users.display_name AS inviter_name",
+		).
+		Joins("JOIN organizations ON organizations.id = organization_invitations.organization_id").
+		Joins("LEFT JOIN users ON users.id = organization_invitations.inviter_id")
+}
+
+func (a *InvitationAdapter) findInvitation(notFound error, query string, args ...any) (*auth.Invitation, error) {
+	var rows []invitationRow
+	if err := a.invitationQuery().Where(query, args...).Limit(1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, notFound
+	}
+	return toAuthInvitation(&rows[0]), nil
+}
+
+func toAuthInvitation(row *invitationRow) *auth.Invitation {
+	return &auth.Invitation{
+		ID:               strconv.FormatUint(uint64(row.ID), 10),
+		OrganizationID:   strconv.FormatUint(uint64(row.OrganizationID), 10),
+		OrganizationName: row.OrganizationName,
+		Email:            row.Email,
+		Role:             row.Role,
+		InviterID:        strconv.FormatUint(uint64(row.InviterID), 10),
+		InviterName:      row.InviterName,
+		TokenHash:        row.TokenHash,
+		ExpiresAt:        row.ExpiresAt,
+		CreatedAt:        row.CreatedAt,
+	}
+}
@@ -0,0 +1,155 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// KnownDeviceAdapter implements auth.KnownDeviceAdapter using GORM
+type KnownDeviceAdapter struct {
+	db *gorm.DB
+}
+
+// NewKnownDeviceAdapter creates a new GORM-based known device adapter
+func NewKnownDeviceAdapter(db *gorm.DB) *KnownDeviceAdapter {
+	return &KnownDeviceAdapter{db: db}
+}
+
+// FindKnownDevice finds the user's device with the given user agent family and IP network
+func (a *KnownDeviceAdapter) FindKnownDevice(userID, userAgentFamily, ipNetwork string) (*auth.KnownDevice, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrKnownDeviceNotFound
+	}
+
+	var record models.KnownDevice
+	err = a.db.
+		Where("user_id = ? AND user_agent_family = ? AND ip_network = ?", uid, userAgentFamily, ipNetwork).
+		First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrKnownDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return toAuthKnownDevice(&record), nil
+}
+
+// HasKnownDevices reports whether the user has any device on record
+func (a *KnownDeviceAdapter) HasKnownDevices(userID string) (bool, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	if err := a.db.Model(&models.KnownDevice{}).Where("user_id = ?", uid).Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SaveKnownDevice stores a new device and sets its ID, or updates the device when ID is set
+func (a *KnownDeviceAdapter) SaveKnownDevice(device *auth.KnownDevice) error {
+	uid, err := strconv.ParseUint(device.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	record := models.KnownDevice{
+		UserID:          uint(uid),
+		UserAgentFamily: device.UserAgentFamily,
+		IPNetwork:       device.IPNetwork,
+		LastIP:          device.LastIP,
+		CreatedAt:       device.CreatedAt,
+		LastSeenAt:      device.LastSeenAt,
+		SessionPublicID: device.SessionPublicID,
+		RevokeTokenHash: device.RevokeTokenHash,
+	}
+	if !device.RevokeExpiresAt.IsZero() {
+		record.RevokeExpiresAt = &device.RevokeExpiresAt
+	}
+
+	if device.ID == "" {
+		if err := a.db.Create(&record).Error; err != nil {
+			return err
+		}
+		device.ID = strconv.FormatUint(uint64(record.ID), 10)
+		return nil
+	}
+
+	id, err := strconv.ParseUint(device.ID, 10, 64)
+	if err != nil {
+		return auth.ErrKnownDeviceNotFound
+	}
+	record.ID = uint(id)
+	return a.db.Save(&record).Error
+}
+
+// ConsumeSessionRevokeToken deletes and returns the device a revoke token was
+// issued for. Only the caller whose delete removed the row gets the device.
+func (a *KnownDeviceAdapter) ConsumeSessionRevokeToken(tokenHash string) (*auth.KnownDevice, error) {
+	if tokenHash == "" {
+		return nil, auth.ErrSessionRevokeLinkInvalid
+	}
+
+	var record models.KnownDevice
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("revoke_token_hash = ?", tokenHash).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return auth.ErrSessionRevokeLinkInvalid
+			}
+			return err
+		}
+
+		result := tx.Where("id = ? AND revoke_token_hash = ?", record.ID, tokenHash).Delete(&models.KnownDevice{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrSessionRevokeLinkInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toAuthKnownDevice(&record), nil
+}
+
+// DeleteStaleKnownDevices removes devices last seen before the cutoff. Each
+// user's most recently seen device is kept, so that a user who comes back
+// after a long absence is still told about a sign-in from somewhere else.
+func (a *KnownDeviceAdapter) DeleteStaleKnownDevices(before time.Time) (int64, error) {
+	result := a.db.
+		Where("last_seen_at < ?", before).
+		Where("last_seen_at < (SELECT MAX(latest.last_seen_at) FROM known_devices latest WHERE latest.user_id = known_devices.user_id)").
+		Delete(&models.KnownDevice{})
+	return result.RowsAffected, result.Error
+}
+
+func toAuthKnownDevice(record *models.KnownDevice) *auth.KnownDevice {
+	device := &auth.KnownDevice{
+		ID:              strconv.FormatUint(uint64(record.ID), 10),
+		UserID:          strconv.FormatUint(uint64(record.UserID), 10),
+		UserAgentFamily: record.UserAgentFamily,
+		IPNetwork:       record.IPNetwork,
+		LastIP:          record.LastIP,
+		SessionPublicID: record.SessionPublicID,
+		RevokeTokenHash: record.RevokeTokenHash,
+		CreatedAt:       record.CreatedAt,
+		LastSeenAt:      record.LastSeenAt,
+	}
+	if record.RevokeExpiresAt != nil {
+		device.RevokeExpiresAt = *record.RevokeExpiresAt
+	}
+	return device
+}
@@ -0,0 +1,85 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnownDeviceAdapter_ConsumeSessionRevokeToken(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.KnownDevice{})
+	adapter := NewKnownDeviceAdapter(db)
+
+	now := time.Now()
+	device := &auth.KnownDevice{
+		UserID:          "1",
+		UserAgentFamily: "Firefox/Linux",
+		IPNetwork:       "203.0.113.0/24",
+		LastIP:          "203.0.113.7",
+		SessionPublicID: "session-1",
+		RevokeTokenHash: auth.HashToken("token"),
+		RevokeExpiresAt: now.Add(time.Hour),
+		CreatedAt:       now,
+		LastSeenAt:      now,
+	}
+	require.NoError(t, adapter.SaveKnownDevice(device))
+	require.NotEmpty(t, device.ID)
+
+	found, err := adapter.FindKnownDevice("1", "Firefox/Linux", "203.0.113.0/24")
+	require.NoError(t, err)
+	assert.Equal(t, device.ID, found.ID)
+	_, err = adapter.FindKnownDevice("2", "Firefox/Linux", "203.0.113.0/24")
+	assert.ErrorIs(t, err, auth.ErrKnownDeviceNotFound)
+
+	_, err = adapter.ConsumeSessionRevokeToken(auth.HashToken("other"))
+	assert.ErrorIs(t, err, auth.ErrSessionRevokeLinkInvalid)
+	_, err = adapter.ConsumeSessionRevokeToken("")
+	assert.ErrorIs(t, err, auth.ErrSessionRevokeLinkInvalid)
+
+	consumed, err := adapter.ConsumeSessionRevokeToken(auth.HashToken("token"))
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", consumed.SessionPublicID)
+	assert.WithinDuration(t, now.Add(time.Hour), consumed.RevokeExpiresAt, time.Second)
+
+	// The device is forgotten along with its token
+	_, err = adapter.ConsumeSessionRevokeToken(auth.HashToken("token"))
+	assert.ErrorIs(t, err, auth.ErrSessionRevokeLinkInvalid)
+	hasDevices, err := adapter.HasKnownDevices("1")
+	require.NoError(t, err)
+	assert.False(t, hasDevices)
+}
+
+func TestKnownDeviceAdapter_DeleteStaleKnownDevicesKeepsLatest(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.KnownDevice{})
+	adapter := NewKnownDeviceAdapter(db)
+
+	now := time.Now()
+	save := func(userID, family string, lastSeen time.Time) {
+		require.NoError(t, adapter.SaveKnownDevice(&auth.KnownDevice{
+			UserID:          userID,
+			UserAgentFamily: family,
+			IPNetwork:       "203.0.113.0/24",
+			CreatedAt:       lastSeen,
+			LastSeenAt:      lastSeen,
+		}))
+	}
+	save("1", "Firefox/Linux", now.Add(-200*24*time.Hour))
+	save("1", "Chrome/Windows", now.Add(-100*24*time.Hour))
+	save("1", "Safari/iOS", now)
+	save("2", "Firefox/Linux", now.Add(-200*24*time.Hour))
+	save("2", "Chrome/Windows", now.Add(-100*24*time.Hour))
+
+	deleted, err := adapter.DeleteStaleKnownDevices(now.Add(-90 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	var families []string
+	require.NoError(t, db.Model(&models.KnownDevice{}).Order("user_id, id").Pluck("user_agent_family", &families).Error)
+	assert.Equal(t, []string{"Safari/iOS", "Chrome/Windows"}, families, "each user keeps their latest device")
+}
@@ -0,0 +1,79 @@
+package gorm
+
+import (
+	"errors"
+	"time"
+
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LockoutAdapter implements auth.LockoutAdapter using GORM, so lockouts are
+// shared by every backend replica and survive restarts
+type LockoutAdapter struct {
+	db *gorm.DB
+}
+
+// NewLockoutAdapter creates a new GORM-based lockout adapter
+func NewLockoutAdapter(db *gorm.DB) *LockoutAdapter {
+	return &LockoutAdapter{db: db}
+}
+
+// RecordFailedAttempt counts a failed attempt and locks the identifier once
+// maxAttempts is reached. The counter is updated with a single upsert so
+// concurrent failures from several replicas are all counted.
+func (a *LockoutAdapter) RecordFailedAttempt(identifier string, maxAttempts int, lockoutDuration time.Duration) error {
+	now := time.Now()
+	windowStart := now.Add(-lockoutDuration)
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "identifier"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"failed_attempts": gorm.Expr(
+					"CASE WHEN login_lockouts.last_failed_at < ? THEN 1 ELSE login_lockouts.failed_attempts + 1 END",
+					windowStart,
+				),
+				"last_failed_at": now,
+				"updated_at":     now,
+			}),
+		}).Create(&models.LoginLockout{
+			Identifier:     identifier,
+			FailedAttempts: 1,
+			LastFailedAt:   now,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.LoginLockout{}).
+			Where("identifier = ? AND failed_attempts >= ?", identifier, maxAttempts).
+			Update("locked_until", now.Add(lockoutDuration)).Error
+	})
+}
+
+// IsLocked reports whether the identifier is currently locked
+func (a *LockoutAdapter) IsLocked(identifier string) (bool, error) {
+	var record models.LoginLockout
+	if err := a.db.Where("identifier = ?", identifier).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return time.Now().Before(record.LockedUntil), nil
+}
+
+// ClearFailedAttempts forgets the identifier's failed attempts and lock
+func (a *LockoutAdapter) ClearFailedAttempts(identifier string) error {
+	return a.db.Where("identifier = ?", identifier).Delete(&models.LoginLockout{}).Error
+}
+
+// PurgeLockouts removes records whose last failed attempt is before the cutoff
+func (a *LockoutAdapter) PurgeLockouts(before time.Time) (int64, error) {
+	result := a.db.Where("last_failed_at < ?", before).Delete(&models.LoginLockout{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,80 @@
+package gorm
+
+import (
+	"testing"
+	"time"
+
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutAdapter_LocksAfterMaxAttempts(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.LoginLockout{})
+	adapter := NewLockoutAdapter(db)
+
+	for range 2 {
+		require.NoError(t, adapter.RecordFailedAttempt("alice", 3, time.Minute))
+	}
+	locked, err := adapter.IsLocked("alice")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	require.NoError(t, adapter.RecordFailedAttempt("alice", 3, time.Minute))
+	locked, err = adapter.IsLocked("alice")
+	require.NoError(t, err)
+	assert.True(t, locked)
+
+	// Other identifiers are unaffected
+	locked, err = adapter.IsLocked("bob")
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	require.NoError(t, adapter.ClearFailedAttempts("alice"))
+	locked, err = adapter.IsLocked("alice")
+	require.NoError(t, err)
+	assert.False(t, locked)
+}
+
+func TestLockoutAdapter_OldFailuresDoNotCount(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.LoginLockout{})
+	adapter := NewLockoutAdapter(db)
+
+	for range 2 {
+		require.NoError(t, adapter.RecordFailedAttempt("alice", 3, time.Minute))
+	}
+	require.NoError(t, db.Model(&models.LoginLockout{}).
+		Where("identifier = ?", "alice").
+		Update("last_failed_at", time.Now().Add(-2*time.Minute)).Error)
+
+	require.NoError(t, adapter.RecordFailedAttempt("alice", 3, time.Minute))
+
+	var record models.LoginLockout
+	require.NoError(t, db.First(&record, "identifier = ?", "alice").Error)
+	assert.Equal(t, 1, record.FailedAttempts)
+
+	locked, err := adapter.IsLocked("alice")
+	require.NoError(t, err)
+	assert.False(t, locked)
+}
+
+func TestLockoutAdapter_PurgeLockouts(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.LoginLockout{})
+	adapter := NewLockoutAdapter(db)
+
+	require.NoError(t, adapter.RecordFailedAttempt("stale", 3, time.Minute))
+	require.NoError(t, adapter.RecordFailedAttempt("recent", 3, time.Minute))
+	require.NoError(t, db.Model(&models.LoginLockout{}).
+		Where("identifier = ?", "stale").
+		Update("last_failed_at", time.Now().Add(-time.Hour)).Error)
+
+	removed, err := adapter.PurgeLockouts(time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	var identifiers []string
+	require.NoError(t, db.Model(&models.LoginLockout{}).Pluck("identifier", &identifiers).Error)
+	assert.Equal(t, []string{"recent"}, identifiers)
+}
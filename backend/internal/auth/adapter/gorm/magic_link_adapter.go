@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MagicLinkAdapter implements auth.MagicLinkAdapter using GORM
+type MagicLinkAdapter struct {
+	db *gorm.DB
+}
+
+// NewMagicLinkAdapter creates a new GORM-based magic link adapter
+func NewMagicLinkAdapter(db *gorm.DB) *MagicLinkAdapter {
+	return &MagicLinkAdapter{db: db}
+}
+
+// ReplaceMagicLinkToken discards the user's pending links and stores the given one
+func (a *MagicLinkAdapter) ReplaceMagicLinkToken(token *auth.MagicLinkToken) error {
+	uid, err := strconv.ParseUint(token.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", uid).Delete(&models.MagicLinkToken{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.MagicLinkToken{
+			ID:        token.TokenHash,
+			UserID:    uint(uid),
+			ExpiresAt: token.ExpiresAt,
+			CreatedAt: token.CreatedAt,
+		}).Error
+	})
+}
+
+// ConsumeMagicLinkToken deletes and returns a pending link. Only the caller
+// whose delete removed the row gets the link, so it cannot be redeemed twice.
+func (a *MagicLinkAdapter) ConsumeMagicLinkToken(tokenHash string) (*auth.MagicLinkToken, error) {
+	var record models.MagicLinkToken
+	err := a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", tokenHash).First(&record).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return auth.ErrMagicLinkInvalid
+			}
+			return err
+		}
+
+		result := tx.Where("id = ?", tokenHash).Delete(&models.MagicLinkToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrMagicLinkInvalid
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.MagicLinkToken{
+		TokenHash: record.ID,
+		UserID:    strconv.FormatUint(uint64(record.UserID), 10),
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+	}, nil
+}
+
+// DeleteExpiredMagicLinkTokens cleans up links that were never used
+func (a *MagicLinkAdapter) DeleteExpiredMagicLinkTokens() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.MagicLinkToken{})
+	return result.RowsAffected, result.Error
+}
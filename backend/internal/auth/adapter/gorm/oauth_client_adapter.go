@@ -0,0 +1,130 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OAuthClientAdapter implements auth.OAuthClientAdapter using GORM
+type OAuthClientAdapter struct {
+	db *gorm.DB
+}
+
+// NewOAuthClientAdapter creates a new GORM-based OAuth client adapter
+func NewOAuthClientAdapter(db *gorm.DB) *OAuthClientAdapter {
+	return &OAuthClientAdapter{db: db}
+}
+
+// CreateOAuthClient stores a new client
+func (a *OAuthClientAdapter) CreateOAuthClient(client *auth.OAuthClient) error {
+	return a.db.Create(&models.OAuthClient{
+		ClientID:   client.ID,
+		Name:       client.Name,
+		SecretHash: client.SecretHash,
+		Role:       client.Role,
+		Scopes:     strings.Join(client.Scopes, " "),
+		CreatedAt:  client.CreatedAt,
+	}).Error
+}
+
+// GetOAuthClient finds a client by its client ID
+func (a *OAuthClientAdapter) GetOAuthClient(clientID string) (*auth.OAuthClient, error) {
+	var record models.OAuthClient
+	if err := a.db.Where("client_id = ?", clientID).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrOAuthClientNotFound
+		}
+		return nil, err
+	}
+
+	return toAuthOAuthClient(&record), nil
+}
+
+// ListOAuthClients returns every client, newest first
+func (a *OAuthClientAdapter) ListOAuthClients() ([]auth.OAuthClient, error) {
+	var records []models.OAuthClient
+	if err := a.db.Order("created_at DESC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	clients := make([]auth.OAuthClient, 0, len(records))
+	for i := range records {
+		clients = append(clients, *toAuthOAuthClient(&records[i]))
+	}
+	return clients, nil
+}
+
+// DeleteOAuthClient removes a client and its tokens
+func (a *OAuthClientAdapter) DeleteOAuthClient(clientID string) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("client_id = ?", clientID).Delete(&models.OAuthClient{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrOAuthClientNotFound
+		}
+		return tx.Where("client_id = ?", clientID).Delete(&models.OAuthClientToken{}).Error
+	})
+}
+
+// CreateClientAccessToken stores a new token and sets its ID
+func (a *OAuthClientAdapter) CreateClientAccessToken(token *auth.ClientAccessToken) error {
+	record := &models.OAuthClientToken{
+		ClientID:  token.ClientID,
+		TokenHash: token.TokenHash,
+		Scopes:    strings.Join(token.Scopes, " "),
+		ExpiresAt: token.ExpiresAt,
+		CreatedAt: token.CreatedAt,
+	}
+	if err := a.db.Create(record).Error; err != nil {
+		return err
+	}
+
+	token.ID = strconv.FormatUint(uint64(record.ID), 10)
+	return nil
+}
+
+// GetClientAccessTokenByHash finds a token by the hash of its value
+func (a *OAuthClientAdapter) GetClientAccessTokenByHash(tokenHash string) (*auth.ClientAccessToken, error) {
+	var record models.OAuthClientToken
+	if err := a.db.Where("token_hash = ?", tokenHash).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrClientAccessTokenNotFound
+		}
+		return nil, err
+	}
+
+	return &auth.ClientAccessToken{
+		ID:        strconv.FormatUint(uint64(record.ID), 10),
+		ClientID:  record.ClientID,
+		TokenHash: record.TokenHash,
+		Scopes:    strings.Fields(record.Scopes),
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+	}, nil
+}
+
+// DeleteExpiredClientAccessTokens cleans up tokens past their expiry
+func (a *OAuthClientAdapter) DeleteExpiredClientAccessTokens() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.OAuthClientToken{})
+	return result.RowsAffected, result.Error
+}
+
+func toAuthOAuthClient(record *models.OAuthClient) *auth.OAuthClient {
+	return &auth.OAuthClient{
+		ID:         record.ClientID,
+		Name:       record.Name,
+		SecretHash: record.SecretHash,
+		Role:       record.Role,
+		Scopes:     strings.Fields(record.Scopes),
+		CreatedAt:  record.CreatedAt,
+	}
+}
@@ -0,0 +1,247 @@
+package gorm
+
+import (
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// OrganizationAdapter implements auth.OrganizationAdapter using GORM
+type OrganizationAdapter struct {
+	db *gorm.DB
+}
+
+// NewOrganizationAdapter creates a new GORM-based organization adapter
+func NewOrganizationAdapter(db *gorm.DB) *OrganizationAdapter {
+	return &OrganizationAdapter{db: db}
+}
+
+// organizationMemberRow is a membership joined with its user and organization
+type organizationMemberRow struct {
+	OrganizationID   uint
+	OrganizationName string
+	UserID           uint
+	Username         string
+	DisplayName      string
+	Email            string
+	Role             string
+	CreatedAt        time.Time
+}
+
+// CreateOrganization stores a new organization and its owner's membership
+func (a *OrganizationAdapter) CreateOrganization(org *auth.Organization, ownerID string) error {
+	uid, err := strconv.ParseUint(ownerID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		record := &models.Organization{Name: org.Name, CreatedAt: org.CreatedAt}
+		if err := tx.Create(record).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.OrganizationMembership{
+			OrganizationID: record.ID,
+			UserID:         uint(uid),
+			Role:           auth.OrganizationRoleOwner,
+			CreatedAt:      org.CreatedAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		org.ID = strconv.FormatUint(uint64(record.ID), 10)
+		return nil
+	})
+}
+
+// DeleteOrganization removes an organization, its memberships and pending
+// invitations, and clears it as active in every session
+func (a *OrganizationAdapter) DeleteOrganization(orgID string) error {
+	id, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return auth.ErrOrganizationNotFound
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Session{}).
+			Where("active_organization_id = ?", id).
+			Update("active_organization_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&models.OrganizationMembership{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("organization_id = ?", id).Delete(&models.OrganizationInvitation{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.Organization{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrOrganizationNotFound
+		}
+		return nil
+	})
+}
+
+// GetOrganizationMember finds a user's membership in an organization
+func (a *OrganizationAdapter) GetOrganizationMember(orgID, userID string) (*auth.OrganizationMember, error) {
+	oid, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrOrganizationMemberNotFound
+	}
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrOrganizationMemberNotFound
+	}
+
+	var rows []organizationMemberRow
+	if err := a.memberQuery().
+		Where("organization_memberships.organization_id = ? AND organization_memberships.user_id = ?", oid, uid).
+		Limit(1).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, auth.ErrOrganizationMemberNotFound
+	}
+
+	return toAuthOrganizationMember(&rows[0]), nil
+}
+
+// ListUserOrganizations returns the user's memberships, oldest first
+func (a *OrganizationAdapter) ListUserOrganizations(userID string) ([]auth.OrganizationMember, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return a.listMembers(a.memberQuery().Where("organization_memberships.user_id = ?", uid))
+}
+
+// ListOrganizationMembers returns an organization's members, oldest first
+func (a *OrganizationAdapter) ListOrganizationMembers(orgID string) ([]auth.OrganizationMember, error) {
+	oid, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return nil, auth.ErrOrganizationNotFound
+	}
+	return a.listMembers(a.memberQuery().Where("organization_memberships.organization_id = ?", oid))
+}
+
+// AddOrganizationMember adds a user to an organization
+func (a *OrganizationAdapter) AddOrganizationMember(orgID, userID, role string) error {
+	oid, err := strconv.ParseUint(orgID, 10, 64)
+	if err != nil {
+		return auth.ErrOrganizationNotFound
+	}
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.OrganizationMembership{}).
+			Where("organization_id = ? AND user_id = ?", oid, uid).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return auth.ErrAlreadyOrganizationMember
+		}
+
+		return tx.Create(&models.OrganizationMembership{
+			OrganizationID: uint(oid),
+			UserID:         uint(uid),
+			Role:           role,
+			CreatedAt:      time.Now(),
+		}).Error
+	})
+}
+
+// UpdateOrganizationMemberRole changes a member's role
+func (a *OrganizationAdapter) UpdateOrganizationMemberRole(orgID, userID, role string) error {
+	result := a.db.Model(&models.OrganizationMembership{}).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrOrganizationMemberNotFound
+	}
+	return nil
+}
+
+// RemoveOrganizationMember removes a member and clears the organization as
+// active in their sessions
+func (a *OrganizationAdapter) RemoveOrganizationMember(orgID, userID string) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&models.OrganizationMembership{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return auth.ErrOrganizationMemberNotFound
+		}
+
+		return tx.Model(&models.Session{}).
+			Where("user_id = ? AND active_organization_id = ?", userID, orgID).
+			Update("active_organization_id", nil).Error
+	})
+}
+
+// CountOrganizationOwners returns how many owners the organization has
+func (a *OrganizationAdapter) CountOrganizationOwners(orgID string) (int64, error) {
+	var count int64
+	err := a.db.Model(&models.OrganizationMembership{}).
+		Where("organization_id = ? AND role = ?", orgID, auth.OrganizationRoleOwner).
+		Count(&count).Error
+	return count, err
+}
+
+func (a *OrganizationAdapter) memberQuery() *gorm.DB {
+	return a.db.Table("organization_memberships").
+		Select(
+			"organization_memberships.organization_id, organizations.name AS organization_name, " +
+				"organization_memberships.user_id, users.username, users.display_name, users.email, " +
+				"organization_memberships.role, organization_memberships.created_at",
+		).
+		Joins("JOIN organizations ON organizations.id = organization_memberships.organization_id").
+		Joins("JOIN users ON users.id = organization_memberships.user_id AND users.deleted_at IS NULL")
+}
+
+func (a *OrganizationAdapter) listMembers(query *gorm.DB) ([]auth.OrganizationMember, error) {
+	var rows []organizationMemberRow
+	if err := query.
+		Order("organization_memberships.created_at ASC").
+		Order("organization_memberships.id ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	members := make([]auth.OrganizationMember, 0, len(rows))
+	for i := range rows {
+		members = append(members, *toAuthOrganizationMember(&rows[i]))
+	}
+	return members, nil
+}
+
+func toAuthOrganizationMember(row *organizationMemberRow) *auth.OrganizationMember {
+	return &auth.OrganizationMember{
+		OrganizationID:   strconv.FormatUint(uint64(row.OrganizationID), 10),
+		OrganizationName: row.OrganizationName,
+		UserID:           strconv.FormatUint(uint64(row.UserID), 10),
+		Identifier:       row.Username,
+		DisplayName:      row.DisplayName,
+		Email:            row.Email,
+		Role:             row.Role,
+		JoinedAt:         row.CreatedAt,
+	}
+}
@@ -0,0 +1,119 @@
+package gorm
+
+import (
+	"strings"
+	"time"
+
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/pagination"
+
+	"gorm.io/gorm"
+)
+
+var adminOrganizationsSortColumns = map[string]string{
+	"created_at": "created_at",
+	"name":       "name",
+}
+
+// AdminOrganization is an organization with its member count, as listed in
+// administrative tables.
+type AdminOrganization struct {
+	models.Organization
+	MemberCount int64
+}
+
+type OrganizationCursorPageResult struct {
+	Organizations []*AdminOrganization
+	NextCursor    *string
+	PrevCursor    *string
+	HasNext       bool
+	HasPrev       bool
+}
+
+// ListOrganizationsOffset returns offset-based paginated organizations for administrative tables.
+func (a *OrganizationAdapter) ListOrganizationsOffset(
+	input pagination.OffsetQuery,
+) ([]*AdminOrganization, int64, error) {
+	query := a.adminOrganizationsQuery(input.Search)
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orderColumn, ok := adminOrganizationsSortColumns[input.Sort]
+	if !ok {
+		orderColumn = "created_at"
+	}
+
+	var organizations []*AdminOrganization
+	if err := query.
+		Select(adminOrganizationsSelect).
+		Order(orderColumn + " " + string(input.Order)).
+		Limit(input.PageSize).
+		Offset((input.Page - 1) * input.PageSize).
+		Find(&organizations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return organizations, totalItems, nil
+}
+
+// ListOrganizationsCursor returns cursor-based paginated organizations for administrative tables.
+func (a *OrganizationAdapter) ListOrganizationsCursor(input pagination.CursorQuery) (*OrganizationCursorPageResult, error) {
+	query := a.adminOrganizationsQuery(input.Search).Select(adminOrganizationsSelect)
+
+	orderColumn, ok := adminOrganizationsSortColumns[input.Sort]
+	if !ok {
+		orderColumn = "created_at"
+	}
+
+	page, err := listCursorPage(query, orderColumn, input, encodeOrganizationCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrganizationCursorPageResult{
+		Organizations: page.items,
+		NextCursor:    page.nextCursor,
+		PrevCursor:    page.prevCursor,
+		HasNext:       page.hasNext,
+		HasPrev:       page.hasPrev,
+	}, nil
+}
+
+const adminOrganizationsSelect = "organizations.*, " +
+	"(SELECT COUNT(*) FROM organization_memberships WHERE organization_memberships.organization_id = organizations.id) AS member_count"
+
+func (a *OrganizationAdapter) adminOrganizationsQuery(search string) *gorm.DB {
+	query := a.db.Model(&models.Organization{})
+
+	search = strings.TrimSpace(search)
+	if search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+	return query
+}
+
+func encodeOrganizationCursor(
+	organization *AdminOrganization,
+	sortField string,
+	direction pagination.SortDirection,
+) (string, error) {
+	value := ""
+	switch sortField {
+	case "created_at":
+		value = organization.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "name":
+		value = organization.Name
+	default:
+		return "", pagination.ErrInvalidCursor
+	}
+
+	return pagination.EncodeCursor(pagination.CursorToken{
+		Sort:      sortField,
+		Direction: direction,
+		Value:     value,
+		ID:        organization.ID,
+	})
+}
@@ -0,0 +1,197 @@
+package gorm
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasskeyAdapter implements auth.CredentialAdapter using GORM
+type PasskeyAdapter struct {
+	db *gorm.DB
+}
+
+// NewPasskeyAdapter creates a new GORM-based passkey adapter
+func NewPasskeyAdapter(db *gorm.DB) *PasskeyAdapter {
+	return &PasskeyAdapter{db: db}
+}
+
+// ListPasskeys returns all passkeys registered by a user
+func (a *PasskeyAdapter) ListPasskeys(userID string) ([]auth.PasskeyCredential, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []models.WebAuthnCredential
+	if err := a.db.Where("user_id = ?", uid).Order("created_at ASC").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	passkeys := make([]auth.PasskeyCredential, 0, len(records))
+	for i := range records {
+		passkey, err := a.toAuthPasskey(&records[i])
+		if err != nil {
+			return nil, err
+		}
+		passkeys = append(passkeys, *passkey)
+	}
+	return passkeys, nil
+}
+
+// GetPasskeyByCredentialID finds a passkey by its raw credential ID
+func (a *PasskeyAdapter) GetPasskeyByCredentialID(credentialID []byte) (*auth.PasskeyCredential, error) {
+	var record models.WebAuthnCredential
+	err := a.db.Where("credential_id = ?", encodeCredentialID(credentialID)).First(&record).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrPasskeyNotFound
+		}
+		return nil, err
+	}
+
+	return a.toAuthPasskey(&record)
+}
+
+// CreatePasskey stores a newly registered passkey and sets its ID
+func (a *PasskeyAdapter) CreatePasskey(credential *auth.PasskeyCredential) error {
+	uid, err := strconv.ParseUint(credential.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	record := &models.WebAuthnCredential{
+		UserID:       uint(uid),
+		CredentialID: encodeCredentialID(credential.CredentialID),
+		Name:         credential.Name,
+		Data:         string(credential.Data),
+		CreatedAt:    credential.CreatedAt,
+	}
+	if err := a.db.Create(record).Error; err != nil {
+		return err
+	}
+
+	credential.ID = strconv.FormatUint(uint64(record.ID), 10)
+	return nil
+}
+
+// UpdatePasskeyUsage stores the refreshed credential record after a login
+func (a *PasskeyAdapter) UpdatePasskeyUsage(id string, data []byte, lastUsedAt time.Time) error {
+	pid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Model(&models.WebAuthnCredential{}).
+		Where("id = ?", pid).
+		Updates(map[string]any{
+			"data":         string(data),
+			"last_used_at": lastUsedAt,
+		}).Error
+}
+
+// DeletePasskey removes a passkey owned by the user
+func (a *PasskeyAdapter) DeletePasskey(userID, id string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return auth.ErrPasskeyNotFound
+	}
+
+	result := a.db.Where("id = ? AND user_id = ?", pid, uid).Delete(&models.WebAuthnCredential{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrPasskeyNotFound
+	}
+	return nil
+}
+
+// SaveCeremony stores a pending registration or login ceremony
+func (a *PasskeyAdapter) SaveCeremony(ceremony *auth.WebAuthnCeremony) error {
+	record := &models.WebAuthnCeremony{
+		ID:        ceremony.TokenHash,
+		Kind:      ceremony.Kind,
+		Data:      string(ceremony.Data),
+		ExpiresAt: ceremony.ExpiresAt,
+		CreatedAt: ceremony.CreatedAt,
+	}
+	if ceremony.UserID != "" {
+		uid, err := strconv.ParseUint(ceremony.UserID, 10, 64)
+		if err != nil {
+			return err
+		}
+		userID := uint(uid)
+		record.UserID = &userID
+	}
+
+	return a.db.Create(record).Error
+}
+
+// GetCeremony finds a pending ceremony by its token hash
+func (a *PasskeyAdapter) GetCeremony(tokenHash string) (*auth.WebAuthnCeremony, error) {
+	var record models.WebAuthnCeremony
+	if err := a.db.Where("id = ?", tokenHash).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrCeremonyNotFound
+		}
+		return nil, err
+	}
+
+	ceremony := &auth.WebAuthnCeremony{
+		TokenHash: record.ID,
+		Kind:      record.Kind,
+		Data:      []byte(record.Data),
+		ExpiresAt: record.ExpiresAt,
+		CreatedAt: record.CreatedAt,
+	}
+	if record.UserID != nil {
+		ceremony.UserID = strconv.FormatUint(uint64(*record.UserID), 10)
+	}
+	return ceremony, nil
+}
+
+// DeleteCeremony removes a ceremony
+func (a *PasskeyAdapter) DeleteCeremony(tokenHash string) error {
+	return a.db.Where("id = ?", tokenHash).Delete(&models.WebAuthnCeremony{}).Error
+}
+
+// DeleteExpiredCeremonies cleans up ceremonies that were never finished
+func (a *PasskeyAdapter) DeleteExpiredCeremonies() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.WebAuthnCeremony{})
+	return result.RowsAffected, result.Error
+}
+
+func (a *PasskeyAdapter) toAuthPasskey(record *models.WebAuthnCredential) (*auth.PasskeyCredential, error) {
+	credentialID, err := base64.RawURLEncoding.DecodeString(record.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey := &auth.PasskeyCredential{
+		ID:           strconv.FormatUint(uint64(record.ID), 10),
+		UserID:       strconv.FormatUint(uint64(record.UserID), 10),
+		CredentialID: credentialID,
+		Name:         record.Name,
+		Data:         []byte(record.Data),
+		CreatedAt:    record.CreatedAt,
+	}
+	if record.LastUsedAt != nil {
+		passkey.LastUsedAt = *record.LastUsedAt
+	}
+	return passkey, nil
+}
+
+func encodeCredentialID(credentialID []byte) string {
+	return base64.RawURLEncoding.EncodeToString(credentialID)
+}
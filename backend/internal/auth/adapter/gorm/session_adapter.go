@@ -3,6 +3,7 @@ package gorm
 import (
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"gosveltekit/internal/auth"
@@ -11,7 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
-// SessionAdapter implements auth.SessionAdapter using GORM
+// SessionAdapter implements auth.SessionAdapter using GORM. Only the SHA-256
+// of each session token is stored; sessions are shown to their owner under a
+// random public ID that cannot be used to authenticate.
 type SessionAdapter struct {
 	db *gorm.DB
 }
@@ -29,49 +32,180 @@ func (a *SessionAdapter) CreateSession(userID string, expiresAt time.Time, metad
 		return nil, err
 	}
 
-	// Generate session ID
-	sessionID, err := auth.GenerateSessionID()
+	// Generate session token and its public ID
+	token, err := auth.GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+	publicID, err := auth.GeneratePublicSessionID()
 	if err != nil {
 		return nil, err
 	}
 
+	now := time.Now()
 	session := &models.Session{
-		ID:        sessionID,
-		UserID:    uint(uid),
-		ExpiresAt: expiresAt,
-		CreatedAt: time.Now(),
-		UserAgent: metadata.UserAgent,
-		IP:        metadata.IP,
+		ID:         auth.HashToken(token),
+		PublicID:   publicID,
+		UserID:     uint(uid),
+		ExpiresAt:  expiresAt,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		UserAgent:  metadata.UserAgent,
+		IP:         metadata.IP,
+
+		ImpersonationReason: metadata.ImpersonationReason,
+	}
+	if metadata.ImpersonatorID != "" {
+		impersonatorID, err := strconv.ParseUint(metadata.ImpersonatorID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		id := uint(impersonatorID)
+		session.ImpersonatorID = &id
 	}
 
 	if err := a.db.Create(session).Error; err != nil {
 		return nil, err
 	}
 
-	return a.toAuthSession(session), nil
+	return a.toAuthSession(session, token), nil
 }
 
-// GetSession retrieves a session by ID
+// GetSession retrieves a session by its token, or by the token it replaced
+// while that is within its grace period
 func (a *SessionAdapter) GetSession(sessionID string) (*auth.Session, error) {
+	hash := auth.HashToken(sessionID)
+
 	var session models.Session
-	if err := a.db.Where("id = ?", sessionID).First(&session).Error; err != nil {
+	err := a.db.
+		Where("id = ?", hash).
+		Or("previous_id = ? AND previous_expires_at > ?", hash, time.Now()).
+		First(&session).Error
+	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, auth.ErrSessionNotFound
 		}
 		return nil, err
 	}
 
-	return a.toAuthSession(&session), nil
+	result := a.toAuthSession(&session, sessionID)
+	result.Superseded = session.ID != hash
+	return result, nil
 }
 
 // UpdateSessionExpiry updates the expiration time of a session
 func (a *SessionAdapter) UpdateSessionExpiry(sessionID string, expiresAt time.Time) error {
-	return a.db.Model(&models.Session{}).Where("id = ?", sessionID).Update("expires_at", expiresAt).Error
+	return a.db.Model(&models.Session{}).Where("id = ?", auth.HashToken(sessionID)).Update("expires_at", expiresAt).Error
+}
+
+// TouchSession records when the session was last used
+func (a *SessionAdapter) TouchSession(sessionID string, lastSeenAt time.Time) error {
+	return a.db.Model(&models.Session{}).Where("id = ?", auth.HashToken(sessionID)).Update("last_seen_at", lastSeenAt).Error
+}
+
+// SetReportedAnomalies replaces the anomalies already reported for the
+// session if nobody changed them since previous was read
+func (a *SessionAdapter) SetReportedAnomalies(sessionID string, previous, reported []string) (bool, error) {
+	result := a.db.Model(&models.Session{}).
+		Where("id = ? AND reported_anomalies = ?", auth.HashToken(sessionID), strings.Join(previous, " ")).
+		Update("reported_anomalies", strings.Join(reported, " "))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RotateSession replaces the session's token, remembering the old one for
+// the grace period, if any
+func (a *SessionAdapter) RotateSession(sessionID string, grace time.Duration) (*auth.Session, error) {
+	token, err := auth.GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := auth.HashToken(sessionID)
+	updates := map[string]any{
+		"id":                  auth.HashToken(token),
+		"previous_id":         nil,
+		"previous_expires_at": nil,
+		"rotation_pending":    false,
+	}
+	if grace > 0 {
+		updates["previous_id"] = hash
+		updates["previous_expires_at"] = time.Now().Add(grace)
+	}
+
+	result := a.db.Model(&models.Session{}).Where("id = ?", hash).Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, auth.ErrSessionNotFound
+	}
+
+	return a.GetSession(token)
+}
+
+// RequireUserSessionsRotation marks every session of the user to be rotated
+// on its next use
+func (a *SessionAdapter) RequireUserSessionsRotation(userID string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return a.db.Model(&models.Session{}).Where("user_id = ?", uid).Update("rotation_pending", true).Error
+}
+
+// MarkSessionReauthenticated records when the user last confirmed their
+// password within the session
+func (a *SessionAdapter) MarkSessionReauthenticated(sessionID string, at time.Time) error {
+	result := a.db.Model(&models.Session{}).Where("id = ?", auth.HashToken(sessionID)).Update("reauthenticated_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrSessionNotFound
+	}
+	return nil
 }
 
-// DeleteSession removes a session
+// SetActiveOrganization records the organization the user works in within
+// the session; an empty organizationID clears it
+func (a *SessionAdapter) SetActiveOrganization(sessionID, organizationID string) error {
+	var value *uint
+	if organizationID != "" {
+		id, err := strconv.ParseUint(organizationID, 10, 64)
+		if err != nil {
+			return auth.ErrOrganizationNotFound
+		}
+		orgID := uint(id)
+		value = &orgID
+	}
+
+	result := a.db.Model(&models.Session{}).Where("id = ?", auth.HashToken(sessionID)).Update("active_organization_id", value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrSessionNotFound
+	}
+	return nil
+}
+
+// DeleteSession removes a session, also when given the token it replaced
 func (a *SessionAdapter) DeleteSession(sessionID string) error {
-	return a.db.Where("id = ?", sessionID).Delete(&models.Session{}).Error
+	hash := auth.HashToken(sessionID)
+	return a.db.Where("id = ? OR previous_id = ?", hash, hash).Delete(&models.Session{}).Error
+}
+
+// DeleteOtherUserSessions removes every session of the user except the one
+// whose token is keepSessionID
+func (a *SessionAdapter) DeleteOtherUserSessions(userID, keepSessionID string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return a.db.Where("user_id = ? AND id <> ?", uid, auth.HashToken(keepSessionID)).Delete(&models.Session{}).Error
 }
 
 // DeleteUserSessions removes all sessions for a user
@@ -83,12 +217,25 @@ func (a *SessionAdapter) DeleteUserSessions(userID string) error {
 	return a.db.Where("user_id = ?", uid).Delete(&models.Session{}).Error
 }
 
-// DeleteExpiredSessions cleans up expired sessions
-func (a *SessionAdapter) DeleteExpiredSessions() error {
-	return a.db.Where("expires_at < ?", time.Now()).Delete(&models.Session{}).Error
+// DeleteExpiredSessions removes sessions past their expiry, sessions not seen
+// since idleBefore and sessions created before createdBefore. A zero cutoff
+// skips that check.
+func (a *SessionAdapter) DeleteExpiredSessions(idleBefore, createdBefore time.Time) (int64, error) {
+	query := a.db.Where("expires_at < ?", time.Now())
+	if !idleBefore.IsZero() {
+		// Sessions that were never touched count as last seen when created
+		query = query.Or("last_seen_at < ? AND created_at < ?", idleBefore, idleBefore)
+	}
+	if !createdBefore.IsZero() {
+		query = query.Or("created_at < ?", createdBefore)
+	}
+
+	result := query.Delete(&models.Session{})
+	return result.RowsAffected, result.Error
 }
 
 // ListUserSessions returns all sessions for a user ordered by most recent.
+// Their tokens are not known, so only PublicID identifies them.
 func (a *SessionAdapter) ListUserSessions(userID string) ([]*auth.Session, error) {
 	uid, err := strconv.ParseUint(userID, 10, 64)
 	if err != nil {
@@ -102,37 +249,54 @@ func (a *SessionAdapter) ListUserSessions(userID string) ([]*auth.Session, error
 
 	result := make([]*auth.Session, 0, len(sessions))
 	for i := range sessions {
-		result = append(result, a.toAuthSession(&sessions[i]))
+		result = append(result, a.toAuthSession(&sessions[i], ""))
 	}
 
 	return result, nil
 }
 
-// GetUserSession returns one session that belongs to a specific user.
-func (a *SessionAdapter) GetUserSession(sessionID, userID string) (*auth.Session, error) {
+// DeleteUserSession removes the session with the given public ID if it
+// belongs to the user
+func (a *SessionAdapter) DeleteUserSession(publicID, userID string) error {
 	uid, err := strconv.ParseUint(userID, 10, 64)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var session models.Session
-	if err := a.db.Where("id = ? AND user_id = ?", sessionID, uid).First(&session).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, auth.ErrSessionNotFound
-		}
-		return nil, err
+	result := a.db.Where("public_id = ? AND user_id = ?", publicID, uid).Delete(&models.Session{})
+	if result.Error != nil {
+		return result.Error
 	}
-
-	return a.toAuthSession(&session), nil
+	if result.RowsAffected == 0 {
+		return auth.ErrSessionNotFound
+	}
+	return nil
 }
 
-func (a *SessionAdapter) toAuthSession(session *models.Session) *auth.Session {
-	return &auth.Session{
-		ID:        session.ID,
-		UserID:    strconv.FormatUint(uint64(session.UserID), 10),
-		ExpiresAt: session.ExpiresAt,
-		CreatedAt: session.CreatedAt,
-		UserAgent: session.UserAgent,
-		IP:        session.IP,
+func (a *SessionAdapter) toAuthSession(session *models.Session, token string) *auth.Session {
+	result := &auth.Session{
+		ID:              token,
+		PublicID:        session.PublicID,
+		UserID:          strconv.FormatUint(uint64(session.UserID), 10),
+		ExpiresAt:       session.ExpiresAt,
+		CreatedAt:       session.CreatedAt,
+		LastSeenAt:      session.LastSeenAt,
+		UserAgent:       session.UserAgent,
+		IP:              session.IP,
+		RotationPending: session.RotationPending,
+
+		ReportedAnomalies: strings.Fields(session.ReportedAnomalies),
+
+		ImpersonationReason: session.ImpersonationReason,
+	}
+	if session.ReauthenticatedAt != nil {
+		result.ReauthenticatedAt = *session.ReauthenticatedAt
+	}
+	if session.ImpersonatorID != nil {
+		result.ImpersonatorID = strconv.FormatUint(uint64(*session.ImpersonatorID), 10)
+	}
+	if session.ActiveOrganizationID != nil {
+		result.ActiveOrganizationID = strconv.FormatUint(uint64(*session.ActiveOrganizationID), 10)
 	}
+	return result
 }
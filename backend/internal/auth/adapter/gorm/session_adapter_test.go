@@ -0,0 +1,107 @@
+package gorm
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionAdapter_StoresOnlyTokenHash(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	adapter := NewSessionAdapter(db)
+
+	user := &models.User{Username: "owner", Email: "owner@example.com", PasswordHash: "hash", Active: true}
+	require.NoError(t, db.Create(user).Error)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	session, err := adapter.CreateSession(userID, time.Now().Add(time.Hour), auth.SessionMetadata{})
+	require.NoError(t, err)
+	require.NotEmpty(t, session.ID)
+	require.NotEmpty(t, session.PublicID)
+
+	var stored models.Session
+	require.NoError(t, db.First(&stored).Error)
+	assert.Equal(t, auth.HashToken(session.ID), stored.ID)
+	assert.NotEqual(t, session.ID, stored.PublicID)
+
+	found, err := adapter.GetSession(session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, session.PublicID, found.PublicID)
+
+	// Neither the stored hash nor the public ID authenticate
+	_, err = adapter.GetSession(stored.ID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	_, err = adapter.GetSession(session.PublicID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+
+	listed, err := adapter.ListUserSessions(userID)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Empty(t, listed[0].ID)
+	assert.Equal(t, session.PublicID, listed[0].PublicID)
+}
+
+func TestSessionAdapter_DeleteUserSessionByPublicID(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	adapter := NewSessionAdapter(db)
+
+	owner := &models.User{Username: "owner", Email: "owner@example.com", PasswordHash: "hash", Active: true}
+	other := &models.User{Username: "other", Email: "other@example.com", PasswordHash: "hash", Active: true}
+	require.NoError(t, db.Create(owner).Error)
+	require.NoError(t, db.Create(other).Error)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	otherID := strconv.FormatUint(uint64(other.ID), 10)
+
+	session, err := adapter.CreateSession(ownerID, time.Now().Add(time.Hour), auth.SessionMetadata{})
+	require.NoError(t, err)
+
+	// Only the owner can delete it
+	assert.ErrorIs(t, adapter.DeleteUserSession(session.PublicID, otherID), auth.ErrSessionNotFound)
+	require.NoError(t, adapter.DeleteUserSession(session.PublicID, ownerID))
+
+	_, err = adapter.GetSession(session.ID)
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+	assert.ErrorIs(t, adapter.DeleteUserSession(session.PublicID, ownerID), auth.ErrSessionNotFound)
+}
+
+func TestSessionAdapter_DeleteExpiredSessions(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	adapter := NewSessionAdapter(db)
+
+	user := &models.User{Username: "owner", Email: "owner@example.com", PasswordHash: "hash", Active: true}
+	require.NoError(t, db.Create(user).Error)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	now := time.Now()
+	create := func(name string, expiresAt, createdAt, lastSeenAt time.Time) {
+		session, err := adapter.CreateSession(userID, expiresAt, auth.SessionMetadata{UserAgent: name})
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.Session{}).
+			Where("id = ?", auth.HashToken(session.ID)).
+			UpdateColumns(map[string]any{"created_at": createdAt, "last_seen_at": lastSeenAt}).Error)
+	}
+	create("active", now.Add(time.Hour), now.Add(-time.Hour), now)
+	create("expired", now.Add(-time.Minute), now.Add(-time.Hour), now)
+	create("idle", now.Add(time.Hour), now.Add(-3*time.Hour), now.Add(-3*time.Hour))
+	create("never-seen", now.Add(time.Hour), now.Add(-3*time.Hour), time.Time{})
+	create("old", now.Add(time.Hour), now.Add(-10*time.Hour), now)
+
+	removed, err := adapter.DeleteExpiredSessions(time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	removed, err = adapter.DeleteExpiredSessions(now.Add(-2*time.Hour), now.Add(-5*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), removed)
+
+	var remaining []string
+	require.NoError(t, db.Model(&models.Session{}).Pluck("user_agent", &remaining).Error)
+	assert.Equal(t, []string{"active"}, remaining)
+}
@@ -0,0 +1,227 @@
+package gorm
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorAdapter implements auth.TwoFactorAdapter using GORM
+type TwoFactorAdapter struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorAdapter creates a new GORM-based two-factor adapter
+func NewTwoFactorAdapter(db *gorm.DB) *TwoFactorAdapter {
+	return &TwoFactorAdapter{db: db}
+}
+
+// GetTOTPCredential returns the TOTP credential for a user
+func (a *TwoFactorAdapter) GetTOTPCredential(userID string) (*auth.TOTPCredential, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var credential models.TOTPCredential
+	if err := a.db.Where("user_id = ?", uid).First(&credential).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrTwoFactorNotEnrolled
+		}
+		return nil, err
+	}
+
+	return a.toAuthCredential(&credential), nil
+}
+
+// SaveTOTPCredential creates or replaces the TOTP credential for a user
+func (a *TwoFactorAdapter) SaveTOTPCredential(credential *auth.TOTPCredential) error {
+	uid, err := strconv.ParseUint(credential.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	record := &models.TOTPCredential{
+		UserID:       uint(uid),
+		Secret:       credential.Secret,
+		Enabled:      credential.Enabled,
+		LastUsedStep: credential.LastUsedStep,
+	}
+	if !credential.ConfirmedAt.IsZero() {
+		confirmedAt := credential.ConfirmedAt
+		record.ConfirmedAt = &confirmedAt
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TOTPCredential
+		err := tx.Where("user_id = ?", uid).First(&existing).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return tx.Create(record).Error
+		case err != nil:
+			return err
+		}
+
+		return tx.Model(&existing).Select("secret", "enabled", "last_used_step", "confirmed_at").Updates(record).Error
+	})
+}
+
+// ClaimTOTPStep advances the last used time step of an enabled credential
+func (a *TwoFactorAdapter) ClaimTOTPStep(userID string, step int64) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	// The last_used_step condition makes the update atomic, so a code cannot
+	// be redeemed twice by concurrent requests
+	result := a.db.Model(&models.TOTPCredential{}).
+		Where("user_id = ? AND enabled = ? AND last_used_step < ?", uid, true, step).
+		Update("last_used_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// DeleteTOTPCredential removes the TOTP credential for a user
+func (a *TwoFactorAdapter) DeleteTOTPCredential(userID string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+	return a.db.Where("user_id = ?", uid).Delete(&models.TOTPCredential{}).Error
+}
+
+// CreateChallenge stores a pending second-factor challenge
+func (a *TwoFactorAdapter) CreateChallenge(challenge *auth.TwoFactorChallenge) error {
+	uid, err := strconv.ParseUint(challenge.UserID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Create(&models.TwoFactorChallenge{
+		ID:        challenge.TokenHash,
+		UserID:    uint(uid),
+		Attempts:  challenge.Attempts,
+		ExpiresAt: challenge.ExpiresAt,
+		CreatedAt: challenge.CreatedAt,
+	}).Error
+}
+
+// GetChallenge finds a pending challenge by its token hash
+func (a *TwoFactorAdapter) GetChallenge(tokenHash string) (*auth.TwoFactorChallenge, error) {
+	var challenge models.TwoFactorChallenge
+	if err := a.db.Where("id = ?", tokenHash).First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, auth.ErrChallengeNotFound
+		}
+		return nil, err
+	}
+
+	return &auth.TwoFactorChallenge{
+		TokenHash: challenge.ID,
+		UserID:    strconv.FormatUint(uint64(challenge.UserID), 10),
+		Attempts:  challenge.Attempts,
+		ExpiresAt: challenge.ExpiresAt,
+		CreatedAt: challenge.CreatedAt,
+	}, nil
+}
+
+// IncrementChallengeAttempts records a failed verification attempt
+func (a *TwoFactorAdapter) IncrementChallengeAttempts(tokenHash string) error {
+	return a.db.Model(&models.TwoFactorChallenge{}).
+		Where("id = ?", tokenHash).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// DeleteChallenge removes a challenge
+func (a *TwoFactorAdapter) DeleteChallenge(tokenHash string) error {
+	return a.db.Where("id = ?", tokenHash).Delete(&models.TwoFactorChallenge{}).Error
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes
+func (a *TwoFactorAdapter) ReplaceRecoveryCodes(userID string, codeHashes []string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", uid).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		if len(codeHashes) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: uint(uid), CodeHash: hash, CreatedAt: now}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used
+func (a *TwoFactorAdapter) ConsumeRecoveryCode(userID, codeHash string) error {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	// The used_at condition makes the update atomic, so a code cannot be
+	// redeemed twice by concurrent requests
+	result := a.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", uid, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return auth.ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left
+func (a *TwoFactorAdapter) CountRecoveryCodes(userID string) (int, error) {
+	uid, err := strconv.ParseUint(userID, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = a.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", uid).
+		Count(&count).Error
+	return int(count), err
+}
+
+// DeleteExpiredChallenges cleans up challenges that were never completed
+func (a *TwoFactorAdapter) DeleteExpiredChallenges() (int64, error) {
+	result := a.db.Where("expires_at < ?", time.Now()).Delete(&models.TwoFactorChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+func (a *TwoFactorAdapter) toAuthCredential(credential *models.TOTPCredential) *auth.TOTPCredential {
+	result := &auth.TOTPCredential{
+		UserID:       strconv.FormatUint(uint64(credential.UserID), 10),
+		Secret:       credential.Secret,
+		Enabled:      credential.Enabled,
+		LastUsedStep: credential.LastUsedStep,
+	}
+	if credential.ConfirmedAt != nil {
+		result.ConfirmedAt = *credential.ConfirmedAt
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package gorm
+
+import (
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorAdapter_ClaimTOTPStep(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.TOTPCredential{})
+	adapter := NewTwoFactorAdapter(db)
+
+	require.NoError(t, adapter.SaveTOTPCredential(&auth.TOTPCredential{UserID: "1", Secret: "secret", Enabled: true, LastUsedStep: 10}))
+	require.NoError(t, adapter.SaveTOTPCredential(&auth.TOTPCredential{UserID: "2", Secret: "secret"}))
+
+	// Steps at or before the last used one are replays
+	assert.ErrorIs(t, adapter.ClaimTOTPStep("1", 9), auth.ErrInvalidTwoFactorCode)
+	assert.ErrorIs(t, adapter.ClaimTOTPStep("1", 10), auth.ErrInvalidTwoFactorCode)
+
+	require.NoError(t, adapter.ClaimTOTPStep("1", 11))
+	assert.ErrorIs(t, adapter.ClaimTOTPStep("1", 11), auth.ErrInvalidTwoFactorCode)
+
+	credential, err := adapter.GetTOTPCredential("1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(11), credential.LastUsedStep)
+
+	// Pending enrollments and unknown users cannot claim a step
+	assert.ErrorIs(t, adapter.ClaimTOTPStep("2", 11), auth.ErrInvalidTwoFactorCode)
+	assert.ErrorIs(t, adapter.ClaimTOTPStep("3", 11), auth.ErrInvalidTwoFactorCode)
+}
@@ -9,18 +9,34 @@ import (
 	"gosveltekit/internal/auth"
 	"gosveltekit/internal/models"
 
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // UserAdapter implements auth.UserAdapter using GORM
 type UserAdapter struct {
-	db *gorm.DB
+	db     *gorm.DB
+	hasher auth.PasswordHasher
 }
 
 // NewUserAdapter creates a new GORM-based user adapter
 func NewUserAdapter(db *gorm.DB) *UserAdapter {
-	return &UserAdapter{db: db}
+	return &UserAdapter{db: db, hasher: auth.DefaultPasswordHasher()}
+}
+
+// SetPasswordHasher replaces the default password hasher
+func (a *UserAdapter) SetPasswordHasher(hasher auth.PasswordHasher) {
+	a.hasher = hasher
+}
+
+// HashPassword hashes a password with the configured hasher
+func (a *UserAdapter) HashPassword(password string) (string, error) {
+	return a.hasher.Hash(password)
+}
+
+// VerifyPassword reports whether password matches the user's stored hash
+func (a *UserAdapter) VerifyPassword(user *models.User, password string) bool {
+	ok, err := a.hasher.Verify(password, user.PasswordHash)
+	return err == nil && ok
 }
 
 // FindUserByIdentifier looks up user by username or email
@@ -53,7 +69,8 @@ func (a *UserAdapter) FindUserByID(id string) (*auth.UserData, error) {
 	return a.toUserData(&user), nil
 }
 
-// ValidateCredentials validates username/email and password
+// ValidateCredentials validates username/email and password. A hash made
+// with an outdated algorithm or cost is replaced with a current one.
 func (a *UserAdapter) ValidateCredentials(identifier, password string) (*auth.UserData, error) {
 	var user models.User
 	err := a.db.Where("username = ? OR email = ?", identifier, identifier).First(&user).Error
@@ -62,10 +79,17 @@ func (a *UserAdapter) ValidateCredentials(identifier, password string) (*auth.Us
 	}
 
 	// Compare password hash
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+	if !a.VerifyPassword(&user, password) {
 		return nil, auth.ErrInvalidCredentials
 	}
 
+	// Upgrade the hash while the plaintext is at hand
+	if a.hasher.NeedsRehash(user.PasswordHash) {
+		if rehashed, err := a.hasher.Hash(password); err == nil {
+			user.PasswordHash = rehashed
+		}
+	}
+
 	// Update last login time
 	user.LastLogin = time.Now()
 	a.db.Save(&user)
@@ -76,7 +100,7 @@ func (a *UserAdapter) ValidateCredentials(identifier, password string) (*auth.Us
 // CreateUser creates a new user
 func (a *UserAdapter) CreateUser(data auth.CreateUserInput) (*auth.UserData, error) {
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(data.Passphrase), bcrypt.DefaultCost)
+	hashedPassword, err := a.hasher.Hash(data.Passphrase)
 	if err != nil {
 		return nil, err
 	}
@@ -85,10 +109,13 @@ func (a *UserAdapter) CreateUser(data auth.CreateUserInput) (*auth.UserData, err
 		Username:     data.Identifier,
 		Email:        data.Email,
 		DisplayName:  data.DisplayName,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
 		Active:       true,
 		Role:         "user",
 	}
+	if verified, ok := data.Attributes["email_verified"].(bool); ok {
+		user.EmailVerified = verified
+	}
 
 	if err := a.db.Create(user).Error; err != nil {
 		return nil, err
@@ -104,12 +131,12 @@ func (a *UserAdapter) UpdatePassword(userID, newPassword string) error {
 		return err
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := a.hasher.Hash(newPassword)
 	if err != nil {
 		return err
 	}
 
-	return a.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", string(hashedPassword)).Error
+	return a.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", hashedPassword).Error
 }
 
 // GetUserModel returns the underlying GORM user model (for advanced queries)
@@ -144,6 +171,15 @@ func (a *UserAdapter) FindByResetTokenHash(tokenHash string) (*models.User, erro
 	return &user, nil
 }
 
+// ClearExpiredResetTokens forgets password reset tokens that can no longer be
+// used and returns how many were cleared
+func (a *UserAdapter) ClearExpiredResetTokens() (int64, error) {
+	result := a.db.Model(&models.User{}).
+		Where("reset_token <> '' AND reset_token_expiry < ?", time.Now()).
+		UpdateColumns(map[string]any{"reset_token": "", "reset_token_expiry": time.Time{}})
+	return result.RowsAffected, result.Error
+}
+
 // UpdateUser saves changes to user model
 func (a *UserAdapter) UpdateUser(user *models.User) error {
 	return a.db.Save(user).Error
@@ -156,6 +192,7 @@ func (a *UserAdapter) toUserData(user *models.User) *auth.UserData {
 		Email:       user.Email,
 		DisplayName: user.DisplayName,
 		Role:        user.Role,
+		Permissions: auth.EffectivePermissions(user.Role, auth.ParsePermissionOverrides(user.Permissions)),
 		Active:      user.Active,
 		Attributes: map[string]any{
 			"first_name":     user.FirstName,
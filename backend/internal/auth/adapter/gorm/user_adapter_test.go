@@ -0,0 +1,113 @@
+package gorm
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserAdapter_ValidateCredentialsRehashesOutdatedHash(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{})
+	adapter := NewUserAdapter(db)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{Username: "legacy", Email: "legacy@example.com", PasswordHash: string(legacy), Active: true}
+	require.NoError(t, db.Create(user).Error)
+
+	// A wrong password leaves the hash alone
+	_, err = adapter.ValidateCredentials("legacy", "wrongpass")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Equal(t, string(legacy), stored.PasswordHash)
+
+	_, err = adapter.ValidateCredentials("legacy", "password123")
+	require.NoError(t, err)
+
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.True(t, strings.HasPrefix(stored.PasswordHash, "$argon2id$"), stored.PasswordHash)
+
+	// The upgraded hash keeps working
+	_, err = adapter.ValidateCredentials("legacy", "password123")
+	assert.NoError(t, err)
+}
+
+func TestUserAdapter_UsesConfiguredHasher(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{})
+	adapter := NewUserAdapter(db)
+	adapter.SetPasswordHasher(auth.NewBcryptHasher(bcrypt.MinCost))
+
+	created, err := adapter.CreateUser(auth.CreateUserInput{
+		Identifier: "newuser",
+		Email:      "new@example.com",
+		Passphrase: "password123",
+	})
+	require.NoError(t, err)
+
+	stored, err := adapter.GetUserModel(created.ID)
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(stored.PasswordHash))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost, cost)
+	assert.True(t, adapter.VerifyPassword(stored, "password123"))
+}
+
+func TestUserAdapter_ValidateCredentialsUpgradesImportedLegacyHash(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{})
+	adapter := NewUserAdapter(db)
+
+	// Django's PBKDF2-SHA256 hash of "correct horse"
+	imported := "pbkdf2_sha256$1000$seasalt$mQnueSakb748zqBAC1tmWVZsZbi2zPGZarEzTGdfmso="
+	user := &models.User{Username: "imported", Email: "imported@example.com", PasswordHash: imported, Active: true}
+	require.NoError(t, db.Create(user).Error)
+
+	_, err := adapter.ValidateCredentials("imported", "battery staple")
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = adapter.ValidateCredentials("imported", "correct horse")
+	require.NoError(t, err)
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.True(t, strings.HasPrefix(stored.PasswordHash, "$argon2id$"), stored.PasswordHash)
+
+	_, err = adapter.ValidateCredentials("imported", "correct horse")
+	assert.NoError(t, err)
+}
+
+func TestUserAdapter_ClearExpiredResetTokens(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{})
+	adapter := NewUserAdapter(db)
+
+	expired := &models.User{Username: "expired", Email: "expired@example.com", PasswordHash: "x", Active: true,
+		ResetToken: "expired-hash", ResetTokenExpiry: time.Now().Add(-time.Minute)}
+	pending := &models.User{Username: "pending", Email: "pending@example.com", PasswordHash: "x", Active: true,
+		ResetToken: "pending-hash", ResetTokenExpiry: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(expired).Error)
+	require.NoError(t, db.Create(pending).Error)
+
+	cleared, err := adapter.ClearExpiredResetTokens()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cleared)
+
+	_, err = adapter.FindByResetTokenHash("expired-hash")
+	assert.Error(t, err)
+	found, err := adapter.FindByResetTokenHash("pending-hash")
+	require.NoError(t, err)
+	assert.Equal(t, pending.ID, found.ID)
+
+	// Nothing is left to clear
+	cleared, err = adapter.ClearExpiredResetTokens()
+	require.NoError(t, err)
+	assert.Zero(t, cleared)
+}
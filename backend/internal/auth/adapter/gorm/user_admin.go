@@ -1,8 +1,6 @@
 package gorm
 
 import (
-	"fmt"
-	"slices"
 	"strings"
 	"time"
 
@@ -86,178 +84,20 @@ func (a *UserAdapter) ListUsersCursor(input pagination.CursorQuery) (*CursorPage
 		orderColumn = "created_at"
 	}
 
-	var (
-		users      []*models.User
-		hasNext    bool
-		hasPrev    bool
-		nextCursor *string
-		prevCursor *string
-	)
-
-	limit := input.PageSize + 1
-	isBefore := input.Before != ""
-	cursorValue := input.After
-	if isBefore {
-		cursorValue = input.Before
-	}
-
-	if cursorValue != "" {
-		token, err := pagination.DecodeCursor(cursorValue)
-		if err != nil {
-			return nil, err
-		}
-
-		if token.Sort != input.Sort || token.Direction != input.Order {
-			return nil, pagination.ErrInvalidCursor
-		}
-
-		cursorQuery, reverseOrder, err := buildCursorQuery(orderColumn, input.Order, token, isBefore)
-		if err != nil {
-			return nil, err
-		}
-
-		query = query.Where(cursorQuery.sql, cursorQuery.args...)
-		if err := query.
-			Order(fmt.Sprintf("%s %s", orderColumn, reverseOrder.primary)).
-			Order(fmt.Sprintf("id %s", reverseOrder.tieBreaker)).
-			Limit(limit).
-			Find(&users).Error; err != nil {
-			return nil, err
-		}
-
-		if len(users) > input.PageSize {
-			if isBefore {
-				hasPrev = true
-			} else {
-				hasNext = true
-			}
-			users = users[:input.PageSize]
-		}
-
-		if isBefore {
-			slices.Reverse(users)
-			hasNext = true
-		} else {
-			hasPrev = true
-		}
-	} else {
-		order := buildOrder(input.Order, false)
-		if err := query.
-			Order(fmt.Sprintf("%s %s", orderColumn, order.primary)).
-			Order(fmt.Sprintf("id %s", order.tieBreaker)).
-			Limit(limit).
-			Find(&users).Error; err != nil {
-			return nil, err
-		}
-
-		if len(users) > input.PageSize {
-			hasNext = true
-			users = users[:input.PageSize]
-		}
-	}
-
-	if len(users) > 0 {
-		if hasPrev {
-			cursor, err := encodeUserCursor(users[0], input.Sort, input.Order)
-			if err != nil {
-				return nil, err
-			}
-			prevCursor = &cursor
-		}
-		if hasNext {
-			cursor, err := encodeUserCursor(users[len(users)-1], input.Sort, input.Order)
-			if err != nil {
-				return nil, err
-			}
-			nextCursor = &cursor
-		}
+	page, err := listCursorPage(query, orderColumn, input, encodeUserCursor)
+	if err != nil {
+		return nil, err
 	}
 
 	return &CursorPageResult{
-		Users:      users,
-		NextCursor: nextCursor,
-		PrevCursor: prevCursor,
-		HasNext:    hasNext,
-		HasPrev:    hasPrev,
+		Users:      page.items,
+		NextCursor: page.nextCursor,
+		PrevCursor: page.prevCursor,
+		HasNext:    page.hasNext,
+		HasPrev:    page.hasPrev,
 	}, nil
 }
 
-type cursorWhereClause struct {
-	sql  string
-	args []any
-}
-
-type orderParts struct {
-	primary    string
-	tieBreaker string
-}
-
-func buildCursorQuery(
-	orderColumn string,
-	direction pagination.SortDirection,
-	token *pagination.CursorToken,
-	isBefore bool,
-) (*cursorWhereClause, orderParts, error) {
-	value, err := parseCursorValue(token.Sort, token.Value)
-	if err != nil {
-		return nil, orderParts{}, pagination.ErrInvalidCursor
-	}
-
-	order := buildOrder(direction, isBefore)
-	comparator := ">"
-	tieComparator := ">"
-	if direction == pagination.SortDesc {
-		comparator = "<"
-		tieComparator = "<"
-	}
-	if isBefore {
-		if comparator == ">" {
-			comparator = "<"
-			tieComparator = "<"
-		} else {
-			comparator = ">"
-			tieComparator = ">"
-		}
-	}
-
-	return &cursorWhereClause{
-		sql: fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", orderColumn, comparator, orderColumn, tieComparator),
-		args: []any{
-			value,
-			value,
-			token.ID,
-		},
-	}, order, nil
-}
-
-func buildOrder(direction pagination.SortDirection, reverse bool) orderParts {
-	primary := string(direction)
-	tieBreaker := string(direction)
-	if reverse {
-		if direction == pagination.SortAsc {
-			primary = string(pagination.SortDesc)
-			tieBreaker = string(pagination.SortDesc)
-		} else {
-			primary = string(pagination.SortAsc)
-			tieBreaker = string(pagination.SortAsc)
-		}
-	}
-
-	return orderParts{
-		primary:    primary,
-		tieBreaker: tieBreaker,
-	}
-}
-
-func parseCursorValue(sortField string, raw string) (any, error) {
-	switch sortField {
-	case "created_at":
-		return time.Parse(time.RFC3339Nano, raw)
-	default:
-		return raw, nil
-	}
-}
-
 func encodeUserCursor(
 	user *models.User,
 	sortField string,
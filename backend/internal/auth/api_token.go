@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// APITokenPrefix starts every personal access token, telling them apart
+	// from session tokens in the Authorization header
+	APITokenPrefix = "gsk_"
+
+	apiTokenBytesLen   = 32
+	apiTokenHintLen    = 4
+	maxAPITokenNameLen = 100
+)
+
+// API token scopes
+const (
+	APITokenScopeRead  = "read"  // requests that only read data
+	APITokenScopeWrite = "write" // requests that change data
+	APITokenScopeAdmin = "admin" // admin routes; only for users with the admin role
+)
+
+// APITokenScopes lists every scope in the order they are stored
+var APITokenScopes = []string{APITokenScopeRead, APITokenScopeWrite, APITokenScopeAdmin}
+
+// HasScope reports whether the token was granted the scope
+func (t *APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// IsAPIToken reports whether a bearer credential is a personal access token
+// rather than a session token
+func IsAPIToken(credential string) bool {
+	return strings.HasPrefix(credential, APITokenPrefix)
+}
+
+// SetAPITokenAdapter enables personal access tokens backed by the given adapter
+func (m *AuthManager) SetAPITokenAdapter(adapter APITokenAdapter) {
+	m.apiTokenAdapter = adapter
+}
+
+// CreateAPIToken issues a personal access token for the user. The returned
+// token string is the only copy and must be shown to the user right away. A
+// zero expiresAt creates a token that never expires.
+func (m *AuthManager) CreateAPIToken(user *UserData, name string, scopes []string, expiresAt time.Time) (*APIToken, string, error) {
+	if m.apiTokenAdapter == nil {
+		return nil, "", ErrAPITokensNotSupported
+	}
+
+	scopes, err := normalizeAPITokenScopes(user.Role, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !expiresAt.IsZero() && !expiresAt.After(now) {
+		return nil, "", ErrAPITokenExpired
+	}
+
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxAPITokenNameLen {
+		name = string(runes[:maxAPITokenNameLen])
+	}
+
+	tokenBytes := make([]byte, apiTokenBytesLen)
+	if _, err := GenerateRandomBytes(tokenBytes); err != nil {
+		return nil, "", err
+	}
+	token := APITokenPrefix + base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	record := &APIToken{
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: HashToken(token),
+		Hint:      token[len(token)-apiTokenHintLen:],
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	if err := m.apiTokenAdapter.CreateAPIToken(record); err != nil {
+		return nil, "", err
+	}
+
+	return record, token, nil
+}
+
+// normalizeAPITokenScopes checks the scopes requested for a principal with
+// the role and returns them deduplicated, in the order of APITokenScopes
+func normalizeAPITokenScopes(role string, requested []string) ([]string, error) {
+	wanted := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		wanted[scope] = true
+	}
+
+	scopes := make([]string, 0, len(wanted))
+	for _, scope := range APITokenScopes {
+		if wanted[scope] {
+			scopes = append(scopes, scope)
+			delete(wanted, scope)
+		}
+	}
+	if len(scopes) == 0 || len(wanted) > 0 {
+		return nil, ErrInvalidAPITokenScope
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(AllowedAPITokenScopes(role), scope) {
+			return nil, ErrInvalidAPITokenScope
+		}
+	}
+	return scopes, nil
+}
+
+// AllowedAPITokenScopes returns every scope a principal with the role may be
+// granted
+func AllowedAPITokenScopes(role string) []string {
+	if role == "admin" {
+		return slices.Clone(APITokenScopes)
+	}
+	return slices.DeleteFunc(slices.Clone(APITokenScopes), func(scope string) bool {
+		return scope == APITokenScopeAdmin
+	})
+}
+
+// ListAPITokens returns the user's personal access tokens
+func (m *AuthManager) ListAPITokens(userID string) ([]APIToken, error) {
+	if m.apiTokenAdapter == nil {
+		return nil, ErrAPITokensNotSupported
+	}
+	return m.apiTokenAdapter.ListAPITokens(userID)
+}
+
+// RevokeAPIToken deletes one of the user's personal access tokens
+func (m *AuthManager) RevokeAPIToken(userID, id string) error {
+	if m.apiTokenAdapter == nil {
+		return ErrAPITokensNotSupported
+	}
+	return m.apiTokenAdapter.DeleteAPIToken(userID, id)
+}
+
+// ValidateAPIToken checks a personal access token and returns it with its
+// user. metadata describes the client; its IP is recorded as the token's
+// last use.
+func (m *AuthManager) ValidateAPIToken(token string, metadata SessionMetadata) (*APIToken, *UserData, error) {
+	if m.apiTokenAdapter == nil {
+		return nil, nil, ErrAPITokenNotFound
+	}
+
+	record, err := m.apiTokenAdapter.GetAPITokenByHash(HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	if !record.ExpiresAt.IsZero() && now.After(record.ExpiresAt) {
+		return nil, nil, ErrAPITokenExpired
+	}
+
+	user, err := m.userAdapter.FindUserByID(record.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.Active {
+		return nil, nil, ErrUserNotActive
+	}
+
+	// Record usage, at most once per touch interval unless the client moved
+	if now.Sub(record.LastUsedAt) >= sessionTouchInterval || record.LastUsedIP != metadata.IP {
+		if err := m.apiTokenAdapter.TouchAPIToken(record.ID, now, metadata.IP); err == nil {
+			record.LastUsedAt = now
+			record.LastUsedIP = metadata.IP
+		}
+	}
+
+	return record, user, nil
+}
@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Audit event types. Lifecycle events are recorded under their EventType,
+// e.g. EventLoginSucceeded.
+const (
+	AuditImpersonationStarted = "impersonation.started"
+	AuditImpersonationStopped = "impersonation.stopped"
+
+	AuditUserRoleChanged        = "user.role_changed"
+	AuditUserPermissionsChanged = "user.permissions_changed"
+	AuditOAuthClientCreated     = "oauth_client.created"
+	AuditOAuthClientDeleted     = "oauth_client.deleted"
+
+	AuditTwoFactorEnabled       = "two_factor.enabled"
+	AuditTwoFactorDisabled      = "two_factor.disabled"
+	AuditRecoveryCodesGenerated = "two_factor.recovery_codes_generated"
+	AuditPasskeyAdded           = "passkey.added"
+	AuditPasskeyRemoved         = "passkey.removed"
+	AuditAPITokenCreated        = "api_token.created"
+	AuditAPITokenRevoked        = "api_token.revoked"
+
+	AuditOrganizationCreated       = "organization.created"
+	AuditOrganizationDeleted       = "organization.deleted"
+	AuditOrganizationMemberRole    = "organization.member_role_changed"
+	AuditOrganizationMemberRemoved = "organization.member_removed"
+	AuditInvitationCreated         = "invitation.created"
+	AuditInvitationResent          = "invitation.resent"
+	AuditInvitationRevoked         = "invitation.revoked"
+	AuditInvitationAccepted        = "invitation.accepted"
+)
+
+// AuditEvent records a security-relevant action. ActorID is the user who
+// acted and UserID the user whose account it concerns; they differ when an
+// admin acts on someone else's account.
+type AuditEvent struct {
+	ID        string
+	Type      string
+	ActorID   string
+	UserID    string
+	IP        string
+	UserAgent string
+	Details   map[string]string
+	CreatedAt time.Time
+}
+
+// AuditAdapter optional interface for a persistent audit log
+type AuditAdapter interface {
+	// RecordAuditEvent stores an event and sets its ID
+	RecordAuditEvent(event *AuditEvent) error
+}
+
+// SetAuditAdapter stores audit events through the given adapter instead of
+// only logging them, and starts recording the lifecycle events that concern
+// an account: registrations, logins, lockouts, revoked sessions and password
+// changes. Lifecycle events are recorded asynchronously, so that looking up
+// the account a failed login named neither slows the login down nor tells by
+// its timing whether the account exists; Events().Wait() flushes them.
+func (m *AuthManager) SetAuditAdapter(adapter AuditAdapter) {
+	if m.auditAdapter == nil {
+		m.events.SubscribeAsync(AllEvents, m.auditLifecycleEvent)
+	}
+	m.auditAdapter = adapter
+}
+
+// RecordAuditEvent stores the event, or logs it when no audit adapter is set.
+// Failing to store it does not fail the action being audited.
+func (m *AuthManager) RecordAuditEvent(event *AuditEvent) {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	if m.auditAdapter == nil {
+		slog.Info("audit event",
+			"type", event.Type,
+			"actor_id", event.ActorID,
+			"user_id", event.UserID,
+			"ip", event.IP,
+			"details", event.Details,
+		)
+		return
+	}
+
+	if err := m.auditAdapter.RecordAuditEvent(event); err != nil {
+		slog.Error("failed to record audit event",
+			"type", event.Type,
+			"actor_id", event.ActorID,
+			"user_id", event.UserID,
+			"error", err,
+		)
+	}
+}
+
+// auditLifecycleEvent turns a lifecycle event into an audit event. Session
+// creation and refreshes are left out: every login already records one, and
+// refreshes are routine.
+func (m *AuthManager) auditLifecycleEvent(event Event) {
+	audit := &AuditEvent{Type: event.EventType()}
+
+	switch e := event.(type) {
+	case UserRegisteredEvent:
+		audit.ActorID, audit.UserID = e.UserID, e.UserID
+		audit.Details = map[string]string{"method": e.Method}
+		audit.CreatedAt = e.OccurredAt
+	case LoginSucceededEvent:
+		audit.ActorID, audit.UserID = e.UserID, e.UserID
+		audit.IP, audit.UserAgent = e.IP, e.UserAgent
+		audit.Details = map[string]string{"method": e.Method, "session_id": e.SessionPublicID}
+		audit.CreatedAt = e.OccurredAt
+	case NewDeviceSignInEvent:
+		audit.ActorID, audit.UserID = e.UserID, e.UserID
+		audit.IP, audit.UserAgent = e.IP, e.UserAgent
+		audit.Details = map[string]string{"device": e.UserAgentFamily, "session_id": e.SessionPublicID}
+		audit.CreatedAt = e.OccurredAt
+	case LoginFailedEvent:
+		audit.UserID = e.UserID
+		if audit.UserID == "" {
+			audit.UserID = m.auditUserID(e.Identifier)
+		}
+		audit.IP, audit.UserAgent = e.IP, e.UserAgent
+		audit.Details = map[string]string{"identifier": e.Identifier}
+		if e.Err != nil {
+			audit.Details["reason"] = e.Err.Error()
+		}
+		audit.CreatedAt = e.OccurredAt
+	case AccountLockedEvent:
+		audit.UserID = m.auditUserID(e.Identifier)
+		audit.Details = map[string]string{
+			"identifier":   e.Identifier,
+			"locked_until": e.LockedUntil.UTC().Format(time.RFC3339),
+		}
+		audit.CreatedAt = e.OccurredAt
+	case SessionRevokedEvent:
+		// Sessions that merely ran out are not worth a record, and ended
+		// impersonations already record AuditImpersonationStopped
+		if e.Reason == SessionRevokedExpired || e.Reason == SessionRevokedImpersonationEnd {
+			return
+		}
+		audit.UserID = e.UserID
+		if e.Reason != SessionRevokedClientMismatch {
+			audit.ActorID = e.UserID
+		}
+		audit.Details = map[string]string{"reason": e.Reason}
+		if e.SessionPublicID != "" {
+			audit.Details["session_id"] = e.SessionPublicID
+		}
+		audit.CreatedAt = e.OccurredAt
+	case PasswordChangedEvent:
+		audit.ActorID, audit.UserID = e.UserID, e.UserID
+		audit.CreatedAt = e.OccurredAt
+	case PasswordResetEvent:
+		audit.ActorID, audit.UserID = e.UserID, e.UserID
+		audit.CreatedAt = e.OccurredAt
+	default:
+		return
+	}
+
+	m.RecordAuditEvent(audit)
+}
+
+// auditUserID finds the account a login identifier names, if any
+func (m *AuthManager) auditUserID(identifier string) string {
+	if identifier == "" {
+		return ""
+	}
+	user, err := m.userAdapter.FindUserByIdentifier(identifier)
+	if err != nil {
+		return ""
+	}
+	return user.ID
+}
+
+// AuditWriter is an AuditAdapter that queues events and stores them through
+// another adapter from a background goroutine, so recording an event never
+// waits for the database. The events it stores do not get an ID. Events still
+// queued when the process dies are lost; Close stores them first.
+type AuditWriter struct {
+	adapter AuditAdapter
+	queue   chan *AuditEvent
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAuditWriter starts a writer that queues up to queueSize events
+func NewAuditWriter(adapter AuditAdapter, queueSize int) *AuditWriter {
+	w := &AuditWriter{
+		adapter: adapter,
+		queue:   make(chan *AuditEvent, queueSize),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// RecordAuditEvent queues the event. It fails with ErrAuditQueueFull rather
+// than wait when the database falls behind.
+func (w *AuditWriter) RecordAuditEvent(event *AuditEvent) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return ErrAuditWriterClosed
+	}
+
+	select {
+	case w.queue <- event:
+		return nil
+	default:
+		return ErrAuditQueueFull
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are stored
+func (w *AuditWriter) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+}
+
+func (w *AuditWriter) run() {
+	defer close(w.done)
+	for event := range w.queue {
+		if err := w.adapter.RecordAuditEvent(event); err != nil {
+			slog.Error("failed to store audit event", "type", event.Type, "error", err)
+		}
+	}
+}
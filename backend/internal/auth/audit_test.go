@@ -0,0 +1,50 @@
+package auth
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingAuditAdapter stores events once release is closed
+type blockingAuditAdapter struct {
+	release chan struct{}
+
+	mu     sync.Mutex
+	events []*AuditEvent
+}
+
+func (a *blockingAuditAdapter) RecordAuditEvent(event *AuditEvent) error {
+	<-a.release
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, event)
+	return nil
+}
+
+func TestAuditWriter_DoesNotBlockAndDrainsOnClose(t *testing.T) {
+	adapter := &blockingAuditAdapter{release: make(chan struct{})}
+	w := NewAuditWriter(adapter, 2)
+
+	// The writer takes one event and waits on the adapter; two more fill the
+	// queue, after which events are dropped instead of waiting
+	var queued int
+	var err error
+	for range 10 {
+		if err = w.RecordAuditEvent(&AuditEvent{Type: AuditPasskeyAdded}); err != nil {
+			break
+		}
+		queued++
+	}
+	require.ErrorIs(t, err, ErrAuditQueueFull)
+	assert.GreaterOrEqual(t, queued, 2)
+
+	close(adapter.release)
+	w.Close()
+	assert.Len(t, adapter.events, queued, "Close stores every queued event")
+
+	assert.ErrorIs(t, w.RecordAuditEvent(&AuditEvent{Type: AuditPasskeyAdded}), ErrAuditWriterClosed)
+	w.Close()
+}
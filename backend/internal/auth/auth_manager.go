@@ -2,19 +2,71 @@ package auth
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"sync"
 	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	sessionIDBytesLen       = 32
+	publicSessionIDBytesLen = 16
 )
 
-const sessionIDBytesLen = 32
+// sessionTouchInterval limits how often last-seen times are written, so an
+// active session costs one write per interval instead of one per request
+const sessionTouchInterval = time.Minute
+
+// SessionRotationGrace is how long a token replaced by a rotation that
+// ValidateSession performed keeps working, so that requests the client sent
+// before it saw the new token do not fail
+const SessionRotationGrace = 30 * time.Second
 
 // AuthConfig holds configuration for the auth manager
 type AuthConfig struct {
 	SessionDuration   time.Duration // Default: 30 days
 	RefreshThreshold  time.Duration // Refresh if less than this remaining (default: 15 days)
+	IdleTimeout       time.Duration // End sessions unused for this long (0 disables)
+	AbsoluteLifetime  time.Duration // End sessions this old, however often refreshed (0 disables)
 	MaxFailedAttempts int           // Max failed login attempts before lockout
 	LockoutDuration   time.Duration // How long to lock account after max attempts
+
+	ReauthenticationWindow time.Duration // How long a password confirmation allows sensitive actions (0 disables)
+
+	SessionUserAgentPolicy SessionBindingPolicy // Session used from another browser or OS family
+	SessionIPPolicy        SessionBindingPolicy // Session used from another IP network
+	SessionIPv4Prefix      int                  // Prefix length that makes up an IPv4 network
+	SessionIPv6Prefix      int                  // Prefix length that makes up an IPv6 network
+
+	TOTPIssuer            string        // Issuer shown by authenticator apps
+	TwoFactorChallengeTTL time.Duration // How long a pending second-factor challenge is valid
+	MaxTwoFactorAttempts  int           // Wrong codes accepted per challenge before it is discarded
+
+	WebAuthnRPID          string        // Relying party ID, the site's domain without scheme or port
+	WebAuthnRPDisplayName string        // Relying party name shown by the authenticator
+	WebAuthnRPOrigins     []string      // Origins allowed to perform WebAuthn ceremonies
+	PasskeyCeremonyTTL    time.Duration // How long a registration or login ceremony is valid
+
+	OAuthStateTTL time.Duration // How long a social login redirect may take to come back
+
+	MagicLinkTTL time.Duration // How long an emailed login link is valid
+
+	RequireEmailVerification        bool          // Refuse login until the user's email is verified
+	EmailVerificationTTL            time.Duration // How long a verification link is valid
+	EmailVerificationResendInterval time.Duration // Minimum time between verification emails to one user
+
+	ClientAccessTokenTTL time.Duration // How long a token issued to an OAuth client is valid
+
+	ImpersonationDuration time.Duration // How long a session an admin opened as another user lasts
+
+	InvitationTTL          time.Duration // How long an invitation to join an organization is valid
+	InviteOnlyRegistration bool          // Refuse new accounts that do not come with an invitation
+
+	KnownDeviceTTL       time.Duration // How long a device stays known after its last sign-in (0 disables new-device alerts)
+	SessionRevokeLinkTTL time.Duration // How long the link in a new-device alert can end that session
 }
 
 // DefaultAuthConfig returns sensible defaults
@@ -22,8 +74,44 @@ func DefaultAuthConfig() *AuthConfig {
 	return &AuthConfig{
 		SessionDuration:   30 * 24 * time.Hour, // 30 days
 		RefreshThreshold:  15 * 24 * time.Hour, // 15 days
+		IdleTimeout:       7 * 24 * time.Hour,  // 7 days
+		AbsoluteLifetime:  90 * 24 * time.Hour, // 90 days
 		MaxFailedAttempts: 5,
 		LockoutDuration:   30 * time.Minute,
+
+		ReauthenticationWindow: 10 * time.Minute,
+
+		SessionUserAgentPolicy: SessionBindingWarn,
+		SessionIPPolicy:        SessionBindingIgnore,
+		SessionIPv4Prefix:      24,
+		SessionIPv6Prefix:      64,
+
+		TOTPIssuer:            "GoSvelteKit",
+		TwoFactorChallengeTTL: 5 * time.Minute,
+		MaxTwoFactorAttempts:  5,
+
+		WebAuthnRPID:          "localhost",
+		WebAuthnRPDisplayName: "GoSvelteKit",
+		WebAuthnRPOrigins:     []string{"http://localhost:5173"},
+		PasskeyCeremonyTTL:    5 * time.Minute,
+
+		OAuthStateTTL: 10 * time.Minute,
+
+		MagicLinkTTL: 15 * time.Minute,
+
+		RequireEmailVerification:        false,
+		EmailVerificationTTL:            24 * time.Hour,
+		EmailVerificationResendInterval: time.Minute,
+
+		ClientAccessTokenTTL: time.Hour,
+
+		ImpersonationDuration: time.Hour,
+
+		InvitationTTL:          7 * 24 * time.Hour,
+		InviteOnlyRegistration: false,
+
+		KnownDeviceTTL:       90 * 24 * time.Hour,
+		SessionRevokeLinkTTL: 7 * 24 * time.Hour,
 	}
 }
 
@@ -33,16 +121,32 @@ type AuthManager struct {
 	sessionAdapter SessionAdapter
 	config         *AuthConfig
 
-	// Rate limiting for failed attempts
-	failedAttempts      map[string]failedAttemptInfo
-	failedAttemptsMutex sync.RWMutex
-}
+	// Optional adapters
+	twoFactorAdapter         TwoFactorAdapter
+	credentialAdapter        CredentialAdapter
+	webAuthn                 *webauthn.WebAuthn
+	identityAdapter          IdentityAdapter
+	oauthProviders           map[string]OAuthProvider
+	magicLinkAdapter         MagicLinkAdapter
+	emailVerificationAdapter EmailVerificationAdapter
+	apiTokenAdapter          APITokenAdapter
+	accessTokenSigner        *AccessTokenSigner
+	oauthClientAdapter       OAuthClientAdapter
+	organizationAdapter      OrganizationAdapter
+	invitationAdapter        InvitationAdapter
+	auditAdapter             AuditAdapter
+	knownDeviceAdapter       KnownDeviceAdapter
+
+	// Called when a session is used from an unexpected client
+	sessionAnomalyHandler func(SessionAnomaly)
+
+	// Lifecycle events for code outside the package
+	events *EventDispatcher
 
-type failedAttemptInfo struct {
-	count    int
-	lastTry  time.Time
-	lockedAt time.Time
-	isLocked bool
+	// Rate limiting for failed attempts
+	lockoutAdapter    LockoutAdapter
+	lockoutPurgedAt   time.Time
+	lockoutPurgeMutex sync.Mutex
 }
 
 // NewAuthManager creates a new AuthManager instance
@@ -54,57 +158,135 @@ func NewAuthManager(userAdapter UserAdapter, sessionAdapter SessionAdapter, conf
 		userAdapter:    userAdapter,
 		sessionAdapter: sessionAdapter,
 		config:         config,
-		failedAttempts: make(map[string]failedAttemptInfo),
+		lockoutAdapter: NewMemoryLockoutAdapter(),
+		events:         NewEventDispatcher(),
 	}
 }
 
-// Login authenticates a user and creates a session
+// SetTwoFactorAdapter enables second-factor support backed by the given adapter
+func (m *AuthManager) SetTwoFactorAdapter(adapter TwoFactorAdapter) {
+	m.twoFactorAdapter = adapter
+}
+
+// Login authenticates a user and creates a session.
+//
+// When the user has a second factor enabled no session is created; instead a
+// *TwoFactorRequiredError carrying a pending challenge token is returned and
+// the login must be finished with CompleteTwoFactorLogin.
 func (m *AuthManager) Login(identifier, password string, metadata SessionMetadata) (*Session, *UserData, error) {
 	// Check if account is locked
 	if m.isAccountLocked(identifier) {
+		m.publishLoginFailed("", identifier, ErrAccountLocked, metadata)
 		return nil, nil, ErrAccountLocked
 	}
 
 	// Validate credentials
 	user, err := m.userAdapter.ValidateCredentials(identifier, password)
 	if err != nil {
+		m.publishLoginFailed("", identifier, err, metadata)
 		m.recordFailedAttempt(identifier)
 		return nil, nil, err
 	}
 
 	// Check if user is active
 	if !user.Active {
+		m.publishLoginFailed(user.ID, identifier, ErrUserNotActive, metadata)
 		return nil, nil, ErrUserNotActive
 	}
 
 	// Clear failed attempts on successful login
 	m.clearFailedAttempts(identifier)
 
-	// Create session
-	expiresAt := time.Now().Add(m.config.SessionDuration)
+	return m.completeFirstFactor(user, LoginMethodPassword, metadata)
+}
+
+// completeFirstFactor creates a session for a user who passed the first
+// factor, or holds it back behind a second-factor challenge when one is enabled
+func (m *AuthManager) completeFirstFactor(user *UserData, method string, metadata SessionMetadata) (*Session, *UserData, error) {
+	if m.config.RequireEmailVerification && !userEmailVerified(user) {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	enabled, err := m.TwoFactorEnabled(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enabled {
+		challenge, err := m.createTwoFactorChallenge(user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, user, challenge
+	}
+
+	return m.createSession(user, method, metadata)
+}
+
+// createSession creates a fresh session for a user who signed in with the
+// given LoginMethod
+func (m *AuthManager) createSession(user *UserData, method string, metadata SessionMetadata) (*Session, *UserData, error) {
+	now := time.Now()
+	expiresAt := m.sessionExpiry(now, now)
 	session, err := m.sessionAdapter.CreateSession(user.ID, expiresAt, metadata)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	session.Fresh = true
+	m.publishSessionCreated(session, metadata)
+	m.events.Publish(LoginSucceededEvent{
+		UserID:          user.ID,
+		SessionPublicID: session.PublicID,
+		Method:          method,
+		IP:              metadata.IP,
+		UserAgent:       metadata.UserAgent,
+		OccurredAt:      now,
+	})
 	return session, user, nil
 }
 
-// ValidateSession validates a session and returns user data
-func (m *AuthManager) ValidateSession(sessionID string) (*Session, *UserData, error) {
+// ValidateSession validates a session and returns user data. metadata
+// describes the client making the request; it is compared with the client the
+// session was created for according to the session binding policies.
+//
+// Sessions end at ExpiresAt, after IdleTimeout without use, or AbsoluteLifetime
+// after creation, whichever comes first; refreshing never extends a session
+// past its absolute lifetime.
+//
+// A session marked for rotation comes back under a new token; callers must
+// compare the returned session's ID with sessionID and hand the new one over.
+func (m *AuthManager) ValidateSession(sessionID string, metadata SessionMetadata) (*Session, *UserData, error) {
 	session, err := m.sessionAdapter.GetSession(sessionID)
 	if err != nil {
 		return nil, nil, ErrSessionNotFound
 	}
 
-	// Check if expired
-	if time.Now().After(session.ExpiresAt) {
+	// Check if expired, idle or past its absolute lifetime
+	now := time.Now()
+	if m.sessionEnded(session, now) {
 		// Clean up expired session
-		_ = m.sessionAdapter.DeleteSession(sessionID)
+		if err := m.sessionAdapter.DeleteSession(sessionID); err == nil {
+			m.publishSessionRevoked(session, SessionRevokedExpired)
+		}
 		return nil, nil, ErrSessionExpired
 	}
 
+	// Check that the session is still used by the client it was created for
+	if m.checkSessionBinding(session, metadata) {
+		if err := m.sessionAdapter.DeleteSession(sessionID); err == nil {
+			m.publishSessionRevoked(session, SessionRevokedClientMismatch)
+		}
+		return nil, nil, ErrSessionRevoked
+	}
+
+	// An impersonation ends once the admin behind it loses their privileges
+	if session.Impersonating() && !m.checkImpersonator(session) {
+		_ = m.sessionAdapter.DeleteSession(sessionID)
+		m.recordImpersonationStopped(session, metadata, "revoked")
+		m.publishSessionRevoked(session, SessionRevokedImpersonationEnd)
+		return nil, nil, ErrSessionRevoked
+	}
+
 	// Get user data
 	user, err := m.userAdapter.FindUserByID(session.UserID)
 	if err != nil {
@@ -116,28 +298,150 @@ func (m *AuthManager) ValidateSession(sessionID string) (*Session, *UserData, er
 		return nil, nil, ErrUserNotActive
 	}
 
-	// Refresh session if needed
-	session.Fresh = false
-	timeRemaining := time.Until(session.ExpiresAt)
-	if timeRemaining < m.config.RefreshThreshold {
-		newExpiresAt := time.Now().Add(m.config.SessionDuration)
-		if err := m.sessionAdapter.UpdateSessionExpiry(sessionID, newExpiresAt); err == nil {
-			session.ExpiresAt = newExpiresAt
-			session.Fresh = true
+	// A replaced token only bridges requests already in flight; the session
+	// is refreshed and touched through its current token
+	if session.Superseded {
+		session.Fresh = false
+		return session, user, nil
+	}
+
+	// Issue a new token if the user's privileges changed since the last request
+	fresh, rotated := false, false
+	if session.RotationPending {
+		if next, err := m.sessionAdapter.RotateSession(sessionID, SessionRotationGrace); err == nil {
+			session, sessionID = next, next.ID
+			fresh, rotated = true, true
+		}
+	}
+
+	// Refresh session if needed; impersonations keep their fixed duration
+	timeRemaining := session.ExpiresAt.Sub(now)
+	if timeRemaining < m.config.RefreshThreshold && !session.Impersonating() {
+		newExpiresAt := m.sessionExpiry(session.CreatedAt, now)
+		if newExpiresAt.After(session.ExpiresAt) {
+			if err := m.sessionAdapter.UpdateSessionExpiry(sessionID, newExpiresAt); err == nil {
+				session.ExpiresAt = newExpiresAt
+				fresh = true
+			}
+		}
+	}
+	session.Fresh = fresh
+	if fresh {
+		m.publishSessionRefreshed(session, rotated)
+	}
+
+	// Record activity for the idle timeout
+	if now.Sub(sessionLastSeen(session)) >= sessionTouchInterval {
+		if err := m.sessionAdapter.TouchSession(sessionID, now); err == nil {
+			session.LastSeenAt = now
 		}
 	}
 
 	return session, user, nil
 }
 
-// Logout invalidates a session
+// sessionExpiry returns the expiry of a session created at createdAt and
+// (re)issued at now: SessionDuration from now, capped by AbsoluteLifetime
+func (m *AuthManager) sessionExpiry(createdAt, now time.Time) time.Time {
+	expiresAt := now.Add(m.config.SessionDuration)
+	if m.config.AbsoluteLifetime > 0 {
+		if deadline := createdAt.Add(m.config.AbsoluteLifetime); deadline.Before(expiresAt) {
+			expiresAt = deadline
+		}
+	}
+	return expiresAt
+}
+
+// sessionEnded reports whether the session expired, sat idle for longer than
+// IdleTimeout or outlived AbsoluteLifetime
+func (m *AuthManager) sessionEnded(session *Session, now time.Time) bool {
+	if now.After(session.ExpiresAt) {
+		return true
+	}
+	if m.config.AbsoluteLifetime > 0 && now.After(session.CreatedAt.Add(m.config.AbsoluteLifetime)) {
+		return true
+	}
+	if m.config.IdleTimeout > 0 && now.Sub(sessionLastSeen(session)) > m.config.IdleTimeout {
+		return true
+	}
+	return false
+}
+
+// PurgeExpiredSessions removes every session that ValidateSession would
+// reject for its age and returns how many were removed
+func (m *AuthManager) PurgeExpiredSessions() (int64, error) {
+	now := time.Now()
+
+	var idleBefore, createdBefore time.Time
+	if m.config.IdleTimeout > 0 {
+		idleBefore = now.Add(-m.config.IdleTimeout)
+	}
+	if m.config.AbsoluteLifetime > 0 {
+		createdBefore = now.Add(-m.config.AbsoluteLifetime)
+	}
+	return m.sessionAdapter.DeleteExpiredSessions(idleBefore, createdBefore)
+}
+
+// sessionLastSeen falls back to the creation time for sessions that were
+// never touched
+func sessionLastSeen(session *Session) time.Time {
+	if session.LastSeenAt.IsZero() {
+		return session.CreatedAt
+	}
+	return session.LastSeenAt
+}
+
+// RotateSession replaces the session's token, protecting against session
+// fixation when the user's authentication level changes. The returned session
+// carries the new token, which must be handed to the client; the old one stops
+// working at once.
+func (m *AuthManager) RotateSession(sessionID string) (*Session, error) {
+	session, err := m.sessionAdapter.RotateSession(sessionID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Fresh = true
+	m.publishSessionRefreshed(session, true)
+	return session, nil
+}
+
+// RequireSessionRotation rotates every session of the user on its next use,
+// for changes made outside those sessions, such as an admin changing the
+// user's role
+func (m *AuthManager) RequireSessionRotation(userID string) error {
+	return m.sessionAdapter.RequireUserSessionsRotation(userID)
+}
+
+// Logout invalidates a session. Logging out of an impersonation session ends
+// the impersonation and is recorded as such.
 func (m *AuthManager) Logout(sessionID string) error {
-	return m.sessionAdapter.DeleteSession(sessionID)
+	session, err := m.sessionAdapter.GetSession(sessionID)
+	if err != nil {
+		return m.sessionAdapter.DeleteSession(sessionID)
+	}
+
+	if err := m.sessionAdapter.DeleteSession(sessionID); err != nil {
+		return err
+	}
+	if session.Impersonating() {
+		m.recordImpersonationStopped(session, SessionMetadata{}, "logout")
+	}
+	m.publishSessionRevoked(session, SessionRevokedLogout)
+	return nil
 }
 
 // LogoutAll invalidates all sessions for a user
 func (m *AuthManager) LogoutAll(userID string) error {
-	return m.sessionAdapter.DeleteUserSessions(userID)
+	if err := m.sessionAdapter.DeleteUserSessions(userID); err != nil {
+		return err
+	}
+	m.events.Publish(SessionRevokedEvent{
+		UserID:     userID,
+		Reason:     SessionRevokedLogout,
+		OccurredAt: time.Now(),
+	})
+	return nil
 }
 
 // GetUserAdapter returns the user adapter (useful for registration, etc)
@@ -159,54 +463,24 @@ func GenerateSessionID() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// GenerateRandomBytes fills a byte slice with cryptographically secure random bytes
-func GenerateRandomBytes(b []byte) (int, error) {
-	return rand.Read(b)
-}
-
-// --- Rate limiting helpers ---
-
-func (m *AuthManager) isAccountLocked(identifier string) bool {
-	m.failedAttemptsMutex.RLock()
-	defer m.failedAttemptsMutex.RUnlock()
-
-	info, exists := m.failedAttempts[identifier]
-	if !exists {
-		return false
-	}
-
-	if !info.isLocked {
-		return false
-	}
-
-	// Check if lockout has expired
-	if time.Since(info.lockedAt) > m.config.LockoutDuration {
-		return false
+// GeneratePublicSessionID generates the non-secret ID a session is listed under
+func GeneratePublicSessionID() (string, error) {
+	bytes := make([]byte, publicSessionIDBytesLen)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
 	}
-
-	return true
+	return hex.EncodeToString(bytes), nil
 }
 
-func (m *AuthManager) recordFailedAttempt(identifier string) {
-	m.failedAttemptsMutex.Lock()
-	defer m.failedAttemptsMutex.Unlock()
-
-	info := m.failedAttempts[identifier]
-	info.count++
-	info.lastTry = time.Now()
-
-	if info.count >= m.config.MaxFailedAttempts {
-		info.isLocked = true
-		info.lockedAt = time.Now()
-	}
-
-	m.failedAttempts[identifier] = info
+// HashToken returns the hex-encoded SHA-256 digest used to store tokens at rest
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
 }
 
-func (m *AuthManager) clearFailedAttempts(identifier string) {
-	m.failedAttemptsMutex.Lock()
-	defer m.failedAttemptsMutex.Unlock()
-	delete(m.failedAttempts, identifier)
+// GenerateRandomBytes fills a byte slice with cryptographically secure random bytes
+func GenerateRandomBytes(b []byte) (int, error) {
+	return rand.Read(b)
 }
 
 // ErrAccountLocked is returned when an account is temporarily locked
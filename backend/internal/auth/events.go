@@ -0,0 +1,344 @@
+package auth
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventUserRegistered   = "user.registered"
+	EventLoginSucceeded   = "login.succeeded"
+	EventLoginFailed      = "login.failed"
+	EventNewDeviceSignIn  = "login.new_device"
+	EventAccountLocked    = "account.locked"
+	EventSessionCreated   = "session.created"
+	EventSessionRefreshed = "session.refreshed"
+	EventSessionRevoked   = "session.revoked"
+	EventPasswordChanged  = "password.changed"
+	EventPasswordReset    = "password.reset"
+)
+
+// AllEvents subscribes a handler to every event type
+const AllEvents = ""
+
+// How an account was registered
+const (
+	RegistrationPassword   = "password"
+	RegistrationInvitation = "invitation"
+	RegistrationOAuth      = "oauth"
+)
+
+// How a user signed in
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodOAuth     = "oauth"
+	LoginMethodPasskey   = "passkey"
+	LoginMethodTwoFactor = "two_factor" // the first factor was completed by a second one
+)
+
+// Why a session ended
+const (
+	SessionRevokedLogout           = "logout"
+	SessionRevokedByUser           = "revoked"         // ended from another of the user's sessions
+	SessionRevokedExpired          = "expired"         // expired, idle or past its absolute lifetime
+	SessionRevokedClientMismatch   = "client_mismatch" // used from an unexpected client
+	SessionRevokedImpersonationEnd = "impersonation_ended"
+	SessionRevokedPasswordChanged  = "password_changed"
+	SessionRevokedPasswordReset    = "password_reset"
+)
+
+// Event is implemented by every auth lifecycle event. Events are plain
+// values; handlers must not rely on being able to modify them.
+type Event interface {
+	EventType() string
+}
+
+// UserRegisteredEvent is published when an account is created
+type UserRegisteredEvent struct {
+	UserID     string
+	Identifier string
+	Email      string
+	Method     string // one of the Registration* constants
+	OccurredAt time.Time
+}
+
+// LoginSucceededEvent is published when a sign-in creates a session
+type LoginSucceededEvent struct {
+	UserID          string
+	SessionPublicID string
+	Method          string // one of the LoginMethod* constants
+	IP              string
+	UserAgent       string
+	OccurredAt      time.Time
+}
+
+// LoginFailedEvent is published when a password or second-factor code is
+// refused. Identifier is what the client submitted, which may not belong to
+// any user; UserID is only set when the user is known without a lookup.
+type LoginFailedEvent struct {
+	UserID     string
+	Identifier string
+	Err        error // the error returned to the caller
+	IP         string
+	UserAgent  string
+	OccurredAt time.Time
+}
+
+// NewDeviceSignInEvent is published after LoginSucceededEvent when the user
+// signed in from a device not seen on the account within KnownDeviceTTL.
+// RevokeToken ends the new session through RevokeSessionByLink; it is meant
+// for the user alone and must not be logged.
+type NewDeviceSignInEvent struct {
+	UserID          string
+	SessionPublicID string
+	UserAgentFamily string // see UserAgentFamily
+	IP              string
+	UserAgent       string
+	RevokeToken     string
+	RevokeExpiresAt time.Time
+	OccurredAt      time.Time
+}
+
+// AccountLockedEvent is published by the failed attempt that locks an
+// identifier
+type AccountLockedEvent struct {
+	Identifier  string
+	LockedUntil time.Time
+	OccurredAt  time.Time
+}
+
+// SessionCreatedEvent is published for every new session, including the ones
+// an admin opens with Impersonate
+type SessionCreatedEvent struct {
+	UserID          string
+	SessionPublicID string
+	ImpersonatorID  string // empty unless the session is an impersonation
+	IP              string
+	UserAgent       string
+	ExpiresAt       time.Time
+	OccurredAt      time.Time
+}
+
+// SessionRefreshedEvent is published when a session's expiry is extended or
+// its token replaced
+type SessionRefreshedEvent struct {
+	UserID          string
+	SessionPublicID string
+	ExpiresAt       time.Time
+	Rotated         bool // the session got a new token
+	OccurredAt      time.Time
+}
+
+// SessionRevokedEvent is published when sessions end before expiring on their
+// own. SessionPublicID is empty when all of the user's sessions, or all but
+// the current one, ended at once.
+type SessionRevokedEvent struct {
+	UserID          string
+	SessionPublicID string
+	Reason          string // one of the SessionRevoked* constants
+	OccurredAt      time.Time
+}
+
+// PasswordChangedEvent is published when a signed-in user changes their password
+type PasswordChangedEvent struct {
+	UserID     string
+	OccurredAt time.Time
+}
+
+// PasswordResetEvent is published when a password is reset through an
+// emailed reset link
+type PasswordResetEvent struct {
+	UserID     string
+	OccurredAt time.Time
+}
+
+func (UserRegisteredEvent) EventType() string   { return EventUserRegistered }
+func (LoginSucceededEvent) EventType() string   { return EventLoginSucceeded }
+func (LoginFailedEvent) EventType() string      { return EventLoginFailed }
+func (NewDeviceSignInEvent) EventType() string  { return EventNewDeviceSignIn }
+func (AccountLockedEvent) EventType() string    { return EventAccountLocked }
+func (SessionCreatedEvent) EventType() string   { return EventSessionCreated }
+func (SessionRefreshedEvent) EventType() string { return EventSessionRefreshed }
+func (SessionRevokedEvent) EventType() string   { return EventSessionRevoked }
+func (PasswordChangedEvent) EventType() string  { return EventPasswordChanged }
+func (PasswordResetEvent) EventType() string    { return EventPasswordReset }
+
+// EventHandler receives published events
+type EventHandler func(Event)
+
+// EventDispatcher delivers auth events to subscribed handlers. Synchronous
+// handlers run in the publishing goroutine, in subscription order, before the
+// action that published the event returns; asynchronous ones run in their own
+// goroutine. A panicking handler is logged and does not affect the action or
+// other handlers.
+type EventDispatcher struct {
+	mu            sync.RWMutex
+	nextID        int
+	subscriptions []eventSubscription
+
+	pending sync.WaitGroup
+}
+
+type eventSubscription struct {
+	id        int
+	eventType string
+	handler   EventHandler
+	async     bool
+}
+
+// NewEventDispatcher creates a dispatcher without subscribers
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{}
+}
+
+// Subscribe runs handler synchronously for every event of the given type, or
+// for every event with AllEvents. The returned function removes the
+// subscription.
+func (d *EventDispatcher) Subscribe(eventType string, handler EventHandler) func() {
+	return d.subscribe(eventType, handler, false)
+}
+
+// SubscribeAsync runs handler in a new goroutine for every event of the given
+// type, or for every event with AllEvents, so slow work such as sending email
+// does not hold up the request. The returned function removes the
+// subscription.
+func (d *EventDispatcher) SubscribeAsync(eventType string, handler EventHandler) func() {
+	return d.subscribe(eventType, handler, true)
+}
+
+// On subscribes a synchronous handler for one event type, e.g.
+//
+//	auth.On(manager.Events(), func(e auth.LoginFailedEvent) { ... })
+func On[E Event](d *EventDispatcher, handler func(E)) func() {
+	var zero E
+	return d.Subscribe(zero.EventType(), typedHandler(handler))
+}
+
+// OnAsync is the asynchronous counterpart of On
+func OnAsync[E Event](d *EventDispatcher, handler func(E)) func() {
+	var zero E
+	return d.SubscribeAsync(zero.EventType(), typedHandler(handler))
+}
+
+func typedHandler[E Event](handler func(E)) EventHandler {
+	return func(event Event) {
+		if typed, ok := event.(E); ok {
+			handler(typed)
+		}
+	}
+}
+
+// Publish delivers the event to its subscribers
+func (d *EventDispatcher) Publish(event Event) {
+	d.mu.RLock()
+	var matched []eventSubscription
+	for _, sub := range d.subscriptions {
+		if sub.eventType == AllEvents || sub.eventType == event.EventType() {
+			matched = append(matched, sub)
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, sub := range matched {
+		if !sub.async {
+			runEventHandler(sub.handler, event)
+			continue
+		}
+
+		d.pending.Go(func() {
+			runEventHandler(sub.handler, event)
+		})
+	}
+}
+
+// Wait blocks until every asynchronous handler started so far has returned,
+// e.g. before the process exits
+func (d *EventDispatcher) Wait() {
+	d.pending.Wait()
+}
+
+func (d *EventDispatcher) subscribe(eventType string, handler EventHandler, async bool) func() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.nextID++
+	id := d.nextID
+	d.subscriptions = append(d.subscriptions, eventSubscription{
+		id:        id,
+		eventType: eventType,
+		handler:   handler,
+		async:     async,
+	})
+
+	return func() {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for i, sub := range d.subscriptions {
+			if sub.id == id {
+				d.subscriptions = slices.Delete(d.subscriptions, i, i+1)
+				return
+			}
+		}
+	}
+}
+
+func runEventHandler(handler EventHandler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("auth event handler panicked", "type", event.EventType(), "panic", r)
+		}
+	}()
+	handler(event)
+}
+
+// Events returns the dispatcher AuthManager and the services built on it
+// publish lifecycle events to
+func (m *AuthManager) Events() *EventDispatcher {
+	return m.events
+}
+
+func (m *AuthManager) publishLoginFailed(userID, identifier string, err error, metadata SessionMetadata) {
+	m.events.Publish(LoginFailedEvent{
+		UserID:     userID,
+		Identifier: identifier,
+		Err:        err,
+		IP:         metadata.IP,
+		UserAgent:  metadata.UserAgent,
+		OccurredAt: time.Now(),
+	})
+}
+
+func (m *AuthManager) publishSessionCreated(session *Session, metadata SessionMetadata) {
+	m.events.Publish(SessionCreatedEvent{
+		UserID:          session.UserID,
+		SessionPublicID: session.PublicID,
+		ImpersonatorID:  metadata.ImpersonatorID,
+		IP:              metadata.IP,
+		UserAgent:       metadata.UserAgent,
+		ExpiresAt:       session.ExpiresAt,
+		OccurredAt:      time.Now(),
+	})
+}
+
+func (m *AuthManager) publishSessionRefreshed(session *Session, rotated bool) {
+	m.events.Publish(SessionRefreshedEvent{
+		UserID:          session.UserID,
+		SessionPublicID: session.PublicID,
+		ExpiresAt:       session.ExpiresAt,
+		Rotated:         rotated,
+		OccurredAt:      time.Now(),
+	})
+}
+
+func (m *AuthManager) publishSessionRevoked(session *Session, reason string) {
+	m.events.Publish(SessionRevokedEvent{
+		UserID:          session.UserID,
+		SessionPublicID: session.PublicID,
+		Reason:          reason,
+		OccurredAt:      time.Now(),
+	})
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventDispatcher_DeliversToMatchingSubscribers(t *testing.T) {
+	d := NewEventDispatcher()
+
+	var types []string
+	d.Subscribe(AllEvents, func(e Event) { types = append(types, e.EventType()) })
+
+	var failed []LoginFailedEvent
+	unsubscribe := On(d, func(e LoginFailedEvent) { failed = append(failed, e) })
+
+	d.Publish(LoginFailedEvent{Identifier: "alice", Err: ErrInvalidCredentials})
+	d.Publish(PasswordChangedEvent{UserID: "1"})
+
+	assert.Equal(t, []string{EventLoginFailed, EventPasswordChanged}, types)
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, "alice", failed[0].Identifier)
+		assert.ErrorIs(t, failed[0].Err, ErrInvalidCredentials)
+	}
+
+	unsubscribe()
+	d.Publish(LoginFailedEvent{Identifier: "bob"})
+	assert.Len(t, failed, 1)
+	assert.Len(t, types, 3)
+}
+
+func TestEventDispatcher_Async(t *testing.T) {
+	d := NewEventDispatcher()
+
+	var delivered atomic.Int32
+	OnAsync(d, func(e SessionRevokedEvent) { delivered.Add(1) })
+
+	for range 5 {
+		d.Publish(SessionRevokedEvent{UserID: "1", Reason: SessionRevokedLogout})
+	}
+	d.Wait()
+
+	assert.Equal(t, int32(5), delivered.Load())
+}
+
+func TestEventDispatcher_RecoversFromPanics(t *testing.T) {
+	d := NewEventDispatcher()
+
+	d.Subscribe(EventAccountLocked, func(Event) { panic("boom") })
+	d.SubscribeAsync(EventAccountLocked, func(Event) { panic("boom") })
+	delivered := false
+	d.Subscribe(EventAccountLocked, func(Event) { delivered = true })
+
+	assert.NotPanics(t, func() {
+		d.Publish(AccountLockedEvent{Identifier: "alice"})
+		d.Wait()
+	})
+	assert.True(t, delivered, "a panicking handler must not stop later ones")
+}
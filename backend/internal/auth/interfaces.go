@@ -4,10 +4,30 @@
 // Key components:
 //   - UserAdapter: Interface for user lookup and credential validation
 //   - SessionAdapter: Interface for session management
+//   - TwoFactorAdapter: Optional interface for second-factor (TOTP) state
+//   - CredentialAdapter: Optional interface for WebAuthn passkeys
+//   - IdentityAdapter: Optional interface for identities from external OAuth/OIDC providers
+//   - MagicLinkAdapter: Optional interface for emailed single-use login links
+//   - EmailVerificationAdapter: Optional interface for proving control of an email address
+//   - APITokenAdapter: Optional interface for personal access tokens used by scripts
+//   - OAuthClientAdapter: Optional interface for machine clients using the client credentials grant
+//   - OrganizationAdapter: Optional interface for organizations and their members
+//   - InvitationAdapter: Optional interface for emailed invitations to join an organization
+//   - AuditAdapter: Optional interface for a persistent audit log of security events;
+//     AuditWriter queues events for it so recording never blocks a request
+//   - KnownDeviceAdapter: Optional interface for the devices each user signed in from,
+//     used to report sign-ins from new devices
+//   - LockoutAdapter: Store for failed login attempts (in-memory by default)
+//   - PasswordHasher: Interface for password hashing algorithms (bcrypt, Argon2id,
+//     and verify-only legacy formats imported from other systems)
+//   - OAuthProvider: Interface implemented by each social login provider
+//   - EventDispatcher: Delivers lifecycle events (logins, sessions, password
+//     changes) to subscribed handlers, synchronously or asynchronously
 //   - AuthManager: Central manager that coordinates authentication flow
 package auth
 
 import (
+	"context"
 	"errors"
 	"time"
 )
@@ -18,6 +38,83 @@ var (
 	ErrUserNotActive      = errors.New("user not active")
 	ErrSessionNotFound    = errors.New("session not found")
 	ErrSessionExpired     = errors.New("session expired")
+	ErrSessionRevoked     = errors.New("session revoked: used from an unexpected client")
+
+	ErrTwoFactorRequired       = errors.New("two-factor authentication required")
+	ErrTwoFactorNotSupported   = errors.New("two-factor authentication not supported")
+	ErrTwoFactorNotEnrolled    = errors.New("two-factor authentication not enrolled")
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrInvalidTwoFactorCode    = errors.New("invalid two-factor code")
+	ErrChallengeNotFound       = errors.New("two-factor challenge not found")
+	ErrChallengeExpired        = errors.New("two-factor challenge expired")
+
+	ErrPasskeysNotSupported = errors.New("passkeys not supported")
+	ErrPasskeyNotFound      = errors.New("passkey not found")
+	ErrInvalidPasskey       = errors.New("invalid passkey response")
+	ErrCeremonyNotFound     = errors.New("webauthn ceremony not found")
+	ErrCeremonyExpired      = errors.New("webauthn ceremony expired")
+
+	ErrOAuthNotSupported     = errors.New("oauth login not supported")
+	ErrOAuthProviderNotFound = errors.New("oauth provider not found")
+	ErrOAuthStateInvalid     = errors.New("oauth state invalid or expired")
+	ErrOAuthExchangeFailed   = errors.New("oauth code exchange failed")
+	ErrOAuthEmailNotVerified = errors.New("oauth provider did not return a verified email")
+	ErrOAuthAccountConflict  = errors.New("an account with this email exists and cannot be linked automatically")
+	ErrIdentityNotFound      = errors.New("identity not found")
+
+	ErrMagicLinkNotSupported = errors.New("magic link login not supported")
+	ErrMagicLinkInvalid      = errors.New("magic link invalid or already used")
+	ErrMagicLinkExpired      = errors.New("magic link expired")
+
+	ErrEmailVerificationNotSupported = errors.New("email verification not supported")
+	ErrEmailVerificationInvalid      = errors.New("email verification token invalid or already used")
+	ErrEmailVerificationExpired      = errors.New("email verification token expired")
+	ErrEmailVerificationThrottled    = errors.New("email verification requested too recently")
+	ErrEmailAlreadyVerified          = errors.New("email already verified")
+	ErrEmailNotVerified              = errors.New("email not verified")
+
+	ErrAPITokensNotSupported = errors.New("api tokens not supported")
+	ErrAPITokenNotFound      = errors.New("api token not found")
+	ErrAPITokenExpired       = errors.New("api token expired")
+	ErrInvalidAPITokenScope  = errors.New("invalid api token scope")
+
+	ErrAccessTokensNotSupported = errors.New("access tokens not supported")
+
+	ErrOAuthClientsNotSupported  = errors.New("oauth clients not supported")
+	ErrOAuthClientNotFound       = errors.New("oauth client not found")
+	ErrInvalidClientCredentials  = errors.New("invalid client credentials")
+	ErrClientAccessTokenNotFound = errors.New("client access token not found")
+	ErrClientAccessTokenExpired  = errors.New("client access token expired")
+
+	ErrImpersonationNotAllowed     = errors.New("impersonation not allowed")
+	ErrImpersonationReasonRequired = errors.New("impersonation reason required")
+	ErrNotImpersonating            = errors.New("session is not an impersonation")
+
+	ErrOrganizationsNotSupported    = errors.New("organizations not supported")
+	ErrOrganizationNotFound         = errors.New("organization not found")
+	ErrOrganizationMemberNotFound   = errors.New("organization member not found")
+	ErrAlreadyOrganizationMember    = errors.New("user is already a member of the organization")
+	ErrInvalidOrganizationRole      = errors.New("invalid organization role")
+	ErrInsufficientOrganizationRole = errors.New("insufficient organization role")
+	ErrLastOrganizationOwner        = errors.New("organization must keep at least one owner")
+
+	ErrInvitationsNotSupported = errors.New("invitations not supported")
+	ErrInvitationNotFound      = errors.New("invitation not found")
+	ErrInvitationInvalid       = errors.New("invitation invalid or already used")
+	ErrInvitationExpired       = errors.New("invitation expired")
+	ErrInvitationEmailMismatch = errors.New("invitation was sent to another email address")
+	ErrRegistrationClosed      = errors.New("registration requires an invitation")
+
+	ErrKnownDevicesNotSupported = errors.New("known devices not supported")
+	ErrKnownDeviceNotFound      = errors.New("known device not found")
+	ErrSessionRevokeLinkInvalid = errors.New("session revoke link invalid or already used")
+	ErrSessionRevokeLinkExpired = errors.New("session revoke link expired")
+
+	ErrAuditQueueFull    = errors.New("audit queue full")
+	ErrAuditWriterClosed = errors.New("audit writer closed")
+
+	ErrUnsupportedPasswordHash = errors.New("unsupported or malformed password hash")
+	ErrLegacyPasswordHash      = errors.New("legacy password hash formats can only be verified")
 )
 
 // UserData represents generic user data (database-agnostic)
@@ -27,25 +124,46 @@ type UserData struct {
 	DisplayName string         `json:"display_name"`
 	Email       string         `json:"email"`
 	Role        string         `json:"role"`
+	Permissions []string       `json:"permissions"` // effective: the role's, plus grants, minus denies
 	Active      bool           `json:"active"`
 	Attributes  map[string]any `json:"attributes,omitempty"` // extra fields
 }
 
-// Session represents an authentication session
+// Session represents an authentication session. ID is the secret session
+// token and is only known when the session was created or looked up by it;
+// PublicID identifies the session to its owner without granting access.
 type Session struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"user_id"`
-	ExpiresAt time.Time `json:"expires_at"`
-	CreatedAt time.Time `json:"created_at"`
-	UserAgent string    `json:"user_agent,omitempty"`
-	IP        string    `json:"ip,omitempty"`
-	Fresh     bool      `json:"fresh"` // true if just created or refreshed
+	ID         string    `json:"-"`
+	PublicID   string    `json:"id"`
+	UserID     string    `json:"user_id"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	Fresh      bool      `json:"fresh"` // true if just created, refreshed or rotated
+
+	RotationPending bool `json:"-"` // a new token is issued on the session's next use
+	Superseded      bool `json:"-"` // ID is the token replaced by the last rotation
+
+	ReportedAnomalies []string `json:"-"` // "<kind>=<actual>" anomalies already reported, see checkSessionBinding
+
+	ReauthenticatedAt time.Time `json:"-"` // last time the user re-entered their password, zero if never
+
+	ImpersonatorID      string `json:"-"` // admin acting as the user through this session, empty if none
+	ImpersonationReason string `json:"-"` // why the admin started the impersonation
+
+	ActiveOrganizationID string `json:"-"` // organization the user is working in, empty if none
 }
 
-// SessionMetadata contains metadata for session creation
+// SessionMetadata contains metadata for session creation. The impersonation
+// fields are only set for sessions created by Impersonate.
 type SessionMetadata struct {
 	UserAgent string
 	IP        string
+
+	ImpersonatorID      string
+	ImpersonationReason string
 }
 
 // CreateUserInput contains data for creating a new user
@@ -75,7 +193,9 @@ type UserAdapter interface {
 	UpdatePassword(userID, newPassword string) error
 }
 
-// SessionAdapter manages authentication sessions
+// SessionAdapter manages authentication sessions. Methods taking a sessionID
+// receive the secret session token; adapters should store only a hash of it
+// (see HashToken) so that a leaked table does not leak usable sessions.
 type SessionAdapter interface {
 	// CreateSession creates a new session for the user
 	CreateSession(userID string, expiresAt time.Time, metadata SessionMetadata) (*Session, error)
@@ -86,14 +206,42 @@ type SessionAdapter interface {
 	// UpdateSessionExpiry updates session expiration time
 	UpdateSessionExpiry(sessionID string, expiresAt time.Time) error
 
+	// TouchSession records when the session was last used
+	TouchSession(sessionID string, lastSeenAt time.Time) error
+
+	// SetReportedAnomalies replaces the anomalies already reported for the
+	// session, but only if they still are previous, and reports whether it
+	// did. The comparison lets concurrent requests report an anomaly once.
+	SetReportedAnomalies(sessionID string, previous, reported []string) (bool, error)
+
 	// DeleteSession removes a session (logout)
 	DeleteSession(sessionID string) error
 
 	// DeleteUserSessions removes all sessions for a user
 	DeleteUserSessions(userID string) error
 
-	// DeleteExpiredSessions cleans up expired sessions
-	DeleteExpiredSessions() error
+	// RotateSession replaces the session's token with a new one, keeping
+	// everything else, and returns the session under the new token. For the
+	// grace period GetSession still finds the session by the old token, with
+	// Superseded set, so that requests already in flight succeed.
+	RotateSession(sessionID string, grace time.Duration) (*Session, error)
+
+	// RequireUserSessionsRotation marks every session of the user to be
+	// rotated on its next use
+	RequireUserSessionsRotation(userID string) error
+
+	// MarkSessionReauthenticated records that the user confirmed their
+	// password within the session at the given time
+	MarkSessionReauthenticated(sessionID string, at time.Time) error
+
+	// SetActiveOrganization records the organization the user works in
+	// within the session; an empty organizationID clears it
+	SetActiveOrganization(sessionID, organizationID string) error
+
+	// DeleteExpiredSessions removes sessions past their expiry, sessions not
+	// seen since idleBefore and sessions created before createdBefore, and
+	// returns how many were removed. A zero cutoff skips that check.
+	DeleteExpiredSessions(idleBefore, createdBefore time.Time) (int64, error)
 }
 
 // PasswordResetAdapter optional interface for password reset functionality
@@ -107,3 +255,471 @@ type PasswordResetAdapter interface {
 	// ClearResetToken clears the reset token after use
 	ClearResetToken(userID string) error
 }
+
+// TOTPCredential represents a user's TOTP secret and enrollment state
+type TOTPCredential struct {
+	UserID       string
+	Secret       string // base32-encoded shared secret
+	Enabled      bool   // false while enrollment is pending confirmation
+	LastUsedStep int64  // last accepted time step, used to reject replayed codes
+	ConfirmedAt  time.Time
+}
+
+// TwoFactorChallenge represents a login that passed the first factor and
+// is waiting for the second one
+type TwoFactorChallenge struct {
+	TokenHash string
+	UserID    string
+	Attempts  int
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// TwoFactorAdapter optional interface for second-factor (TOTP) functionality
+type TwoFactorAdapter interface {
+	// GetTOTPCredential returns the user's TOTP credential (ErrTwoFactorNotEnrolled if none)
+	GetTOTPCredential(userID string) (*TOTPCredential, error)
+
+	// SaveTOTPCredential creates or replaces the user's TOTP credential
+	SaveTOTPCredential(credential *TOTPCredential) error
+
+	// ClaimTOTPStep records step as the last one used, but only if it is
+	// newer than the stored one, in a single conditional update so that
+	// concurrent verifications of one code cannot both succeed
+	// (ErrInvalidTwoFactorCode if the step was already used)
+	ClaimTOTPStep(userID string, step int64) error
+
+	// DeleteTOTPCredential removes the user's TOTP credential
+	DeleteTOTPCredential(userID string) error
+
+	// CreateChallenge stores a pending login challenge
+	CreateChallenge(challenge *TwoFactorChallenge) error
+
+	// GetChallenge finds a pending challenge by token hash (ErrChallengeNotFound if none)
+	GetChallenge(tokenHash string) (*TwoFactorChallenge, error)
+
+	// IncrementChallengeAttempts records a failed verification against a challenge
+	IncrementChallengeAttempts(tokenHash string) error
+
+	// DeleteChallenge removes a challenge once it is used or exhausted
+	DeleteChallenge(tokenHash string) error
+
+	// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes
+	ReplaceRecoveryCodes(userID string, codeHashes []string) error
+
+	// ConsumeRecoveryCode marks an unused code as used (ErrInvalidTwoFactorCode if none matches)
+	ConsumeRecoveryCode(userID, codeHash string) error
+
+	// CountRecoveryCodes returns how many unused recovery codes the user has left
+	CountRecoveryCodes(userID string) (int, error)
+}
+
+// PasskeyCredential represents a WebAuthn credential registered by a user
+type PasskeyCredential struct {
+	ID           string
+	UserID       string
+	CredentialID []byte // raw credential ID chosen by the authenticator
+	Name         string // label chosen by the user
+	Data         []byte // serialized credential record (public key, sign count, flags)
+	CreatedAt    time.Time
+	LastUsedAt   time.Time
+}
+
+// WebAuthnCeremony stores the server-side state of a registration or login
+// ceremony between its begin and finish steps
+type WebAuthnCeremony struct {
+	TokenHash string
+	UserID    string // empty for discoverable logins
+	Kind      string
+	Data      []byte // serialized ceremony session data
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// CredentialAdapter optional interface for WebAuthn passkey functionality
+type CredentialAdapter interface {
+	// ListPasskeys returns all passkeys registered by a user
+	ListPasskeys(userID string) ([]PasskeyCredential, error)
+
+	// GetPasskeyByCredentialID finds a passkey by its raw credential ID (ErrPasskeyNotFound if none)
+	GetPasskeyByCredentialID(credentialID []byte) (*PasskeyCredential, error)
+
+	// CreatePasskey stores a newly registered passkey
+	CreatePasskey(credential *PasskeyCredential) error
+
+	// UpdatePasskeyUsage stores the refreshed credential record after a login
+	UpdatePasskeyUsage(id string, data []byte, lastUsedAt time.Time) error
+
+	// DeletePasskey removes a passkey owned by the user (ErrPasskeyNotFound if none)
+	DeletePasskey(userID, id string) error
+
+	// SaveCeremony stores a pending ceremony
+	SaveCeremony(ceremony *WebAuthnCeremony) error
+
+	// GetCeremony finds a pending ceremony by token hash (ErrCeremonyNotFound if none)
+	GetCeremony(tokenHash string) (*WebAuthnCeremony, error)
+
+	// DeleteCeremony removes a ceremony once it is used
+	DeleteCeremony(tokenHash string) error
+}
+
+// OAuthIdentity is what an external provider asserts about the signed-in user
+type OAuthIdentity struct {
+	Provider      string
+	Subject       string // stable user ID at the provider
+	Email         string
+	EmailVerified bool
+	Name          string
+	Username      string // preferred username or login, if the provider has one
+}
+
+// UserIdentity links a local user to an account at an external provider
+type UserIdentity struct {
+	ID        string
+	UserID    string
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
+
+// OAuthState stores what is needed to finish an authorization code flow
+type OAuthState struct {
+	StateHash    string
+	Provider     string
+	CodeVerifier string // PKCE verifier
+	Nonce        string
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+}
+
+// OAuthProvider is implemented by each social login provider
+type OAuthProvider interface {
+	// Name returns the provider key used in URLs and stored identities
+	Name() string
+
+	// AuthCodeURL returns the provider URL the browser is sent to, carrying the
+	// state, the PKCE challenge for codeVerifier and, for OIDC, the nonce
+	AuthCodeURL(state, codeVerifier, nonce string) string
+
+	// Exchange redeems the authorization code and returns the verified identity
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error)
+}
+
+// IdentityAdapter optional interface for social login functionality
+type IdentityAdapter interface {
+	// FindIdentity looks up a linked identity (ErrIdentityNotFound if none)
+	FindIdentity(provider, subject string) (*UserIdentity, error)
+
+	// CreateIdentity links an external identity to a user
+	CreateIdentity(identity *UserIdentity) error
+
+	// ListIdentities returns the identities linked to a user
+	ListIdentities(userID string) ([]UserIdentity, error)
+
+	// SaveOAuthState stores a pending authorization request
+	SaveOAuthState(state *OAuthState) error
+
+	// GetOAuthState finds a pending authorization request (ErrOAuthStateInvalid if none)
+	GetOAuthState(stateHash string) (*OAuthState, error)
+
+	// DeleteOAuthState removes a pending authorization request
+	DeleteOAuthState(stateHash string) error
+}
+
+// MagicLinkToken is a single-use login link sent by email. Only the hash of
+// the token is stored.
+type MagicLinkToken struct {
+	TokenHash string
+	UserID    string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// MagicLinkAdapter optional interface for passwordless login by email link
+type MagicLinkAdapter interface {
+	// ReplaceMagicLinkToken discards the user's pending links and stores the given one
+	ReplaceMagicLinkToken(token *MagicLinkToken) error
+
+	// ConsumeMagicLinkToken deletes and returns a pending link (ErrMagicLinkInvalid if none)
+	ConsumeMagicLinkToken(tokenHash string) (*MagicLinkToken, error)
+}
+
+// EmailVerificationToken proves that a user controls the address it was sent
+// to. Only the hash of the token is stored.
+type EmailVerificationToken struct {
+	TokenHash string
+	UserID    string
+	Email     string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// EmailVerificationAdapter optional interface for email address verification
+type EmailVerificationAdapter interface {
+	// ReplaceEmailVerificationToken discards the user's pending tokens and stores the given one
+	ReplaceEmailVerificationToken(token *EmailVerificationToken) error
+
+	// FindEmailVerificationTokenByUser returns the user's pending token (ErrEmailVerificationInvalid if none)
+	FindEmailVerificationTokenByUser(userID string) (*EmailVerificationToken, error)
+
+	// ConsumeEmailVerificationToken deletes and returns a pending token (ErrEmailVerificationInvalid if none)
+	ConsumeEmailVerificationToken(tokenHash string) (*EmailVerificationToken, error)
+
+	// MarkEmailVerified flags the user's email as verified, provided it is
+	// still the given address (ErrEmailVerificationInvalid otherwise)
+	MarkEmailVerified(userID, email string) error
+}
+
+// APIToken is a personal access token, a credential for scripts that is
+// separate from browser sessions. Only the hash of the token is stored.
+type APIToken struct {
+	ID         string
+	UserID     string
+	Name       string    // label chosen by the user
+	TokenHash  string    // SHA-256 of the token
+	Hint       string    // last characters of the token, to recognise it
+	Scopes     []string  // what the token may do, see APITokenScopes
+	ExpiresAt  time.Time // zero if the token never expires
+	LastUsedAt time.Time
+	LastUsedIP string
+	CreatedAt  time.Time
+}
+
+// APITokenAdapter optional interface for personal access tokens
+type APITokenAdapter interface {
+	// CreateAPIToken stores a new token and sets its ID
+	CreateAPIToken(token *APIToken) error
+
+	// GetAPITokenByHash finds a token by its hash (ErrAPITokenNotFound if none)
+	GetAPITokenByHash(tokenHash string) (*APIToken, error)
+
+	// ListAPITokens returns the user's tokens, newest first
+	ListAPITokens(userID string) ([]APIToken, error)
+
+	// TouchAPIToken records when and from where the token was last used
+	TouchAPIToken(id string, lastUsedAt time.Time, ip string) error
+
+	// DeleteAPIToken removes a token owned by the user (ErrAPITokenNotFound if none)
+	DeleteAPIToken(userID, id string) error
+}
+
+// OAuthClient is a machine client registered by an admin. It authenticates
+// with its ID and secret to obtain access tokens through the OAuth 2.0 client
+// credentials grant. Only the hash of the secret is stored.
+type OAuthClient struct {
+	ID         string   // public client_id
+	Name       string   // label chosen by the admin
+	SecretHash string   // SHA-256 of the client secret
+	Role       string   // role the client acts with, like a user's
+	Scopes     []string // scopes the client may request, see APITokenScopes
+	CreatedAt  time.Time
+}
+
+// ClientAccessToken is an access token issued to an OAuth client
+type ClientAccessToken struct {
+	ID        string
+	ClientID  string
+	TokenHash string // SHA-256 of the token
+	Scopes    []string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// OAuthClientAdapter optional interface for machine clients and their tokens
+type OAuthClientAdapter interface {
+	// CreateOAuthClient stores a new client
+	CreateOAuthClient(client *OAuthClient) error
+
+	// GetOAuthClient finds a client by its client ID (ErrOAuthClientNotFound if none)
+	GetOAuthClient(clientID string) (*OAuthClient, error)
+
+	// ListOAuthClients returns every client, newest first
+	ListOAuthClients() ([]OAuthClient, error)
+
+	// DeleteOAuthClient removes a client and its tokens (ErrOAuthClientNotFound if none)
+	DeleteOAuthClient(clientID string) error
+
+	// CreateClientAccessToken stores a new token and sets its ID
+	CreateClientAccessToken(token *ClientAccessToken) error
+
+	// GetClientAccessTokenByHash finds a token by its hash (ErrClientAccessTokenNotFound if none)
+	GetClientAccessTokenByHash(tokenHash string) (*ClientAccessToken, error)
+}
+
+// Organization groups users who share resources
+type Organization struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+}
+
+// OrganizationMember is a user's membership in an organization. The user and
+// organization names are filled in by lookups for display.
+type OrganizationMember struct {
+	OrganizationID   string
+	OrganizationName string
+	UserID           string
+	Identifier       string
+	DisplayName      string
+	Email            string
+	Role             string // see OrganizationRoleOwner and the other roles
+	JoinedAt         time.Time
+}
+
+// OrganizationAdapter optional interface for organizations and memberships
+type OrganizationAdapter interface {
+	// CreateOrganization stores a new organization with ownerID as its owner and sets its ID
+	CreateOrganization(org *Organization, ownerID string) error
+
+	// DeleteOrganization removes an organization and its memberships (ErrOrganizationNotFound if none)
+	DeleteOrganization(orgID string) error
+
+	// GetOrganizationMember finds a user's membership (ErrOrganizationMemberNotFound if none)
+	GetOrganizationMember(orgID, userID string) (*OrganizationMember, error)
+
+	// ListUserOrganizations returns the user's memberships, oldest first
+	ListUserOrganizations(userID string) ([]OrganizationMember, error)
+
+	// ListOrganizationMembers returns an organization's members, oldest first
+	ListOrganizationMembers(orgID string) ([]OrganizationMember, error)
+
+	// AddOrganizationMember adds a user (ErrAlreadyOrganizationMember if they are one)
+	AddOrganizationMember(orgID, userID, role string) error
+
+	// UpdateOrganizationMemberRole changes a member's role (ErrOrganizationMemberNotFound if none)
+	UpdateOrganizationMemberRole(orgID, userID, role string) error
+
+	// RemoveOrganizationMember removes a member and clears the organization
+	// as active in their sessions (ErrOrganizationMemberNotFound if none)
+	RemoveOrganizationMember(orgID, userID string) error
+
+	// CountOrganizationOwners returns how many owners the organization has
+	CountOrganizationOwners(orgID string) (int64, error)
+}
+
+// Invitation asks the owner of an email address to join an organization
+// with a role. The organization and inviter names are filled in by lookups
+// for display.
+type Invitation struct {
+	ID               string
+	OrganizationID   string
+	OrganizationName string
+	Email            string
+	Role             string
+	InviterID        string
+	InviterName      string
+	TokenHash        string
+	ExpiresAt        time.Time
+	CreatedAt        time.Time
+}
+
+// InvitationAdapter optional interface for invitations to join an organization
+type InvitationAdapter interface {
+	// ReplaceInvitation stores a new invitation, discarding any pending one
+	// for the same email in the organization, and sets its ID
+	ReplaceInvitation(invitation *Invitation) error
+
+	// GetInvitation finds a pending invitation (ErrInvitationNotFound if none)
+	GetInvitation(orgID, invitationID string) (*Invitation, error)
+
+	// GetInvitationByTokenHash finds a pending invitation by the hash of its
+	// token (ErrInvitationInvalid if none)
+	GetInvitationByTokenHash(tokenHash string) (*Invitation, error)
+
+	// ListInvitations returns an organization's pending invitations, newest first
+	ListInvitations(orgID string) ([]Invitation, error)
+
+	// RenewInvitation gives an invitation a new token and expiry (ErrInvitationNotFound if none)
+	RenewInvitation(invitationID, tokenHash string, expiresAt time.Time) error
+
+	// DeleteInvitation removes a pending invitation (ErrInvitationNotFound if none)
+	DeleteInvitation(orgID, invitationID string) error
+
+	// ConsumeInvitation deletes and returns the invitation with the given
+	// token hash, so it cannot be accepted twice (ErrInvitationInvalid if none)
+	ConsumeInvitation(tokenHash string) (*Invitation, error)
+}
+
+// KnownDevice is a device a user has signed in from: a user agent family on
+// an IP network, so that browser updates and a new address from the same
+// provider do not count as another device. When a sign-in from it was
+// reported as new, the revoke token that ends that session is kept until it
+// is used or expires; only its hash is stored.
+type KnownDevice struct {
+	ID              string
+	UserID          string
+	UserAgentFamily string // see UserAgentFamily
+	IPNetwork       string // the IP address's network, see SessionIPv4Prefix
+	LastIP          string
+	SessionPublicID string // session of the sign-in reported as new, empty if none
+	RevokeTokenHash string // SHA-256 of the token that revokes that session, empty if none
+	RevokeExpiresAt time.Time
+	CreatedAt       time.Time
+	LastSeenAt      time.Time
+}
+
+// KnownDeviceAdapter optional interface for the devices users signed in from
+type KnownDeviceAdapter interface {
+	// FindKnownDevice finds the user's device with the given user agent
+	// family and IP network (ErrKnownDeviceNotFound if none)
+	FindKnownDevice(userID, userAgentFamily, ipNetwork string) (*KnownDevice, error)
+
+	// HasKnownDevices reports whether the user has any device on record
+	HasKnownDevices(userID string) (bool, error)
+
+	// SaveKnownDevice stores a new device and sets its ID, or updates the
+	// device when ID is set
+	SaveKnownDevice(device *KnownDevice) error
+
+	// ConsumeSessionRevokeToken deletes and returns the device a revoke token
+	// was issued for (ErrSessionRevokeLinkInvalid if none)
+	ConsumeSessionRevokeToken(tokenHash string) (*KnownDevice, error)
+
+	// DeleteStaleKnownDevices removes devices last seen before the cutoff and
+	// returns how many were removed
+	DeleteStaleKnownDevices(before time.Time) (int64, error)
+}
+
+// LockoutAdapter stores failed login attempts and account lockouts. A lock
+// never outlasts LockoutDuration after the last failed attempt, so records
+// whose last failure is older than that can be purged.
+type LockoutAdapter interface {
+	// RecordFailedAttempt counts a failed attempt, ignoring failures older than
+	// lockoutDuration, and locks the identifier for lockoutDuration once
+	// maxAttempts is reached
+	RecordFailedAttempt(identifier string, maxAttempts int, lockoutDuration time.Duration) error
+
+	// IsLocked reports whether the identifier is currently locked
+	IsLocked(identifier string) (bool, error)
+
+	// ClearFailedAttempts forgets the identifier's failed attempts and lock
+	ClearFailedAttempts(identifier string) error
+
+	// PurgeLockouts removes records whose last failed attempt is before the
+	// cutoff and returns how many were removed
+	PurgeLockouts(before time.Time) (int64, error)
+}
+
+// PasswordHasher hashes and verifies passwords with one algorithm, or several
+// when it accepts hashes made before the current algorithm was chosen
+type PasswordHasher interface {
+	// Hash returns the encoded hash of password
+	Hash(password string) (string, error)
+
+	// Verify reports whether password matches the encoded hash
+	// (ErrUnsupportedPasswordHash if the hash cannot be read)
+	Verify(password, encoded string) (bool, error)
+
+	// Recognizes reports whether the hasher can verify the encoded hash
+	Recognizes(encoded string) bool
+
+	// CheckHash decodes the encoded hash without verifying a password and
+	// reports ErrUnsupportedPasswordHash if it is malformed or its cost
+	// parameters are out of range
+	CheckHash(encoded string) error
+
+	// NeedsRehash reports whether the encoded hash uses another algorithm or
+	// outdated parameters and should be replaced on the next successful login
+	NeedsRehash(encoded string) bool
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+const invitationTokenBytesLen = 32
+
+// SetInvitationAdapter enables invitations to organizations backed by the
+// given adapter. Organizations must be enabled too.
+func (m *AuthManager) SetInvitationAdapter(adapter InvitationAdapter) {
+	m.invitationAdapter = adapter
+}
+
+// InviteOnlyRegistration reports whether new accounts need an invitation
+func (m *AuthManager) InviteOnlyRegistration() bool {
+	return m.config.InviteOnlyRegistration
+}
+
+// CreateInvitation invites the owner of email to join the organization with
+// role on behalf of the inviter, who must be an admin of it; only owners may
+// invite owners. A pending invitation for the same email is replaced. The
+// returned token must be delivered by email and is not stored anywhere.
+func (m *AuthManager) CreateInvitation(orgID, inviterID, email, role string) (*Invitation, string, error) {
+	if m.invitationAdapter == nil || m.organizationAdapter == nil {
+		return nil, "", ErrInvitationsNotSupported
+	}
+	if !IsOrganizationRole(role) {
+		return nil, "", ErrInvalidOrganizationRole
+	}
+
+	inviter, err := m.organizationActor(orgID, inviterID, role)
+	if err != nil {
+		return nil, "", err
+	}
+
+	email = strings.TrimSpace(email)
+	if user, err := m.userAdapter.FindUserByIdentifier(email); err == nil && strings.EqualFold(user.Email, email) {
+		_, err := m.organizationAdapter.GetOrganizationMember(orgID, user.ID)
+		if err == nil {
+			return nil, "", ErrAlreadyOrganizationMember
+		}
+		if !errors.Is(err, ErrOrganizationMemberNotFound) {
+			return nil, "", err
+		}
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	invitation := &Invitation{
+		OrganizationID:   orgID,
+		OrganizationName: inviter.OrganizationName,
+		Email:            email,
+		Role:             role,
+		InviterID:        inviter.UserID,
+		InviterName:      inviter.DisplayName,
+		TokenHash:        HashToken(token),
+		ExpiresAt:        now.Add(m.config.InvitationTTL),
+		CreatedAt:        now,
+	}
+	if err := m.invitationAdapter.ReplaceInvitation(invitation); err != nil {
+		return nil, "", err
+	}
+
+	return invitation, token, nil
+}
+
+// ListInvitations returns the organization's pending invitations, expired
+// ones included until they are purged
+func (m *AuthManager) ListInvitations(orgID string) ([]Invitation, error) {
+	if m.invitationAdapter == nil {
+		return nil, ErrInvitationsNotSupported
+	}
+	return m.invitationAdapter.ListInvitations(orgID)
+}
+
+// ResendInvitation issues a new token for a pending invitation and restarts
+// its expiry, invalidating the link sent earlier. The actor must be allowed
+// to create the invitation in the first place.
+func (m *AuthManager) ResendInvitation(orgID, actorID, invitationID string) (*Invitation, string, error) {
+	invitation, err := m.invitationForActor(orgID, actorID, invitationID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	expiresAt := time.Now().Add(m.config.InvitationTTL)
+	if err := m.invitationAdapter.RenewInvitation(invitation.ID, HashToken(token), expiresAt); err != nil {
+		return nil, "", err
+	}
+
+	invitation.TokenHash = HashToken(token)
+	invitation.ExpiresAt = expiresAt
+	return invitation, token, nil
+}
+
+// RevokeInvitation deletes a pending invitation on behalf of the actor, who
+// must be allowed to create it
+func (m *AuthManager) RevokeInvitation(orgID, actorID, invitationID string) error {
+	invitation, err := m.invitationForActor(orgID, actorID, invitationID)
+	if err != nil {
+		return err
+	}
+	return m.invitationAdapter.DeleteInvitation(orgID, invitation.ID)
+}
+
+// PendingInvitation returns the invitation a token belongs to without
+// accepting it
+func (m *AuthManager) PendingInvitation(token string) (*Invitation, error) {
+	if m.invitationAdapter == nil {
+		return nil, ErrInvitationsNotSupported
+	}
+	if token == "" {
+		return nil, ErrInvitationInvalid
+	}
+
+	invitation, err := m.invitationAdapter.GetInvitationByTokenHash(HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+// AcceptInvitation adds the user to the organization with the invited role
+// and consumes the invitation. The user's email must be the invited one;
+// since the token was delivered there, accepting also verifies it.
+func (m *AuthManager) AcceptInvitation(token, userID string) (*Invitation, error) {
+	invitation, err := m.PendingInvitation(token)
+	if err != nil {
+		return nil, err
+	}
+	if m.organizationAdapter == nil {
+		return nil, ErrInvitationsNotSupported
+	}
+
+	user, err := m.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvitationEmailMismatch
+	}
+
+	// Only the caller whose delete removed the invitation goes on
+	if _, err := m.invitationAdapter.ConsumeInvitation(invitation.TokenHash); err != nil {
+		return nil, err
+	}
+
+	if err := m.organizationAdapter.AddOrganizationMember(invitation.OrganizationID, user.ID, invitation.Role); err != nil {
+		return nil, err
+	}
+
+	if err := m.markEmailVerifiedByLogin(user); err != nil {
+		return nil, err
+	}
+
+	return invitation, nil
+}
+
+// invitationForActor returns a pending invitation the actor may manage: they
+// must be an admin of the organization, and an owner for owner invitations
+func (m *AuthManager) invitationForActor(orgID, actorID, invitationID string) (*Invitation, error) {
+	if m.invitationAdapter == nil || m.organizationAdapter == nil {
+		return nil, ErrInvitationsNotSupported
+	}
+
+	invitation, err := m.invitationAdapter.GetInvitation(orgID, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.organizationActor(orgID, actorID, invitation.Role); err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
+
+func newInvitationToken() (string, error) {
+	tokenBytes := make([]byte, invitationTokenBytesLen)
+	if _, err := GenerateRandomBytes(tokenBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(tokenBytes), nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const sessionRevokeTokenBytesLen = 32
+
+// SetKnownDeviceAdapter remembers the device behind every sign-in and
+// publishes a NewDeviceSignInEvent when a user signs in from a device not
+// seen on the account within KnownDeviceTTL. A user without any device on
+// record, such as a new account, is not alerted about the first one.
+func (m *AuthManager) SetKnownDeviceAdapter(adapter KnownDeviceAdapter) {
+	if m.knownDeviceAdapter == nil {
+		On(m.events, m.recordSignInDevice)
+	}
+	m.knownDeviceAdapter = adapter
+}
+
+// RevokeSessionByLink redeems the revoke token of a NewDeviceSignInEvent and
+// returns the device it was issued for; the caller ends the device's
+// SessionPublicID. The device is forgotten, so the next sign-in from it is
+// reported again. The token is consumed even when it has expired.
+func (m *AuthManager) RevokeSessionByLink(token string) (*KnownDevice, error) {
+	if m.knownDeviceAdapter == nil {
+		return nil, ErrKnownDevicesNotSupported
+	}
+	if token == "" {
+		return nil, ErrSessionRevokeLinkInvalid
+	}
+
+	device, err := m.knownDeviceAdapter.ConsumeSessionRevokeToken(HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(device.RevokeExpiresAt) {
+		return nil, ErrSessionRevokeLinkExpired
+	}
+	return device, nil
+}
+
+// PurgeStaleKnownDevices removes devices not seen within KnownDeviceTTL,
+// which would count as new anyway
+func (m *AuthManager) PurgeStaleKnownDevices() (int64, error) {
+	if m.knownDeviceAdapter == nil || m.config.KnownDeviceTTL <= 0 {
+		return 0, nil
+	}
+	return m.knownDeviceAdapter.DeleteStaleKnownDevices(time.Now().Add(-m.config.KnownDeviceTTL))
+}
+
+func (m *AuthManager) recordSignInDevice(e LoginSucceededEvent) {
+	if m.config.KnownDeviceTTL <= 0 {
+		return
+	}
+
+	newDevice, err := m.rememberSignInDevice(e)
+	if err != nil {
+		slog.Error("failed to record sign-in device", "user_id", e.UserID, "error", err)
+		return
+	}
+	if newDevice != nil {
+		m.events.Publish(*newDevice)
+	}
+}
+
+// rememberSignInDevice stores the device a sign-in came from and returns the
+// event to publish when the device is new to the account
+func (m *AuthManager) rememberSignInDevice(e LoginSucceededEvent) (*NewDeviceSignInEvent, error) {
+	family := UserAgentFamily(e.UserAgent)
+	network := m.ipNetwork(e.IP)
+	if network == "" {
+		network = e.IP
+	}
+
+	device, err := m.knownDeviceAdapter.FindKnownDevice(e.UserID, family, network)
+	switch {
+	case err == nil:
+		recent := e.OccurredAt.Sub(device.LastSeenAt) < m.config.KnownDeviceTTL
+		device.LastIP = e.IP
+		device.LastSeenAt = e.OccurredAt
+		if recent {
+			return nil, m.knownDeviceAdapter.SaveKnownDevice(device)
+		}
+	case errors.Is(err, ErrKnownDeviceNotFound):
+		hasDevices, err := m.knownDeviceAdapter.HasKnownDevices(e.UserID)
+		if err != nil {
+			return nil, err
+		}
+		device = &KnownDevice{
+			UserID:          e.UserID,
+			UserAgentFamily: family,
+			IPNetwork:       network,
+			LastIP:          e.IP,
+			CreatedAt:       e.OccurredAt,
+			LastSeenAt:      e.OccurredAt,
+		}
+		if !hasDevices {
+			return nil, m.knownDeviceAdapter.SaveKnownDevice(device)
+		}
+	default:
+		return nil, err
+	}
+
+	tokenBytes := make([]byte, sessionRevokeTokenBytesLen)
+	if _, err := GenerateRandomBytes(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	device.SessionPublicID = e.SessionPublicID
+	device.RevokeTokenHash = HashToken(token)
+	device.RevokeExpiresAt = e.OccurredAt.Add(m.config.SessionRevokeLinkTTL)
+	if err := m.knownDeviceAdapter.SaveKnownDevice(device); err != nil {
+		return nil, err
+	}
+
+	return &NewDeviceSignInEvent{
+		UserID:          e.UserID,
+		SessionPublicID: e.SessionPublicID,
+		UserAgentFamily: family,
+		IP:              e.IP,
+		UserAgent:       e.UserAgent,
+		RevokeToken:     token,
+		RevokeExpiresAt: device.RevokeExpiresAt,
+		OccurredAt:      e.OccurredAt,
+	}, nil
+}
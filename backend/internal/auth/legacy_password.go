@@ -0,0 +1,603 @@
+package auth
+
+import (
+	"crypto/md5"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Legacy password hash formats understood by DefaultLegacyHashFormats
+const (
+	DjangoPBKDF2SHA256Format = "django_pbkdf2_sha256"
+	DjangoPBKDF2SHA1Format   = "django_pbkdf2_sha1"
+	DjangoBcryptSHA256Format = "django_bcrypt_sha256"
+	DjangoBcryptFormat       = "django_bcrypt"
+	DjangoArgon2Format       = "django_argon2"
+	PBKDF2SHA256Format       = "pbkdf2_sha256"
+	PBKDF2SHA512Format       = "pbkdf2_sha512"
+	SHA256CryptFormat        = "sha256_crypt"
+	SHA512CryptFormat        = "sha512_crypt"
+	MD5CryptFormat           = "md5_crypt"
+	APR1MD5CryptFormat       = "apr1_md5_crypt"
+	Argon2iFormat            = "argon2i"
+)
+
+// LegacyHashFormat verifies password hashes of one format written by another
+// system. Recognize must only look at the shape of the hash; Check decodes it
+// without verifying a password; formats without Check are only recognized.
+type LegacyHashFormat struct {
+	Name      string
+	Recognize func(encoded string) bool
+	Verify    func(password, encoded string) (bool, error)
+	Check     func(encoded string) error
+}
+
+// maxLegacyIterations limits the PBKDF2 iterations and SHA-crypt rounds a
+// legacy hash may ask for, so that verifying one cannot tie up a login
+const maxLegacyIterations = 10_000_000
+
+// LegacyHasher is a registry of password hash formats imported from other
+// systems (Django, Laravel, /etc/shadow, passlib). It only verifies: every
+// hash it recognizes needs a rehash, so users move to the preferred algorithm
+// on their first successful login.
+type LegacyHasher struct {
+	formats []LegacyHashFormat
+}
+
+// NewLegacyHasher returns a registry of the given formats, or of
+// DefaultLegacyHashFormats when none are given
+func NewLegacyHasher(formats ...LegacyHashFormat) *LegacyHasher {
+	if len(formats) == 0 {
+		formats = DefaultLegacyHashFormats()
+	}
+	return &LegacyHasher{formats: formats}
+}
+
+// Register adds a format; formats registered first are tried first
+func (h *LegacyHasher) Register(format LegacyHashFormat) {
+	h.formats = append(h.formats, format)
+}
+
+// Hash always fails with ErrLegacyPasswordHash
+func (h *LegacyHasher) Hash(string) (string, error) {
+	return "", ErrLegacyPasswordHash
+}
+
+// Verify checks password with the format that recognizes encoded
+func (h *LegacyHasher) Verify(password, encoded string) (bool, error) {
+	format := h.formatFor(encoded)
+	if format == nil {
+		return false, ErrUnsupportedPasswordHash
+	}
+	return format.Verify(password, encoded)
+}
+
+// Recognizes reports whether any registered format recognizes encoded
+func (h *LegacyHasher) Recognizes(encoded string) bool {
+	return h.formatFor(encoded) != nil
+}
+
+// CheckHash checks encoded with the format that recognizes it
+func (h *LegacyHasher) CheckHash(encoded string) error {
+	format := h.formatFor(encoded)
+	if format == nil {
+		return ErrUnsupportedPasswordHash
+	}
+	if format.Check == nil {
+		return nil
+	}
+	return format.Check(encoded)
+}
+
+// NeedsRehash is always true: legacy hashes are never kept after a login
+func (h *LegacyHasher) NeedsRehash(string) bool {
+	return true
+}
+
+// Format returns the name of the format that recognizes encoded
+func (h *LegacyHasher) Format(encoded string) (string, bool) {
+	format := h.formatFor(encoded)
+	if format == nil {
+		return "", false
+	}
+	return format.Name, true
+}
+
+func (h *LegacyHasher) formatFor(encoded string) *LegacyHashFormat {
+	for i := range h.formats {
+		if h.formats[i].Recognize(encoded) {
+			return &h.formats[i]
+		}
+	}
+	return nil
+}
+
+// DefaultLegacyHashFormats returns the built-in formats. Laravel's bcrypt
+// ("$2y$") and Argon2id hashes are read by the current hashers; its Argon2i
+// hashes are handled here.
+func DefaultLegacyHashFormats() []LegacyHashFormat {
+	return []LegacyHashFormat{
+		{
+			Name: DjangoPBKDF2SHA256Format, Recognize: hasPrefix("pbkdf2_sha256$"),
+			Verify: verifyPBKDF2(parseDjangoPBKDF2, sha256.New), Check: checkWith(parseDjangoPBKDF2),
+		},
+		{
+			Name: DjangoPBKDF2SHA1Format, Recognize: hasPrefix("pbkdf2_sha1$"),
+			Verify: verifyPBKDF2(parseDjangoPBKDF2, sha1.New), Check: checkWith(parseDjangoPBKDF2),
+		},
+		{
+			Name: DjangoBcryptSHA256Format, Recognize: hasPrefix("bcrypt_sha256$"),
+			Verify: verifyDjangoBcrypt("bcrypt_sha256$", true), Check: checkWith(parseDjangoBcrypt("bcrypt_sha256$")),
+		},
+		{
+			Name: DjangoBcryptFormat, Recognize: hasPrefix("bcrypt$"),
+			Verify: verifyDjangoBcrypt("bcrypt$", false), Check: checkWith(parseDjangoBcrypt("bcrypt$")),
+		},
+		{
+			Name: DjangoArgon2Format, Recognize: hasPrefix("argon2$"),
+			Verify: verifyDjangoArgon2, Check: checkDjangoArgon2,
+		},
+		{
+			Name: PBKDF2SHA256Format, Recognize: hasPrefix("$pbkdf2-sha256$"),
+			Verify: verifyPBKDF2(parsePasslibPBKDF2, sha256.New), Check: checkWith(parsePasslibPBKDF2),
+		},
+		{
+			Name: PBKDF2SHA512Format, Recognize: hasPrefix("$pbkdf2-sha512$"),
+			Verify: verifyPBKDF2(parsePasslibPBKDF2, sha512.New), Check: checkWith(parsePasslibPBKDF2),
+		},
+		{
+			Name: SHA256CryptFormat, Recognize: hasPrefix("$5$"),
+			Verify: verifySHACrypt("$5$", sha256.New), Check: checkWith(parseSHACrypt("$5$")),
+		},
+		{
+			Name: SHA512CryptFormat, Recognize: hasPrefix("$6$"),
+			Verify: verifySHACrypt("$6$", sha512.New), Check: checkWith(parseSHACrypt("$6$")),
+		},
+		{
+			Name: MD5CryptFormat, Recognize: hasPrefix("$1$"),
+			Verify: verifyMD5Crypt("$1$"), Check: checkWith(parseMD5Crypt("$1$")),
+		},
+		{
+			Name: APR1MD5CryptFormat, Recognize: hasPrefix("$apr1$"),
+			Verify: verifyMD5Crypt("$apr1$"), Check: checkWith(parseMD5Crypt("$apr1$")),
+		},
+		{
+			Name: Argon2iFormat, Recognize: hasPrefix("$argon2i$"),
+			Verify: verifyArgon2i, Check: checkArgon2i,
+		},
+	}
+}
+
+// checkWith turns a format's parse function into its Check
+func checkWith[T any](parse func(encoded string) (T, error)) func(string) error {
+	return func(encoded string) error {
+		_, err := parse(encoded)
+		return err
+	}
+}
+
+func hasPrefix(prefix string) func(string) bool {
+	return func(encoded string) bool {
+		return strings.HasPrefix(encoded, prefix)
+	}
+}
+
+// pbkdf2Hash is a decoded PBKDF2 hash
+type pbkdf2Hash struct {
+	iterations int
+	salt       []byte
+	key        []byte
+}
+
+// parseDjangoPBKDF2 reads Django's "<algorithm>$<iterations>$<salt>$<base64 hash>"
+func parseDjangoPBKDF2(encoded string) (pbkdf2Hash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 4 {
+		return pbkdf2Hash{}, ErrUnsupportedPasswordHash
+	}
+	iterations, err := parsePBKDF2Iterations(parts[1])
+	if err != nil {
+		return pbkdf2Hash{}, err
+	}
+	key, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return pbkdf2Hash{}, ErrUnsupportedPasswordHash
+	}
+	return pbkdf2Hash{iterations: iterations, salt: []byte(parts[2]), key: key}, nil
+}
+
+// parsePasslibPBKDF2 reads passlib's "$pbkdf2-<digest>$<iterations>$<salt>$<hash>",
+// salt and hash in its base64 variant using "." for "+"; "i=<iterations>" as
+// written by PHC encoders is accepted too
+func parsePasslibPBKDF2(encoded string) (pbkdf2Hash, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 5 {
+		return pbkdf2Hash{}, ErrUnsupportedPasswordHash
+	}
+	iterations, err := parsePBKDF2Iterations(strings.TrimPrefix(parts[2], "i="))
+	if err != nil {
+		return pbkdf2Hash{}, err
+	}
+	salt, err := decodeAdaptedBase64(parts[3])
+	if err != nil {
+		return pbkdf2Hash{}, ErrUnsupportedPasswordHash
+	}
+	key, err := decodeAdaptedBase64(parts[4])
+	if err != nil || len(key) == 0 {
+		return pbkdf2Hash{}, ErrUnsupportedPasswordHash
+	}
+	return pbkdf2Hash{iterations: iterations, salt: salt, key: key}, nil
+}
+
+func parsePBKDF2Iterations(value string) (int, error) {
+	iterations, err := strconv.Atoi(value)
+	if err != nil || iterations <= 0 || iterations > maxLegacyIterations {
+		return 0, ErrUnsupportedPasswordHash
+	}
+	return iterations, nil
+}
+
+func decodeAdaptedBase64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.ReplaceAll(strings.TrimRight(s, "="), ".", "+"))
+}
+
+// verifyPBKDF2 verifies the PBKDF2 hashes read by parse with the given digest
+func verifyPBKDF2(parse func(string) (pbkdf2Hash, error), newHash func() hash.Hash) func(string, string) (bool, error) {
+	return func(password, encoded string) (bool, error) {
+		h, err := parse(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		candidate, err := pbkdf2.Key(newHash, password, h.salt, h.iterations, len(h.key))
+		if err != nil {
+			return false, fmt.Errorf("%w: %v", ErrUnsupportedPasswordHash, err)
+		}
+		return subtle.ConstantTimeCompare(candidate, h.key) == 1, nil
+	}
+}
+
+// verifyDjangoBcrypt reads Django's "bcrypt$<bcrypt hash>" and
+// "bcrypt_sha256$<bcrypt hash>"; the latter bcrypts the hex SHA-256 of the
+// password so long passwords are not truncated
+func verifyDjangoBcrypt(prefix string, prehash bool) func(string, string) (bool, error) {
+	parse := parseDjangoBcrypt(prefix)
+	return func(password, encoded string) (bool, error) {
+		inner, err := parse(encoded)
+		if err != nil {
+			return false, err
+		}
+		if prehash {
+			sum := sha256.Sum256([]byte(password))
+			password = hex.EncodeToString(sum[:])
+		}
+		return (&BcryptHasher{}).Verify(password, inner)
+	}
+}
+
+// parseDjangoBcrypt returns the bcrypt hash inside a Django bcrypt hash
+func parseDjangoBcrypt(prefix string) func(string) (string, error) {
+	return func(encoded string) (string, error) {
+		inner := strings.TrimPrefix(encoded, prefix)
+		if err := (&BcryptHasher{}).CheckHash(inner); err != nil {
+			return "", err
+		}
+		return inner, nil
+	}
+}
+
+// verifyDjangoArgon2 reads Django's "argon2" prefixed Argon2 PHC strings
+func verifyDjangoArgon2(password, encoded string) (bool, error) {
+	inner := strings.TrimPrefix(encoded, "argon2")
+	switch {
+	case strings.HasPrefix(inner, "$argon2id$"):
+		return (&Argon2idHasher{}).Verify(password, inner)
+	case strings.HasPrefix(inner, "$argon2i$"):
+		return verifyArgon2i(password, inner)
+	default:
+		return false, ErrUnsupportedPasswordHash
+	}
+}
+
+func checkDjangoArgon2(encoded string) error {
+	inner := strings.TrimPrefix(encoded, "argon2")
+	switch {
+	case strings.HasPrefix(inner, "$argon2id$"):
+		return (&Argon2idHasher{}).CheckHash(inner)
+	case strings.HasPrefix(inner, "$argon2i$"):
+		return checkArgon2i(inner)
+	default:
+		return ErrUnsupportedPasswordHash
+	}
+}
+
+// verifyArgon2i reads Argon2i PHC strings, such as Laravel's PASSWORD_ARGON2I
+func verifyArgon2i(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2(encoded, "argon2i")
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.Key([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+func checkArgon2i(encoded string) error {
+	_, _, _, err := decodeArgon2(encoded, "argon2i")
+	return err
+}
+
+// cryptAlphabet is the base64 alphabet of the crypt(3) family
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// cryptEncode writes digest bytes in the order given by groups, three bytes
+// (most significant first) to four characters, least significant bits first.
+// The last group may be shorter and produce fewer characters.
+func cryptEncode(digest []byte, groups [][]int) string {
+	var out strings.Builder
+	for _, group := range groups {
+		var w uint
+		for _, i := range group {
+			w = w<<8 | uint(digest[i])
+		}
+		for n := len(group) + 1; n > 0; n-- {
+			out.WriteByte(cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	return out.String()
+}
+
+// SHA-crypt rounds as specified by Ulrich Drepper. The specified maximum of
+// 999999999 rounds is far above maxLegacyIterations, which applies instead.
+const (
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxSaltLen    = 16
+)
+
+var (
+	sha256CryptGroups = [][]int{
+		{0, 10, 20}, {21, 1, 11}, {12, 22, 2}, {3, 13, 23}, {24, 4, 14},
+		{15, 25, 5}, {6, 16, 26}, {27, 7, 17}, {18, 28, 8}, {9, 19, 29}, {31, 30},
+	}
+	sha512CryptGroups = [][]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41}, {63},
+	}
+)
+
+// shaCryptHash is a decoded SHA-crypt hash
+type shaCryptHash struct {
+	rounds   int
+	salt     string
+	checksum string
+}
+
+// sha256CryptChecksumLen and sha512CryptChecksumLen are the lengths of the
+// encoded digests
+const (
+	sha256CryptChecksumLen = 43
+	sha512CryptChecksumLen = 86
+)
+
+// parseSHACrypt reads glibc's "$5$[rounds=<n>$]<salt>$<hash>" (SHA-256) and
+// "$6$..." (SHA-512) hashes, as found in /etc/shadow
+func parseSHACrypt(magic string) func(string) (shaCryptHash, error) {
+	checksumLen := sha256CryptChecksumLen
+	if magic == "$6$" {
+		checksumLen = sha512CryptChecksumLen
+	}
+
+	return func(encoded string) (shaCryptHash, error) {
+		rest := strings.TrimPrefix(encoded, magic)
+		rounds := shaCryptDefaultRounds
+		if strings.HasPrefix(rest, "rounds=") {
+			value, remainder, ok := strings.Cut(strings.TrimPrefix(rest, "rounds="), "$")
+			n, err := strconv.Atoi(value)
+			if !ok || err != nil || n > maxLegacyIterations {
+				return shaCryptHash{}, ErrUnsupportedPasswordHash
+			}
+			rounds = max(n, shaCryptMinRounds)
+			rest = remainder
+		}
+
+		salt, checksum, ok := strings.Cut(rest, "$")
+		if !ok || len(checksum) != checksumLen || len(salt) > shaCryptMaxSaltLen {
+			return shaCryptHash{}, ErrUnsupportedPasswordHash
+		}
+		return shaCryptHash{rounds: rounds, salt: salt, checksum: checksum}, nil
+	}
+}
+
+// verifySHACrypt verifies the hashes read by parseSHACrypt
+func verifySHACrypt(magic string, newHash func() hash.Hash) func(string, string) (bool, error) {
+	parse := parseSHACrypt(magic)
+	groups := sha256CryptGroups
+	if magic == "$6$" {
+		groups = sha512CryptGroups
+	}
+
+	return func(password, encoded string) (bool, error) {
+		h, err := parse(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		digest := shaCrypt(newHash, []byte(password), []byte(h.salt), h.rounds)
+		candidate := cryptEncode(digest, groups)
+		return subtle.ConstantTimeCompare([]byte(candidate), []byte(h.checksum)) == 1, nil
+	}
+}
+
+func shaCrypt(newHash func() hash.Hash, password, salt []byte, rounds int) []byte {
+	b := newHash()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	digestB := b.Sum(nil)
+	size := len(digestB)
+
+	a := newHash()
+	a.Write(password)
+	a.Write(salt)
+	n := len(password)
+	for ; n > size; n -= size {
+		a.Write(digestB)
+	}
+	a.Write(digestB[:n])
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(password)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := newHash()
+	for range password {
+		dp.Write(password)
+	}
+	pSeq := repeatToLength(dp.Sum(nil), len(password))
+
+	ds := newHash()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(salt)
+	}
+	sSeq := repeatToLength(ds.Sum(nil), len(salt))
+
+	c := newHash()
+	for i := range rounds {
+		c.Reset()
+		if i&1 != 0 {
+			c.Write(pSeq)
+		} else {
+			c.Write(digestA)
+		}
+		if i%3 != 0 {
+			c.Write(sSeq)
+		}
+		if i%7 != 0 {
+			c.Write(pSeq)
+		}
+		if i&1 != 0 {
+			c.Write(digestA)
+		} else {
+			c.Write(pSeq)
+		}
+		digestA = c.Sum(digestA[:0])
+	}
+	return digestA
+}
+
+func repeatToLength(digest []byte, length int) []byte {
+	out := make([]byte, 0, length)
+	for len(out) < length {
+		out = append(out, digest[:min(len(digest), length-len(out))]...)
+	}
+	return out
+}
+
+const (
+	md5CryptMaxSaltLen  = 8
+	md5CryptChecksumLen = 22
+)
+
+var md5CryptGroups = [][]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}, {11}}
+
+// md5CryptHash is a decoded MD5-crypt hash
+type md5CryptHash struct {
+	salt     string
+	checksum string
+}
+
+// parseMD5Crypt reads Poul-Henning Kamp's "$1$<salt>$<hash>" and Apache's
+// "$apr1$" variant of it
+func parseMD5Crypt(magic string) func(string) (md5CryptHash, error) {
+	return func(encoded string) (md5CryptHash, error) {
+		salt, checksum, ok := strings.Cut(strings.TrimPrefix(encoded, magic), "$")
+		if !ok || len(checksum) != md5CryptChecksumLen {
+			return md5CryptHash{}, ErrUnsupportedPasswordHash
+		}
+		if len(salt) > md5CryptMaxSaltLen {
+			salt = salt[:md5CryptMaxSaltLen]
+		}
+		return md5CryptHash{salt: salt, checksum: checksum}, nil
+	}
+}
+
+// verifyMD5Crypt verifies the hashes read by parseMD5Crypt
+func verifyMD5Crypt(magic string) func(string, string) (bool, error) {
+	parse := parseMD5Crypt(magic)
+	return func(password, encoded string) (bool, error) {
+		h, err := parse(encoded)
+		if err != nil {
+			return false, err
+		}
+
+		digest := md5Crypt([]byte(password), []byte(magic), []byte(h.salt))
+		candidate := cryptEncode(digest, md5CryptGroups)
+		return subtle.ConstantTimeCompare([]byte(candidate), []byte(h.checksum)) == 1, nil
+	}
+}
+
+func md5Crypt(password, magic, salt []byte) []byte {
+	alt := md5.New()
+	alt.Write(password)
+	alt.Write(salt)
+	alt.Write(password)
+	altSum := alt.Sum(nil)
+
+	ctx := md5.New()
+	ctx.Write(password)
+	ctx.Write(magic)
+	ctx.Write(salt)
+	for n := len(password); n > 0; n -= md5.Size {
+		ctx.Write(altSum[:min(n, md5.Size)])
+	}
+	for i := len(password); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(password[:1])
+		}
+	}
+	final := ctx.Sum(nil)
+
+	for i := range 1000 {
+		round := md5.New()
+		if i&1 != 0 {
+			round.Write(password)
+		} else {
+			round.Write(final)
+		}
+		if i%3 != 0 {
+			round.Write(salt)
+		}
+		if i%7 != 0 {
+			round.Write(password)
+		}
+		if i&1 != 0 {
+			round.Write(final)
+		} else {
+			round.Write(password)
+		}
+		final = round.Sum(final[:0])
+	}
+	return final
+}
@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Vectors were produced by Python's hashlib and crypt modules and by
+// `openssl passwd`, all for the password "correct horse"
+var legacyHashVectors = []struct {
+	format  string
+	encoded string
+}{
+	{DjangoPBKDF2SHA256Format, "pbkdf2_sha256$1000$seasalt$mQnueSakb748zqBAC1tmWVZsZbi2zPGZarEzTGdfmso="},
+	{DjangoPBKDF2SHA1Format, "pbkdf2_sha1$1000$seasalt$iQvkNOF1wEL4Khh8eogJ8rUhipM="},
+	{PBKDF2SHA256Format, "$pbkdf2-sha256$1000$MDEyMzQ1Njc4OWFiY2RlZg$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M"},
+	{PBKDF2SHA512Format, "$pbkdf2-sha512$1000$MDEyMzQ1Njc4OWFiY2RlZg$OM0FAoIqCVK1sWtxDiffVlBejtLa.ks4TP71JiecwuSZCG8iLbnlIEPOMoVX.i2B2wkSxjQ8CRGR9OkNGuIPMQ"},
+	{SHA256CryptFormat, "$5$saltstring$vc6YOOogU4kWVvwga8e9zTFgKcy4tb5LaPxIiPJzqEC"},
+	{SHA256CryptFormat, "$5$rounds=1000$saltstringsaltst$q9YlbkuMoociauONftd2e5Ed6VxRd/LLwz2oOt1AaW3"},
+	{SHA512CryptFormat, "$6$saltstring$.r0X7ub5wGR2v4Xz7svCIozfnlorFh19V89t8KC9Umd81uGhFgsyTRNyzQDXDIm17fFcR9z3z7oBNdsyYBbtm/"},
+	{SHA512CryptFormat, "$6$rounds=1000$saltstring$YiR6NSM0LQXmu7vAhkMXfkdYk7qtFouVx1Mdr3HmzXspT8kONjlpU3CsV7ezCB62uXJoAdaagsH4ck7/tB9js."},
+	{MD5CryptFormat, "$1$abcdefgh$y6iHhJNbuC0xpbk0w9pm80"},
+	{APR1MD5CryptFormat, "$apr1$abcdefgh$sIQmFnT1CuEXAsyjuXjUX/"},
+}
+
+func TestLegacyHasher_KnownVectors(t *testing.T) {
+	hasher := NewLegacyHasher()
+
+	for _, vector := range legacyHashVectors {
+		t.Run(vector.encoded, func(t *testing.T) {
+			format, ok := hasher.Format(vector.encoded)
+			require.True(t, ok)
+			assert.Equal(t, vector.format, format)
+			assert.True(t, hasher.NeedsRehash(vector.encoded))
+
+			ok, err := hasher.Verify("correct horse", vector.encoded)
+			require.NoError(t, err)
+			assert.True(t, ok)
+
+			ok, err = hasher.Verify("battery staple", vector.encoded)
+			require.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestLegacyHasher_EmptyPasswordMD5Crypt(t *testing.T) {
+	ok, err := NewLegacyHasher().Verify("", "$1$ab$rn6aQS/o7141mj179E/zA.")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestLegacyHasher_DjangoBcryptSHA256(t *testing.T) {
+	sum := sha256.Sum256([]byte("correct horse"))
+	inner, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(sum[:])), bcrypt.MinCost)
+	require.NoError(t, err)
+	encoded := "bcrypt_sha256$" + string(inner)
+
+	hasher := NewLegacyHasher()
+	format, _ := hasher.Format(encoded)
+	assert.Equal(t, DjangoBcryptSHA256Format, format)
+
+	ok, err := hasher.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("battery staple", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestLegacyHasher_Argon2i(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	key := argon2.Key([]byte("correct horse"), salt, 1, 1024, 1, 32)
+	encoded := fmt.Sprintf("$argon2i$v=19$m=1024,t=1,p=1$%s$%s",
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+
+	hasher := NewLegacyHasher()
+	for _, candidate := range []string{encoded, "argon2" + encoded} {
+		ok, err := hasher.Verify("correct horse", candidate)
+		require.NoError(t, err)
+		assert.True(t, ok, candidate)
+
+		ok, err = hasher.Verify("battery staple", candidate)
+		require.NoError(t, err)
+		assert.False(t, ok, candidate)
+	}
+}
+
+func TestLegacyHasher_RejectsMalformedAndUnknownHashes(t *testing.T) {
+	hasher := NewLegacyHasher()
+
+	for _, encoded := range []string{
+		"pbkdf2_sha256$abc$seasalt$AAAA",
+		"$pbkdf2-sha256$1000$salt",
+		"$5$saltstring",
+		"$5$rounds=x$saltstring$abc",
+		"$1$abcdefgh",
+	} {
+		_, err := hasher.Verify("correct horse", encoded)
+		assert.ErrorIs(t, err, ErrUnsupportedPasswordHash, encoded)
+	}
+
+	assert.False(t, hasher.Recognizes("plaintext"))
+	_, err := hasher.Verify("correct horse", "plaintext")
+	assert.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+
+	_, err = hasher.Hash("correct horse")
+	assert.ErrorIs(t, err, ErrLegacyPasswordHash)
+}
+
+func TestPasswordHasher_CheckHash(t *testing.T) {
+	hasher, err := NewPasswordHasher(Argon2idAlgorithm, bcrypt.MinCost, testArgon2idParams)
+	require.NoError(t, err)
+
+	current, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	bcryptHash, err := NewBcryptHasher(bcrypt.MinCost).Hash("correct horse")
+	require.NoError(t, err)
+
+	valid := []string{current, bcryptHash, "bcrypt$" + bcryptHash, "argon2" + current}
+	for _, vector := range legacyHashVectors {
+		valid = append(valid, vector.encoded)
+	}
+	for _, encoded := range valid {
+		assert.NoError(t, hasher.CheckHash(encoded), encoded)
+	}
+
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{name: "unknown format", encoded: "plaintext"},
+		{name: "truncated bcrypt", encoded: bcryptHash[:40]},
+		{name: "argon2id without lanes", encoded: "$argon2id$v=19$m=1024,t=1,p=0$c2FsdHNhbHQ$aGFzaA"},
+		{name: "argon2id with huge memory", encoded: "$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{name: "django argon2 without passes", encoded: "argon2$argon2i$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$aGFzaA"},
+		{name: "pbkdf2 without iterations", encoded: "pbkdf2_sha256$0$seasalt$AAAA"},
+		{name: "pbkdf2 with too many iterations", encoded: "$pbkdf2-sha256$999999999$MDEy$cBg8D2DungRB9k76szThf5ehfyBz991ay6PT8Srwk4M"},
+		{name: "sha-crypt with too many rounds", encoded: "$5$rounds=999999999$saltstring$vc6YOOogU4kWVvwga8e9zTFgKcy4tb5LaPxIiPJzqEC"},
+		{name: "sha-crypt with short checksum", encoded: "$6$saltstring$abc"},
+		{name: "md5-crypt with short checksum", encoded: "$1$abcdefgh$y6iH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, hasher.CheckHash(tt.encoded), ErrUnsupportedPasswordHash)
+		})
+	}
+}
+
+func TestLegacyHasher_Register(t *testing.T) {
+	hasher := NewLegacyHasher()
+	hasher.Register(LegacyHashFormat{
+		Name:      "plain_sha256",
+		Recognize: hasPrefix("sha256:"),
+		Verify: func(password, encoded string) (bool, error) {
+			sum := sha256.Sum256([]byte(password))
+			return encoded == "sha256:"+hex.EncodeToString(sum[:]), nil
+		},
+	})
+
+	sum := sha256.Sum256([]byte("correct horse"))
+	ok, err := hasher.Verify("correct horse", "sha256:"+hex.EncodeToString(sum[:]))
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestPasswordHasher_AcceptsLegacyHashesAndRehashes(t *testing.T) {
+	hasher, err := NewPasswordHasher(Argon2idAlgorithm, bcrypt.MinCost, testArgon2idParams)
+	require.NoError(t, err)
+
+	for _, vector := range legacyHashVectors {
+		assert.True(t, hasher.Recognizes(vector.encoded), vector.encoded)
+		assert.True(t, hasher.NeedsRehash(vector.encoded), vector.encoded)
+
+		ok, err := hasher.Verify("correct horse", vector.encoded)
+		require.NoError(t, err)
+		assert.True(t, ok, vector.encoded)
+	}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// SetLockoutAdapter replaces the default in-memory lockout store, e.g. with
+// one shared by every replica
+func (m *AuthManager) SetLockoutAdapter(adapter LockoutAdapter) {
+	m.lockoutAdapter = adapter
+}
+
+// PurgeLockouts removes lockout records that no longer affect anyone and
+// returns how many were removed
+func (m *AuthManager) PurgeLockouts() (int64, error) {
+	return m.lockoutAdapter.PurgeLockouts(time.Now().Add(-m.config.LockoutDuration))
+}
+
+// --- Rate limiting helpers ---
+
+// isAccountLocked fails closed: if the lockout store cannot be read the
+// account is treated as locked.
+func (m *AuthManager) isAccountLocked(identifier string) bool {
+	locked, err := m.lockoutAdapter.IsLocked(identifier)
+	if err != nil {
+		return true
+	}
+	return locked
+}
+
+// recordFailedAttempt counts a failure for an identifier that callers found
+// unlocked, so a lock seen afterwards was caused by this attempt
+func (m *AuthManager) recordFailedAttempt(identifier string) {
+	if err := m.lockoutAdapter.RecordFailedAttempt(identifier, m.config.MaxFailedAttempts, m.config.LockoutDuration); err == nil {
+		if locked, err := m.lockoutAdapter.IsLocked(identifier); err == nil && locked {
+			now := time.Now()
+			m.events.Publish(AccountLockedEvent{
+				Identifier:  identifier,
+				LockedUntil: now.Add(m.config.LockoutDuration),
+				OccurredAt:  now,
+			})
+		}
+	}
+	m.purgeLockoutsIfDue()
+}
+
+func (m *AuthManager) clearFailedAttempts(identifier string) {
+	_ = m.lockoutAdapter.ClearFailedAttempts(identifier)
+}
+
+// purgeLockoutsIfDue purges stale records at most once per LockoutDuration,
+// so the store shrinks without a separate cleanup job
+func (m *AuthManager) purgeLockoutsIfDue() {
+	m.lockoutPurgeMutex.Lock()
+	due := time.Since(m.lockoutPurgedAt) >= m.config.LockoutDuration
+	if due {
+		m.lockoutPurgedAt = time.Now()
+	}
+	m.lockoutPurgeMutex.Unlock()
+
+	if due {
+		_, _ = m.PurgeLockouts()
+	}
+}
+
+// MemoryLockoutAdapter keeps lockout state in process memory. It is the
+// default; state is lost on restart and not shared between replicas.
+type MemoryLockoutAdapter struct {
+	mu      sync.Mutex
+	records map[string]memoryLockout
+}
+
+type memoryLockout struct {
+	failedAttempts int
+	lastFailedAt   time.Time
+	lockedUntil    time.Time
+}
+
+// NewMemoryLockoutAdapter creates an empty in-memory lockout store
+func NewMemoryLockoutAdapter() *MemoryLockoutAdapter {
+	return &MemoryLockoutAdapter{records: make(map[string]memoryLockout)}
+}
+
+// RecordFailedAttempt counts a failed attempt and locks the identifier once maxAttempts is reached
+func (a *MemoryLockoutAdapter) RecordFailedAttempt(identifier string, maxAttempts int, lockoutDuration time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	record := a.records[identifier]
+	if now.Sub(record.lastFailedAt) > lockoutDuration {
+		record.failedAttempts = 0
+	}
+	record.failedAttempts++
+	record.lastFailedAt = now
+	if record.failedAttempts >= maxAttempts {
+		record.lockedUntil = now.Add(lockoutDuration)
+	}
+
+	a.records[identifier] = record
+	return nil
+}
+
+// IsLocked reports whether the identifier is currently locked
+func (a *MemoryLockoutAdapter) IsLocked(identifier string) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	record, exists := a.records[identifier]
+	return exists && time.Now().Before(record.lockedUntil), nil
+}
+
+// ClearFailedAttempts forgets the identifier's failed attempts and lock
+func (a *MemoryLockoutAdapter) ClearFailedAttempts(identifier string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.records, identifier)
+	return nil
+}
+
+// PurgeLockouts removes records whose last failed attempt is before the cutoff
+func (a *MemoryLockoutAdapter) PurgeLockouts(before time.Time) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var removed int64
+	for identifier, record := range a.records {
+		if record.lastFailedAt.Before(before) {
+			delete(a.records, identifier)
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"encoding/hex"
+	"time"
+)
+
+const magicLinkTokenBytesLen = 32
+
+// MagicLink is a freshly issued login link. Token must be delivered to the
+// user out of band (by email) and is not stored anywhere.
+type MagicLink struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// SetMagicLinkAdapter enables magic-link login backed by the given adapter
+func (m *AuthManager) SetMagicLinkAdapter(adapter MagicLinkAdapter) {
+	m.magicLinkAdapter = adapter
+}
+
+// CreateMagicLink issues a single-use login link for the user, replacing any
+// link sent earlier. Inactive and locked accounts get no link.
+func (m *AuthManager) CreateMagicLink(user *UserData) (*MagicLink, error) {
+	if m.magicLinkAdapter == nil {
+		return nil, ErrMagicLinkNotSupported
+	}
+	if !user.Active {
+		return nil, ErrUserNotActive
+	}
+	if m.isAccountLocked(user.Identifier) {
+		return nil, ErrAccountLocked
+	}
+
+	tokenBytes := make([]byte, magicLinkTokenBytesLen)
+	if _, err := GenerateRandomBytes(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	now := time.Now()
+	expiresAt := now.Add(m.config.MagicLinkTTL)
+	if err := m.magicLinkAdapter.ReplaceMagicLinkToken(&MagicLinkToken{
+		TokenHash: HashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &MagicLink{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// LoginWithMagicLink redeems a link created by CreateMagicLink. The link is
+// consumed even when the login is refused. Since the link proves control of
+// the inbox it also verifies the user's email. Like Login, a
+// *TwoFactorRequiredError is returned when the user has a second factor.
+func (m *AuthManager) LoginWithMagicLink(token string, metadata SessionMetadata) (*Session, *UserData, error) {
+	if m.magicLinkAdapter == nil {
+		return nil, nil, ErrMagicLinkNotSupported
+	}
+	if token == "" {
+		return nil, nil, ErrMagicLinkInvalid
+	}
+
+	link, err := m.magicLinkAdapter.ConsumeMagicLinkToken(HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(link.ExpiresAt) {
+		return nil, nil, ErrMagicLinkExpired
+	}
+
+	user, err := m.userAdapter.FindUserByID(link.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if m.isAccountLocked(user.Identifier) {
+		return nil, nil, ErrAccountLocked
+	}
+	if !user.Active {
+		return nil, nil, ErrUserNotActive
+	}
+
+	m.clearFailedAttempts(user.Identifier)
+
+	if err := m.markEmailVerifiedByLogin(user); err != nil {
+		return nil, nil, err
+	}
+
+	return m.completeFirstFactor(user, LoginMethodMagicLink, metadata)
+}
@@ -0,0 +1,319 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const maxOAuthUsernameLen = 30
+
+var oauthUsernameDisallowed = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// OAuthAuthorization is returned when a social login starts. The browser is
+// sent to URL and State must come back unchanged on the callback.
+type OAuthAuthorization struct {
+	URL       string
+	State     string
+	ExpiresAt time.Time
+}
+
+// SetIdentityAdapter enables social login backed by the given adapter
+func (m *AuthManager) SetIdentityAdapter(adapter IdentityAdapter) {
+	m.identityAdapter = adapter
+}
+
+// RegisterOAuthProvider makes a social login provider available under its name
+func (m *AuthManager) RegisterOAuthProvider(provider OAuthProvider) {
+	if m.oauthProviders == nil {
+		m.oauthProviders = make(map[string]OAuthProvider)
+	}
+	m.oauthProviders[provider.Name()] = provider
+}
+
+// OAuthProviders returns the names of the registered providers, sorted
+func (m *AuthManager) OAuthProviders() []string {
+	if m.identityAdapter == nil {
+		return []string{}
+	}
+
+	names := make([]string, 0, len(m.oauthProviders))
+	for name := range m.oauthProviders {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ListIdentities returns the external identities linked to the user
+func (m *AuthManager) ListIdentities(userID string) ([]UserIdentity, error) {
+	if m.identityAdapter == nil {
+		return nil, ErrOAuthNotSupported
+	}
+	return m.identityAdapter.ListIdentities(userID)
+}
+
+// BeginOAuthLogin starts an authorization code flow with PKCE. The state,
+// code verifier and nonce are stored server-side; only the state hash is kept.
+func (m *AuthManager) BeginOAuthLogin(providerName string) (*OAuthAuthorization, error) {
+	provider, err := m.oauthProvider(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := generateOAuthSecret()
+	if err != nil {
+		return nil, err
+	}
+	codeVerifier, err := generateOAuthSecret()
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := generateOAuthSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(m.config.OAuthStateTTL)
+	if err := m.identityAdapter.SaveOAuthState(&OAuthState{
+		StateHash:    HashToken(state),
+		Provider:     providerName,
+		CodeVerifier: codeVerifier,
+		Nonce:        nonce,
+		ExpiresAt:    expiresAt,
+		CreatedAt:    now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &OAuthAuthorization{
+		URL:       provider.AuthCodeURL(state, codeVerifier, nonce),
+		State:     state,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteOAuthLogin finishes a social login started by BeginOAuthLogin.
+//
+// The identity is matched by provider subject first. An unknown identity is
+// linked to the local account with the same email when both sides have the
+// address verified, otherwise a new user is created. Like Login, a
+// *TwoFactorRequiredError is returned when the user has a second factor.
+func (m *AuthManager) CompleteOAuthLogin(
+	ctx context.Context,
+	providerName, state, code string,
+	metadata SessionMetadata,
+) (*Session, *UserData, error) {
+	provider, err := m.oauthProvider(providerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending, err := m.consumeOAuthState(state, providerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity, err := provider.Exchange(ctx, code, pending.CodeVerifier, pending.Nonce)
+	if err != nil {
+		return nil, nil, err
+	}
+	identity.Provider = providerName
+
+	user, err := m.resolveOAuthUser(identity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if m.isAccountLocked(user.Identifier) {
+		return nil, nil, ErrAccountLocked
+	}
+	if !user.Active {
+		return nil, nil, ErrUserNotActive
+	}
+
+	return m.completeFirstFactor(user, LoginMethodOAuth, metadata)
+}
+
+func (m *AuthManager) oauthProvider(name string) (OAuthProvider, error) {
+	if m.identityAdapter == nil {
+		return nil, ErrOAuthNotSupported
+	}
+
+	provider, ok := m.oauthProviders[name]
+	if !ok {
+		return nil, ErrOAuthProviderNotFound
+	}
+	return provider, nil
+}
+
+// consumeOAuthState loads and deletes the pending request so a callback URL
+// cannot be replayed
+func (m *AuthManager) consumeOAuthState(state, providerName string) (*OAuthState, error) {
+	if state == "" {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	stateHash := HashToken(state)
+	pending, err := m.identityAdapter.GetOAuthState(stateHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.identityAdapter.DeleteOAuthState(stateHash); err != nil {
+		return nil, err
+	}
+
+	if pending.Provider != providerName || time.Now().After(pending.ExpiresAt) {
+		return nil, ErrOAuthStateInvalid
+	}
+	return pending, nil
+}
+
+func (m *AuthManager) resolveOAuthUser(identity *OAuthIdentity) (*UserData, error) {
+	linked, err := m.identityAdapter.FindIdentity(identity.Provider, identity.Subject)
+	if err == nil {
+		return m.userAdapter.FindUserByID(linked.UserID)
+	}
+	if !errors.Is(err, ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrOAuthEmailNotVerified
+	}
+
+	user, err := m.userAdapter.FindUserByIdentifier(identity.Email)
+	switch {
+	case err == nil:
+		// Linking to an address nobody proved to own would let whoever
+		// registered it first take over the provider account's login
+		if !strings.EqualFold(user.Email, identity.Email) || !userEmailVerified(user) {
+			return nil, ErrOAuthAccountConflict
+		}
+	case errors.Is(err, ErrInvalidCredentials):
+		if m.config.InviteOnlyRegistration {
+			return nil, ErrRegistrationClosed
+		}
+		if user, err = m.createOAuthUser(identity); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	if err := m.identityAdapter.CreateIdentity(&UserIdentity{
+		UserID:    user.ID,
+		Provider:  identity.Provider,
+		Subject:   identity.Subject,
+		Email:     identity.Email,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createOAuthUser creates a local account for a first-time social login. The
+// account gets a random password, so it can only sign in through the provider
+// until the user resets it.
+func (m *AuthManager) createOAuthUser(identity *OAuthIdentity) (*UserData, error) {
+	username, err := m.availableOAuthUsername(identity)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase, err := GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := strings.TrimSpace(identity.Name)
+	if displayName == "" {
+		displayName = username
+	}
+
+	user, err := m.userAdapter.CreateUser(CreateUserInput{
+		Identifier:  username,
+		Email:       identity.Email,
+		Passphrase:  passphrase,
+		DisplayName: displayName,
+		Attributes:  map[string]any{"email_verified": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.events.Publish(UserRegisteredEvent{
+		UserID:     user.ID,
+		Identifier: user.Identifier,
+		Email:      user.Email,
+		Method:     RegistrationOAuth,
+		OccurredAt: time.Now(),
+	})
+	return user, nil
+}
+
+// availableOAuthUsername derives a username from the provider's username or
+// the email's local part, adding a random suffix when it is already taken
+func (m *AuthManager) availableOAuthUsername(identity *OAuthIdentity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = oauthUsernameDisallowed.ReplaceAllString(base, "")
+	if len(base) > maxOAuthUsernameLen-5 {
+		base = base[:maxOAuthUsernameLen-5]
+	}
+	for len(base) < 3 {
+		base += "_"
+	}
+
+	candidate := base
+	for range 5 {
+		_, err := m.userAdapter.FindUserByIdentifier(candidate)
+		if errors.Is(err, ErrInvalidCredentials) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix, err := randomOAuthSuffix()
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "-" + suffix
+	}
+	return "", ErrOAuthAccountConflict
+}
+
+// generateOAuthSecret returns a random value usable as state, nonce or PKCE
+// verifier (RFC 7636 forbids the padding character)
+func generateOAuthSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func randomOAuthSuffix() (string, error) {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = alphabet[int(b[i])%len(alphabet)]
+	}
+	return string(b), nil
+}
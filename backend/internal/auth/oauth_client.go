@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// ClientAccessTokenPrefix starts every token issued to an OAuth client,
+	// telling them apart from session and personal access tokens
+	ClientAccessTokenPrefix = "gsc_"
+
+	oauthClientIDBytesLen     = 12
+	oauthClientSecretBytesLen = 32
+	clientAccessTokenBytesLen = 32
+	maxOAuthClientNameLen     = 100
+)
+
+// PrincipalType tells who an authenticated request acts for
+type PrincipalType string
+
+const (
+	PrincipalUser   PrincipalType = "user"   // a person, through a session or personal access token
+	PrincipalClient PrincipalType = "client" // a machine, through an OAuth client access token
+)
+
+// ClientToken is a freshly issued client access token
+type ClientToken struct {
+	Token     string
+	Scopes    []string
+	ExpiresAt time.Time
+}
+
+// HasScope reports whether the token was granted the scope
+func (t *ClientAccessToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// IsClientAccessToken reports whether a bearer credential is a token issued
+// to an OAuth client
+func IsClientAccessToken(credential string) bool {
+	return strings.HasPrefix(credential, ClientAccessTokenPrefix)
+}
+
+// SetOAuthClientAdapter enables OAuth clients backed by the given adapter
+func (m *AuthManager) SetOAuthClientAdapter(adapter OAuthClientAdapter) {
+	m.oauthClientAdapter = adapter
+}
+
+// CreateOAuthClient registers a machine client that acts with the role and
+// may request the scopes. The returned secret is the only copy and must be
+// handed to the client's operator right away.
+func (m *AuthManager) CreateOAuthClient(name, role string, scopes []string) (*OAuthClient, string, error) {
+	if m.oauthClientAdapter == nil {
+		return nil, "", ErrOAuthClientsNotSupported
+	}
+
+	scopes, err := normalizeAPITokenScopes(role, scopes)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxOAuthClientNameLen {
+		name = string(runes[:maxOAuthClientNameLen])
+	}
+
+	idBytes := make([]byte, oauthClientIDBytesLen)
+	if _, err := GenerateRandomBytes(idBytes); err != nil {
+		return nil, "", err
+	}
+	secretBytes := make([]byte, oauthClientSecretBytesLen)
+	if _, err := GenerateRandomBytes(secretBytes); err != nil {
+		return nil, "", err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(secretBytes)
+
+	client := &OAuthClient{
+		ID:         hex.EncodeToString(idBytes),
+		Name:       name,
+		SecretHash: HashToken(secret),
+		Role:       role,
+		Scopes:     scopes,
+		CreatedAt:  time.Now(),
+	}
+	if err := m.oauthClientAdapter.CreateOAuthClient(client); err != nil {
+		return nil, "", err
+	}
+
+	return client, secret, nil
+}
+
+// ListOAuthClients returns every registered client
+func (m *AuthManager) ListOAuthClients() ([]OAuthClient, error) {
+	if m.oauthClientAdapter == nil {
+		return nil, ErrOAuthClientsNotSupported
+	}
+	return m.oauthClientAdapter.ListOAuthClients()
+}
+
+// DeleteOAuthClient removes a client; its tokens stop working at once
+func (m *AuthManager) DeleteOAuthClient(clientID string) error {
+	if m.oauthClientAdapter == nil {
+		return ErrOAuthClientsNotSupported
+	}
+	return m.oauthClientAdapter.DeleteOAuthClient(clientID)
+}
+
+// IssueClientAccessToken implements the client credentials grant: it checks
+// the client's secret and issues a token carrying the requested scopes, or
+// every scope the client may request if none are.
+func (m *AuthManager) IssueClientAccessToken(clientID, secret string, scopes []string) (*ClientToken, error) {
+	if m.oauthClientAdapter == nil {
+		return nil, ErrOAuthClientsNotSupported
+	}
+
+	client, err := m.oauthClientAdapter.GetOAuthClient(clientID)
+	if err != nil {
+		if errors.Is(err, ErrOAuthClientNotFound) {
+			return nil, ErrInvalidClientCredentials
+		}
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return nil, ErrInvalidClientCredentials
+	}
+
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	scopes, err = normalizeAPITokenScopes(client.Role, scopes)
+	if err != nil {
+		return nil, err
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(client.Scopes, scope) {
+			return nil, ErrInvalidAPITokenScope
+		}
+	}
+
+	tokenBytes := make([]byte, clientAccessTokenBytesLen)
+	if _, err := GenerateRandomBytes(tokenBytes); err != nil {
+		return nil, err
+	}
+	token := ClientAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(tokenBytes)
+
+	now := time.Now()
+	record := &ClientAccessToken{
+		ClientID:  client.ID,
+		TokenHash: HashToken(token),
+		Scopes:    scopes,
+		ExpiresAt: now.Add(m.config.ClientAccessTokenTTL),
+		CreatedAt: now,
+	}
+	if err := m.oauthClientAdapter.CreateClientAccessToken(record); err != nil {
+		return nil, err
+	}
+
+	return &ClientToken{Token: token, Scopes: scopes, ExpiresAt: record.ExpiresAt}, nil
+}
+
+// ValidateClientAccessToken checks a token issued to an OAuth client and
+// returns it with its client
+func (m *AuthManager) ValidateClientAccessToken(token string) (*ClientAccessToken, *OAuthClient, error) {
+	if m.oauthClientAdapter == nil {
+		return nil, nil, ErrClientAccessTokenNotFound
+	}
+
+	record, err := m.oauthClientAdapter.GetClientAccessTokenByHash(HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	if time.Now().After(record.ExpiresAt) {
+		return nil, nil, ErrClientAccessTokenExpired
+	}
+
+	client, err := m.oauthClientAdapter.GetOAuthClient(record.ClientID)
+	if err != nil {
+		if errors.Is(err, ErrOAuthClientNotFound) {
+			return nil, nil, ErrClientAccessTokenNotFound
+		}
+		return nil, nil, err
+	}
+
+	return record, client, nil
+}
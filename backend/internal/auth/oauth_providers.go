@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+const (
+	// GoogleIssuerURL is the OpenID Connect issuer used for Google sign-in
+	GoogleIssuerURL = "https://accounts.google.com"
+
+	githubAPIURL = "https://api.github.com"
+)
+
+// OAuthClientConfig holds the client registration at a provider
+type OAuthClientConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// OIDCProvider signs users in with any OpenID Connect issuer (Google,
+// Keycloak, Auth0, ...). The ID token signature, audience and nonce are verified.
+type OIDCProvider struct {
+	name     string
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewOIDCProvider discovers the issuer's endpoints and keys and returns a
+// provider registered under name
+func NewOIDCProvider(ctx context.Context, name, issuerURL string, client OAuthClientConfig) (*OIDCProvider, error) {
+	discovered, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := client.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	if !slices.Contains(scopes, oidc.ScopeOpenID) {
+		scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+	}
+
+	return &OIDCProvider{
+		name: name,
+		oauth: oauth2.Config{
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+			RedirectURL:  client.RedirectURL,
+			Endpoint:     discovered.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: discovered.Verifier(&oidc.Config{ClientID: client.ClientID}),
+	}, nil
+}
+
+// Name returns the provider key
+func (p *OIDCProvider) Name() string { return p.name }
+
+// AuthCodeURL returns the issuer's authorization URL
+func (p *OIDCProvider) AuthCodeURL(state, codeVerifier, nonce string) string {
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier), oidc.Nonce(nonce))
+}
+
+// Exchange redeems the code and verifies the returned ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*OAuthIdentity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("%w: no id_token in response", ErrOAuthExchangeFailed)
+	}
+
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrOAuthExchangeFailed)
+	}
+
+	var claims struct {
+		Email             string `json:"email"`
+		EmailVerified     any    `json:"email_verified"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+
+	return &OAuthIdentity{
+		Provider:      p.name,
+		Subject:       idToken.Subject,
+		Email:         claims.Email,
+		EmailVerified: claimIsTrue(claims.EmailVerified),
+		Name:          claims.Name,
+		Username:      claims.PreferredUsername,
+	}, nil
+}
+
+// GitHubProvider signs users in with GitHub, which speaks plain OAuth2.
+// The identity comes from the REST API and only the primary verified email is used.
+type GitHubProvider struct {
+	name  string
+	oauth oauth2.Config
+}
+
+// NewGitHubProvider returns a GitHub provider registered under name
+func NewGitHubProvider(name string, client OAuthClientConfig) *GitHubProvider {
+	scopes := client.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+
+	return &GitHubProvider{
+		name: name,
+		oauth: oauth2.Config{
+			ClientID:     client.ClientID,
+			ClientSecret: client.ClientSecret,
+			RedirectURL:  client.RedirectURL,
+			Endpoint:     endpoints.GitHub,
+			Scopes:       scopes,
+		},
+	}
+}
+
+// Name returns the provider key
+func (p *GitHubProvider) Name() string { return p.name }
+
+// AuthCodeURL returns GitHub's authorization URL. GitHub has no nonce; the
+// state and PKCE verifier bind the callback to this request.
+func (p *GitHubProvider) AuthCodeURL(state, codeVerifier, _ string) string {
+	return p.oauth.AuthCodeURL(state, oauth2.S256ChallengeOption(codeVerifier))
+}
+
+// Exchange redeems the code and reads the user's profile and primary email
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, _ string) (*OAuthIdentity, error) {
+	token, err := p.oauth.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	client := p.oauth.Client(ctx, token)
+
+	var profile struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getJSON(ctx, client, "/user", &profile); err != nil {
+		return nil, err
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, client, "/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &OAuthIdentity{
+		Provider: p.name,
+		Subject:  strconv.FormatInt(profile.ID, 10),
+		Name:     profile.Name,
+		Username: profile.Login,
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
+
+func (p *GitHubProvider) getJSON(ctx context.Context, client *http.Client, path string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: github %s returned %d", ErrOAuthExchangeFailed, path, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// claimIsTrue accepts email_verified as a boolean or, as some issuers send
+// it, the string "true"
+func claimIsTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
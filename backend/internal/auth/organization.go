@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Organization roles, from least to most privileged
+const (
+	OrganizationRoleMember = "member" // uses the organization's resources
+	OrganizationRoleAdmin  = "admin"  // also manages members
+	OrganizationRoleOwner  = "owner"  // also manages admins and owners and deletes the organization
+)
+
+const maxOrganizationNameLen = 100
+
+var organizationRoleRanks = map[string]int{
+	OrganizationRoleMember: 1,
+	OrganizationRoleAdmin:  2,
+	OrganizationRoleOwner:  3,
+}
+
+// IsOrganizationRole reports whether role is a known organization role
+func IsOrganizationRole(role string) bool {
+	_, ok := organizationRoleRanks[role]
+	return ok
+}
+
+// OrganizationRoleAtLeast reports whether role grants everything minRole does
+func OrganizationRoleAtLeast(role, minRole string) bool {
+	rank, ok := organizationRoleRanks[role]
+	return ok && rank >= organizationRoleRanks[minRole]
+}
+
+// SetOrganizationAdapter enables organizations backed by the given adapter
+func (m *AuthManager) SetOrganizationAdapter(adapter OrganizationAdapter) {
+	m.organizationAdapter = adapter
+}
+
+// CreateOrganization creates an organization owned by the user
+func (m *AuthManager) CreateOrganization(userID, name string) (*Organization, error) {
+	if m.organizationAdapter == nil {
+		return nil, ErrOrganizationsNotSupported
+	}
+
+	name = strings.TrimSpace(name)
+	if runes := []rune(name); len(runes) > maxOrganizationNameLen {
+		name = string(runes[:maxOrganizationNameLen])
+	}
+
+	org := &Organization{Name: name, CreatedAt: time.Now()}
+	if err := m.organizationAdapter.CreateOrganization(org, userID); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// OrganizationMember returns the user's membership in the organization. An
+// organization the user does not belong to is reported as not found, so that
+// its existence is not revealed.
+func (m *AuthManager) OrganizationMember(orgID, userID string) (*OrganizationMember, error) {
+	if m.organizationAdapter == nil {
+		return nil, ErrOrganizationsNotSupported
+	}
+
+	member, err := m.organizationAdapter.GetOrganizationMember(orgID, userID)
+	if errors.Is(err, ErrOrganizationMemberNotFound) {
+		return nil, ErrOrganizationNotFound
+	}
+	return member, err
+}
+
+// ListUserOrganizations returns the organizations the user belongs to
+func (m *AuthManager) ListUserOrganizations(userID string) ([]OrganizationMember, error) {
+	if m.organizationAdapter == nil {
+		return nil, ErrOrganizationsNotSupported
+	}
+	return m.organizationAdapter.ListUserOrganizations(userID)
+}
+
+// ListOrganizationMembers returns the organization's members
+func (m *AuthManager) ListOrganizationMembers(orgID string) ([]OrganizationMember, error) {
+	if m.organizationAdapter == nil {
+		return nil, ErrOrganizationsNotSupported
+	}
+	return m.organizationAdapter.ListOrganizationMembers(orgID)
+}
+
+// UpdateOrganizationMemberRole changes a member's role on behalf of the actor,
+// who must be an admin of the organization. Only owners may promote members
+// to owner or change an owner's role, and the last owner cannot be demoted.
+func (m *AuthManager) UpdateOrganizationMemberRole(orgID, actorID, userID, role string) error {
+	if !IsOrganizationRole(role) {
+		return ErrInvalidOrganizationRole
+	}
+
+	member, err := m.organizationTarget(orgID, actorID, userID, role)
+	if err != nil {
+		return err
+	}
+	if member.Role == role {
+		return nil
+	}
+	if member.Role == OrganizationRoleOwner {
+		if err := m.checkNotLastOwner(orgID); err != nil {
+			return err
+		}
+	}
+
+	return m.organizationAdapter.UpdateOrganizationMemberRole(orgID, userID, role)
+}
+
+// RemoveOrganizationMember removes a member on behalf of the actor. Members
+// may always leave; removing someone else takes an admin, and removing an
+// owner takes an owner. The last owner cannot leave.
+func (m *AuthManager) RemoveOrganizationMember(orgID, actorID, userID string) error {
+	var member *OrganizationMember
+	var err error
+	if actorID == userID {
+		member, err = m.OrganizationMember(orgID, userID)
+	} else {
+		member, err = m.organizationTarget(orgID, actorID, userID, OrganizationRoleMember)
+	}
+	if err != nil {
+		return err
+	}
+
+	if member.Role == OrganizationRoleOwner {
+		if err := m.checkNotLastOwner(orgID); err != nil {
+			return err
+		}
+	}
+
+	return m.organizationAdapter.RemoveOrganizationMember(orgID, userID)
+}
+
+// DeleteOrganization removes the organization on behalf of the actor, who
+// must own it
+func (m *AuthManager) DeleteOrganization(orgID, actorID string) error {
+	actor, err := m.OrganizationMember(orgID, actorID)
+	if err != nil {
+		return err
+	}
+	if !OrganizationRoleAtLeast(actor.Role, OrganizationRoleOwner) {
+		return ErrInsufficientOrganizationRole
+	}
+	return m.organizationAdapter.DeleteOrganization(orgID)
+}
+
+// SetActiveOrganization makes the organization the one the user works in
+// within the session. The user must belong to it; an empty orgID clears it.
+func (m *AuthManager) SetActiveOrganization(sessionID, userID, orgID string) error {
+	if orgID != "" {
+		if _, err := m.OrganizationMember(orgID, userID); err != nil {
+			return err
+		}
+	}
+	return m.sessionAdapter.SetActiveOrganization(sessionID, orgID)
+}
+
+// organizationActor returns the actor's membership, provided they are an
+// admin of the organization and, when role is owner, an owner
+func (m *AuthManager) organizationActor(orgID, actorID, role string) (*OrganizationMember, error) {
+	actor, err := m.OrganizationMember(orgID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	required := OrganizationRoleAdmin
+	if role == OrganizationRoleOwner {
+		required = OrganizationRoleOwner
+	}
+	if !OrganizationRoleAtLeast(actor.Role, required) {
+		return nil, ErrInsufficientOrganizationRole
+	}
+	return actor, nil
+}
+
+// organizationTarget returns the membership the actor wants to change to
+// role, provided the actor may change it: owners are only managed by owners
+func (m *AuthManager) organizationTarget(orgID, actorID, userID, role string) (*OrganizationMember, error) {
+	actor, err := m.organizationActor(orgID, actorID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := m.organizationAdapter.GetOrganizationMember(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == OrganizationRoleOwner && actor.Role != OrganizationRoleOwner {
+		return nil, ErrInsufficientOrganizationRole
+	}
+	return member, nil
+}
+
+func (m *AuthManager) checkNotLastOwner(orgID string) error {
+	owners, err := m.organizationAdapter.CountOrganizationOwners(orgID)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOrganizationOwner
+	}
+	return nil
+}
@@ -0,0 +1,359 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	passkeyCeremonyRegistration = "registration"
+	passkeyCeremonyLogin        = "login"
+
+	maxPasskeyNameLen = 100
+)
+
+// PasskeyCeremony is returned when a WebAuthn ceremony starts. Options must be
+// handed to navigator.credentials and Token sent back with the response.
+type PasskeyCeremony struct {
+	Token     string
+	ExpiresAt time.Time
+	Options   any
+}
+
+// passkeyUser adapts UserData to the webauthn.User interface
+type passkeyUser struct {
+	user        *UserData
+	credentials []webauthn.Credential
+}
+
+func (u *passkeyUser) WebAuthnID() []byte { return []byte(u.user.ID) }
+
+func (u *passkeyUser) WebAuthnName() string { return u.user.Identifier }
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	if u.user.DisplayName != "" {
+		return u.user.DisplayName
+	}
+	return u.user.Identifier
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential { return u.credentials }
+
+// SetPasskeyAdapter enables passkey support backed by the given adapter, using
+// the relying party settings from the auth config
+func (m *AuthManager) SetPasskeyAdapter(adapter CredentialAdapter) error {
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    m.config.PasskeyCeremonyTTL,
+		TimeoutUVD: m.config.PasskeyCeremonyTTL,
+	}
+
+	relyingParty, err := webauthn.New(&webauthn.Config{
+		RPID:          m.config.WebAuthnRPID,
+		RPDisplayName: m.config.WebAuthnRPDisplayName,
+		RPOrigins:     m.config.WebAuthnRPOrigins,
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	m.credentialAdapter = adapter
+	m.webAuthn = relyingParty
+	return nil
+}
+
+// ListPasskeys returns the passkeys registered by the user
+func (m *AuthManager) ListPasskeys(userID string) ([]PasskeyCredential, error) {
+	if m.credentialAdapter == nil {
+		return nil, ErrPasskeysNotSupported
+	}
+	return m.credentialAdapter.ListPasskeys(userID)
+}
+
+// DeletePasskey removes one of the user's passkeys
+func (m *AuthManager) DeletePasskey(userID, passkeyID string) error {
+	if m.credentialAdapter == nil {
+		return ErrPasskeysNotSupported
+	}
+	return m.credentialAdapter.DeletePasskey(userID, passkeyID)
+}
+
+// BeginPasskeyRegistration starts registering a new passkey for the user.
+// Passkeys the user already owns are excluded so an authenticator is not
+// registered twice.
+func (m *AuthManager) BeginPasskeyRegistration(user *UserData) (*PasskeyCeremony, error) {
+	if m.credentialAdapter == nil {
+		return nil, ErrPasskeysNotSupported
+	}
+
+	owner, err := m.loadPasskeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := m.webAuthn.BeginRegistration(
+		owner,
+		webauthn.WithExclusions(webauthn.Credentials(owner.credentials).CredentialDescriptors()),
+		webauthn.WithAuthenticatorSelection(protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.savePasskeyCeremony(passkeyCeremonyRegistration, user.ID, session, creation)
+}
+
+// FinishPasskeyRegistration verifies the authenticator's attestation and
+// stores the new passkey under the given name
+func (m *AuthManager) FinishPasskeyRegistration(
+	user *UserData,
+	ceremonyToken, name string,
+	response []byte,
+) (*PasskeyCredential, error) {
+	if m.credentialAdapter == nil {
+		return nil, ErrPasskeysNotSupported
+	}
+
+	session, err := m.consumePasskeyCeremony(ceremonyToken, passkeyCeremonyRegistration, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPasskey, err)
+	}
+
+	owner, err := m.loadPasskeyUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	credential, err := m.webAuthn.CreateCredential(owner, *session, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidPasskey, err)
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Passkey"
+	}
+	if runes := []rune(name); len(runes) > maxPasskeyNameLen {
+		name = string(runes[:maxPasskeyNameLen])
+	}
+
+	passkey := &PasskeyCredential{
+		UserID:       user.ID,
+		CredentialID: credential.ID,
+		Name:         name,
+		Data:         data,
+		CreatedAt:    time.Now(),
+	}
+	if err := m.credentialAdapter.CreatePasskey(passkey); err != nil {
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+// BeginPasskeyLogin starts a discoverable (usernameless) login ceremony
+func (m *AuthManager) BeginPasskeyLogin() (*PasskeyCeremony, error) {
+	if m.credentialAdapter == nil {
+		return nil, ErrPasskeysNotSupported
+	}
+
+	assertion, session, err := m.webAuthn.BeginDiscoverableLogin(
+		webauthn.WithUserVerification(protocol.VerificationRequired),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.savePasskeyCeremony(passkeyCeremonyLogin, "", session, assertion)
+}
+
+// FinishPasskeyLogin verifies a passkey assertion and creates a session for
+// the credential's owner.
+//
+// User verification is required by the ceremony, so the passkey already
+// combines possession and a local PIN or biometric and no TOTP challenge is
+// issued afterwards.
+func (m *AuthManager) FinishPasskeyLogin(
+	ceremonyToken string,
+	response []byte,
+	metadata SessionMetadata,
+) (*Session, *UserData, error) {
+	if m.credentialAdapter == nil {
+		return nil, nil, ErrPasskeysNotSupported
+	}
+
+	session, err := m.consumePasskeyCeremony(ceremonyToken, passkeyCeremonyLogin, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(response)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidPasskey, err)
+	}
+
+	var passkey *PasskeyCredential
+	var owner *passkeyUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		found, err := m.credentialAdapter.GetPasskeyByCredentialID(rawID)
+		if err != nil {
+			return nil, err
+		}
+		if found.UserID != string(userHandle) {
+			return nil, ErrPasskeyNotFound
+		}
+
+		user, err := m.userAdapter.FindUserByID(found.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		passkey = found
+		owner, err = m.loadPasskeyUser(user)
+		if err != nil {
+			return nil, err
+		}
+		return owner, nil
+	}
+
+	_, credential, err := m.webAuthn.ValidatePasskeyLogin(handler, *session, parsed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %w", ErrInvalidPasskey, err)
+	}
+
+	// A sign counter that went backwards means the key may have been cloned
+	if credential.Authenticator.CloneWarning {
+		return nil, nil, ErrInvalidPasskey
+	}
+
+	user := owner.user
+	if m.isAccountLocked(user.Identifier) {
+		return nil, nil, ErrAccountLocked
+	}
+	if !user.Active {
+		return nil, nil, ErrUserNotActive
+	}
+	// Passkeys skip completeFirstFactor, so the verification gate is repeated here
+	if m.config.RequireEmailVerification && !userEmailVerified(user) {
+		return nil, nil, ErrEmailNotVerified
+	}
+
+	data, err := json.Marshal(credential)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := m.credentialAdapter.UpdatePasskeyUsage(passkey.ID, data, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	m.clearFailedAttempts(user.Identifier)
+
+	return m.createSession(user, LoginMethodPasskey, metadata)
+}
+
+func (m *AuthManager) loadPasskeyUser(user *UserData) (*passkeyUser, error) {
+	passkeys, err := m.credentialAdapter.ListPasskeys(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]webauthn.Credential, 0, len(passkeys))
+	for _, passkey := range passkeys {
+		var credential webauthn.Credential
+		if err := json.Unmarshal(passkey.Data, &credential); err != nil {
+			return nil, err
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return &passkeyUser{user: user, credentials: credentials}, nil
+}
+
+func (m *AuthManager) savePasskeyCeremony(
+	kind, userID string,
+	session *webauthn.SessionData,
+	options any,
+) (*PasskeyCeremony, error) {
+	token, err := GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := session.Expires
+	if expiresAt.IsZero() {
+		expiresAt = now.Add(m.config.PasskeyCeremonyTTL)
+	}
+
+	if err := m.credentialAdapter.SaveCeremony(&WebAuthnCeremony{
+		TokenHash: HashToken(token),
+		UserID:    userID,
+		Kind:      kind,
+		Data:      data,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &PasskeyCeremony{
+		Token:     token,
+		ExpiresAt: expiresAt,
+		Options:   options,
+	}, nil
+}
+
+// consumePasskeyCeremony loads and deletes a ceremony so each challenge can
+// only be answered once, whether or not verification succeeds
+func (m *AuthManager) consumePasskeyCeremony(token, kind, userID string) (*webauthn.SessionData, error) {
+	tokenHash := HashToken(token)
+	ceremony, err := m.credentialAdapter.GetCeremony(tokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.credentialAdapter.DeleteCeremony(tokenHash); err != nil {
+		return nil, err
+	}
+
+	if ceremony.Kind != kind || ceremony.UserID != userID {
+		return nil, ErrCeremonyNotFound
+	}
+	if time.Now().After(ceremony.ExpiresAt) {
+		return nil, ErrCeremonyExpired
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(ceremony.Data, &session); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCeremonyNotFound, err)
+	}
+
+	return &session, nil
+}
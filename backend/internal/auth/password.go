@@ -0,0 +1,324 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms that can be selected for new hashes
+const (
+	BcryptAlgorithm   = "bcrypt"
+	Argon2idAlgorithm = "argon2id"
+)
+
+// bcryptHashLen is the length of "$2a$<cost>$<salt><hash>"
+const bcryptHashLen = 60
+
+// BcryptHasher hashes passwords with bcrypt. Its "$2a$<cost>$..." strings are
+// the modular crypt format the PHC string format was derived from, so hashes
+// created before hashing was pluggable are read as they are.
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a bcrypt hasher; cost 0 means bcrypt.DefaultCost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash returns a bcrypt hash of password
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify reports whether password matches the bcrypt hash
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: %v", ErrUnsupportedPasswordHash, err)
+	}
+}
+
+// Recognizes reports whether encoded is a bcrypt hash
+func (h *BcryptHasher) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// CheckHash reports whether encoded is a well-formed bcrypt hash
+func (h *BcryptHasher) CheckHash(encoded string) error {
+	if !h.Recognizes(encoded) || len(encoded) != bcryptHashLen {
+		return ErrUnsupportedPasswordHash
+	}
+	if _, err := bcrypt.Cost([]byte(encoded)); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedPasswordHash, err)
+	}
+	return nil
+}
+
+// NeedsRehash reports whether encoded was made with a different cost
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// Argon2idParams are the Argon2id cost parameters. Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// Limits on the Argon2 parameters a hash may carry. Verifying a hash costs
+// what its parameters say, so a stored hash, or an imported one, must not be
+// able to make a login allocate gigabytes or run for minutes.
+const (
+	maxArgon2Memory      = 1024 * 1024 // KiB, 1 GiB
+	maxArgon2Iterations  = 64
+	maxArgon2Parallelism = 64
+)
+
+// Validate reports whether the parameters are ones Argon2 can run with and
+// within the limits accepted for stored hashes
+func (p Argon2idParams) Validate() error {
+	switch {
+	case p.Iterations < 1 || p.Iterations > maxArgon2Iterations,
+		p.Parallelism < 1 || p.Parallelism > maxArgon2Parallelism,
+		p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory:
+		return fmt.Errorf("%w: argon2 parameters m=%d,t=%d,p=%d out of range",
+			ErrUnsupportedPasswordHash, p.Memory, p.Iterations, p.Parallelism)
+	}
+	return nil
+}
+
+// DefaultArgon2idParams returns the OWASP recommended minimum (19 MiB, 2 passes)
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2idHasher hashes passwords with Argon2id and encodes them as PHC
+// strings: $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher returns an Argon2id hasher; zero parameters take their defaults
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	defaults := DefaultArgon2idParams()
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = defaults.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	return &Argon2idHasher{params: params}
+}
+
+// Hash returns an Argon2id PHC string for password with a random salt
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf(
+		"$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether password matches the Argon2id hash, using the
+// parameters stored in the hash
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2(encoded, Argon2idAlgorithm)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+// Recognizes reports whether encoded is an Argon2id PHC string
+func (h *Argon2idHasher) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+// CheckHash reports whether encoded is a well-formed Argon2id PHC string
+// with parameters in range
+func (h *Argon2idHasher) CheckHash(encoded string) error {
+	_, _, _, err := decodeArgon2(encoded, Argon2idAlgorithm)
+	return err
+}
+
+// NeedsRehash reports whether encoded was made with different parameters
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, salt, key, err := decodeArgon2(encoded, Argon2idAlgorithm)
+	if err != nil {
+		return true
+	}
+	return p.Memory != h.params.Memory ||
+		p.Iterations != h.params.Iterations ||
+		p.Parallelism != h.params.Parallelism ||
+		uint32(len(salt)) != h.params.SaltLength ||
+		uint32(len(key)) != h.params.KeyLength
+}
+
+// decodeArgon2 parses a PHC string of the given Argon2 variant ("argon2id" or "argon2i")
+func decodeArgon2(encoded, variant string) (Argon2idParams, []byte, []byte, error) {
+	var p Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != variant {
+		return p, nil, nil, ErrUnsupportedPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrUnsupportedPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrUnsupportedPasswordHash
+	}
+	if err := p.Validate(); err != nil {
+		return p, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrUnsupportedPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, ErrUnsupportedPasswordHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
+
+// MultiHasher hashes new passwords with a preferred hasher and still verifies
+// hashes made by the other accepted ones. Any hash not made by the preferred
+// hasher with its current parameters needs a rehash.
+type MultiHasher struct {
+	preferred PasswordHasher
+	accepted  []PasswordHasher
+}
+
+// NewMultiHasher returns a hasher that writes with preferred and reads with
+// preferred or any of accepted
+func NewMultiHasher(preferred PasswordHasher, accepted ...PasswordHasher) *MultiHasher {
+	return &MultiHasher{preferred: preferred, accepted: accepted}
+}
+
+// Hash hashes password with the preferred hasher
+func (h *MultiHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify checks password with whichever hasher recognizes encoded
+func (h *MultiHasher) Verify(password, encoded string) (bool, error) {
+	hasher := h.hasherFor(encoded)
+	if hasher == nil {
+		return false, ErrUnsupportedPasswordHash
+	}
+	return hasher.Verify(password, encoded)
+}
+
+// Recognizes reports whether any of the hashers recognizes encoded
+func (h *MultiHasher) Recognizes(encoded string) bool {
+	return h.hasherFor(encoded) != nil
+}
+
+// CheckHash checks encoded with whichever hasher recognizes it
+func (h *MultiHasher) CheckHash(encoded string) error {
+	hasher := h.hasherFor(encoded)
+	if hasher == nil {
+		return ErrUnsupportedPasswordHash
+	}
+	return hasher.CheckHash(encoded)
+}
+
+// NeedsRehash reports whether encoded is not a current preferred hash
+func (h *MultiHasher) NeedsRehash(encoded string) bool {
+	return !h.preferred.Recognizes(encoded) || h.preferred.NeedsRehash(encoded)
+}
+
+func (h *MultiHasher) hasherFor(encoded string) PasswordHasher {
+	if h.preferred.Recognizes(encoded) {
+		return h.preferred
+	}
+	for _, hasher := range h.accepted {
+		if hasher.Recognizes(encoded) {
+			return hasher
+		}
+	}
+	return nil
+}
+
+// NewPasswordHasher returns a hasher writing with the named algorithm
+// (Argon2id if empty) and reading bcrypt, Argon2id and the legacy formats
+func NewPasswordHasher(algorithm string, bcryptCost int, argon2Params Argon2idParams) (PasswordHasher, error) {
+	bcryptHasher := NewBcryptHasher(bcryptCost)
+	argon2Hasher := NewArgon2idHasher(argon2Params)
+	if err := argon2Hasher.params.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch algorithm {
+	case "", Argon2idAlgorithm:
+		return NewMultiHasher(argon2Hasher, bcryptHasher, NewLegacyHasher()), nil
+	case BcryptAlgorithm:
+		return NewMultiHasher(bcryptHasher, argon2Hasher, NewLegacyHasher()), nil
+	default:
+		return nil, fmt.Errorf("unknown password hash algorithm %q", algorithm)
+	}
+}
+
+// DefaultPasswordHasher writes Argon2id hashes with the default parameters
+// and accepts bcrypt hashes at the default cost and the legacy formats
+func DefaultPasswordHasher() PasswordHasher {
+	return NewMultiHasher(NewArgon2idHasher(DefaultArgon2idParams()), NewBcryptHasher(bcrypt.DefaultCost), NewLegacyHasher())
+}
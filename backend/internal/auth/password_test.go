@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams keeps the tests fast
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestArgon2idHasher_PHCRoundTrip(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	encoded, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=1024,t=1,p=1$"), encoded)
+	assert.True(t, hasher.Recognizes(encoded))
+	assert.False(t, hasher.NeedsRehash(encoded))
+
+	ok, err := hasher.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = hasher.Verify("battery staple", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Salted: the same password never hashes the same way twice
+	again, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, again)
+}
+
+func TestArgon2idHasher_VerifiesWithStoredParameters(t *testing.T) {
+	old := NewArgon2idHasher(testArgon2idParams)
+	encoded, err := old.Hash("correct horse")
+	require.NoError(t, err)
+
+	stronger := NewArgon2idHasher(Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1})
+	ok, err := stronger.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, stronger.NeedsRehash(encoded))
+}
+
+func TestArgon2idHasher_RejectsMalformedHashes(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	for _, encoded := range []string{
+		"",
+		"$argon2id$v=19$m=1024,t=1,p=1$c2FsdA",
+		"$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHQ$aGFzaA",
+		"$argon2i$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$aGFzaA",
+	} {
+		_, err := hasher.Verify("password", encoded)
+		assert.ErrorIs(t, err, ErrUnsupportedPasswordHash, encoded)
+	}
+}
+
+func TestArgon2idHasher_RejectsOutOfRangeParameters(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	tests := []struct {
+		name   string
+		params string
+	}{
+		{name: "zero iterations", params: "m=1024,t=0,p=1"},
+		{name: "zero parallelism", params: "m=1024,t=1,p=0"},
+		{name: "memory below 8 KiB per lane", params: "m=15,t=1,p=2"},
+		{name: "memory above limit", params: "m=4194304,t=1,p=1"},
+		{name: "iterations above limit", params: "m=1024,t=100000,p=1"},
+		{name: "parallelism above limit", params: "m=1024,t=1,p=255"},
+		{name: "parallelism overflow", params: "m=1024,t=1,p=256"},
+		{name: "negative memory", params: "m=-1,t=1,p=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := "$argon2id$v=19$" + tt.params + "$c2FsdHNhbHQ$aGFzaA"
+			_, err := hasher.Verify("password", encoded)
+			assert.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+			assert.True(t, hasher.NeedsRehash(encoded))
+
+			_, err = NewLegacyHasher().Verify("password", strings.Replace(encoded, "argon2id", "argon2i", 1))
+			assert.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+		})
+	}
+}
+
+func TestBcryptHasher_NeedsRehashOnCostChange(t *testing.T) {
+	cheap := NewBcryptHasher(bcrypt.MinCost)
+	encoded, err := cheap.Hash("correct horse")
+	require.NoError(t, err)
+	assert.False(t, cheap.NeedsRehash(encoded))
+
+	ok, err := cheap.Verify("correct horse", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.True(t, NewBcryptHasher(bcrypt.MinCost+1).NeedsRehash(encoded))
+}
+
+func TestMultiHasher_UpgradesOtherAlgorithms(t *testing.T) {
+	bcryptHasher := NewBcryptHasher(bcrypt.MinCost)
+	argon2Hasher := NewArgon2idHasher(testArgon2idParams)
+	hasher := NewMultiHasher(argon2Hasher, bcryptHasher)
+
+	legacy, err := bcryptHasher.Hash("correct horse")
+	require.NoError(t, err)
+
+	ok, err := hasher.Verify("correct horse", legacy)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, hasher.NeedsRehash(legacy))
+
+	current, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, argon2Hasher.Recognizes(current))
+	assert.False(t, hasher.NeedsRehash(current))
+
+	_, err = hasher.Verify("correct horse", "plaintext")
+	assert.ErrorIs(t, err, ErrUnsupportedPasswordHash)
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	hasher, err := NewPasswordHasher(BcryptAlgorithm, bcrypt.MinCost, testArgon2idParams)
+	require.NoError(t, err)
+
+	encoded, err := hasher.Hash("correct horse")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$2a$"))
+
+	_, err = NewPasswordHasher("md5", 0, Argon2idParams{})
+	assert.Error(t, err)
+
+	_, err = NewPasswordHasher(Argon2idAlgorithm, 0, Argon2idParams{Memory: 4 * 1024 * 1024})
+	assert.ErrorIs(t, err, ErrUnsupportedPasswordHash, "hashes it wrote could not be verified")
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"encoding/json"
+	"slices"
+)
+
+// Permissions name what a principal may do as "resource:action"
+const (
+	PermissionAdminAccess       = "admin:access"        // admin area and dashboard
+	PermissionUsersRead         = "users:read"          // list users
+	PermissionUsersWrite        = "users:write"         // change roles and permissions
+	PermissionUsersImpersonate  = "users:impersonate"   // act as another user
+	PermissionOAuthClientsRead  = "oauth_clients:read"  // list machine clients
+	PermissionOAuthClientsWrite = "oauth_clients:write" // create and delete machine clients
+	PermissionMetricsRead       = "metrics:read"        // process counters
+	PermissionOrganizationsRead = "organizations:read"  // list every organization
+	PermissionAuditRead         = "audit:read"          // security audit log
+)
+
+// Permissions lists every known permission
+var Permissions = []string{
+	PermissionAdminAccess,
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+	PermissionOAuthClientsRead,
+	PermissionOAuthClientsWrite,
+	PermissionMetricsRead,
+	PermissionOrganizationsRead,
+	PermissionAuditRead,
+}
+
+// rolePermissions maps each role to the permissions it grants
+var rolePermissions = map[string][]string{
+	"user":  {},
+	"admin": Permissions,
+}
+
+// PermissionOverrides are per-user exceptions to the role's permissions,
+// stored as JSON in the user's permissions column. Deny wins over Grant.
+type PermissionOverrides struct {
+	Grant []string `json:"grant,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
+
+// RolePermissions returns the permissions the role grants, none for unknown
+// roles
+func RolePermissions(role string) []string {
+	return slices.Clone(rolePermissions[role])
+}
+
+// EffectivePermissions returns the role's permissions plus the overrides'
+// grants minus their denies, in the order of Permissions
+func EffectivePermissions(role string, overrides PermissionOverrides) []string {
+	granted := append(RolePermissions(role), overrides.Grant...)
+
+	result := make([]string, 0, len(granted))
+	for _, permission := range Permissions {
+		if slices.Contains(granted, permission) && !slices.Contains(overrides.Deny, permission) {
+			result = append(result, permission)
+		}
+	}
+	return result
+}
+
+// IsRole reports whether the role is known
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// IsPermission reports whether the permission is known
+func IsPermission(permission string) bool {
+	return slices.Contains(Permissions, permission)
+}
+
+// ParsePermissionOverrides reads the JSON stored in a user's permissions
+// column. A bare array is read as grants; anything unreadable as no overrides.
+func ParsePermissionOverrides(raw string) PermissionOverrides {
+	var overrides PermissionOverrides
+	if raw == "" {
+		return overrides
+	}
+	if err := json.Unmarshal([]byte(raw), &overrides); err == nil {
+		return overrides
+	}
+	var grants []string
+	if err := json.Unmarshal([]byte(raw), &grants); err == nil {
+		overrides.Grant = grants
+	}
+	return overrides
+}
+
+// Encode returns the JSON stored in a user's permissions column, or "" if
+// there are no overrides
+func (o PermissionOverrides) Encode() (string, error) {
+	if len(o.Grant) == 0 && len(o.Deny) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// HasPermission reports whether the user's effective permissions include the
+// permission
+func (u *UserData) HasPermission(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
+}
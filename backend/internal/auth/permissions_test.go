@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectivePermissions(t *testing.T) {
+	assert.Empty(t, EffectivePermissions("user", PermissionOverrides{}))
+	assert.Equal(t, Permissions, EffectivePermissions("admin", PermissionOverrides{}))
+	assert.Empty(t, EffectivePermissions("unknown", PermissionOverrides{}))
+
+	// Grants add to the role, denies win over both
+	assert.Equal(t,
+		[]string{PermissionAdminAccess, PermissionUsersRead},
+		EffectivePermissions("user", PermissionOverrides{
+			Grant: []string{PermissionUsersRead, PermissionAdminAccess, PermissionMetricsRead},
+			Deny:  []string{PermissionMetricsRead},
+		}),
+	)
+	assert.NotContains(t,
+		EffectivePermissions("admin", PermissionOverrides{Deny: []string{PermissionUsersImpersonate}}),
+		PermissionUsersImpersonate,
+	)
+
+	// Unknown grants are ignored
+	assert.Empty(t, EffectivePermissions("user", PermissionOverrides{Grant: []string{"root"}}))
+}
+
+func TestPermissionOverridesEncoding(t *testing.T) {
+	encoded, err := PermissionOverrides{}.Encode()
+	require.NoError(t, err)
+	assert.Empty(t, encoded)
+
+	overrides := PermissionOverrides{Grant: []string{PermissionUsersRead}, Deny: []string{PermissionMetricsRead}}
+	encoded, err = overrides.Encode()
+	require.NoError(t, err)
+	assert.Equal(t, overrides, ParsePermissionOverrides(encoded))
+
+	// A bare array from before overrides had grants and denies counts as grants
+	assert.Equal(t, PermissionOverrides{Grant: []string{PermissionUsersRead}}, ParsePermissionOverrides(`["users:read"]`))
+	assert.Equal(t, PermissionOverrides{}, ParsePermissionOverrides("not json"))
+	assert.Equal(t, PermissionOverrides{}, ParsePermissionOverrides(""))
+}
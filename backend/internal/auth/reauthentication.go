@@ -0,0 +1,78 @@
+package auth
+
+import "time"
+
+// Reauthenticate confirms the password of the session's user ("sudo mode")
+// and records the time on the session, so that sensitive actions are allowed
+// for the next ReauthenticationWindow. Wrong passwords count towards the
+// account lockout like failed logins do.
+func (m *AuthManager) Reauthenticate(sessionID, password string) (*Session, error) {
+	session, err := m.sessionAdapter.GetSession(sessionID)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+
+	if err := m.ConfirmPassword(session.UserID, password); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := m.sessionAdapter.MarkSessionReauthenticated(sessionID, now); err != nil {
+		return nil, err
+	}
+
+	session.ReauthenticatedAt = now
+	return session, nil
+}
+
+// ConfirmPassword checks the password of a signed-in user before a sensitive
+// change. Like a login, it is refused while the account is locked, and wrong
+// passwords count towards the lockout.
+func (m *AuthManager) ConfirmPassword(userID, password string) error {
+	user, err := m.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.Active {
+		return ErrUserNotActive
+	}
+	if m.isAccountLocked(user.Identifier) {
+		return ErrAccountLocked
+	}
+
+	if _, err := m.userAdapter.ValidateCredentials(user.Identifier, password); err != nil {
+		m.recordFailedAttempt(user.Identifier)
+		return err
+	}
+	m.clearFailedAttempts(user.Identifier)
+	return nil
+}
+
+// RecentlyAuthenticated reports whether the session's user proved who they
+// are within the last ReauthenticationWindow. Signing in counts, so a session
+// is in sudo mode right after it was created. A zero window disables the
+// check.
+func (m *AuthManager) RecentlyAuthenticated(session *Session) bool {
+	if m.config.ReauthenticationWindow <= 0 {
+		return true
+	}
+	return time.Since(session.AuthenticatedAt()) <= m.config.ReauthenticationWindow
+}
+
+// ReauthenticatedUntil returns when the session leaves sudo mode, or the zero
+// time if the window is disabled
+func (m *AuthManager) ReauthenticatedUntil(session *Session) time.Time {
+	if m.config.ReauthenticationWindow <= 0 {
+		return time.Time{}
+	}
+	return session.AuthenticatedAt().Add(m.config.ReauthenticationWindow)
+}
+
+// AuthenticatedAt is when the user last proved who they are within the
+// session: the last re-authentication, or else when they signed in
+func (s *Session) AuthenticatedAt() time.Time {
+	if s.ReauthenticatedAt.After(s.CreatedAt) {
+		return s.ReauthenticatedAt
+	}
+	return s.CreatedAt
+}
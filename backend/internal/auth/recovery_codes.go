@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"strings"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeBytesLen = 5 // 40 bits, 8 base32 characters
+)
+
+var recoveryCodeEncoding = totpEncoding
+
+// GenerateRecoveryCodes returns a fresh set of human-friendly recovery codes
+// formatted as "xxxx-xxxx"
+func GenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		raw := make([]byte, recoveryCodeBytesLen)
+		if _, err := GenerateRandomBytes(raw); err != nil {
+			return nil, err
+		}
+
+		encoded := strings.ToLower(recoveryCodeEncoding.EncodeToString(raw))
+		codes = append(codes, encoded[:4]+"-"+encoded[4:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode normalizes a recovery code as typed by the user and hashes
+// it for storage or lookup
+func HashRecoveryCode(code string) string {
+	return HashToken(normalizeRecoveryCode(code))
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes with a new set.
+// Only accounts with a second factor enabled have recovery codes.
+func (m *AuthManager) RegenerateRecoveryCodes(userID string) ([]string, error) {
+	if m.twoFactorAdapter == nil {
+		return nil, ErrTwoFactorNotSupported
+	}
+
+	enabled, err := m.TwoFactorEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	return m.issueRecoveryCodes(userID)
+}
+
+// RecoveryCodesRemaining returns how many unused recovery codes the user has
+func (m *AuthManager) RecoveryCodesRemaining(userID string) (int, error) {
+	if m.twoFactorAdapter == nil {
+		return 0, ErrTwoFactorNotSupported
+	}
+	return m.twoFactorAdapter.CountRecoveryCodes(userID)
+}
+
+func (m *AuthManager) issueRecoveryCodes(userID string) ([]string, error) {
+	codes, err := GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = HashRecoveryCode(code)
+	}
+
+	if err := m.twoFactorAdapter.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// isRecoveryCode tells recovery codes apart from TOTP codes, which are digits only
+func isRecoveryCode(code string) bool {
+	normalized := normalizeRecoveryCode(code)
+	if len(normalized) != totpDigits {
+		return true
+	}
+	return strings.ContainsFunc(normalized, func(r rune) bool { return r < '0' || r > '9' })
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
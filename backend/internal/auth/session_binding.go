@@ -0,0 +1,218 @@
+package auth
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SessionBindingPolicy decides what happens when a session is used from a
+// client that does not match the one it was created for
+type SessionBindingPolicy string
+
+// Session binding policies
+const (
+	SessionBindingIgnore SessionBindingPolicy = "ignore" // accept the request silently
+	SessionBindingWarn   SessionBindingPolicy = "warn"   // accept the request and report the anomaly
+	SessionBindingRevoke SessionBindingPolicy = "revoke" // report the anomaly and delete the session
+)
+
+// ParseSessionBindingPolicy reads a policy name; "" means SessionBindingIgnore
+func ParseSessionBindingPolicy(name string) (SessionBindingPolicy, error) {
+	switch policy := SessionBindingPolicy(name); policy {
+	case "":
+		return SessionBindingIgnore, nil
+	case SessionBindingIgnore, SessionBindingWarn, SessionBindingRevoke:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown session binding policy %q", name)
+	}
+}
+
+// Kinds of session anomaly
+const (
+	SessionAnomalyUserAgent = "user_agent" // another browser or operating system family
+	SessionAnomalyIP        = "ip"         // another IP network
+)
+
+// SessionAnomaly describes a session used from a client that does not match
+// the one it was created for, which may mean its token was stolen. Expected
+// and Actual hold the compared values: user agent families or IP networks.
+type SessionAnomaly struct {
+	UserID     string
+	Kind       string
+	Expected   string
+	Actual     string
+	Policy     SessionBindingPolicy
+	UserAgent  string // as sent by the request
+	IP         string // as sent by the request
+	DetectedAt time.Time
+}
+
+// key identifies the anomaly within its session
+func (a SessionAnomaly) key() string {
+	return a.Kind + "=" + a.Actual
+}
+
+// SetSessionAnomalyHandler replaces the default handler, which logs every
+// anomaly as a warning. The handler runs synchronously inside ValidateSession
+// and sees each anomaly of a session once.
+func (m *AuthManager) SetSessionAnomalyHandler(handler func(SessionAnomaly)) {
+	m.sessionAnomalyHandler = handler
+}
+
+// checkSessionBinding compares the request with the client the session was
+// created for, reports every mismatch whose policy is not ignore and tells
+// whether the session must be revoked. Values missing on either side are not
+// compared. A session that is kept is flagged with what was reported, so that
+// a client which keeps using it under warn is reported once, not per request.
+func (m *AuthManager) checkSessionBinding(session *Session, metadata SessionMetadata) bool {
+	var anomalies []SessionAnomaly
+
+	if policy := m.config.SessionUserAgentPolicy; policy != "" && policy != SessionBindingIgnore {
+		expected, actual := UserAgentFamily(session.UserAgent), UserAgentFamily(metadata.UserAgent)
+		if expected != "" && actual != "" && expected != actual {
+			anomalies = append(anomalies, SessionAnomaly{Kind: SessionAnomalyUserAgent, Expected: expected, Actual: actual, Policy: policy})
+		}
+	}
+
+	if policy := m.config.SessionIPPolicy; policy != "" && policy != SessionBindingIgnore {
+		expected := m.ipNetwork(session.IP)
+		actual := m.ipNetwork(metadata.IP)
+		if expected != "" && actual != "" && expected != actual {
+			anomalies = append(anomalies, SessionAnomaly{Kind: SessionAnomalyIP, Expected: expected, Actual: actual, Policy: policy})
+		}
+	}
+
+	revoke := slices.ContainsFunc(anomalies, func(a SessionAnomaly) bool { return a.Policy == SessionBindingRevoke })
+	if !revoke {
+		anomalies = m.unreportedAnomalies(session, anomalies)
+	}
+
+	now := time.Now()
+	for _, anomaly := range anomalies {
+		anomaly.UserID = session.UserID
+		anomaly.UserAgent = metadata.UserAgent
+		anomaly.IP = metadata.IP
+		anomaly.DetectedAt = now
+		m.reportSessionAnomaly(anomaly)
+	}
+	return revoke
+}
+
+// unreportedAnomalies drops the anomalies already reported for the session and
+// records the rest. When a concurrent request recorded first, it reports them
+// and none are returned.
+func (m *AuthManager) unreportedAnomalies(session *Session, anomalies []SessionAnomaly) []SessionAnomaly {
+	var fresh []SessionAnomaly
+	reported := slices.Clone(session.ReportedAnomalies)
+	for _, anomaly := range anomalies {
+		if !slices.Contains(session.ReportedAnomalies, anomaly.key()) {
+			fresh = append(fresh, anomaly)
+			reported = append(reported, anomaly.key())
+		}
+	}
+	if len(fresh) == 0 {
+		return nil
+	}
+
+	recorded, err := m.sessionAdapter.SetReportedAnomalies(session.ID, session.ReportedAnomalies, reported)
+	if err != nil {
+		// Reporting twice beats not reporting at all
+		slog.Error("recording session anomaly failed", "err", err)
+		return fresh
+	}
+	if !recorded {
+		return nil
+	}
+	session.ReportedAnomalies = reported
+	return fresh
+}
+
+func (m *AuthManager) reportSessionAnomaly(anomaly SessionAnomaly) {
+	if m.sessionAnomalyHandler != nil {
+		m.sessionAnomalyHandler(anomaly)
+		return
+	}
+
+	slog.Warn("session anomaly",
+		"user_id", anomaly.UserID,
+		"kind", anomaly.Kind,
+		"expected", anomaly.Expected,
+		"actual", anomaly.Actual,
+		"policy", anomaly.Policy,
+		"ip", anomaly.IP,
+		"user_agent", anomaly.UserAgent,
+	)
+}
+
+// ipNetwork returns the network ip belongs to, using the configured prefix
+// lengths, or "" if ip cannot be parsed
+func (m *AuthManager) ipNetwork(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+
+	bits := m.config.SessionIPv6Prefix
+	if addr.Is4() {
+		bits = m.config.SessionIPv4Prefix
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+	return prefix.String()
+}
+
+// UserAgentFamily reduces a User-Agent header to "<browser>/<os>", dropping
+// versions so that browser updates do not look like a different client.
+// Clients other than the common browsers are named after their first product
+// token. It returns "" for an empty header.
+func UserAgentFamily(userAgent string) string {
+	if strings.TrimSpace(userAgent) == "" {
+		return ""
+	}
+	return userAgentBrowser(userAgent) + "/" + userAgentOS(userAgent)
+}
+
+func userAgentBrowser(ua string) string {
+	switch {
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "EdgA/"), strings.Contains(ua, "EdgiOS/"):
+		return "Edge"
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		return "Opera"
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		return "Firefox"
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"), strings.Contains(ua, "Chromium/"):
+		return "Chrome"
+	case strings.Contains(ua, "Safari/"):
+		return "Safari"
+	}
+
+	product, _, _ := strings.Cut(strings.Fields(ua)[0], "/")
+	return product
+}
+
+func userAgentOS(ua string) string {
+	switch {
+	case strings.Contains(ua, "Windows"):
+		return "Windows"
+	case strings.Contains(ua, "Android"):
+		return "Android"
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPad"), strings.Contains(ua, "iPod"):
+		return "iOS"
+	case strings.Contains(ua, "Mac OS X"), strings.Contains(ua, "Macintosh"):
+		return "macOS"
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS"
+	case strings.Contains(ua, "Linux"):
+		return "Linux"
+	default:
+		return "other"
+	}
+}
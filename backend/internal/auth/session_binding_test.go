@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserAgentFamily(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		family    string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36", "Chrome/Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36 Edg/129.0.0.0", "Edge/Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_6) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.6 Safari/605.1.15", "Safari/macOS"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0", "Firefox/Linux"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36", "Chrome/Android"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/129.0 Mobile/15E148 Safari/604.1", "Chrome/iOS"},
+		{"curl/8.9.1", "curl/other"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.family, UserAgentFamily(tt.userAgent), tt.userAgent)
+	}
+
+	// Browser updates keep the family
+	assert.Equal(t,
+		UserAgentFamily("Mozilla/5.0 (X11; Linux x86_64; rv:130.0) Gecko/20100101 Firefox/130.0"),
+		UserAgentFamily("Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"),
+	)
+}
+
+func TestParseSessionBindingPolicy(t *testing.T) {
+	for name, want := range map[string]SessionBindingPolicy{
+		"":       SessionBindingIgnore,
+		"ignore": SessionBindingIgnore,
+		"warn":   SessionBindingWarn,
+		"revoke": SessionBindingRevoke,
+	} {
+		policy, err := ParseSessionBindingPolicy(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, policy)
+	}
+
+	_, err := ParseSessionBindingPolicy("block")
+	assert.Error(t, err)
+}
+
+func TestAuthManager_IPNetwork(t *testing.T) {
+	m := NewAuthManager(nil, nil, nil)
+
+	assert.Equal(t, "203.0.113.0/24", m.ipNetwork("203.0.113.10"))
+	assert.Equal(t, "203.0.113.0/24", m.ipNetwork("::ffff:203.0.113.99"))
+	assert.Equal(t, "2001:db8:1:2::/64", m.ipNetwork("2001:db8:1:2::1"))
+	assert.Equal(t, "", m.ipNetwork("not-an-ip"))
+	assert.Equal(t, "", m.ipNetwork(""))
+}
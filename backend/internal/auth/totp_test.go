@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA-1 test key from RFC 6238 appendix B
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestGenerateTOTPCode_RFC6238Vectors(t *testing.T) {
+	// RFC 6238 publishes 8-digit values; the last 6 digits are the 6-digit code.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := GenerateTOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "unix time %d", unix)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, err := GenerateTOTPCode(rfc6238Secret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTPCode(rfc6238Secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, totpStep(now), step)
+
+	// One step of clock drift is tolerated
+	_, ok = ValidateTOTPCode(rfc6238Secret, code, now.Add(totpPeriod))
+	assert.True(t, ok)
+
+	// Older codes are rejected
+	_, ok = ValidateTOTPCode(rfc6238Secret, code, now.Add(3*totpPeriod))
+	assert.False(t, ok)
+
+	_, ok = ValidateTOTPCode(rfc6238Secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	require.NoError(t, err)
+
+	uri := TOTPProvisioningURI("GoSvelteKit", "user@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/GoSvelteKit:user@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=GoSvelteKit")
+}
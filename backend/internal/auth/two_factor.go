@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// TwoFactorRequiredError is returned by Login when the password was accepted
+// but the account requires a second factor before a session is issued
+type TwoFactorRequiredError struct {
+	ChallengeToken string
+	ExpiresAt      time.Time
+}
+
+func (e *TwoFactorRequiredError) Error() string { return ErrTwoFactorRequired.Error() }
+
+func (e *TwoFactorRequiredError) Unwrap() error { return ErrTwoFactorRequired }
+
+// TOTPEnrollment contains what the user needs to configure an authenticator app
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// TwoFactorEnabled reports whether the user has a confirmed second factor
+func (m *AuthManager) TwoFactorEnabled(userID string) (bool, error) {
+	if m.twoFactorAdapter == nil {
+		return false, nil
+	}
+
+	credential, err := m.twoFactorAdapter.GetTOTPCredential(userID)
+	if err != nil {
+		if errors.Is(err, ErrTwoFactorNotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return credential.Enabled, nil
+}
+
+// GetTOTPCredential returns the user's TOTP credential, if any
+func (m *AuthManager) GetTOTPCredential(userID string) (*TOTPCredential, error) {
+	if m.twoFactorAdapter == nil {
+		return nil, ErrTwoFactorNotSupported
+	}
+	return m.twoFactorAdapter.GetTOTPCredential(userID)
+}
+
+// BeginTOTPEnrollment generates a new pending TOTP secret for the user.
+// Any previous unconfirmed secret is replaced.
+func (m *AuthManager) BeginTOTPEnrollment(user *UserData) (*TOTPEnrollment, error) {
+	if m.twoFactorAdapter == nil {
+		return nil, ErrTwoFactorNotSupported
+	}
+
+	enabled, err := m.TwoFactorEnabled(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.twoFactorAdapter.SaveTOTPCredential(&TOTPCredential{
+		UserID: user.ID,
+		Secret: secret,
+	}); err != nil {
+		return nil, err
+	}
+
+	accountName := user.Email
+	if accountName == "" {
+		accountName = user.Identifier
+	}
+
+	return &TOTPEnrollment{
+		Secret: secret,
+		URI:    TOTPProvisioningURI(m.config.TOTPIssuer, accountName, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables TOTP after the user proves the authenticator
+// app produces valid codes. It returns the recovery codes issued for the
+// account, which are only ever shown at this point.
+func (m *AuthManager) ConfirmTOTPEnrollment(userID, code string) ([]string, error) {
+	if m.twoFactorAdapter == nil {
+		return nil, ErrTwoFactorNotSupported
+	}
+
+	credential, err := m.twoFactorAdapter.GetTOTPCredential(userID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.Enabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := ValidateTOTPCode(credential.Secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	credential.Enabled = true
+	credential.LastUsedStep = step
+	credential.ConfirmedAt = time.Now()
+	if err := m.twoFactorAdapter.SaveTOTPCredential(credential); err != nil {
+		return nil, err
+	}
+
+	return m.issueRecoveryCodes(userID)
+}
+
+// DisableTOTP removes the user's TOTP credential and recovery codes. Callers
+// are expected to have re-verified the user's password.
+func (m *AuthManager) DisableTOTP(userID string) error {
+	if m.twoFactorAdapter == nil {
+		return ErrTwoFactorNotSupported
+	}
+
+	if _, err := m.twoFactorAdapter.GetTOTPCredential(userID); err != nil {
+		return err
+	}
+
+	if err := m.twoFactorAdapter.ReplaceRecoveryCodes(userID, nil); err != nil {
+		return err
+	}
+	return m.twoFactorAdapter.DeleteTOTPCredential(userID)
+}
+
+// CompleteTwoFactorLogin verifies the second factor for a pending challenge
+// and creates the session that Login held back. The code may be a TOTP code
+// or one of the user's single-use recovery codes.
+func (m *AuthManager) CompleteTwoFactorLogin(
+	challengeToken, code string,
+	metadata SessionMetadata,
+) (*Session, *UserData, error) {
+	if m.twoFactorAdapter == nil {
+		return nil, nil, ErrTwoFactorNotSupported
+	}
+
+	tokenHash := HashToken(challengeToken)
+	challenge, err := m.twoFactorAdapter.GetChallenge(tokenHash)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if time.Now().After(challenge.ExpiresAt) {
+		_ = m.twoFactorAdapter.DeleteChallenge(tokenHash)
+		return nil, nil, ErrChallengeExpired
+	}
+
+	user, err := m.userAdapter.FindUserByID(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if m.isAccountLocked(user.Identifier) {
+		return nil, nil, ErrAccountLocked
+	}
+
+	if !user.Active {
+		_ = m.twoFactorAdapter.DeleteChallenge(tokenHash)
+		return nil, nil, ErrUserNotActive
+	}
+
+	if err := m.verifySecondFactor(user.ID, code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			m.publishLoginFailed(user.ID, user.Identifier, err, metadata)
+			m.recordFailedChallengeAttempt(challenge, user.Identifier)
+		}
+		return nil, nil, err
+	}
+
+	// A challenge can only be redeemed once
+	if err := m.twoFactorAdapter.DeleteChallenge(tokenHash); err != nil {
+		return nil, nil, err
+	}
+
+	m.clearFailedAttempts(user.Identifier)
+
+	return m.createSession(user, LoginMethodTwoFactor, metadata)
+}
+
+func (m *AuthManager) verifySecondFactor(userID, code string) error {
+	if isRecoveryCode(code) {
+		return m.twoFactorAdapter.ConsumeRecoveryCode(userID, HashRecoveryCode(code))
+	}
+	return m.verifyTOTP(userID, code)
+}
+
+func (m *AuthManager) verifyTOTP(userID, code string) error {
+	credential, err := m.twoFactorAdapter.GetTOTPCredential(userID)
+	if err != nil {
+		return err
+	}
+	if !credential.Enabled {
+		return ErrTwoFactorNotEnrolled
+	}
+
+	step, ok := ValidateTOTPCode(credential.Secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// The adapter refuses a step that is not newer than the last one used,
+	// which rejects replays even when two requests race with the same code
+	return m.twoFactorAdapter.ClaimTOTPStep(userID, step)
+}
+
+func (m *AuthManager) createTwoFactorChallenge(userID string) (*TwoFactorRequiredError, error) {
+	token, err := GenerateSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	challenge := &TwoFactorChallenge{
+		TokenHash: HashToken(token),
+		UserID:    userID,
+		ExpiresAt: now.Add(m.config.TwoFactorChallengeTTL),
+		CreatedAt: now,
+	}
+	if err := m.twoFactorAdapter.CreateChallenge(challenge); err != nil {
+		return nil, err
+	}
+
+	return &TwoFactorRequiredError{
+		ChallengeToken: token,
+		ExpiresAt:      challenge.ExpiresAt,
+	}, nil
+}
+
+func (m *AuthManager) recordFailedChallengeAttempt(challenge *TwoFactorChallenge, identifier string) {
+	m.recordFailedAttempt(identifier)
+
+	if challenge.Attempts+1 >= m.config.MaxTwoFactorAttempts {
+		_ = m.twoFactorAdapter.DeleteChallenge(challenge.TokenHash)
+		return
+	}
+	_ = m.twoFactorAdapter.IncrementChallengeAttempts(challenge.TokenHash)
+}
@@ -0,0 +1,16 @@
+package bootstrap
+
+import (
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/config"
+)
+
+// NewPasswordHasher builds the password hasher selected in the auth config.
+// Hashes made with the other supported algorithm are still accepted.
+func NewPasswordHasher(cfg *config.Config) (auth.PasswordHasher, error) {
+	return auth.NewPasswordHasher(cfg.Auth.PasswordHashAlgorithm, cfg.Auth.BcryptCost, auth.Argon2idParams{
+		Memory:      cfg.Auth.Argon2Memory,
+		Iterations:  cfg.Auth.Argon2Iterations,
+		Parallelism: cfg.Auth.Argon2Parallelism,
+	})
+}
@@ -22,13 +22,80 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	SessionTTL        time.Duration `mapstructure:"session_ttl"`
-	RefreshThreshold  time.Duration `mapstructure:"refresh_threshold"`
-	MaxFailedAttempts int           `mapstructure:"max_failed_attempts"`
-	LockoutDuration   time.Duration `mapstructure:"lockout_duration"`
-	AllowHeaderAuth   bool          `mapstructure:"allow_header_auth"`
-	AllowCookieAuth   bool          `mapstructure:"allow_cookie_auth"`
-	CookieSecure      bool          `mapstructure:"cookie_secure"`
+	SessionTTL             time.Duration `mapstructure:"session_ttl"`
+	RefreshThreshold       time.Duration `mapstructure:"refresh_threshold"`
+	IdleTimeout            time.Duration `mapstructure:"session_idle_timeout"`      // 0 desativa
+	AbsoluteLifetime       time.Duration `mapstructure:"session_absolute_lifetime"` // 0 desativa
+	MaxFailedAttempts      int           `mapstructure:"max_failed_attempts"`
+	LockoutDuration        time.Duration `mapstructure:"lockout_duration"`
+	LockoutStore           string        `mapstructure:"lockout_store"`
+	ReauthenticationWindow time.Duration `mapstructure:"reauthentication_window"` // 0 desativa
+	AllowHeaderAuth        bool          `mapstructure:"allow_header_auth"`
+	AllowCookieAuth        bool          `mapstructure:"allow_cookie_auth"`
+	CookieSecure           bool          `mapstructure:"cookie_secure"`
+
+	SessionUserAgentPolicy string `mapstructure:"session_user_agent_policy"` // ignore, warn ou revoke
+	SessionIPPolicy        string `mapstructure:"session_ip_policy"`         // ignore, warn ou revoke
+	SessionIPv4Prefix      int    `mapstructure:"session_ipv4_prefix"`
+	SessionIPv6Prefix      int    `mapstructure:"session_ipv6_prefix"`
+
+	PasswordHashAlgorithm string `mapstructure:"password_hash_algorithm"`
+	BcryptCost            int    `mapstructure:"bcrypt_cost"`
+	Argon2Memory          uint32 `mapstructure:"argon2_memory"` // KiB
+	Argon2Iterations      uint32 `mapstructure:"argon2_iterations"`
+	Argon2Parallelism     uint8  `mapstructure:"argon2_parallelism"`
+
+	TOTPIssuer            string        `mapstructure:"totp_issuer"`
+	TwoFactorChallengeTTL time.Duration `mapstructure:"two_factor_challenge_ttl"`
+
+	WebAuthnRPID          string   `mapstructure:"webauthn_rp_id"`
+	WebAuthnRPDisplayName string   `mapstructure:"webauthn_rp_display_name"`
+	WebAuthnRPOrigins     []string `mapstructure:"webauthn_rp_origins"`
+
+	OAuthRedirectBaseURL string                         `mapstructure:"oauth_redirect_base_url"`
+	OAuthFrontendURL     string                         `mapstructure:"oauth_frontend_url"`
+	OAuthProviders       map[string]OAuthProviderConfig `mapstructure:"oauth_providers"`
+
+	MagicLinkTTL time.Duration `mapstructure:"magic_link_ttl"`
+
+	RequireEmailVerification        bool          `mapstructure:"require_email_verification"`
+	EmailVerificationTTL            time.Duration `mapstructure:"email_verification_ttl"`
+	EmailVerificationResendInterval time.Duration `mapstructure:"email_verification_resend_interval"`
+
+	AccessTokenKeyFile          string        `mapstructure:"access_token_key_file"` // vazio desativa
+	AccessTokenPreviousKeyFiles []string      `mapstructure:"access_token_previous_key_files"`
+	AccessTokenTTL              time.Duration `mapstructure:"access_token_ttl"`
+	AccessTokenIssuer           string        `mapstructure:"access_token_issuer"`
+	AccessTokenAudience         string        `mapstructure:"access_token_audience"`
+
+	ClientAccessTokenTTL time.Duration `mapstructure:"client_access_token_ttl"`
+
+	ImpersonationDuration time.Duration `mapstructure:"impersonation_duration"` // validade da sessão aberta por um admin como outro usuário
+
+	InvitationTTL          time.Duration `mapstructure:"invitation_ttl"`           // validade dos convites para organizações
+	InviteOnlyRegistration bool          `mapstructure:"invite_only_registration"` // recusa cadastros sem um convite válido
+
+	KnownDeviceTTL       time.Duration `mapstructure:"known_device_ttl"`        // por quanto tempo um dispositivo segue conhecido; 0 desativa os avisos de novo acesso
+	SessionRevokeLinkTTL time.Duration `mapstructure:"session_revoke_link_ttl"` // validade do link que encerra a sessão de um novo acesso
+}
+
+// OAuthProviderConfig configura um provedor de login social. Type é "google",
+// "github" ou "oidc" e, se vazio, assume o nome do provedor.
+type OAuthProviderConfig struct {
+	Type         string   `mapstructure:"type"`
+	ClientID     string   `mapstructure:"client_id"`
+	ClientSecret string   `mapstructure:"client_secret"`
+	IssuerURL    string   `mapstructure:"issuer_url"`
+	Scopes       []string `mapstructure:"scopes"`
+}
+
+// MaintenanceConfig controla a limpeza periódica de sessões, tokens e
+// bloqueios expirados. Um intervalo 0 desativa a tarefa correspondente.
+type MaintenanceConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	SessionsInterval time.Duration `mapstructure:"sessions_interval"`
+	TokensInterval   time.Duration `mapstructure:"tokens_interval"`
+	LockoutsInterval time.Duration `mapstructure:"lockouts_interval"`
 }
 
 // EmailConfig contém configurações para envio de email
@@ -40,13 +107,19 @@ type EmailConfig struct {
 	FromEmail    string `mapstructure:"from_email"`
 	FromName     string `mapstructure:"from_name"`
 	ResetURL     string `mapstructure:"reset_url"`
+	MagicLinkURL string `mapstructure:"magic_link_url"`
+	VerifyURL    string `mapstructure:"verify_url"`
+	InviteURL    string `mapstructure:"invite_url"`
+
+	RevokeSessionURL string `mapstructure:"revoke_session_url"` // link do aviso de novo acesso que encerra a sessão
 }
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Auth     AuthConfig     `mapstructure:"auth"`
-	Email    EmailConfig    `mapstructure:"email"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	Email       EmailConfig       `mapstructure:"email"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance"`
 }
 
 var cfg *Config
@@ -56,11 +129,50 @@ var envConfigKeys = []string{
 	"database.dsn",
 	"auth.session_ttl",
 	"auth.refresh_threshold",
+	"auth.session_idle_timeout",
+	"auth.session_absolute_lifetime",
 	"auth.max_failed_attempts",
 	"auth.lockout_duration",
+	"auth.reauthentication_window",
+	"auth.lockout_store",
 	"auth.allow_header_auth",
 	"auth.allow_cookie_auth",
 	"auth.cookie_secure",
+	"auth.session_user_agent_policy",
+	"auth.session_ip_policy",
+	"auth.session_ipv4_prefix",
+	"auth.session_ipv6_prefix",
+	"auth.password_hash_algorithm",
+	"auth.bcrypt_cost",
+	"auth.argon2_memory",
+	"auth.argon2_iterations",
+	"auth.argon2_parallelism",
+	"auth.totp_issuer",
+	"auth.two_factor_challenge_ttl",
+	"auth.webauthn_rp_id",
+	"auth.webauthn_rp_display_name",
+	"auth.webauthn_rp_origins",
+	"auth.oauth_redirect_base_url",
+	"auth.oauth_frontend_url",
+	"auth.oauth_providers.google.client_id",
+	"auth.oauth_providers.google.client_secret",
+	"auth.oauth_providers.github.client_id",
+	"auth.oauth_providers.github.client_secret",
+	"auth.magic_link_ttl",
+	"auth.require_email_verification",
+	"auth.email_verification_ttl",
+	"auth.email_verification_resend_interval",
+	"auth.access_token_key_file",
+	"auth.access_token_previous_key_files",
+	"auth.access_token_ttl",
+	"auth.access_token_issuer",
+	"auth.access_token_audience",
+	"auth.client_access_token_ttl",
+	"auth.impersonation_duration",
+	"auth.invitation_ttl",
+	"auth.invite_only_registration",
+	"auth.known_device_ttl",
+	"auth.session_revoke_link_ttl",
 	"email.smtp_host",
 	"email.smtp_port",
 	"email.smtp_username",
@@ -68,6 +180,14 @@ var envConfigKeys = []string{
 	"email.from_email",
 	"email.from_name",
 	"email.reset_url",
+	"email.magic_link_url",
+	"email.verify_url",
+	"email.invite_url",
+	"email.revoke_session_url",
+	"maintenance.enabled",
+	"maintenance.sessions_interval",
+	"maintenance.tokens_interval",
+	"maintenance.lockouts_interval",
 }
 
 func LoadConfig() (*Config, error) {
@@ -83,11 +203,54 @@ func LoadConfig() (*Config, error) {
 
 	viper.SetDefault("auth.session_ttl", "720h")
 	viper.SetDefault("auth.refresh_threshold", "360h")
+	viper.SetDefault("auth.session_idle_timeout", "168h")
+	viper.SetDefault("auth.session_absolute_lifetime", "2160h")
 	viper.SetDefault("auth.max_failed_attempts", defaultMaxFailedAttempts)
 	viper.SetDefault("auth.lockout_duration", "30m")
+	viper.SetDefault("auth.reauthentication_window", "10m")
+	viper.SetDefault("auth.lockout_store", "memory")
 	viper.SetDefault("auth.allow_header_auth", true)
 	viper.SetDefault("auth.allow_cookie_auth", true)
 	viper.SetDefault("auth.cookie_secure", false)
+	viper.SetDefault("auth.session_user_agent_policy", "warn")
+	viper.SetDefault("auth.session_ip_policy", "ignore")
+	viper.SetDefault("auth.session_ipv4_prefix", 24)
+	viper.SetDefault("auth.session_ipv6_prefix", 64)
+	viper.SetDefault("auth.password_hash_algorithm", "argon2id")
+	viper.SetDefault("auth.bcrypt_cost", 10)
+	viper.SetDefault("auth.argon2_memory", 19456)
+	viper.SetDefault("auth.argon2_iterations", 2)
+	viper.SetDefault("auth.argon2_parallelism", 1)
+	viper.SetDefault("auth.totp_issuer", "GoSvelteKit")
+	viper.SetDefault("auth.two_factor_challenge_ttl", "5m")
+	viper.SetDefault("auth.webauthn_rp_id", "localhost")
+	viper.SetDefault("auth.webauthn_rp_display_name", "GoSvelteKit")
+	viper.SetDefault("auth.webauthn_rp_origins", []string{"http://localhost:5173"})
+	viper.SetDefault("auth.oauth_redirect_base_url", "http://localhost:8080")
+	viper.SetDefault("auth.oauth_frontend_url", "http://localhost:5173/login")
+	viper.SetDefault("auth.magic_link_ttl", "15m")
+	viper.SetDefault("auth.require_email_verification", false)
+	viper.SetDefault("auth.email_verification_ttl", "24h")
+	viper.SetDefault("auth.email_verification_resend_interval", "1m")
+	viper.SetDefault("auth.access_token_key_file", "")
+	viper.SetDefault("auth.access_token_previous_key_files", []string{})
+	viper.SetDefault("auth.access_token_ttl", "5m")
+	viper.SetDefault("auth.access_token_issuer", "http://localhost:8080")
+	viper.SetDefault("auth.access_token_audience", "")
+	viper.SetDefault("auth.client_access_token_ttl", "1h")
+	viper.SetDefault("auth.impersonation_duration", "1h")
+	viper.SetDefault("auth.invitation_ttl", "168h")
+	viper.SetDefault("auth.invite_only_registration", false)
+	viper.SetDefault("auth.known_device_ttl", "2160h")
+	viper.SetDefault("auth.session_revoke_link_ttl", "168h")
+	viper.SetDefault("email.magic_link_url", "http://localhost:5173/magic-link?token=")
+	viper.SetDefault("email.verify_url", "http://localhost:5173/verify-email?token=")
+	viper.SetDefault("email.invite_url", "http://localhost:5173/invite?token=")
+	viper.SetDefault("email.revoke_session_url", "http://localhost:5173/revoke-session?token=")
+	viper.SetDefault("maintenance.enabled", true)
+	viper.SetDefault("maintenance.sessions_interval", "1h")
+	viper.SetDefault("maintenance.tokens_interval", "15m")
+	viper.SetDefault("maintenance.lockouts_interval", "1h")
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
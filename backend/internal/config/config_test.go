@@ -170,3 +170,217 @@ func TestGetConfigBeforeLoad(t *testing.T) {
 	config := GetConfig()
 	assert.Nil(t, config)
 }
+
+func TestLoadConfigSplitsWebAuthnOriginsFromEnv(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_WEBAUTHN_RP_ORIGINS", "https://app.example.com,https://admin.example.com")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.Equal(t, "localhost", config.Auth.WebAuthnRPID)
+	assert.Equal(t, []string{"https://app.example.com", "https://admin.example.com"}, config.Auth.WebAuthnRPOrigins)
+}
+
+func TestLoadConfigAccessTokens(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Empty(t, config.Auth.AccessTokenKeyFile)
+	assert.Equal(t, 5*time.Minute, config.Auth.AccessTokenTTL)
+	assert.Equal(t, "http://localhost:8080", config.Auth.AccessTokenIssuer)
+
+	t.Setenv("AUTH_ACCESS_TOKEN_KEY_FILE", "/etc/gosveltekit/access-token.pem")
+	t.Setenv("AUTH_ACCESS_TOKEN_PREVIOUS_KEY_FILES", "/etc/gosveltekit/old-1.pem,/etc/gosveltekit/old-2.pem")
+	t.Setenv("AUTH_ACCESS_TOKEN_AUDIENCE", "orders")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/gosveltekit/access-token.pem", config.Auth.AccessTokenKeyFile)
+	assert.Equal(t, []string{"/etc/gosveltekit/old-1.pem", "/etc/gosveltekit/old-2.pem"}, config.Auth.AccessTokenPreviousKeyFiles)
+	assert.Equal(t, "orders", config.Auth.AccessTokenAudience)
+}
+
+func TestLoadConfigClientAccessTokenTTL(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, config.Auth.ClientAccessTokenTTL)
+
+	t.Setenv("AUTH_CLIENT_ACCESS_TOKEN_TTL", "15m")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, config.Auth.ClientAccessTokenTTL)
+}
+
+func TestLoadConfigImpersonationDuration(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, config.Auth.ImpersonationDuration)
+
+	t.Setenv("AUTH_IMPERSONATION_DURATION", "20m")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 20*time.Minute, config.Auth.ImpersonationDuration)
+}
+
+func TestLoadConfigInvitations(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, config.Auth.InvitationTTL)
+	assert.False(t, config.Auth.InviteOnlyRegistration)
+	assert.Equal(t, "http://localhost:5173/invite?token=", config.Email.InviteURL)
+
+	t.Setenv("AUTH_INVITE_ONLY_REGISTRATION", "true")
+	t.Setenv("EMAIL_INVITE_URL", "https://app.example.com/invite?token=")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.True(t, config.Auth.InviteOnlyRegistration)
+	assert.Equal(t, "https://app.example.com/invite?token=", config.Email.InviteURL)
+}
+
+func TestLoadConfigNewDeviceAlerts(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 90*24*time.Hour, config.Auth.KnownDeviceTTL)
+	assert.Equal(t, 7*24*time.Hour, config.Auth.SessionRevokeLinkTTL)
+	assert.Equal(t, "http://localhost:5173/revoke-session?token=", config.Email.RevokeSessionURL)
+
+	t.Setenv("AUTH_KNOWN_DEVICE_TTL", "0")
+	t.Setenv("EMAIL_REVOKE_SESSION_URL", "https://app.example.com/revoke-session?token=")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.Zero(t, config.Auth.KnownDeviceTTL)
+	assert.Equal(t, "https://app.example.com/revoke-session?token=", config.Email.RevokeSessionURL)
+}
+
+func TestLoadConfigReadsOAuthProvidersFromEnv(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_OAUTH_PROVIDERS_GITHUB_CLIENT_ID", "github-client")
+	t.Setenv("AUTH_OAUTH_PROVIDERS_GITHUB_CLIENT_SECRET", "github-secret")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.Equal(t, "http://localhost:8080", config.Auth.OAuthRedirectBaseURL)
+	assert.Equal(t, "github-client", config.Auth.OAuthProviders["github"].ClientID)
+	assert.Equal(t, "github-secret", config.Auth.OAuthProviders["github"].ClientSecret)
+	assert.Empty(t, config.Auth.OAuthProviders["google"].ClientID)
+}
+
+func TestLoadConfigEmailVerificationDefaults(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_REQUIRE_EMAIL_VERIFICATION", "true")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.True(t, config.Auth.RequireEmailVerification)
+	assert.Equal(t, 24*time.Hour, config.Auth.EmailVerificationTTL)
+	assert.Equal(t, time.Minute, config.Auth.EmailVerificationResendInterval)
+	assert.Equal(t, "http://localhost:5173/verify-email?token=", config.Email.VerifyURL)
+}
+
+func TestLoadConfigPasswordHashingDefaults(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_ARGON2_MEMORY", "65536")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.Equal(t, "argon2id", config.Auth.PasswordHashAlgorithm)
+	assert.Equal(t, 10, config.Auth.BcryptCost)
+	assert.Equal(t, uint32(65536), config.Auth.Argon2Memory)
+	assert.Equal(t, uint32(2), config.Auth.Argon2Iterations)
+	assert.Equal(t, uint8(1), config.Auth.Argon2Parallelism)
+}
+
+func TestLoadConfigSessionBindingDefaults(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_SESSION_IP_POLICY", "revoke")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.Equal(t, "warn", config.Auth.SessionUserAgentPolicy)
+	assert.Equal(t, "revoke", config.Auth.SessionIPPolicy)
+	assert.Equal(t, 24, config.Auth.SessionIPv4Prefix)
+	assert.Equal(t, 64, config.Auth.SessionIPv6Prefix)
+}
+
+func TestLoadConfigSessionLifetimeDefaults(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("AUTH_SESSION_IDLE_TIMEOUT", "0")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.Zero(t, config.Auth.IdleTimeout)
+	assert.Equal(t, 90*24*time.Hour, config.Auth.AbsoluteLifetime)
+}
+
+func TestLoadConfigMaintenanceDefaults(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	t.Setenv("MAINTENANCE_TOKENS_INTERVAL", "5m")
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.NotNil(t, config)
+
+	assert.True(t, config.Maintenance.Enabled)
+	assert.Equal(t, time.Hour, config.Maintenance.SessionsInterval)
+	assert.Equal(t, 5*time.Minute, config.Maintenance.TokensInterval)
+	assert.Equal(t, time.Hour, config.Maintenance.LockoutsInterval)
+}
+
+func TestLoadConfigReauthenticationWindow(t *testing.T) {
+	cleanup := setupTestConfig(t)
+	defer cleanup()
+
+	config, err := LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 10*time.Minute, config.Auth.ReauthenticationWindow)
+
+	t.Setenv("AUTH_REAUTHENTICATION_WINDOW", "0")
+
+	config, err = LoadConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), config.Auth.ReauthenticationWindow)
+}
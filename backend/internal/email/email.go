@@ -15,11 +15,23 @@ import (
 	"gosveltekit/internal/config"
 	"html/template"
 	"net/smtp"
+	"time"
 )
 
 // EmailServiceInterface defines the interface for email services
 type EmailServiceInterface interface {
 	SendPasswordResetEmail(to, token, username, displayName string) error
+	SendMagicLinkEmail(to, token, username, displayName string) error
+	SendVerificationEmail(to, token, username, displayName string) error
+	SendInvitationEmail(to, token, organizationName, inviterName string) error
+	SendNewSignInEmail(to, token, displayName string, signIn NewSignIn) error
+}
+
+// NewSignIn descreve um acesso à conta a partir de um dispositivo novo
+type NewSignIn struct {
+	Device string // navegador e sistema, ex.: "Firefox/Linux"
+	IP     string
+	At     time.Time
 }
 
 // EmailService é o serviço responsável pelo envio de emails
@@ -38,9 +50,20 @@ func NewEmailService(cfg *config.Config) *EmailService {
 type EmailData struct {
 	Username     string
 	ResetLink    string
+	LoginLink    string
+	VerifyLink   string
+	InviteLink   string
+	RevokeLink   string
 	DisplayName  string
 	AppName      string
 	SupportEmail string
+
+	OrganizationName string
+	InviterName      string
+
+	Device     string
+	IP         string
+	SignInTime string
 }
 
 // SendPasswordResetEmail envia um email de recuperação de senha com um link contendo o token
@@ -100,20 +123,278 @@ func (s *EmailService) SendPasswordResetEmail(to, token, username, displayName s
 	</html>
 	`
 
-	// Criamos um template a partir do HTML
-	t, err := template.New("reset_email").Parse(htmlBody)
+	body, err := renderTemplate("reset_email", htmlBody, data)
+	if err != nil {
+		return err
+	}
+
+	// Enviamos o email usando a função auxiliar
+	return s.sendEmail(to, subject, body)
+}
+
+// SendNewSignInEmail avisa sobre um acesso à conta a partir de um dispositivo
+// novo, com um link que encerra essa sessão
+func (s *EmailService) SendNewSignInEmail(to, token, displayName string, signIn NewSignIn) error {
+	subject := "Novo acesso à sua conta"
+
+	data := EmailData{
+		RevokeLink:   s.config.RevokeSessionURL + token,
+		DisplayName:  displayName,
+		AppName:      s.config.FromName,
+		SupportEmail: s.config.FromEmail,
+		Device:       signIn.Device,
+		IP:           signIn.IP,
+		SignInTime:   signIn.At.UTC().Format("02/01/2006 15:04") + " (UTC)",
+	}
+
+	htmlBody := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Novo acesso à sua conta</title>
+		<style>
+			body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f9f9f9; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #1e293b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+			.content { background-color: white; padding: 20px; border-radius: 0 0 5px 5px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
+			.button { display: inline-block; background-color: #1e293b; color: white; text-decoration: none; padding: 10px 20px; border-radius: 5px; margin: 20px 0; }
+			.footer { margin-top: 20px; text-align: center; font-size: 12px; color: #666; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Novo acesso à sua conta</h1>
+			</div>
+			<div class="content">
+				<p>Olá {{.DisplayName}},</p>
+				<p>Sua conta em {{.AppName}} foi acessada a partir de um dispositivo que não reconhecemos:</p>
+				<ul>
+					<li>Dispositivo: {{.Device}}</li>
+					<li>Horário aproximado: {{.SignInTime}}</li>
+					<li>Endereço IP: {{.IP}}</li>
+				</ul>
+				<p>Se foi você, não é preciso fazer nada.</p>
+				<p>Se não reconhece este acesso, encerre essa sessão clicando no botão abaixo e troque sua senha:</p>
+				<p style="text-align: center;">
+					<a href="{{.RevokeLink}}" class="button">Encerrar Sessão</a>
+				</p>
+				<p>Ou copie e cole o seguinte link no seu navegador:</p>
+				<p>{{.RevokeLink}}</p>
+				<p>Atenciosamente,<br>Equipe {{.AppName}}</p>
+			</div>
+			<div class="footer">
+				<p>Este é um email automático, por favor não responda.<br>
+				Em caso de dúvidas, entre em contato com {{.SupportEmail}}</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`
+
+	body, err := renderTemplate("new_sign_in_email", htmlBody, data)
+	if err != nil {
+		return err
+	}
+
+	return s.sendEmail(to, subject, body)
+}
+
+// SendMagicLinkEmail envia um link de acesso de uso único que dispensa a senha
+func (s *EmailService) SendMagicLinkEmail(to, token, username, displayName string) error {
+	subject := "Seu link de acesso"
+
+	data := EmailData{
+		Username:     username,
+		LoginLink:    s.config.MagicLinkURL + token,
+		DisplayName:  displayName,
+		AppName:      s.config.FromName,
+		SupportEmail: s.config.FromEmail,
+	}
+
+	htmlBody := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Seu link de acesso</title>
+		<style>
+			body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f9f9f9; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #1e293b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+			.content { background-color: white; padding: 20px; border-radius: 0 0 5px 5px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
+			.button { display: inline-block; background-color: #1e293b; color: white; text-decoration: none; padding: 10px 20px; border-radius: 5px; margin: 20px 0; }
+			.footer { margin-top: 20px; text-align: center; font-size: 12px; color: #666; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Entrar em {{.AppName}}</h1>
+			</div>
+			<div class="content">
+				<p>Olá {{.DisplayName}},</p>
+				<p>Recebemos um pedido para entrar na sua conta sem senha.</p>
+				<p>Se não foi você, ignore este email; ninguém terá acesso à sua conta sem este link.</p>
+				<p style="text-align: center;">
+					<a href="{{.LoginLink}}" class="button">Entrar</a>
+				</p>
+				<p>Ou copie e cole o seguinte link no seu navegador:</p>
+				<p>{{.LoginLink}}</p>
+				<p>O link só pode ser usado uma vez e expira em poucos minutos.</p>
+				<p>Atenciosamente,<br>Equipe {{.AppName}}</p>
+			</div>
+			<div class="footer">
+				<p>Este é um email automático, por favor não responda.<br>
+				Em caso de dúvidas, entre em contato com {{.SupportEmail}}</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`
+
+	body, err := renderTemplate("magic_link_email", htmlBody, data)
 	if err != nil {
-		return fmt.Errorf("erro ao analisar template: %w", err)
+		return err
+	}
+
+	return s.sendEmail(to, subject, body)
+}
+
+// SendVerificationEmail envia o link que confirma que o usuário controla o endereço
+func (s *EmailService) SendVerificationEmail(to, token, username, displayName string) error {
+	subject := "Confirme seu email"
+
+	data := EmailData{
+		Username:     username,
+		VerifyLink:   s.config.VerifyURL + token,
+		DisplayName:  displayName,
+		AppName:      s.config.FromName,
+		SupportEmail: s.config.FromEmail,
+	}
+
+	htmlBody := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Confirme seu email</title>
+		<style>
+			body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f9f9f9; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #1e293b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+			.content { background-color: white; padding: 20px; border-radius: 0 0 5px 5px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
+			.button { display: inline-block; background-color: #1e293b; color: white; text-decoration: none; padding: 10px 20px; border-radius: 5px; margin: 20px 0; }
+			.footer { margin-top: 20px; text-align: center; font-size: 12px; color: #666; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Confirme seu email</h1>
+			</div>
+			<div class="content">
+				<p>Olá {{.DisplayName}},</p>
+				<p>Obrigado por criar sua conta em {{.AppName}}. Para confirmar que este endereço é seu, clique no botão abaixo:</p>
+				<p style="text-align: center;">
+					<a href="{{.VerifyLink}}" class="button">Confirmar Email</a>
+				</p>
+				<p>Ou copie e cole o seguinte link no seu navegador:</p>
+				<p>{{.VerifyLink}}</p>
+				<p>Se você não criou uma conta, ignore este email.</p>
+				<p>Atenciosamente,<br>Equipe {{.AppName}}</p>
+			</div>
+			<div class="footer">
+				<p>Este é um email automático, por favor não responda.<br>
+				Em caso de dúvidas, entre em contato com {{.SupportEmail}}</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`
+
+	body, err := renderTemplate("verification_email", htmlBody, data)
+	if err != nil {
+		return err
+	}
+
+	return s.sendEmail(to, subject, body)
+}
+
+// SendInvitationEmail envia o convite para entrar em uma organização. O link
+// leva ao cadastro ou, para quem já tem conta, à aceitação do convite.
+func (s *EmailService) SendInvitationEmail(to, token, organizationName, inviterName string) error {
+	subject := "Convite para " + organizationName
+
+	data := EmailData{
+		InviteLink:       s.config.InviteURL + token,
+		AppName:          s.config.FromName,
+		SupportEmail:     s.config.FromEmail,
+		OrganizationName: organizationName,
+		InviterName:      inviterName,
+	}
+
+	htmlBody := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Convite para {{.OrganizationName}}</title>
+		<style>
+			body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; margin: 0; padding: 0; background-color: #f9f9f9; color: #333; }
+			.container { max-width: 600px; margin: 0 auto; padding: 20px; }
+			.header { background-color: #1e293b; color: white; padding: 20px; text-align: center; border-radius: 5px 5px 0 0; }
+			.content { background-color: white; padding: 20px; border-radius: 0 0 5px 5px; box-shadow: 0 2px 5px rgba(0,0,0,0.1); }
+			.button { display: inline-block; background-color: #1e293b; color: white; text-decoration: none; padding: 10px 20px; border-radius: 5px; margin: 20px 0; }
+			.footer { margin-top: 20px; text-align: center; font-size: 12px; color: #666; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1>Você foi convidado</h1>
+			</div>
+			<div class="content">
+				<p>Olá,</p>
+				<p>{{.InviterName}} convidou você para participar da organização {{.OrganizationName}} em {{.AppName}}. Para aceitar, clique no botão abaixo:</p>
+				<p style="text-align: center;">
+					<a href="{{.InviteLink}}" class="button">Aceitar Convite</a>
+				</p>
+				<p>Ou copie e cole o seguinte link no seu navegador:</p>
+				<p>{{.InviteLink}}</p>
+				<p>Se você não esperava este convite, ignore este email.</p>
+				<p>Atenciosamente,<br>Equipe {{.AppName}}</p>
+			</div>
+			<div class="footer">
+				<p>Este é um email automático, por favor não responda.<br>
+				Em caso de dúvidas, entre em contato com {{.SupportEmail}}</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`
+
+	body, err := renderTemplate("invitation_email", htmlBody, data)
+	if err != nil {
+		return err
+	}
+
+	return s.sendEmail(to, subject, body)
+}
+
+// renderTemplate aplica os dados a um template HTML de email
+func renderTemplate(name, htmlBody string, data EmailData) (string, error) {
+	t, err := template.New(name).Parse(htmlBody)
+	if err != nil {
+		return "", fmt.Errorf("erro ao analisar template: %w", err)
 	}
 
-	// Aplicamos os dados ao template
 	var body bytes.Buffer
 	if err := t.Execute(&body, data); err != nil {
-		return fmt.Errorf("erro ao executar template: %w", err)
+		return "", fmt.Errorf("erro ao executar template: %w", err)
 	}
-
-	// Enviamos o email usando a função auxiliar
-	return s.sendEmail(to, subject, body.String())
+	return body.String(), nil
 }
 
 // sendEmail é uma função auxiliar que envia um email usando SMTP
@@ -13,12 +13,29 @@ type MockEmailService struct {
 	mu             sync.Mutex
 }
 
+// Kinds of email recorded by MockEmailService
+const (
+	MockEmailPasswordReset = "password_reset"
+	MockEmailMagicLink     = "magic_link"
+	MockEmailVerification  = "email_verification"
+	MockEmailInvitation    = "invitation"
+	MockEmailNewSignIn     = "new_sign_in"
+)
+
 // MockEmail represents a sent email for testing
 type MockEmail struct {
+	Kind        string
 	To          string
 	Token       string
 	Username    string
 	DisplayName string
+
+	// Set for invitations only
+	OrganizationName string
+	InviterName      string
+
+	// Set for new sign-in alerts only
+	SignIn NewSignIn
 }
 
 // NewMockEmailService creates a new mock email service
@@ -30,10 +47,57 @@ func NewMockEmailService() *MockEmailService {
 
 // SendPasswordResetEmail records the email that would be sent
 func (m *MockEmailService) SendPasswordResetEmail(to, token, username, displayName string) error {
+	return m.record(MockEmailPasswordReset, to, token, username, displayName)
+}
+
+// SendMagicLinkEmail records the email that would be sent
+func (m *MockEmailService) SendMagicLinkEmail(to, token, username, displayName string) error {
+	return m.record(MockEmailMagicLink, to, token, username, displayName)
+}
+
+// SendVerificationEmail records the email that would be sent
+func (m *MockEmailService) SendVerificationEmail(to, token, username, displayName string) error {
+	return m.record(MockEmailVerification, to, token, username, displayName)
+}
+
+// SendInvitationEmail records the email that would be sent
+func (m *MockEmailService) SendInvitationEmail(to, token, organizationName, inviterName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sentEmails = append(m.sentEmails, MockEmail{
+		Kind:             MockEmailInvitation,
+		To:               to,
+		Token:            token,
+		OrganizationName: organizationName,
+		InviterName:      inviterName,
+	})
+
+	return m.sendEmailError
+}
+
+// SendNewSignInEmail records the email that would be sent
+func (m *MockEmailService) SendNewSignInEmail(to, token, displayName string, signIn NewSignIn) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sentEmails = append(m.sentEmails, MockEmail{
+		Kind:        MockEmailNewSignIn,
+		To:          to,
+		Token:       token,
+		DisplayName: displayName,
+		SignIn:      signIn,
+	})
+
+	return m.sendEmailError
+}
+
+func (m *MockEmailService) record(kind, to, token, username, displayName string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	m.sentEmails = append(m.sentEmails, MockEmail{
+		Kind:        kind,
 		To:          to,
 		Token:       token,
 		Username:    username,
@@ -43,7 +107,7 @@ func (m *MockEmailService) SendPasswordResetEmail(to, token, username, displayNa
 	return m.sendEmailError
 }
 
-// SetSendEmailError sets an error to be returned by SendPasswordResetEmail
+// SetSendEmailError sets an error to be returned by the Send methods
 func (m *MockEmailService) SetSendEmailError(err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueAccessTokenRequest defines the optional payload for issuing a signed
+// access token. Without scopes the token carries every scope the user may have.
+type IssueAccessTokenRequest struct {
+	Scopes []string `json:"scopes"`
+}
+
+// IssueAccessToken exchanges the current session for a short-lived signed
+// access token for downstream services.
+func (h *AuthHandler) IssueAccessToken(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+	sessionPublicID, _ := getContextString(c, "sessionPublicID")
+
+	var req IssueAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.authService.IssueAccessToken(userID, sessionPublicID, req.Scopes)
+	if err != nil {
+		writeAccessTokenError(c, err, "falha ao emitir token de acesso")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, token)
+}
+
+// AccessTokenJWKS publishes the public keys that verify access tokens.
+func (h *AuthHandler) AccessTokenJWKS(c *gin.Context) {
+	keys, err := h.authService.AccessTokenJWKS()
+	if err != nil {
+		writeAccessTokenError(c, err, "falha ao carregar chaves públicas")
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys)
+}
+
+func writeAccessTokenError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAccessTokenScope):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserNotActive):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAccessTokensUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
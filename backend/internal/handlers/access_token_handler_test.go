@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"gosveltekit/internal/service"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+func TestAuthHandler_IssueAccessToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success without body",
+			setupMock: func(m *MockAuthService) {
+				m.IssueAccessTokenFunc = func(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+					if userID != "1" || sessionPublicID != "session-1" || scopes != nil {
+						t.Errorf("unexpected arguments %q %q %v", userID, sessionPublicID, scopes)
+					}
+					return &service.AccessTokenResponse{AccessToken: "jwt", TokenType: "Bearer", ExpiresIn: 300}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "requested scopes",
+			body: `{"scopes":["read"]}`,
+			setupMock: func(m *MockAuthService) {
+				m.IssueAccessTokenFunc = func(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+					if !slices.Equal(scopes, []string{"read"}) {
+						t.Errorf("unexpected scopes %v", scopes)
+					}
+					return &service.AccessTokenResponse{AccessToken: "jwt", TokenType: "Bearer", Scope: "read"}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "invalid scope",
+			body: `{"scopes":["admin"]}`,
+			setupMock: func(m *MockAuthService) {
+				m.IssueAccessTokenFunc = func(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+					return nil, service.ErrInvalidAccessTokenScope
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "not configured",
+			setupMock: func(m *MockAuthService) {
+				m.IssueAccessTokenFunc = func(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+					return nil, service.ErrAccessTokensUnavailable
+				}
+			},
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			c.Set("sessionPublicID", "session-1")
+			req, _ := http.NewRequest(http.MethodPost, "/api/token", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.IssueAccessToken(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusOK && w.Header().Get("Cache-Control") != "no-store" {
+				t.Fatalf("expected Cache-Control no-store, got %q", w.Header().Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+func TestAuthHandler_AccessTokenJWKS(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		AccessTokenJWKSFunc: func() (*jose.JSONWebKeySet, error) {
+			return &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{}}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+	c.Request, _ = http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+
+	handler.AccessTokenJWKS(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := body["keys"]; !ok {
+		t.Fatalf("expected keys in response, got %s", w.Body.String())
+	}
+}
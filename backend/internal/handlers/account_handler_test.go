@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/middleware"
 	"gosveltekit/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -85,29 +88,32 @@ func TestAuthHandler_UpdateAccountProfile(t *testing.T) {
 }
 
 func TestAuthHandler_ChangeAccountPassword(t *testing.T) {
+	rotate := func(m *MockAuthService) {
+		m.ChangePasswordFunc = func(userID, sessionID string, input service.ChangePasswordInput) (*auth.Session, error) {
+			if sessionID != "old-session" {
+				t.Fatalf("expected current session, got %q", sessionID)
+			}
+			return &auth.Session{ID: "rotated-session", ExpiresAt: time.Now().Add(time.Hour)}, nil
+		}
+	}
+
 	tests := []struct {
-		name           string
-		setupMock      func(*MockAuthService)
-		expectedStatus int
+		name              string
+		sessionFromHeader bool
+		setupMock         func(*MockAuthService)
+		expectedStatus    int
 	}{
 		{
 			name: "wrong current password",
 			setupMock: func(m *MockAuthService) {
-				m.ChangePasswordFunc = func(userID string, input service.ChangePasswordInput) error {
-					return service.ErrWrongPassword
+				m.ChangePasswordFunc = func(userID, sessionID string, input service.ChangePasswordInput) (*auth.Session, error) {
+					return nil, service.ErrWrongPassword
 				}
 			},
 			expectedStatus: http.StatusUnauthorized,
 		},
-		{
-			name: "success",
-			setupMock: func(m *MockAuthService) {
-				m.ChangePasswordFunc = func(userID string, input service.ChangePasswordInput) error {
-					return nil
-				}
-			},
-			expectedStatus: http.StatusOK,
-		},
+		{name: "success with cookie", setupMock: rotate, expectedStatus: http.StatusOK},
+		{name: "success with header", sessionFromHeader: true, setupMock: rotate, expectedStatus: http.StatusOK},
 	}
 
 	for _, tt := range tests {
@@ -118,6 +124,8 @@ func TestAuthHandler_ChangeAccountPassword(t *testing.T) {
 			handler := NewAuthHandler(mockService)
 
 			c.Set("userID", "1")
+			c.Set("sessionID", "old-session")
+			c.Set("sessionFromHeader", tt.sessionFromHeader)
 			payload := map[string]any{
 				"current_password": "old",
 				"new_password":     "Secur3!PassA",
@@ -133,6 +141,92 @@ func TestAuthHandler_ChangeAccountPassword(t *testing.T) {
 			if w.Code != tt.expectedStatus {
 				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
 			}
+			// The current session continues under its new token, which only
+			// header clients get in the session header
+			if w.Code == http.StatusOK {
+				want := ""
+				if tt.sessionFromHeader {
+					want = "rotated-session"
+				}
+				if got := w.Header().Get(middleware.SessionHeaderName); got != want {
+					t.Fatalf("expected session header %q, got %q", want, got)
+				}
+				if cookie := w.Header().Get("Set-Cookie"); !strings.Contains(cookie, "session_id=rotated-session") {
+					t.Fatalf("expected rotated session cookie, got %q", cookie)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_Reauthenticate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: `{"password":"password123"}`,
+			setupMock: func(m *MockAuthService) {
+				m.ReauthenticateFunc = func(sessionID, password string) (*service.ReauthenticationStatus, error) {
+					if sessionID != "current-session" || password != "password123" {
+						t.Fatalf("unexpected reauthentication of %q with %q", sessionID, password)
+					}
+					return &service.ReauthenticationStatus{ReauthenticatedUntil: time.Now().Add(10 * time.Minute)}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "wrong password",
+			body: `{"password":"wrong"}`,
+			setupMock: func(m *MockAuthService) {
+				m.ReauthenticateFunc = func(sessionID, password string) (*service.ReauthenticationStatus, error) {
+					return nil, service.ErrWrongPassword
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "account locked",
+			body: `{"password":"password123"}`,
+			setupMock: func(m *MockAuthService) {
+				m.ReauthenticateFunc = func(sessionID, password string) (*service.ReauthenticationStatus, error) {
+					return nil, service.ErrAccountLocked
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing password",
+			body:           `{}`,
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("sessionID", "current-session")
+			req, _ := http.NewRequest(http.MethodPost, "/api/account/reauthenticate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.Reauthenticate(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusOK && !strings.Contains(w.Body.String(), "reauthenticated_until") {
+				t.Fatalf("expected reauthentication deadline, got %s", w.Body.String())
+			}
 		})
 	}
 }
@@ -153,7 +247,7 @@ func TestAuthHandler_ListAccountSessions(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 
 	c.Set("userID", "1")
-	c.Set("sessionID", "current-session")
+	c.Set("sessionPublicID", "current-session")
 
 	handler.ListAccountSessions(c)
 
@@ -196,7 +290,7 @@ func TestAuthHandler_RevokeAccountSession(t *testing.T) {
 			handler := NewAuthHandler(mockService)
 
 			c.Set("userID", "1")
-			c.Set("sessionID", "current-session")
+			c.Set("sessionPublicID", "current-session")
 			c.Params = gin.Params{{Key: "session_id", Value: "session-to-revoke"}}
 			c.Request, _ = http.NewRequest(http.MethodDelete, "/api/account/sessions/session-to-revoke", nil)
 
@@ -218,7 +312,7 @@ func TestAuthHandler_RevokeAccountSession_MissingParam(t *testing.T) {
 	handler := NewAuthHandler(mockService)
 
 	c.Set("userID", "1")
-	c.Set("sessionID", "current-session")
+	c.Set("sessionPublicID", "current-session")
 	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/account/sessions/", nil)
 
 	handler.RevokeAccountSession(c)
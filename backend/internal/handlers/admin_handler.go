@@ -4,13 +4,107 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/middleware"
 	"gosveltekit/internal/pagination"
 	"gosveltekit/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// UpdateUserRoleRequest is the body of an admin role change
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateAdminUserRole changes a user's role.
+func (h *AuthHandler) UpdateAdminUserRole(c *gin.Context) {
+	actorID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "disponível apenas para usuários"})
+		return
+	}
+
+	var req UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update, err := h.authService.UpdateUserRole(actorID, c.Param("user_id"), req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPermissionNotHeld),
+			errors.Is(err, service.ErrCannotChangeOwnRole):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao alterar papel do usuário"})
+		}
+		return
+	}
+
+	middleware.AddAuditDetails(c, map[string]string{
+		"previous_role": update.Previous.Role,
+		"role":          update.Role,
+	})
+	c.JSON(http.StatusOK, update.AdminUserRow)
+}
+
+// UpdateUserPermissionsRequest is the body of an admin permission change. It
+// replaces the user's grants and denies; empty lists clear them.
+type UpdateUserPermissionsRequest struct {
+	Grant []string `json:"grant"`
+	Deny  []string `json:"deny"`
+}
+
+// UpdateAdminUserPermissions changes a user's permission grants and denies.
+func (h *AuthHandler) UpdateAdminUserPermissions(c *gin.Context) {
+	actorID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "disponível apenas para usuários"})
+		return
+	}
+
+	var req UpdateUserPermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	update, err := h.authService.UpdateUserPermissions(actorID, c.Param("user_id"), auth.PermissionOverrides{
+		Grant: req.Grant,
+		Deny:  req.Deny,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPermission):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrPermissionNotHeld),
+			errors.Is(err, service.ErrCannotChangeOwnPermissions):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao alterar permissões do usuário"})
+		}
+		return
+	}
+
+	middleware.AddAuditDetails(c, map[string]string{
+		"previous_grant": strings.Join(update.Previous.PermissionOverrides.Grant, ","),
+		"previous_deny":  strings.Join(update.Previous.PermissionOverrides.Deny, ","),
+		"grant":          strings.Join(update.PermissionOverrides.Grant, ","),
+		"deny":           strings.Join(update.PermissionOverrides.Deny, ","),
+	})
+	c.JSON(http.StatusOK, update.AdminUserRow)
+}
+
 // ListAdminUsers returns a paginated administrative users listing.
 func (h *AuthHandler) ListAdminUsers(c *gin.Context) {
 	mode := pagination.Mode(c.Query("pagination_mode"))
@@ -64,6 +158,54 @@ func (h *AuthHandler) ListAdminUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
+// ListAdminOrganizations returns a paginated administrative organizations listing.
+func (h *AuthHandler) ListAdminOrganizations(c *gin.Context) {
+	mode := pagination.Mode(c.Query("pagination_mode"))
+	if mode == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrPaginationModeRequired.Error()})
+		return
+	}
+
+	input := service.ListAdminOrganizationsInput{PaginationMode: mode}
+
+	switch mode {
+	case pagination.ModeOffset:
+		offsetInput, err := buildOffsetAdminUsersInput(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetros de listagem inválidos"})
+			return
+		}
+		input.Offset = offsetInput
+	case pagination.ModeCursor:
+		cursorInput, err := buildCursorAdminUsersInput(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "parâmetros de listagem inválidos"})
+			return
+		}
+		input.Cursor = cursorInput
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrInvalidPaginationMode.Error()})
+		return
+	}
+
+	organizations, err := h.authService.ListAdminOrganizations(input)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrPaginationModeRequired),
+			errors.Is(err, service.ErrInvalidPaginationMode),
+			errors.Is(err, service.ErrInvalidAdminUsersQuery):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOrganizationsUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao listar organizações"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, organizations)
+}
+
 func buildOffsetAdminUsersInput(c *gin.Context) (*pagination.OffsetQuery, error) {
 	page, err := parseOptionalPositiveInt(c.Query("page"))
 	if err != nil {
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"gosveltekit/internal/auth"
 	"gosveltekit/internal/pagination"
 	"gosveltekit/internal/service"
 
@@ -150,3 +152,108 @@ func TestAuthHandler_ListAdminUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthHandler_UpdateAdminUserPermissions(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "unknown permission", err: service.ErrInvalidPermission, expectedStatus: http.StatusBadRequest},
+		{name: "permission not held", err: service.ErrPermissionNotHeld, expectedStatus: http.StatusForbidden},
+		{name: "own permissions", err: service.ErrCannotChangeOwnPermissions, expectedStatus: http.StatusForbidden},
+		{name: "unknown user", err: service.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				UpdateUserPermissionsFunc: func(actorID, userID string, overrides auth.PermissionOverrides) (*service.AdminUserUpdate, error) {
+					if actorID != "1" || userID != "2" || len(overrides.Grant) != 1 || overrides.Grant[0] != auth.PermissionUsersRead {
+						t.Errorf("unexpected arguments %q %q %+v", actorID, userID, overrides)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.AdminUserUpdate{
+						AdminUserRow: service.AdminUserRow{ID: userID, PermissionOverrides: overrides},
+						Previous: service.AdminUserRow{ID: userID, PermissionOverrides: auth.PermissionOverrides{
+							Deny: []string{auth.PermissionUsersRead},
+						}},
+					}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			c.Params = gin.Params{{Key: "user_id", Value: "2"}}
+			req, _ := http.NewRequest(http.MethodPut, "/api/admin/users/2/permissions", strings.NewReader(`{"grant":["users:read"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.UpdateAdminUserPermissions(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.err == nil {
+				details := c.GetStringMapString("auditDetails")
+				if details["previous_deny"] != auth.PermissionUsersRead || details["grant"] != auth.PermissionUsersRead {
+					t.Errorf("expected the old and new overrides in the audit details, got %v", details)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ListAdminOrganizations(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawQuery       string
+		err            error
+		expectedStatus int
+	}{
+		{name: "offset success", rawQuery: "pagination_mode=offset&page=1&sort=name", expectedStatus: http.StatusOK},
+		{name: "cursor success", rawQuery: "pagination_mode=cursor&page_size=5&after=test-cursor", expectedStatus: http.StatusOK},
+		{name: "missing pagination mode", rawQuery: "page=1", expectedStatus: http.StatusBadRequest},
+		{name: "invalid query", rawQuery: "pagination_mode=offset&page_size=abc", expectedStatus: http.StatusBadRequest},
+		{name: "service validation failure", rawQuery: "pagination_mode=offset&sort=unknown", err: service.ErrInvalidAdminUsersQuery, expectedStatus: http.StatusBadRequest},
+		{name: "service failure", rawQuery: "pagination_mode=offset", err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := setupTestRouter()
+			mockService := &MockAuthService{
+				ListAdminOrgsFunc: func(
+					input service.ListAdminOrganizationsInput,
+				) (*pagination.Response[service.AdminOrganizationRow], error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					if (input.PaginationMode == pagination.ModeOffset) != (input.Offset != nil) {
+						t.Fatalf("unexpected input: %#v", input)
+					}
+					if input.Cursor != nil && input.Cursor.After != "test-cursor" {
+						t.Fatalf("unexpected input: %#v", input)
+					}
+					return &pagination.Response[service.AdminOrganizationRow]{
+						Items:          []service.AdminOrganizationRow{},
+						PaginationMode: input.PaginationMode,
+					}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/admin/organizations?"+tt.rawQuery, nil)
+
+			handler.ListAdminOrganizations(c)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
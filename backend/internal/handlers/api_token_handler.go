@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPITokenRequest defines payload for creating a personal access token.
+// Without expires_at the token never expires.
+type CreateAPITokenRequest struct {
+	Name      string     `json:"name"       binding:"required,max=100"`
+	Scopes    []string   `json:"scopes"     binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ListAPITokens returns the authenticated user's personal access tokens.
+func (h *AuthHandler) ListAPITokens(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	tokens, err := h.authService.ListAPITokens(userID)
+	if err != nil {
+		writeAPITokenError(c, err, "falha ao listar tokens de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// CreateAPIToken issues a personal access token. The response is the only
+// time the token's value is shown.
+func (h *AuthHandler) CreateAPIToken(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req CreateAPITokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := h.authService.CreateAPIToken(userID, service.CreateAPITokenInput{
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		writeAPITokenError(c, err, "falha ao criar token de API")
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+// RevokeAPIToken deletes one of the authenticated user's personal access tokens.
+func (h *AuthHandler) RevokeAPIToken(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	if err := h.authService.RevokeAPIToken(userID, c.Param("token_id")); err != nil {
+		writeAPITokenError(c, err, "falha ao revogar token de API")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "token de API revogado"})
+}
+
+func writeAPITokenError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAPITokenName),
+		errors.Is(err, service.ErrInvalidAPITokenScope),
+		errors.Is(err, service.ErrInvalidAPITokenExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAPITokenNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAPITokensUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_CreateAPIToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: map[string]any{"name": "ci", "scopes": []string{"read"}, "expires_at": "2030-01-02T15:04:05Z"},
+			setupMock: func(m *MockAuthService) {
+				m.CreateAPITokenFunc = func(userID string, input service.CreateAPITokenInput) (*service.CreatedAPIToken, error) {
+					if userID != "1" || input.Name != "ci" || input.ExpiresAt == nil || input.ExpiresAt.Year() != 2030 {
+						t.Errorf("unexpected arguments %q %+v", userID, input)
+					}
+					return &service.CreatedAPIToken{
+						APITokenInfo: service.APITokenInfo{ID: "7", Name: input.Name, Scopes: input.Scopes},
+						Token:        "gsk_secret",
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "invalid scope",
+			body: map[string]any{"name": "ci", "scopes": []string{"root"}},
+			setupMock: func(m *MockAuthService) {
+				m.CreateAPITokenFunc = func(userID string, input service.CreateAPITokenInput) (*service.CreatedAPIToken, error) {
+					return nil, service.ErrInvalidAPITokenScope
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing scopes",
+			body:           map[string]any{"name": "ci"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/account/tokens", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.CreateAPIToken(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusCreated {
+				var response map[string]any
+				if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+					t.Fatal(err)
+				}
+				if response["token"] != "gsk_secret" || response["id"] != "7" {
+					t.Fatalf("unexpected response %v", response)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RevokeAPIToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "not found", err: service.ErrAPITokenNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				RevokeAPITokenFunc: func(userID, tokenID string) error {
+					if tokenID != "7" {
+						t.Errorf("unexpected token %q", tokenID)
+					}
+					return tt.err
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			c.Params = append(c.Params, gin.Param{Key: "token_id", Value: "7"})
+			c.Request, _ = http.NewRequest(http.MethodDelete, "/api/account/tokens/7", nil)
+
+			handler.RevokeAPIToken(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gosveltekit/internal/pagination"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAuditEvents returns the audit log, filtered by actor_id, user_id,
+// action and a from/to time range (RFC 3339). It only pages by cursor.
+func (h *AuthHandler) ListAuditEvents(c *gin.Context) {
+	cursor, err := buildAuditCursorInput(c)
+	if err != nil {
+		writeAuditError(c, err)
+		return
+	}
+
+	from, err := parseOptionalTime(c.Query("from"))
+	if err != nil {
+		writeAuditError(c, service.ErrInvalidAuditQuery)
+		return
+	}
+	to, err := parseOptionalTime(c.Query("to"))
+	if err != nil {
+		writeAuditError(c, service.ErrInvalidAuditQuery)
+		return
+	}
+
+	events, err := h.authService.ListAuditEvents(service.ListAuditEventsInput{
+		ActorID: c.Query("actor_id"),
+		UserID:  c.Query("user_id"),
+		Type:    c.Query("action"),
+		From:    from,
+		To:      to,
+		Cursor:  cursor,
+	})
+	if err != nil {
+		writeAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// ListSecurityActivity returns the audit events that concern the signed-in
+// user's account, newest first.
+func (h *AuthHandler) ListSecurityActivity(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	cursor, err := buildAuditCursorInput(c)
+	if err != nil {
+		writeAuditError(c, err)
+		return
+	}
+
+	activity, err := h.authService.ListSecurityActivity(userID, cursor)
+	if err != nil {
+		writeAuditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, activity)
+}
+
+// buildAuditCursorInput reads the cursor parameters; pagination_mode may be
+// omitted since the audit log has no offset mode
+func buildAuditCursorInput(c *gin.Context) (*pagination.CursorQuery, error) {
+	if mode := pagination.Mode(c.Query("pagination_mode")); mode != "" && mode != pagination.ModeCursor {
+		return nil, service.ErrInvalidPaginationMode
+	}
+
+	cursor, err := buildCursorAdminUsersInput(c)
+	if err != nil {
+		return nil, service.ErrInvalidAuditQuery
+	}
+	return cursor, nil
+}
+
+func parseOptionalTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func writeAuditError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidAuditQuery),
+		errors.Is(err, service.ErrInvalidPaginationMode):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAuditLogUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao consultar auditoria"})
+	}
+}
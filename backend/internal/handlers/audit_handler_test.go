@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/pagination"
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_ListAuditEvents(t *testing.T) {
+	tests := []struct {
+		name           string
+		rawQuery       string
+		err            error
+		expectedStatus int
+	}{
+		{name: "no filters", rawQuery: "", expectedStatus: http.StatusOK},
+		{
+			name:           "filters and cursor",
+			rawQuery:       "pagination_mode=cursor&actor_id=1&user_id=2&action=login.failed&from=2026-01-01T00:00:00Z&to=2026-02-01T00:00:00Z&page_size=5&after=test-cursor",
+			expectedStatus: http.StatusOK,
+		},
+		{name: "offset mode", rawQuery: "pagination_mode=offset&page=1", expectedStatus: http.StatusBadRequest},
+		{name: "invalid time", rawQuery: "from=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "invalid page size", rawQuery: "page_size=abc", expectedStatus: http.StatusBadRequest},
+		{name: "service validation failure", rawQuery: "actor_id=admin", err: service.ErrInvalidAuditQuery, expectedStatus: http.StatusBadRequest},
+		{name: "audit log unavailable", err: service.ErrAuditLogUnavailable, expectedStatus: http.StatusNotImplemented},
+		{name: "service failure", err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := setupTestRouter()
+			mockService := &MockAuthService{
+				ListAuditEventsFunc: func(
+					input service.ListAuditEventsInput,
+				) (*pagination.Response[service.AuditEventRow], error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					if input.Cursor == nil {
+						t.Fatalf("unexpected input: %#v", input)
+					}
+					if input.ActorID == "1" {
+						from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+						if input.UserID != "2" || input.Type != "login.failed" || !input.From.Equal(from) ||
+							input.To.IsZero() || input.Cursor.PageSize != 5 || input.Cursor.After != "test-cursor" {
+							t.Fatalf("unexpected input: %#v", input)
+						}
+					}
+					return &pagination.Response[service.AuditEventRow]{
+						Items:          []service.AuditEventRow{},
+						PaginationMode: pagination.ModeCursor,
+					}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			c.Request, _ = http.NewRequest(http.MethodGet, "/api/admin/audit?"+tt.rawQuery, nil)
+
+			handler.ListAuditEvents(c)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ListSecurityActivity(t *testing.T) {
+	c, rec := setupTestRouter()
+	var gotUserID string
+	mockService := &MockAuthService{
+		ListSecurityActivityFunc: func(
+			userID string,
+			cursor *pagination.CursorQuery,
+		) (*pagination.Response[service.AuditEventRow], error) {
+			gotUserID = userID
+			return &pagination.Response[service.AuditEventRow]{Items: []service.AuditEventRow{}}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/account/security-activity?page_size=10", nil)
+	handler.ListSecurityActivity(c)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d without a user, got %d", http.StatusUnauthorized, rec.Code)
+	}
+
+	c, rec = setupTestRouter()
+	c.Set("userID", "1")
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/account/security-activity?page_size=10", nil)
+	handler.ListSecurityActivity(c)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected %d, got %d", http.StatusOK, rec.Code)
+	}
+	if gotUserID != "1" {
+		t.Fatalf("expected the signed-in user, got %q", gotUserID)
+	}
+}
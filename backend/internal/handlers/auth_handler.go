@@ -3,10 +3,12 @@ package handlers
 
 import (
 	"errors"
+	"log/slog"
 	"net/http"
 
 	"gosveltekit/internal/auth"
 	"gosveltekit/internal/middleware"
+	"gosveltekit/internal/models"
 	"gosveltekit/internal/service"
 	"gosveltekit/internal/validation"
 
@@ -15,8 +17,9 @@ import (
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authService  service.AuthServiceInterface
-	cookieSecure bool
+	authService      service.AuthServiceInterface
+	cookieSecure     bool
+	oauthFrontendURL string
 }
 
 // NewAuthHandler creates a new AuthHandler instance
@@ -27,11 +30,18 @@ func NewAuthHandler(authService service.AuthServiceInterface, cookieSecure ...bo
 	}
 
 	return &AuthHandler{
-		authService:  authService,
-		cookieSecure: secure,
+		authService:      authService,
+		cookieSecure:     secure,
+		oauthFrontendURL: "/",
 	}
 }
 
+// SetOAuthFrontendURL sets the frontend page the browser returns to after a
+// social login callback
+func (h *AuthHandler) SetOAuthFrontendURL(url string) {
+	h.oauthFrontendURL = url
+}
+
 // LoginRequest represents the login request body
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
@@ -42,10 +52,12 @@ type LoginRequest struct {
 // RegistrationRequest represents the registration request body
 type RegistrationRequest struct {
 	Username string `json:"username" binding:"required"`
-	Email    string `json:"email"    binding:"required"`
+	Email    string `json:"email"    binding:"required_without=InvitationToken"` // taken from the invitation when there is one
 
 	Passphrase  string `json:"password"     binding:"required"`
 	DisplayName string `json:"display_name" binding:"required"`
+
+	InvitationToken string `json:"invitation_token,omitempty"`
 }
 
 // PasswordResetRequest represents the password reset request body
@@ -55,6 +67,12 @@ type PasswordResetRequest struct {
 	ConfirmPassword string `json:"confirm_password" binding:"required"`
 }
 
+// ReauthenticateRequest defines payload for confirming the password before
+// sensitive account actions.
+type ReauthenticateRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
 // UpdateProfileRequest represents fields that can be changed by the user.
 type UpdateProfileRequest struct {
 	DisplayName *string `json:"display_name"`
@@ -62,6 +80,14 @@ type UpdateProfileRequest struct {
 	LastName    *string `json:"last_name"`
 }
 
+// CurrentUserResponse is the authenticated user, who is impersonating them if
+// an admin is, and the organization they are working in, if any
+type CurrentUserResponse struct {
+	*auth.UserData
+	Impersonation      *service.ImpersonationInfo `json:"impersonation,omitempty"`
+	ActiveOrganization *service.OrganizationInfo  `json:"active_organization,omitempty"`
+}
+
 // ChangePasswordRequest represents the request body for changing password.
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
@@ -88,6 +114,10 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 
 	response, err := h.authService.Login(req.Username, req.Passphrase, ip, userAgent)
+	if errors.Is(err, service.ErrEmailNotVerified) {
+		writeEmailNotVerified(c)
+		return
+	}
 	if err != nil {
 		status := http.StatusUnauthorized
 		message := "credenciais inválidas"
@@ -95,7 +125,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		switch {
 		case errors.Is(err, service.ErrUserNotActive):
 			message = "usuário inativo"
-		case err.Error() == "conta temporariamente bloqueada, tente novamente mais tarde":
+		case errors.Is(err, service.ErrAccountLocked):
 			message = err.Error()
 		}
 
@@ -103,12 +133,55 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Set session cookie
-	middleware.SetSessionCookie(c, response.SessionID, response.ExpiresAt, h.cookieSecure)
+	h.respondWithLogin(c, response)
+}
+
+// respondWithLogin sets the session cookie and returns the login response, or
+// only the challenge when a second factor is still pending
+func (h *AuthHandler) respondWithLogin(c *gin.Context, response *service.LoginResponse) {
+	// Second factor pending: no session yet, hand back the challenge only
+	if response.TwoFactorRequired {
+		c.JSON(http.StatusOK, gin.H{
+			"two_factor_required": true,
+			"challenge_token":     response.ChallengeToken,
+			"expires_at":          response.ExpiresAt,
+		})
+		return
+	}
+
+	h.startSession(c, response)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// startSession sets the cookie of a session created by a login and ends the
+// session the request came with, if any, so that a login never keeps using a
+// session ID that existed before it
+func (h *AuthHandler) startSession(c *gin.Context, response *service.LoginResponse) {
+	if previous := middleware.RequestSessionID(c); previous != "" && previous != response.SessionID {
+		_ = h.authService.Logout(previous)
+	}
+
+	middleware.SetSessionCookie(c, response.SessionID, response.ExpiresAt, h.cookieSecure)
+}
+
+// rotateCurrentSession gives the request's session a new token after the
+// user's authentication level changed and hands it to the client. Failing to
+// rotate does not undo the change, so it is only logged.
+func (h *AuthHandler) rotateCurrentSession(c *gin.Context) {
+	sessionID, ok := getContextString(c, "sessionID")
+	if !ok {
+		return
+	}
+
+	session, err := h.authService.RotateSession(sessionID)
+	if err != nil {
+		slog.Warn("failed to rotate session", "err", err)
+		return
+	}
+	middleware.SetRotatedSession(c, session, h.cookieSecure)
+}
+
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *gin.Context) {
 	sessionID, exists := c.Get("sessionID")
@@ -136,21 +209,31 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Validate all registration data
-	if err := validation.ValidateRegistrationRequest(
-		req.Username,
-		req.Email,
-		req.Passphrase,
-		req.DisplayName,
-	); err != nil {
+	// Validate all registration data; an invitation supplies the email
+	var err error
+	if req.InvitationToken != "" {
+		err = validation.ValidateInvitedRegistrationRequest(req.Username, req.Passphrase, req.DisplayName)
+	} else {
+		err = validation.ValidateRegistrationRequest(req.Username, req.Email, req.Passphrase, req.DisplayName)
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Forward to service layer
-	user, err := h.authService.Register(req.Username, req.Email, req.Passphrase, req.DisplayName)
+	user, err := h.registerUser(&req)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, service.ErrInvitationRequired),
+			errors.Is(err, service.ErrInvitationInvalid),
+			errors.Is(err, service.ErrInvitationExpired),
+			errors.Is(err, service.ErrInvitationAccountExists),
+			errors.Is(err, service.ErrInvitationsUnavailable):
+			writeInvitationError(c, err, "falha ao criar conta")
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -159,6 +242,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+func (h *AuthHandler) registerUser(req *RegistrationRequest) (*models.User, error) {
+	if req.InvitationToken != "" {
+		return h.authService.RegisterWithInvitation(req.InvitationToken, req.Username, req.Passphrase, req.DisplayName)
+	}
+	return h.authService.Register(req.Username, req.Email, req.Passphrase, req.DisplayName)
+}
+
 // RequestPasswordReset handles password reset requests
 func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
 	var req struct {
@@ -229,7 +319,27 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user.(*auth.UserData))
+	response := CurrentUserResponse{UserData: user.(*auth.UserData)}
+	if value, ok := c.Get("session"); ok {
+		session := value.(*auth.Session)
+		impersonation, err := h.authService.GetImpersonation(session)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao obter usuário"})
+			return
+		}
+		response.Impersonation = impersonation
+
+		if session.ActiveOrganizationID != "" {
+			organization, err := h.authService.GetOrganization(session.ActiveOrganizationID, session.UserID)
+			if err != nil && !errors.Is(err, service.ErrOrganizationNotFound) {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao obter usuário"})
+				return
+			}
+			response.ActiveOrganization = organization
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetAccountProfile returns profile data for the current authenticated user.
@@ -300,7 +410,8 @@ func (h *AuthHandler) ChangeAccountPassword(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ChangePassword(userID, service.ChangePasswordInput{
+	sessionID, _ := getContextString(c, "sessionID")
+	session, err := h.authService.ChangePassword(userID, sessionID, service.ChangePasswordInput{
 		CurrentPassword: req.CurrentPassword,
 		NewPassword:     req.NewPassword,
 		ConfirmPassword: req.ConfirmPassword,
@@ -324,12 +435,44 @@ func (h *AuthHandler) ChangeAccountPassword(c *gin.Context) {
 		return
 	}
 
-	// Current session is invalidated during password change.
-	middleware.ClearSessionCookie(c, h.cookieSecure)
+	// Other sessions ended; the current one continues under a new token
+	middleware.SetRotatedSession(c, session, h.cookieSecure)
 
 	c.JSON(http.StatusOK, gin.H{"message": "senha alterada com sucesso"})
 }
 
+// Reauthenticate confirms the authenticated user's password, allowing the
+// routes behind middleware.RequireRecentAuth for a while.
+func (h *AuthHandler) Reauthenticate(c *gin.Context) {
+	sessionID, ok := getContextString(c, "sessionID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req ReauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status, err := h.authService.Reauthenticate(sessionID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWrongPassword),
+			errors.Is(err, service.ErrAccountLocked),
+			errors.Is(err, service.ErrUserNotActive),
+			errors.Is(err, service.ErrInvalidToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao confirmar senha"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 // ListAccountSessions returns all sessions from the authenticated user.
 func (h *AuthHandler) ListAccountSessions(c *gin.Context) {
 	userID, ok := getContextString(c, "userID")
@@ -338,13 +481,13 @@ func (h *AuthHandler) ListAccountSessions(c *gin.Context) {
 		return
 	}
 
-	currentSessionID, ok := getContextString(c, "sessionID")
+	currentPublicID, ok := getContextString(c, "sessionPublicID")
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
 		return
 	}
 
-	sessions, err := h.authService.ListSessions(userID, currentSessionID)
+	sessions, err := h.authService.ListSessions(userID, currentPublicID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao listar sessões"})
 		return
@@ -361,19 +504,19 @@ func (h *AuthHandler) RevokeAccountSession(c *gin.Context) {
 		return
 	}
 
-	currentSessionID, ok := getContextString(c, "sessionID")
+	currentPublicID, ok := getContextString(c, "sessionPublicID")
 	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
 		return
 	}
 
-	sessionID := c.Param("session_id")
-	if sessionID == "" {
+	publicID := c.Param("session_id")
+	if publicID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id é obrigatório"})
 		return
 	}
 
-	if err := h.authService.RevokeSession(userID, sessionID, currentSessionID); err != nil {
+	if err := h.authService.RevokeSession(userID, publicID, currentPublicID); err != nil {
 		switch {
 		case errors.Is(err, service.ErrAccessDenied):
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
@@ -383,7 +526,7 @@ func (h *AuthHandler) RevokeAccountSession(c *gin.Context) {
 		return
 	}
 
-	if sessionID == currentSessionID {
+	if publicID == currentPublicID {
 		middleware.ClearSessionCookie(c, h.cookieSecure)
 	}
 
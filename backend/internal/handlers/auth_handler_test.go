@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
@@ -17,23 +18,79 @@ import (
 	"gosveltekit/internal/service"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
 )
 
 // MockAuthService implements the service.AuthServiceInterface interface
 type MockAuthService struct {
-	LoginFunc                func(username, password, ip, userAgent string) (*service.LoginResponse, error)
-	ValidateSessionFunc      func(sessionID string) (*auth.Session, *auth.UserData, error)
-	LogoutFunc               func(sessionID string) error
-	LogoutAllFunc            func(userID string) error
-	RegisterFunc             func(username, email, password, displayName string) (*models.User, error)
-	RequestPasswordResetFunc func(email string) error
-	ResetPasswordFunc        func(token, newPassword string) error
-	GetProfileFunc           func(userID string) (*service.AccountProfile, error)
-	UpdateProfileFunc        func(userID string, input service.UpdateProfileInput) (*service.AccountProfile, error)
-	ChangePasswordFunc       func(userID string, input service.ChangePasswordInput) error
-	ListSessionsFunc         func(userID, currentSessionID string) ([]service.SessionInfo, error)
-	RevokeSessionFunc        func(userID, sessionID, currentSessionID string) error
-	ListAdminUsersFunc       func(input service.ListAdminUsersInput) (*pagination.Response[service.AdminUserRow], error)
+	LoginFunc                   func(username, password, ip, userAgent string) (*service.LoginResponse, error)
+	ValidateSessionFunc         func(sessionID string, metadata auth.SessionMetadata) (*auth.Session, *auth.UserData, error)
+	LogoutFunc                  func(sessionID string) error
+	LogoutAllFunc               func(userID string) error
+	RegisterFunc                func(username, email, password, displayName string) (*models.User, error)
+	RegisterWithInvitationFunc  func(token, username, password, displayName string) (*models.User, error)
+	RequestPasswordResetFunc    func(email string) error
+	ResetPasswordFunc           func(token, newPassword string) error
+	GetProfileFunc              func(userID string) (*service.AccountProfile, error)
+	UpdateProfileFunc           func(userID string, input service.UpdateProfileInput) (*service.AccountProfile, error)
+	ChangePasswordFunc          func(userID, sessionID string, input service.ChangePasswordInput) (*auth.Session, error)
+	RotateSessionFunc           func(sessionID string) (*auth.Session, error)
+	ReauthenticateFunc          func(sessionID, password string) (*service.ReauthenticationStatus, error)
+	ListSessionsFunc            func(userID, currentSessionID string) ([]service.SessionInfo, error)
+	RevokeSessionFunc           func(userID, sessionID, currentSessionID string) error
+	ListAdminUsersFunc          func(input service.ListAdminUsersInput) (*pagination.Response[service.AdminUserRow], error)
+	UpdateUserRoleFunc          func(actorID, userID, role string) (*service.AdminUserUpdate, error)
+	UpdateUserPermissionsFunc   func(actorID, userID string, overrides auth.PermissionOverrides) (*service.AdminUserUpdate, error)
+	ImpersonateUserFunc         func(adminID, targetUserID, reason, ip, userAgent string) (*service.LoginResponse, error)
+	StopImpersonationFunc       func(sessionID, ip, userAgent string) error
+	GetImpersonationFunc        func(session *auth.Session) (*service.ImpersonationInfo, error)
+	ListOrganizationsFunc       func(userID string) ([]service.OrganizationInfo, error)
+	CreateOrganizationFunc      func(userID, name string) (*service.OrganizationInfo, error)
+	GetOrganizationFunc         func(orgID, userID string) (*service.OrganizationInfo, error)
+	DeleteOrganizationFunc      func(orgID, actorID string) error
+	ListOrgMembersFunc          func(orgID string) ([]service.OrganizationMemberInfo, error)
+	UpdateOrgMemberRoleFunc     func(orgID, actorID, userID, role string) (*service.OrganizationMemberInfo, error)
+	RemoveOrgMemberFunc         func(orgID, actorID, userID string) error
+	SwitchOrganizationFunc      func(sessionID, userID, orgID string) (*service.OrganizationInfo, error)
+	ListAdminOrgsFunc           func(input service.ListAdminOrganizationsInput) (*pagination.Response[service.AdminOrganizationRow], error)
+	ListAuditEventsFunc         func(input service.ListAuditEventsInput) (*pagination.Response[service.AuditEventRow], error)
+	ListSecurityActivityFunc    func(userID string, cursor *pagination.CursorQuery) (*pagination.Response[service.AuditEventRow], error)
+	InviteOrgMemberFunc         func(orgID, actorID string, input service.InviteOrganizationMemberInput) (*service.InvitationInfo, error)
+	ListOrgInvitationsFunc      func(orgID string) ([]service.InvitationInfo, error)
+	ResendOrgInvitationFunc     func(orgID, actorID, invitationID string) (*service.InvitationInfo, error)
+	RevokeOrgInvitationFunc     func(orgID, actorID, invitationID string) error
+	GetInvitationFunc           func(token string) (*service.InvitationPreview, error)
+	AcceptInvitationFunc        func(token, userID string) (*service.OrganizationInfo, error)
+	VerifyTwoFactorFunc         func(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error)
+	GetTwoFactorStatusFunc      func(userID string) (*service.TwoFactorStatus, error)
+	SetupTOTPFunc               func(userID string) (*service.TOTPSetup, error)
+	ConfirmTOTPFunc             func(userID, code string) ([]string, error)
+	DisableTOTPFunc             func(userID, password string) error
+	RegenerateCodesFunc         func(userID, password string) ([]string, error)
+	BeginPasskeyLoginFunc       func() (*service.PasskeyOptions, error)
+	FinishPasskeyLoginFunc      func(ceremonyToken string, credential []byte, ip, userAgent string) (*service.LoginResponse, error)
+	ListPasskeysFunc            func(userID string) ([]service.PasskeyInfo, error)
+	BeginPasskeyRegFunc         func(userID string) (*service.PasskeyOptions, error)
+	FinishPasskeyRegFunc        func(userID, ceremonyToken, name string, credential []byte) (*service.PasskeyInfo, error)
+	DeletePasskeyFunc           func(userID, passkeyID string) error
+	ListAPITokensFunc           func(userID string) ([]service.APITokenInfo, error)
+	CreateAPITokenFunc          func(userID string, input service.CreateAPITokenInput) (*service.CreatedAPIToken, error)
+	RevokeAPITokenFunc          func(userID, tokenID string) error
+	IssueAccessTokenFunc        func(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error)
+	AccessTokenJWKSFunc         func() (*jose.JSONWebKeySet, error)
+	ListOAuthClientsFunc        func() ([]service.OAuthClientInfo, error)
+	CreateOAuthClientFunc       func(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error)
+	DeleteOAuthClientFunc       func(clientID string) error
+	IssueClientTokenFunc        func(clientID, clientSecret string, scopes []string) (*service.AccessTokenResponse, error)
+	ListOAuthProvidersFunc      func() []string
+	BeginOAuthLoginFunc         func(provider string) (*service.OAuthRedirect, error)
+	CompleteOAuthLoginFunc      func(ctx context.Context, provider, state, code, ip, userAgent string) (*service.LoginResponse, error)
+	ListIdentitiesFunc          func(userID string) ([]service.IdentityInfo, error)
+	RequestMagicLinkFunc        func(email string) error
+	LoginWithMagicLinkFunc      func(token, ip, userAgent string) (*service.LoginResponse, error)
+	RevokeSessionByLinkFunc     func(token string) error
+	VerifyEmailFunc             func(token string) error
+	ResendVerificationEmailFunc func(email string) error
 }
 
 func (m *MockAuthService) Login(username, password, ip, userAgent string) (*service.LoginResponse, error) {
@@ -43,11 +100,11 @@ func (m *MockAuthService) Login(username, password, ip, userAgent string) (*serv
 	return m.LoginFunc(username, password, ip, userAgent)
 }
 
-func (m *MockAuthService) ValidateSession(sessionID string) (*auth.Session, *auth.UserData, error) {
+func (m *MockAuthService) ValidateSession(sessionID string, metadata auth.SessionMetadata) (*auth.Session, *auth.UserData, error) {
 	if m.ValidateSessionFunc == nil {
 		return nil, nil, nil
 	}
-	return m.ValidateSessionFunc(sessionID)
+	return m.ValidateSessionFunc(sessionID, metadata)
 }
 
 func (m *MockAuthService) Logout(sessionID string) error {
@@ -71,6 +128,13 @@ func (m *MockAuthService) Register(username, email, password, displayName string
 	return m.RegisterFunc(username, email, password, displayName)
 }
 
+func (m *MockAuthService) RegisterWithInvitation(token, username, password, displayName string) (*models.User, error) {
+	if m.RegisterWithInvitationFunc == nil {
+		return nil, nil
+	}
+	return m.RegisterWithInvitationFunc(token, username, password, displayName)
+}
+
 func (m *MockAuthService) RequestPasswordReset(email string) error {
 	if m.RequestPasswordResetFunc == nil {
 		return nil
@@ -102,11 +166,25 @@ func (m *MockAuthService) UpdateProfile(
 	return m.UpdateProfileFunc(userID, input)
 }
 
-func (m *MockAuthService) ChangePassword(userID string, input service.ChangePasswordInput) error {
+func (m *MockAuthService) ChangePassword(userID, sessionID string, input service.ChangePasswordInput) (*auth.Session, error) {
 	if m.ChangePasswordFunc == nil {
-		return nil
+		return &auth.Session{ID: sessionID}, nil
+	}
+	return m.ChangePasswordFunc(userID, sessionID, input)
+}
+
+func (m *MockAuthService) RotateSession(sessionID string) (*auth.Session, error) {
+	if m.RotateSessionFunc == nil {
+		return &auth.Session{ID: sessionID}, nil
+	}
+	return m.RotateSessionFunc(sessionID)
+}
+
+func (m *MockAuthService) Reauthenticate(sessionID, password string) (*service.ReauthenticationStatus, error) {
+	if m.ReauthenticateFunc == nil {
+		return &service.ReauthenticationStatus{}, nil
 	}
-	return m.ChangePasswordFunc(userID, input)
+	return m.ReauthenticateFunc(sessionID, password)
 }
 
 func (m *MockAuthService) ListSessions(userID, currentSessionID string) ([]service.SessionInfo, error) {
@@ -132,6 +210,383 @@ func (m *MockAuthService) ListAdminUsers(
 	return m.ListAdminUsersFunc(input)
 }
 
+func (m *MockAuthService) UpdateUserRole(actorID, userID, role string) (*service.AdminUserUpdate, error) {
+	if m.UpdateUserRoleFunc == nil {
+		return nil, nil
+	}
+	return m.UpdateUserRoleFunc(actorID, userID, role)
+}
+
+func (m *MockAuthService) UpdateUserPermissions(actorID, userID string, overrides auth.PermissionOverrides) (*service.AdminUserUpdate, error) {
+	if m.UpdateUserPermissionsFunc == nil {
+		return nil, nil
+	}
+	return m.UpdateUserPermissionsFunc(actorID, userID, overrides)
+}
+
+func (m *MockAuthService) ImpersonateUser(adminID, targetUserID, reason, ip, userAgent string) (*service.LoginResponse, error) {
+	if m.ImpersonateUserFunc == nil {
+		return nil, nil
+	}
+	return m.ImpersonateUserFunc(adminID, targetUserID, reason, ip, userAgent)
+}
+
+func (m *MockAuthService) StopImpersonation(sessionID, ip, userAgent string) error {
+	if m.StopImpersonationFunc == nil {
+		return nil
+	}
+	return m.StopImpersonationFunc(sessionID, ip, userAgent)
+}
+
+func (m *MockAuthService) GetImpersonation(session *auth.Session) (*service.ImpersonationInfo, error) {
+	if m.GetImpersonationFunc == nil {
+		return nil, nil
+	}
+	return m.GetImpersonationFunc(session)
+}
+
+func (m *MockAuthService) ListOrganizations(userID string) ([]service.OrganizationInfo, error) {
+	if m.ListOrganizationsFunc == nil {
+		return []service.OrganizationInfo{}, nil
+	}
+	return m.ListOrganizationsFunc(userID)
+}
+
+func (m *MockAuthService) CreateOrganization(userID, name string) (*service.OrganizationInfo, error) {
+	if m.CreateOrganizationFunc == nil {
+		return nil, nil
+	}
+	return m.CreateOrganizationFunc(userID, name)
+}
+
+func (m *MockAuthService) GetOrganization(orgID, userID string) (*service.OrganizationInfo, error) {
+	if m.GetOrganizationFunc == nil {
+		return nil, nil
+	}
+	return m.GetOrganizationFunc(orgID, userID)
+}
+
+func (m *MockAuthService) DeleteOrganization(orgID, actorID string) error {
+	if m.DeleteOrganizationFunc == nil {
+		return nil
+	}
+	return m.DeleteOrganizationFunc(orgID, actorID)
+}
+
+func (m *MockAuthService) ListOrganizationMembers(orgID string) ([]service.OrganizationMemberInfo, error) {
+	if m.ListOrgMembersFunc == nil {
+		return []service.OrganizationMemberInfo{}, nil
+	}
+	return m.ListOrgMembersFunc(orgID)
+}
+
+func (m *MockAuthService) UpdateOrganizationMemberRole(orgID, actorID, userID, role string) (*service.OrganizationMemberInfo, error) {
+	if m.UpdateOrgMemberRoleFunc == nil {
+		return nil, nil
+	}
+	return m.UpdateOrgMemberRoleFunc(orgID, actorID, userID, role)
+}
+
+func (m *MockAuthService) RemoveOrganizationMember(orgID, actorID, userID string) error {
+	if m.RemoveOrgMemberFunc == nil {
+		return nil
+	}
+	return m.RemoveOrgMemberFunc(orgID, actorID, userID)
+}
+
+func (m *MockAuthService) SwitchOrganization(sessionID, userID, orgID string) (*service.OrganizationInfo, error) {
+	if m.SwitchOrganizationFunc == nil {
+		return nil, nil
+	}
+	return m.SwitchOrganizationFunc(sessionID, userID, orgID)
+}
+
+func (m *MockAuthService) ListAdminOrganizations(input service.ListAdminOrganizationsInput) (*pagination.Response[service.AdminOrganizationRow], error) {
+	if m.ListAdminOrgsFunc == nil {
+		return nil, nil
+	}
+	return m.ListAdminOrgsFunc(input)
+}
+
+func (m *MockAuthService) ListAuditEvents(input service.ListAuditEventsInput) (*pagination.Response[service.AuditEventRow], error) {
+	if m.ListAuditEventsFunc == nil {
+		return nil, nil
+	}
+	return m.ListAuditEventsFunc(input)
+}
+
+func (m *MockAuthService) ListSecurityActivity(userID string, cursor *pagination.CursorQuery) (*pagination.Response[service.AuditEventRow], error) {
+	if m.ListSecurityActivityFunc == nil {
+		return nil, nil
+	}
+	return m.ListSecurityActivityFunc(userID, cursor)
+}
+
+func (m *MockAuthService) InviteOrganizationMember(orgID, actorID string, input service.InviteOrganizationMemberInput) (*service.InvitationInfo, error) {
+	if m.InviteOrgMemberFunc == nil {
+		return nil, nil
+	}
+	return m.InviteOrgMemberFunc(orgID, actorID, input)
+}
+
+func (m *MockAuthService) ListOrganizationInvitations(orgID string) ([]service.InvitationInfo, error) {
+	if m.ListOrgInvitationsFunc == nil {
+		return nil, nil
+	}
+	return m.ListOrgInvitationsFunc(orgID)
+}
+
+func (m *MockAuthService) ResendOrganizationInvitation(orgID, actorID, invitationID string) (*service.InvitationInfo, error) {
+	if m.ResendOrgInvitationFunc == nil {
+		return nil, nil
+	}
+	return m.ResendOrgInvitationFunc(orgID, actorID, invitationID)
+}
+
+func (m *MockAuthService) RevokeOrganizationInvitation(orgID, actorID, invitationID string) error {
+	if m.RevokeOrgInvitationFunc == nil {
+		return nil
+	}
+	return m.RevokeOrgInvitationFunc(orgID, actorID, invitationID)
+}
+
+func (m *MockAuthService) GetInvitation(token string) (*service.InvitationPreview, error) {
+	if m.GetInvitationFunc == nil {
+		return nil, nil
+	}
+	return m.GetInvitationFunc(token)
+}
+
+func (m *MockAuthService) AcceptInvitation(token, userID string) (*service.OrganizationInfo, error) {
+	if m.AcceptInvitationFunc == nil {
+		return nil, nil
+	}
+	return m.AcceptInvitationFunc(token, userID)
+}
+
+func (m *MockAuthService) VerifyTwoFactor(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error) {
+	if m.VerifyTwoFactorFunc == nil {
+		return nil, nil
+	}
+	return m.VerifyTwoFactorFunc(challengeToken, code, ip, userAgent)
+}
+
+func (m *MockAuthService) GetTwoFactorStatus(userID string) (*service.TwoFactorStatus, error) {
+	if m.GetTwoFactorStatusFunc == nil {
+		return nil, nil
+	}
+	return m.GetTwoFactorStatusFunc(userID)
+}
+
+func (m *MockAuthService) SetupTOTP(userID string) (*service.TOTPSetup, error) {
+	if m.SetupTOTPFunc == nil {
+		return nil, nil
+	}
+	return m.SetupTOTPFunc(userID)
+}
+
+func (m *MockAuthService) ConfirmTOTP(userID, code string) ([]string, error) {
+	if m.ConfirmTOTPFunc == nil {
+		return nil, nil
+	}
+	return m.ConfirmTOTPFunc(userID, code)
+}
+
+func (m *MockAuthService) DisableTOTP(userID, password string) error {
+	if m.DisableTOTPFunc == nil {
+		return nil
+	}
+	return m.DisableTOTPFunc(userID, password)
+}
+
+func (m *MockAuthService) RegenerateRecoveryCodes(userID, password string) ([]string, error) {
+	if m.RegenerateCodesFunc == nil {
+		return nil, nil
+	}
+	return m.RegenerateCodesFunc(userID, password)
+}
+
+func (m *MockAuthService) BeginPasskeyLogin() (*service.PasskeyOptions, error) {
+	if m.BeginPasskeyLoginFunc == nil {
+		return nil, nil
+	}
+	return m.BeginPasskeyLoginFunc()
+}
+
+func (m *MockAuthService) FinishPasskeyLogin(
+	ceremonyToken string,
+	credential []byte,
+	ip, userAgent string,
+) (*service.LoginResponse, error) {
+	if m.FinishPasskeyLoginFunc == nil {
+		return nil, nil
+	}
+	return m.FinishPasskeyLoginFunc(ceremonyToken, credential, ip, userAgent)
+}
+
+func (m *MockAuthService) ListPasskeys(userID string) ([]service.PasskeyInfo, error) {
+	if m.ListPasskeysFunc == nil {
+		return nil, nil
+	}
+	return m.ListPasskeysFunc(userID)
+}
+
+func (m *MockAuthService) BeginPasskeyRegistration(userID string) (*service.PasskeyOptions, error) {
+	if m.BeginPasskeyRegFunc == nil {
+		return nil, nil
+	}
+	return m.BeginPasskeyRegFunc(userID)
+}
+
+func (m *MockAuthService) FinishPasskeyRegistration(
+	userID, ceremonyToken, name string,
+	credential []byte,
+) (*service.PasskeyInfo, error) {
+	if m.FinishPasskeyRegFunc == nil {
+		return nil, nil
+	}
+	return m.FinishPasskeyRegFunc(userID, ceremonyToken, name, credential)
+}
+
+func (m *MockAuthService) DeletePasskey(userID, passkeyID string) error {
+	if m.DeletePasskeyFunc == nil {
+		return nil
+	}
+	return m.DeletePasskeyFunc(userID, passkeyID)
+}
+
+func (m *MockAuthService) ListAPITokens(userID string) ([]service.APITokenInfo, error) {
+	if m.ListAPITokensFunc == nil {
+		return nil, nil
+	}
+	return m.ListAPITokensFunc(userID)
+}
+
+func (m *MockAuthService) CreateAPIToken(userID string, input service.CreateAPITokenInput) (*service.CreatedAPIToken, error) {
+	if m.CreateAPITokenFunc == nil {
+		return &service.CreatedAPIToken{}, nil
+	}
+	return m.CreateAPITokenFunc(userID, input)
+}
+
+func (m *MockAuthService) RevokeAPIToken(userID, tokenID string) error {
+	if m.RevokeAPITokenFunc == nil {
+		return nil
+	}
+	return m.RevokeAPITokenFunc(userID, tokenID)
+}
+
+func (m *MockAuthService) IssueAccessToken(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+	if m.IssueAccessTokenFunc == nil {
+		return &service.AccessTokenResponse{}, nil
+	}
+	return m.IssueAccessTokenFunc(userID, sessionPublicID, scopes)
+}
+
+func (m *MockAuthService) AccessTokenJWKS() (*jose.JSONWebKeySet, error) {
+	if m.AccessTokenJWKSFunc == nil {
+		return &jose.JSONWebKeySet{}, nil
+	}
+	return m.AccessTokenJWKSFunc()
+}
+
+func (m *MockAuthService) ListOAuthClients() ([]service.OAuthClientInfo, error) {
+	if m.ListOAuthClientsFunc == nil {
+		return nil, nil
+	}
+	return m.ListOAuthClientsFunc()
+}
+
+func (m *MockAuthService) CreateOAuthClient(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error) {
+	if m.CreateOAuthClientFunc == nil {
+		return &service.CreatedOAuthClient{}, nil
+	}
+	return m.CreateOAuthClientFunc(actorID, input)
+}
+
+func (m *MockAuthService) DeleteOAuthClient(clientID string) error {
+	if m.DeleteOAuthClientFunc == nil {
+		return nil
+	}
+	return m.DeleteOAuthClientFunc(clientID)
+}
+
+func (m *MockAuthService) IssueClientCredentialsToken(
+	clientID, clientSecret string,
+	scopes []string,
+) (*service.AccessTokenResponse, error) {
+	if m.IssueClientTokenFunc == nil {
+		return &service.AccessTokenResponse{}, nil
+	}
+	return m.IssueClientTokenFunc(clientID, clientSecret, scopes)
+}
+
+func (m *MockAuthService) ListOAuthProviders() []string {
+	if m.ListOAuthProvidersFunc == nil {
+		return nil
+	}
+	return m.ListOAuthProvidersFunc()
+}
+
+func (m *MockAuthService) BeginOAuthLogin(provider string) (*service.OAuthRedirect, error) {
+	if m.BeginOAuthLoginFunc == nil {
+		return nil, nil
+	}
+	return m.BeginOAuthLoginFunc(provider)
+}
+
+func (m *MockAuthService) CompleteOAuthLogin(
+	ctx context.Context,
+	provider, state, code, ip, userAgent string,
+) (*service.LoginResponse, error) {
+	if m.CompleteOAuthLoginFunc == nil {
+		return nil, nil
+	}
+	return m.CompleteOAuthLoginFunc(ctx, provider, state, code, ip, userAgent)
+}
+
+func (m *MockAuthService) ListIdentities(userID string) ([]service.IdentityInfo, error) {
+	if m.ListIdentitiesFunc == nil {
+		return nil, nil
+	}
+	return m.ListIdentitiesFunc(userID)
+}
+
+func (m *MockAuthService) RequestMagicLink(email string) error {
+	if m.RequestMagicLinkFunc == nil {
+		return nil
+	}
+	return m.RequestMagicLinkFunc(email)
+}
+
+func (m *MockAuthService) LoginWithMagicLink(token, ip, userAgent string) (*service.LoginResponse, error) {
+	if m.LoginWithMagicLinkFunc == nil {
+		return nil, nil
+	}
+	return m.LoginWithMagicLinkFunc(token, ip, userAgent)
+}
+
+func (m *MockAuthService) RevokeSessionByLink(token string) error {
+	if m.RevokeSessionByLinkFunc == nil {
+		return nil
+	}
+	return m.RevokeSessionByLinkFunc(token)
+}
+
+func (m *MockAuthService) VerifyEmail(token string) error {
+	if m.VerifyEmailFunc == nil {
+		return nil
+	}
+	return m.VerifyEmailFunc(token)
+}
+
+func (m *MockAuthService) ResendVerificationEmail(email string) error {
+	if m.ResendVerificationEmailFunc == nil {
+		return nil
+	}
+	return m.ResendVerificationEmailFunc(email)
+}
+
 func setupTestRouter() (*gin.Context, *httptest.ResponseRecorder) {
 	gin.SetMode(gin.TestMode)
 	w := httptest.NewRecorder()
@@ -227,7 +682,7 @@ func TestAuthHandler_Login(t *testing.T) {
 			},
 			setupMock: func(m *MockAuthService) {
 				m.LoginFunc = func(username, password, ip, userAgent string) (*service.LoginResponse, error) {
-					return nil, errors.New("conta temporariamente bloqueada, tente novamente mais tarde")
+					return nil, service.ErrAccountLocked
 				}
 			},
 			expectedStatus: http.StatusUnauthorized,
@@ -235,6 +690,43 @@ func TestAuthHandler_Login(t *testing.T) {
 				"error": "conta temporariamente bloqueada, tente novamente mais tarde",
 			},
 		},
+		{
+			name: "Email not verified",
+			request: LoginRequest{
+				Username:   "unverified",
+				Passphrase: "password123",
+			},
+			setupMock: func(m *MockAuthService) {
+				m.LoginFunc = func(username, password, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrEmailNotVerified
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedBody: map[string]any{
+				"email_not_verified": true,
+			},
+		},
+		{
+			name: "Two-factor required",
+			request: LoginRequest{
+				Username:   "twofactor",
+				Passphrase: "password123",
+			},
+			setupMock: func(m *MockAuthService) {
+				m.LoginFunc = func(username, password, ip, userAgent string) (*service.LoginResponse, error) {
+					return &service.LoginResponse{
+						ExpiresAt:         time.Now().Add(5 * time.Minute),
+						TwoFactorRequired: true,
+						ChallengeToken:    "challenge-token",
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: map[string]any{
+				"two_factor_required": true,
+				"challenge_token":     "challenge-token",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -278,6 +770,96 @@ func TestAuthHandler_Login(t *testing.T) {
 	}
 }
 
+func TestAuthHandler_Login_EndsPreviousSession(t *testing.T) {
+	c, w := setupTestRouter()
+	var loggedOut []string
+	mockService := &MockAuthService{
+		LoginFunc: func(username, password, ip, userAgent string) (*service.LoginResponse, error) {
+			return &service.LoginResponse{SessionID: "new-session", ExpiresAt: time.Now().Add(time.Hour)}, nil
+		},
+		LogoutFunc: func(sessionID string) error {
+			loggedOut = append(loggedOut, sessionID)
+			return nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	jsonData, _ := json.Marshal(LoginRequest{Username: "testuser", Passphrase: "password123"})
+	req, _ := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Cookie", "session_id=old-session")
+	c.Request = req
+
+	handler.Login(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if len(loggedOut) != 1 || loggedOut[0] != "old-session" {
+		t.Errorf("expected the previous session to be logged out, got %v", loggedOut)
+	}
+	if cookie := w.Header().Get("Set-Cookie"); !strings.Contains(cookie, "session_id=new-session") {
+		t.Errorf("expected new session cookie, got %q", cookie)
+	}
+}
+
+func TestAuthHandler_UpdateAdminUserRole(t *testing.T) {
+	tests := []struct {
+		name            string
+		clientPrincipal bool
+		err             error
+		expectedStatus  int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "invalid role", err: service.ErrInvalidRole, expectedStatus: http.StatusBadRequest},
+		{name: "unknown user", err: service.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+		{name: "permission not held", err: service.ErrPermissionNotHeld, expectedStatus: http.StatusForbidden},
+		{name: "own role", err: service.ErrCannotChangeOwnRole, expectedStatus: http.StatusForbidden},
+		{name: "client principal", clientPrincipal: true, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				UpdateUserRoleFunc: func(actorID, userID, role string) (*service.AdminUserUpdate, error) {
+					if actorID != "1" {
+						t.Fatalf("expected the signed-in actor, got %q", actorID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.AdminUserUpdate{
+						AdminUserRow: service.AdminUserRow{ID: userID, Role: role},
+						Previous:     service.AdminUserRow{ID: userID, Role: "user"},
+					}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			if !tt.clientPrincipal {
+				c.Set("userID", "1")
+			}
+			c.Params = gin.Params{{Key: "user_id", Value: "2"}}
+			req, _ := http.NewRequest(http.MethodPatch, "/api/admin/users/2/role", strings.NewReader(`{"role":"admin"}`))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.UpdateAdminUserRole(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if w.Code == http.StatusOK {
+				details := c.GetStringMapString("auditDetails")
+				if details["previous_role"] != "user" || details["role"] != "admin" {
+					t.Errorf("expected the old and new role in the audit details, got %v", details)
+				}
+			}
+		})
+	}
+}
+
 func TestAuthHandler_Logout(t *testing.T) {
 	tests := []struct {
 		name           string
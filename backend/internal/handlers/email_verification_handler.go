@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+	"gosveltekit/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmailRequest carries the token from an emailed verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ResendVerificationRequest asks for a new verification link
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// VerifyEmail confirms the user's email address with an emailed token.
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.VerifyEmail(req.Token); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "link inválido ou já utilizado"})
+		case errors.Is(err, service.ErrExpiredToken):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "link expirado"})
+		case errors.Is(err, service.ErrEmailVerificationUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao verificar email"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "email verificado com sucesso"})
+}
+
+// ResendVerificationEmail sends a new verification link if the address
+// belongs to an unverified account.
+func (h *AuthHandler) ResendVerificationEmail(c *gin.Context) {
+	var req ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validation.ValidateEmail(req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.ResendVerificationEmail(req.Email); errors.Is(err, service.ErrEmailVerificationUnavailable) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Don't reveal if email exists for security reasons
+	c.JSON(http.StatusOK, gin.H{"message": "se o email precisar de confirmação, um novo link será enviado"})
+}
+
+// writeEmailNotVerified answers a login refused until the email is confirmed,
+// flagged so the frontend can offer to resend the link
+func writeEmailNotVerified(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":              service.ErrEmailNotVerified.Error(),
+		"email_not_verified": true,
+	})
+}
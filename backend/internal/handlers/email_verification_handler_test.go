@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_VerifyEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: map[string]any{"token": "verify-token"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyEmailFunc = func(token string) error { return nil }
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "used token",
+			body: map[string]any{"token": "verify-token"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyEmailFunc = func(token string) error { return service.ErrInvalidToken }
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "expired token",
+			body: map[string]any{"token": "verify-token"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyEmailFunc = func(token string) error { return service.ErrExpiredToken }
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "missing token",
+			body:           map[string]any{},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/verify-email", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.VerifyEmail(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ResendVerificationEmail(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.ResendVerificationEmailFunc = func(email string) error { return nil }
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "service error is not revealed",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.ResendVerificationEmailFunc = func(email string) error { return errors.New("db down") }
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not configured",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.ResendVerificationEmailFunc = func(email string) error {
+					return service.ErrEmailVerificationUnavailable
+				}
+			},
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name:           "invalid email",
+			body:           map[string]any{"email": "not-an-email"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/verify-email/resend", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.ResendVerificationEmail(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
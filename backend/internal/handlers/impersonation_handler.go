@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"gosveltekit/internal/middleware"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ImpersonateUserRequest is the body of an admin starting to impersonate a user
+type ImpersonateUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ImpersonateUser opens a session as another user for the admin. The admin's
+// own session is kept in a separate cookie and comes back when they stop.
+func (h *AuthHandler) ImpersonateUser(c *gin.Context) {
+	adminID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+	adminSessionID, _ := getContextString(c, "sessionID")
+
+	var req ImpersonateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrImpersonationReasonRequired.Error()})
+		return
+	}
+
+	response, err := h.authService.ImpersonateUser(adminID, c.Param("user_id"), req.Reason, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		writeImpersonationError(c, err, "falha ao iniciar personificação")
+		return
+	}
+
+	if adminSessionID != "" {
+		middleware.SetImpersonatorCookie(c, adminSessionID, response.ExpiresAt, h.cookieSecure)
+	}
+	middleware.SetSessionCookie(c, response.SessionID, response.ExpiresAt, h.cookieSecure)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// StopImpersonation ends the impersonation session and, for browsers, puts
+// the admin's own session back in place.
+func (h *AuthHandler) StopImpersonation(c *gin.Context) {
+	sessionID, ok := getContextString(c, "sessionID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	if err := h.authService.StopImpersonation(sessionID, c.ClientIP(), c.Request.UserAgent()); err != nil {
+		writeImpersonationError(c, err, "falha ao encerrar personificação")
+		return
+	}
+
+	restored := false
+	if adminSessionID, err := c.Cookie(middleware.ImpersonatorCookieName); err == nil && adminSessionID != "" {
+		// Expiry is unknown here; the next request refreshes the cookie
+		middleware.SetSessionCookie(c, adminSessionID, time.Time{}, h.cookieSecure)
+		middleware.ClearImpersonatorCookie(c, h.cookieSecure)
+		restored = true
+	} else {
+		middleware.ClearSessionCookie(c, h.cookieSecure)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "personificação encerrada", "restored": restored})
+}
+
+func writeImpersonationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrImpersonationReasonRequired),
+		errors.Is(err, service.ErrNotImpersonating):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrImpersonationNotAllowed),
+		errors.Is(err, service.ErrUserNotActive):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/middleware"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_ImpersonateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", body: `{"reason":"ticket 42"}`, expectedStatus: http.StatusOK},
+		{name: "missing reason", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "admin target", body: `{"reason":"x"}`, err: service.ErrImpersonationNotAllowed, expectedStatus: http.StatusForbidden},
+		{name: "unknown user", body: `{"reason":"x"}`, err: service.ErrUserNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				ImpersonateUserFunc: func(adminID, targetUserID, reason, ip, userAgent string) (*service.LoginResponse, error) {
+					if adminID != "1" || targetUserID != "2" {
+						t.Errorf("unexpected arguments %q %q", adminID, targetUserID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.LoginResponse{
+						SessionID: "impersonation-token",
+						ExpiresAt: time.Now().Add(time.Hour),
+						User:      auth.UserData{ID: targetUserID},
+					}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService, false)
+
+			c.Set("userID", "1")
+			c.Set("sessionID", "admin-token")
+			c.Params = gin.Params{{Key: "user_id", Value: "2"}}
+			req, _ := http.NewRequest(http.MethodPost, "/api/admin/users/2/impersonate", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.ImpersonateUser(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+
+			cookies := map[string]string{}
+			for _, cookie := range w.Result().Cookies() {
+				cookies[cookie.Name] = cookie.Value
+			}
+			if cookies[middleware.SessionCookieName] != "impersonation-token" {
+				t.Fatalf("expected session cookie for the impersonation, got %v", cookies)
+			}
+			if cookies[middleware.ImpersonatorCookieName] != "admin-token" {
+				t.Fatalf("expected the admin session to be kept, got %v", cookies)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_StopImpersonation(t *testing.T) {
+	t.Run("restores admin session", func(t *testing.T) {
+		c, w := setupTestRouter()
+		mockService := &MockAuthService{
+			StopImpersonationFunc: func(sessionID, ip, userAgent string) error {
+				if sessionID != "impersonation-token" {
+					t.Errorf("unexpected session %q", sessionID)
+				}
+				return nil
+			},
+		}
+		handler := NewAuthHandler(mockService, false)
+
+		c.Set("sessionID", "impersonation-token")
+		req, _ := http.NewRequest(http.MethodPost, "/api/impersonation/stop", nil)
+		req.AddCookie(&http.Cookie{Name: middleware.ImpersonatorCookieName, Value: "admin-token"})
+		c.Request = req
+
+		handler.StopImpersonation(c)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		cookies := map[string]*http.Cookie{}
+		for _, cookie := range w.Result().Cookies() {
+			cookies[cookie.Name] = cookie
+		}
+		if cookies[middleware.SessionCookieName].Value != "admin-token" {
+			t.Fatalf("expected the admin session cookie back, got %v", cookies)
+		}
+		if cookies[middleware.ImpersonatorCookieName].MaxAge >= 0 {
+			t.Fatalf("expected the impersonator cookie to be cleared")
+		}
+	})
+
+	t.Run("not impersonating", func(t *testing.T) {
+		c, w := setupTestRouter()
+		mockService := &MockAuthService{
+			StopImpersonationFunc: func(sessionID, ip, userAgent string) error {
+				return service.ErrNotImpersonating
+			},
+		}
+		handler := NewAuthHandler(mockService, false)
+
+		c.Set("sessionID", "session-token")
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/impersonation/stop", nil)
+
+		handler.StopImpersonation(c)
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestAuthHandler_GetCurrentUserWhileImpersonating(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		GetImpersonationFunc: func(session *auth.Session) (*service.ImpersonationInfo, error) {
+			return &service.ImpersonationInfo{ImpersonatorID: session.ImpersonatorID, Reason: "ticket 42"}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Set("user", &auth.UserData{ID: "2", Identifier: "alice"})
+	c.Set("session", &auth.Session{ImpersonatorID: "1"})
+	c.Request, _ = http.NewRequest(http.MethodGet, "/api/me", nil)
+
+	handler.GetCurrentUser(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var response struct {
+		ID            string `json:"id"`
+		Identifier    string `json:"identifier"`
+		Impersonation *struct {
+			ImpersonatorID string `json:"impersonator_id"`
+			Reason         string `json:"reason"`
+		} `json:"impersonation"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal(err)
+	}
+	if response.ID != "2" || response.Identifier != "alice" {
+		t.Fatalf("unexpected user %+v", response)
+	}
+	if response.Impersonation == nil || response.Impersonation.ImpersonatorID != "1" || response.Impersonation.Reason != "ticket 42" {
+		t.Fatalf("unexpected impersonation %+v", response.Impersonation)
+	}
+}
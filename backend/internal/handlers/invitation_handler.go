@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InviteOrganizationMemberRequest names the email to invite. Without a role
+// the invitee joins as a member.
+type InviteOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role"`
+}
+
+// InvitationTokenRequest carries the token from an emailed invitation link
+type InvitationTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ListOrganizationInvitations returns the organization's pending invitations.
+func (h *AuthHandler) ListOrganizationInvitations(c *gin.Context) {
+	orgID, _, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	invitations, err := h.authService.ListOrganizationInvitations(orgID)
+	if err != nil {
+		writeInvitationError(c, err, "falha ao listar convites")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invitations": invitations})
+}
+
+// InviteOrganizationMember emails an invitation to join the organization.
+func (h *AuthHandler) InviteOrganizationMember(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	var req InviteOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.authService.InviteOrganizationMember(orgID, userID, service.InviteOrganizationMemberInput{
+		Email: req.Email,
+		Role:  req.Role,
+	})
+	if err != nil {
+		writeInvitationError(c, err, "falha ao enviar convite")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+// ResendOrganizationInvitation emails a pending invitation again.
+func (h *AuthHandler) ResendOrganizationInvitation(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	invitation, err := h.authService.ResendOrganizationInvitation(orgID, userID, c.Param("invitation_id"))
+	if err != nil {
+		writeInvitationError(c, err, "falha ao reenviar convite")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// RevokeOrganizationInvitation cancels a pending invitation.
+func (h *AuthHandler) RevokeOrganizationInvitation(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeOrganizationInvitation(orgID, userID, c.Param("invitation_id")); err != nil {
+		writeInvitationError(c, err, "falha ao cancelar convite")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "convite cancelado"})
+}
+
+// GetInvitation describes an invitation to whoever holds its link, before
+// they sign up or sign in to accept it.
+func (h *AuthHandler) GetInvitation(c *gin.Context) {
+	var req InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	invitation, err := h.authService.GetInvitation(req.Token)
+	if err != nil {
+		writeInvitationError(c, err, "falha ao obter convite")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitation)
+}
+
+// AcceptInvitation adds the signed-in user to the organization they were
+// invited to.
+func (h *AuthHandler) AcceptInvitation(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req InvitationTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.authService.AcceptInvitation(req.Token, userID)
+	if err != nil {
+		writeInvitationError(c, err, "falha ao aceitar convite")
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+func writeInvitationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInvitationEmail):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationInvalid),
+		errors.Is(err, service.ErrInvitationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationExpired):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationEmailMismatch),
+		errors.Is(err, service.ErrInvitationRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationAccountExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvitationsUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		writeOrganizationError(c, err, fallback)
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_InviteOrganizationMember(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", body: `{"email":"new@example.com","role":"admin"}`, expectedStatus: http.StatusCreated},
+		{name: "missing email", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid email", body: `{"email":"x"}`, err: service.ErrInvalidInvitationEmail, expectedStatus: http.StatusBadRequest},
+		{name: "owner only", body: `{"email":"new@example.com","role":"owner"}`, err: service.ErrInsufficientOrganizationRole, expectedStatus: http.StatusForbidden},
+		{name: "already member", body: `{"email":"bob@example.com"}`, err: service.ErrAlreadyOrganizationMember, expectedStatus: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				InviteOrgMemberFunc: func(orgID, actorID string, input service.InviteOrganizationMemberInput) (*service.InvitationInfo, error) {
+					if orgID != "7" || actorID != "1" {
+						t.Errorf("unexpected arguments %q %q", orgID, actorID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.InvitationInfo{ID: "3", Email: input.Email, Role: input.Role}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService, false)
+
+			c.Set("userID", "1")
+			c.Set("organizationID", "7")
+			req, _ := http.NewRequest(http.MethodPost, "/api/orgs/7/invitations", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.InviteOrganizationMember(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RevokeOrganizationInvitation(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		RevokeOrgInvitationFunc: func(orgID, actorID, invitationID string) error {
+			if orgID != "7" || actorID != "1" || invitationID != "3" {
+				t.Errorf("unexpected arguments %q %q %q", orgID, actorID, invitationID)
+			}
+			return service.ErrInvitationNotFound
+		},
+	}
+	handler := NewAuthHandler(mockService, false)
+
+	c.Set("userID", "1")
+	c.Set("organizationID", "7")
+	c.Params = gin.Params{{Key: "invitation_id", Value: "3"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/orgs/7/invitations/3", nil)
+
+	handler.RevokeOrganizationInvitation(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandler_AcceptInvitation(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", expectedStatus: http.StatusOK},
+		{name: "used", err: service.ErrInvitationInvalid, expectedStatus: http.StatusNotFound},
+		{name: "expired", err: service.ErrInvitationExpired, expectedStatus: http.StatusGone},
+		{name: "other email", err: service.ErrInvitationEmailMismatch, expectedStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				AcceptInvitationFunc: func(token, userID string) (*service.OrganizationInfo, error) {
+					if token != "invite-token" || userID != "1" {
+						t.Errorf("unexpected arguments %q %q", token, userID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.OrganizationInfo{ID: "7", Name: "Acme", Role: "member"}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService, false)
+
+			c.Set("userID", "1")
+			req, _ := http.NewRequest(http.MethodPost, "/api/invitations/accept", strings.NewReader(`{"token":"invite-token"}`))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.AcceptInvitation(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RegisterWithInvitation(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		RegisterFunc: func(username, email, password, displayName string) (*models.User, error) {
+			t.Error("registration with an invitation must not go through Register")
+			return nil, nil
+		},
+		RegisterWithInvitationFunc: func(token, username, password, displayName string) (*models.User, error) {
+			if token != "invite-token" || username != "newuser" {
+				t.Errorf("unexpected arguments %q %q", token, username)
+			}
+			return &models.User{Username: username, Email: "invited@example.com"}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService, false)
+
+	body := `{"username":"newuser","password":"Padasdasdasdd123!","display_name":"New User","invitation_token":"invite-token"}`
+	req, _ := http.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+
+	handler.Register(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandler_RegisterInviteOnly(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		RegisterFunc: func(username, email, password, displayName string) (*models.User, error) {
+			return nil, service.ErrInvitationRequired
+		},
+	}
+	handler := NewAuthHandler(mockService, false)
+
+	body := `{"username":"newuser","email":"new@example.com","password":"Padasdasdasdd123!","display_name":"New User"}`
+	req, _ := http.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+
+	handler.Register(c)
+
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+	"gosveltekit/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MagicLinkRequest represents a request for a passwordless login link
+type MagicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ConsumeMagicLinkRequest carries the token from an emailed login link
+type ConsumeMagicLinkRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RequestMagicLink emails a login link if the address belongs to an account.
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req MagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validation.ValidateEmail(req.Email); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.RequestMagicLink(req.Email); errors.Is(err, service.ErrMagicLinkUnavailable) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Don't reveal if email exists for security reasons
+	c.JSON(http.StatusOK, gin.H{"message": "se o email existir, um link de acesso será enviado"})
+}
+
+// ConsumeMagicLink redeems a login link and starts a session.
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	var req ConsumeMagicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.LoginWithMagicLink(req.Token, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "link inválido ou já utilizado"})
+		case errors.Is(err, service.ErrExpiredToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "link expirado"})
+		case errors.Is(err, service.ErrUserNotActive),
+			errors.Is(err, service.ErrAccountLocked):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrEmailNotVerified):
+			writeEmailNotVerified(c)
+		case errors.Is(err, service.ErrMagicLinkUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao entrar com o link"})
+		}
+		return
+	}
+
+	h.respondWithLogin(c, response)
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_RequestMagicLink(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.RequestMagicLinkFunc = func(email string) error { return nil }
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "service error is not revealed",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.RequestMagicLinkFunc = func(email string) error { return errors.New("smtp down") }
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "not configured",
+			body: map[string]any{"email": "test@example.com"},
+			setupMock: func(m *MockAuthService) {
+				m.RequestMagicLinkFunc = func(email string) error { return service.ErrMagicLinkUnavailable }
+			},
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name:           "invalid email",
+			body:           map[string]any{"email": "not-an-email"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/magic-link", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.RequestMagicLink(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ConsumeMagicLink(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+		expectCookie   bool
+	}{
+		{
+			name: "success",
+			body: map[string]any{"token": "link-token"},
+			setupMock: func(m *MockAuthService) {
+				m.LoginWithMagicLinkFunc = func(token, ip, userAgent string) (*service.LoginResponse, error) {
+					return &service.LoginResponse{
+						SessionID: "session-id",
+						ExpiresAt: time.Now().Add(time.Hour),
+						User:      auth.UserData{ID: "1", Identifier: "testuser"},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectCookie:   true,
+		},
+		{
+			name: "second factor required",
+			body: map[string]any{"token": "link-token"},
+			setupMock: func(m *MockAuthService) {
+				m.LoginWithMagicLinkFunc = func(token, ip, userAgent string) (*service.LoginResponse, error) {
+					return &service.LoginResponse{
+						TwoFactorRequired: true,
+						ChallengeToken:    "challenge",
+						ExpiresAt:         time.Now().Add(5 * time.Minute),
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "used link",
+			body: map[string]any{"token": "link-token"},
+			setupMock: func(m *MockAuthService) {
+				m.LoginWithMagicLinkFunc = func(token, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrInvalidToken
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "expired link",
+			body: map[string]any{"token": "link-token"},
+			setupMock: func(m *MockAuthService) {
+				m.LoginWithMagicLinkFunc = func(token, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrExpiredToken
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing token",
+			body:           map[string]any{},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/magic-link/consume", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.ConsumeMagicLink(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			hasCookie := strings.Contains(w.Header().Get("Set-Cookie"), "session_id=session-id")
+			if hasCookie != tt.expectCookie {
+				t.Fatalf("expected session cookie %v, got %v", tt.expectCookie, hasCookie)
+			}
+		})
+	}
+}
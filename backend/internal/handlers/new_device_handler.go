@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevokeSessionByLinkRequest carries the token from a new sign-in email
+type RevokeSessionByLinkRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RevokeSessionByLink ends the session a new sign-in email was about. It is
+// public, since the user may not be signed in where they read the email.
+func (h *AuthHandler) RevokeSessionByLink(c *gin.Context) {
+	var req RevokeSessionByLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.RevokeSessionByLink(req.Token); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "link inválido ou já utilizado"})
+		case errors.Is(err, service.ErrExpiredToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "link expirado"})
+		case errors.Is(err, service.ErrNewDeviceAlertsUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao revogar sessão"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "sessão revogada com sucesso"})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_RevokeSessionByLink(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", body: `{"token":"revoke-token"}`, expectedStatus: http.StatusOK},
+		{name: "missing token", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid link", body: `{"token":"revoke-token"}`, err: service.ErrInvalidToken, expectedStatus: http.StatusUnauthorized},
+		{name: "expired link", body: `{"token":"revoke-token"}`, err: service.ErrExpiredToken, expectedStatus: http.StatusUnauthorized},
+		{name: "not configured", body: `{"token":"revoke-token"}`, err: service.ErrNewDeviceAlertsUnavailable, expectedStatus: http.StatusNotImplemented},
+		{name: "service failure", body: `{"token":"revoke-token"}`, err: errors.New("boom"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, rec := setupTestRouter()
+			mockService := &MockAuthService{
+				RevokeSessionByLinkFunc: func(token string) error {
+					if token != "revoke-token" {
+						t.Fatalf("unexpected token %q", token)
+					}
+					return tt.err
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			req, _ := http.NewRequest(http.MethodPost, "/auth/revoke-session", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.RevokeSessionByLink(c)
+
+			if rec.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
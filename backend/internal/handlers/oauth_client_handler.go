@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOAuthClientRequest defines payload for registering a machine client.
+// Without a role the client acts as a regular user.
+type CreateOAuthClientRequest struct {
+	Name   string   `json:"name"   binding:"required,max=100"`
+	Role   string   `json:"role"`
+	Scopes []string `json:"scopes" binding:"required,min=1"`
+}
+
+// ListOAuthClients returns every registered machine client.
+func (h *AuthHandler) ListOAuthClients(c *gin.Context) {
+	clients, err := h.authService.ListOAuthClients()
+	if err != nil {
+		writeOAuthClientError(c, err, "falha ao listar clientes OAuth")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clients": clients})
+}
+
+// CreateOAuthClient registers a machine client. The response is the only
+// time the client secret is shown.
+func (h *AuthHandler) CreateOAuthClient(c *gin.Context) {
+	actorID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "disponível apenas para usuários"})
+		return
+	}
+
+	var req CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, err := h.authService.CreateOAuthClient(actorID, service.CreateOAuthClientInput{
+		Name:   req.Name,
+		Role:   req.Role,
+		Scopes: req.Scopes,
+	})
+	if err != nil {
+		writeOAuthClientError(c, err, "falha ao criar cliente OAuth")
+		return
+	}
+
+	c.JSON(http.StatusCreated, client)
+}
+
+// DeleteOAuthClient removes a machine client and invalidates its tokens.
+func (h *AuthHandler) DeleteOAuthClient(c *gin.Context) {
+	if err := h.authService.DeleteOAuthClient(c.Param("client_id")); err != nil {
+		writeOAuthClientError(c, err, "falha ao remover cliente OAuth")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "cliente OAuth removido"})
+}
+
+// OAuthToken is the OAuth 2.0 token endpoint (RFC 6749). It supports the
+// client credentials grant only; clients authenticate with HTTP Basic or with
+// client_id and client_secret in the form body. Errors use the OAuth error
+// codes so that standard client libraries understand them.
+func (h *AuthHandler) OAuthToken(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	switch c.PostForm("grant_type") {
+	case "client_credentials":
+	case "":
+		writeOAuthTokenError(c, http.StatusBadRequest, "invalid_request", "grant_type é obrigatório")
+		return
+	default:
+		writeOAuthTokenError(c, http.StatusBadRequest, "unsupported_grant_type", "apenas client_credentials é suportado")
+		return
+	}
+
+	clientID, clientSecret, basic := clientCredentials(c)
+	if clientID == "" || clientSecret == "" {
+		writeOAuthTokenError(c, http.StatusUnauthorized, "invalid_client", service.ErrInvalidClientCredentials.Error())
+		return
+	}
+
+	token, err := h.authService.IssueClientCredentialsToken(clientID, clientSecret, strings.Fields(c.PostForm("scope")))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidClientCredentials):
+			if basic {
+				c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+			}
+			writeOAuthTokenError(c, http.StatusUnauthorized, "invalid_client", err.Error())
+		case errors.Is(err, service.ErrInvalidOAuthClientScope):
+			writeOAuthTokenError(c, http.StatusBadRequest, "invalid_scope", err.Error())
+		case errors.Is(err, service.ErrOAuthClientsUnavailable):
+			writeOAuthTokenError(c, http.StatusBadRequest, "unsupported_grant_type", err.Error())
+		default:
+			writeOAuthTokenError(c, http.StatusInternalServerError, "server_error", "falha ao emitir token")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// clientCredentials returns the client ID and secret from the Authorization
+// header, where RFC 6749 has them form-encoded, or else from the form body.
+// basic reports whether they came from the header.
+func clientCredentials(c *gin.Context) (clientID, clientSecret string, basic bool) {
+	if username, password, ok := c.Request.BasicAuth(); ok {
+		clientID, errID := url.QueryUnescape(username)
+		clientSecret, errSecret := url.QueryUnescape(password)
+		if errID != nil || errSecret != nil {
+			return "", "", true
+		}
+		return clientID, clientSecret, true
+	}
+	return c.PostForm("client_id"), c.PostForm("client_secret"), false
+}
+
+func writeOAuthTokenError(c *gin.Context, status int, code, description string) {
+	c.JSON(status, gin.H{"error": code, "error_description": description})
+}
+
+func writeOAuthClientError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOAuthClientName),
+		errors.Is(err, service.ErrInvalidOAuthClientScope),
+		errors.Is(err, service.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPermissionNotHeld):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOAuthClientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOAuthClientsUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
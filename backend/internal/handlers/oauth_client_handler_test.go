@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_CreateOAuthClient(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			body: map[string]any{"name": "export", "role": "admin", "scopes": []string{"read", "admin"}},
+			setupMock: func(m *MockAuthService) {
+				m.CreateOAuthClientFunc = func(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error) {
+					if actorID != "1" || input.Name != "export" || input.Role != "admin" || len(input.Scopes) != 2 {
+						t.Errorf("unexpected input %+v", input)
+					}
+					return &service.CreatedOAuthClient{
+						OAuthClientInfo: service.OAuthClientInfo{ClientID: "abc", Name: input.Name},
+						ClientSecret:    "secret",
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "invalid role",
+			body: map[string]any{"name": "export", "role": "root", "scopes": []string{"read"}},
+			setupMock: func(m *MockAuthService) {
+				m.CreateOAuthClientFunc = func(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error) {
+					return nil, service.ErrInvalidRole
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "permission not held",
+			body: map[string]any{"name": "export", "role": "admin", "scopes": []string{"read"}},
+			setupMock: func(m *MockAuthService) {
+				m.CreateOAuthClientFunc = func(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error) {
+					return nil, service.ErrPermissionNotHeld
+				}
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "missing scopes",
+			body:           map[string]any{"name": "export"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/api/admin/oauth-clients", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.CreateOAuthClient(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_DeleteOAuthClient_NotFound(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		DeleteOAuthClientFunc: func(clientID string) error {
+			return service.ErrOAuthClientNotFound
+		},
+	}
+	handler := NewAuthHandler(mockService)
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/admin/oauth-clients/abc", nil)
+
+	handler.DeleteOAuthClient(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", w.Code)
+	}
+}
+
+func TestAuthHandler_OAuthToken(t *testing.T) {
+	issue := func(clientID, clientSecret string, scopes []string) (*service.AccessTokenResponse, error) {
+		if clientID != "abc" || clientSecret != "s3cret/+" {
+			return nil, service.ErrInvalidClientCredentials
+		}
+		if slices.Contains(scopes, "admin") {
+			return nil, service.ErrInvalidOAuthClientScope
+		}
+		return &service.AccessTokenResponse{AccessToken: "gsc_token", TokenType: "Bearer", ExpiresIn: 3600}, nil
+	}
+
+	tests := []struct {
+		name           string
+		form           url.Values
+		basicAuth      []string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "basic auth",
+			form:           url.Values{"grant_type": {"client_credentials"}},
+			basicAuth:      []string{"abc", url.QueryEscape("s3cret/+")},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "form credentials",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"abc"}, "client_secret": {"s3cret/+"}, "scope": {"read"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "wrong secret",
+			form:           url.Values{"grant_type": {"client_credentials"}},
+			basicAuth:      []string{"abc", "wrong"},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid_client",
+		},
+		{
+			name:           "missing credentials",
+			form:           url.Values{"grant_type": {"client_credentials"}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid_client",
+		},
+		{
+			name:           "invalid scope",
+			form:           url.Values{"grant_type": {"client_credentials"}, "client_id": {"abc"}, "client_secret": {"s3cret/+"}, "scope": {"read admin"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_scope",
+		},
+		{
+			name:           "unsupported grant",
+			form:           url.Values{"grant_type": {"password"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "unsupported_grant_type",
+		},
+		{
+			name:           "missing grant",
+			form:           url.Values{},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			handler := NewAuthHandler(&MockAuthService{IssueClientTokenFunc: issue})
+
+			req, _ := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.basicAuth != nil {
+				req.SetBasicAuth(tt.basicAuth[0], tt.basicAuth[1])
+			}
+			c.Request = req
+
+			handler.OAuthToken(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Header().Get("Cache-Control") != "no-store" {
+				t.Fatalf("expected Cache-Control no-store, got %q", w.Header().Get("Cache-Control"))
+			}
+
+			var response map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if tt.expectedError != "" && response["error"] != tt.expectedError {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, response["error"])
+			}
+			if tt.expectedError == "" && response["access_token"] != "gsc_token" {
+				t.Fatalf("expected access token, got %v", response)
+			}
+		})
+	}
+}
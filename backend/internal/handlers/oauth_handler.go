@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthStateCookieName binds a social login callback to the browser that started it
+const oauthStateCookieName = "oauth_state"
+
+// ListOAuthProviders returns the social login providers that are enabled.
+func (h *AuthHandler) ListOAuthProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"providers": h.authService.ListOAuthProviders()})
+}
+
+// StartOAuthLogin redirects the browser to the provider's sign-in page.
+func (h *AuthHandler) StartOAuthLogin(c *gin.Context) {
+	redirect, err := h.authService.BeginOAuthLogin(c.Param("provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOAuthProviderNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrOAuthUnavailable):
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao iniciar login social"})
+		}
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(
+		oauthStateCookieName,
+		redirect.State,
+		int(time.Until(redirect.ExpiresAt).Seconds()),
+		"/auth/oauth",
+		"",
+		h.cookieSecure,
+		true,
+	)
+
+	c.Redirect(http.StatusFound, redirect.URL)
+}
+
+// OAuthCallback finishes a social login and sends the browser back to the
+// frontend, either signed in or with an oauth_error code in the query.
+//
+// When the account has a second factor the challenge token is passed in the
+// URL fragment, so it is not sent to servers or written to access logs.
+func (h *AuthHandler) OAuthCallback(c *gin.Context) {
+	state := c.Query("state")
+	stateCookie, _ := c.Cookie(oauthStateCookieName)
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oauthStateCookieName, "", -1, "/auth/oauth", "", h.cookieSecure, true)
+
+	if c.Query("error") != "" {
+		h.redirectOAuthError(c, "access_denied")
+		return
+	}
+	if state == "" || state != stateCookie {
+		h.redirectOAuthError(c, "invalid_state")
+		return
+	}
+
+	response, err := h.authService.CompleteOAuthLogin(
+		c.Request.Context(),
+		c.Param("provider"),
+		state,
+		c.Query("code"),
+		c.ClientIP(),
+		c.Request.UserAgent(),
+	)
+	if err != nil {
+		h.redirectOAuthError(c, oauthErrorCode(err))
+		return
+	}
+
+	if response.TwoFactorRequired {
+		fragment := url.Values{"two_factor_challenge": {response.ChallengeToken}}
+		c.Redirect(http.StatusFound, h.oauthFrontendURL+"#"+fragment.Encode())
+		return
+	}
+
+	h.startSession(c, response)
+	c.Redirect(http.StatusFound, h.oauthFrontendURL)
+}
+
+// ListIdentities returns the external accounts linked to the authenticated user.
+func (h *AuthHandler) ListIdentities(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	identities, err := h.authService.ListIdentities(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrOAuthUnavailable) {
+			c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao listar contas vinculadas"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"identities": identities})
+}
+
+func (h *AuthHandler) redirectOAuthError(c *gin.Context, code string) {
+	target, err := url.Parse(h.oauthFrontendURL)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": code})
+		return
+	}
+
+	query := target.Query()
+	query.Set("oauth_error", code)
+	target.RawQuery = query.Encode()
+	c.Redirect(http.StatusFound, target.String())
+}
+
+func oauthErrorCode(err error) string {
+	switch {
+	case errors.Is(err, service.ErrOAuthProviderNotFound):
+		return "provider_not_found"
+	case errors.Is(err, service.ErrInvalidOAuthState):
+		return "invalid_state"
+	case errors.Is(err, service.ErrOAuthEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, service.ErrOAuthAccountConflict):
+		return "account_conflict"
+	case errors.Is(err, service.ErrInvitationRequired):
+		return "invitation_required"
+	case errors.Is(err, service.ErrUserNotActive):
+		return "user_not_active"
+	case errors.Is(err, service.ErrAccountLocked):
+		return "account_locked"
+	case errors.Is(err, service.ErrEmailNotVerified):
+		return "account_email_not_verified"
+	default:
+		return "login_failed"
+	}
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_StartOAuthLogin(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		BeginOAuthLoginFunc: func(provider string) (*service.OAuthRedirect, error) {
+			return &service.OAuthRedirect{
+				URL:       "https://accounts.example.com/authorize?state=state-value",
+				State:     "state-value",
+				ExpiresAt: time.Now().Add(10 * time.Minute),
+			}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Params = gin.Params{{Key: "provider", Value: "google"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/auth/oauth/google/start", nil)
+
+	handler.StartOAuthLogin(c)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected %d, got %d", http.StatusFound, w.Code)
+	}
+	if location := w.Header().Get("Location"); !strings.HasPrefix(location, "https://accounts.example.com/authorize") {
+		t.Fatalf("unexpected redirect %q", location)
+	}
+	if cookie := w.Header().Get("Set-Cookie"); !strings.Contains(cookie, "oauth_state=state-value") ||
+		!strings.Contains(cookie, "HttpOnly") {
+		t.Fatalf("expected http-only state cookie, got %q", cookie)
+	}
+}
+
+func TestAuthHandler_StartOAuthLogin_UnknownProvider(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		BeginOAuthLoginFunc: func(provider string) (*service.OAuthRedirect, error) {
+			return nil, service.ErrOAuthProviderNotFound
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Params = gin.Params{{Key: "provider", Value: "myspace"}}
+	c.Request, _ = http.NewRequest(http.MethodGet, "/auth/oauth/myspace/start", nil)
+
+	handler.StartOAuthLogin(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestAuthHandler_OAuthCallback(t *testing.T) {
+	const frontendURL = "http://localhost:5173/login"
+
+	tests := []struct {
+		name             string
+		query            string
+		stateCookie      string
+		setupMock        func(*MockAuthService)
+		expectedLocation string
+		expectCookie     bool
+	}{
+		{
+			name:        "success",
+			query:       "state=state-value&code=code-value",
+			stateCookie: "state-value",
+			setupMock: func(m *MockAuthService) {
+				m.CompleteOAuthLoginFunc = func(ctx context.Context, provider, state, code, ip, userAgent string) (*service.LoginResponse, error) {
+					if provider != "google" || state != "state-value" || code != "code-value" {
+						t.Errorf("unexpected arguments %q %q %q", provider, state, code)
+					}
+					return &service.LoginResponse{
+						SessionID: "session-id",
+						ExpiresAt: time.Now().Add(time.Hour),
+						User:      auth.UserData{ID: "1", Identifier: "testuser"},
+					}, nil
+				}
+			},
+			expectedLocation: frontendURL,
+			expectCookie:     true,
+		},
+		{
+			name:        "second factor required",
+			query:       "state=state-value&code=code-value",
+			stateCookie: "state-value",
+			setupMock: func(m *MockAuthService) {
+				m.CompleteOAuthLoginFunc = func(ctx context.Context, provider, state, code, ip, userAgent string) (*service.LoginResponse, error) {
+					return &service.LoginResponse{TwoFactorRequired: true, ChallengeToken: "challenge"}, nil
+				}
+			},
+			expectedLocation: frontendURL + "#two_factor_challenge=challenge",
+		},
+		{
+			name:             "state from another browser",
+			query:            "state=state-value&code=code-value",
+			stateCookie:      "other-state",
+			setupMock:        func(m *MockAuthService) {},
+			expectedLocation: frontendURL + "?oauth_error=invalid_state",
+		},
+		{
+			name:             "user denied consent",
+			query:            "state=state-value&error=access_denied",
+			stateCookie:      "state-value",
+			setupMock:        func(m *MockAuthService) {},
+			expectedLocation: frontendURL + "?oauth_error=access_denied",
+		},
+		{
+			name:        "account conflict",
+			query:       "state=state-value&code=code-value",
+			stateCookie: "state-value",
+			setupMock: func(m *MockAuthService) {
+				m.CompleteOAuthLoginFunc = func(ctx context.Context, provider, state, code, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrOAuthAccountConflict
+				}
+			},
+			expectedLocation: frontendURL + "?oauth_error=account_conflict",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+			handler.SetOAuthFrontendURL(frontendURL)
+
+			c.Params = gin.Params{{Key: "provider", Value: "google"}}
+			req, _ := http.NewRequest(http.MethodGet, "/auth/oauth/google/callback?"+tt.query, nil)
+			req.AddCookie(&http.Cookie{Name: "oauth_state", Value: tt.stateCookie})
+			c.Request = req
+
+			handler.OAuthCallback(c)
+
+			if w.Code != http.StatusFound {
+				t.Fatalf("expected %d, got %d", http.StatusFound, w.Code)
+			}
+			if location := w.Header().Get("Location"); location != tt.expectedLocation {
+				t.Fatalf("expected redirect to %q, got %q", tt.expectedLocation, location)
+			}
+
+			hasCookie := false
+			for _, cookie := range w.Header().Values("Set-Cookie") {
+				if strings.Contains(cookie, "session_id=session-id") {
+					hasCookie = true
+				}
+			}
+			if hasCookie != tt.expectCookie {
+				t.Fatalf("expected session cookie %v, got %v", tt.expectCookie, hasCookie)
+			}
+		})
+	}
+}
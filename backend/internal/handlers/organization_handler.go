@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateOrganizationRequest defines payload for creating an organization.
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// UpdateOrganizationMemberRoleRequest defines payload for changing a member's role.
+type UpdateOrganizationMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// ListOrganizations returns the organizations the user belongs to.
+func (h *AuthHandler) ListOrganizations(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	organizations, err := h.authService.ListOrganizations(userID)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao listar organizações")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": organizations})
+}
+
+// CreateOrganization creates an organization owned by the user.
+func (h *AuthHandler) CreateOrganization(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	organization, err := h.authService.CreateOrganization(userID, req.Name)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao criar organização")
+		return
+	}
+
+	c.JSON(http.StatusCreated, organization)
+}
+
+// GetOrganization returns the organization resolved by RequireOrganization.
+func (h *AuthHandler) GetOrganization(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	organization, err := h.authService.GetOrganization(orgID, userID)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao obter organização")
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// DeleteOrganization removes the organization; only owners may.
+func (h *AuthHandler) DeleteOrganization(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.DeleteOrganization(orgID, userID); err != nil {
+		writeOrganizationError(c, err, "falha ao remover organização")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "organização removida"})
+}
+
+// ListOrganizationMembers returns the organization's members.
+func (h *AuthHandler) ListOrganizationMembers(c *gin.Context) {
+	orgID, _, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.authService.ListOrganizationMembers(orgID)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao listar membros")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// UpdateOrganizationMemberRole changes a member's role in the organization.
+func (h *AuthHandler) UpdateOrganizationMemberRole(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateOrganizationMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	member, err := h.authService.UpdateOrganizationMemberRole(orgID, userID, c.Param("user_id"), req.Role)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao alterar papel do membro")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+// RemoveOrganizationMember removes a member, or lets the user leave when the
+// route names themselves.
+func (h *AuthHandler) RemoveOrganizationMember(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RemoveOrganizationMember(orgID, userID, c.Param("user_id")); err != nil {
+		writeOrganizationError(c, err, "falha ao remover membro")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "membro removido"})
+}
+
+// SwitchOrganization makes the organization the active one in the session.
+func (h *AuthHandler) SwitchOrganization(c *gin.Context) {
+	orgID, userID, ok := organizationContext(c)
+	if !ok {
+		return
+	}
+	sessionID, ok := getContextString(c, "sessionID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	organization, err := h.authService.SwitchOrganization(sessionID, userID, orgID)
+	if err != nil {
+		writeOrganizationError(c, err, "falha ao trocar de organização")
+		return
+	}
+
+	c.JSON(http.StatusOK, organization)
+}
+
+// organizationContext returns the organization resolved by RequireOrganization
+// and the user acting in it, responding with an error if either is missing
+func organizationContext(c *gin.Context) (string, string, bool) {
+	orgID, ok := getContextString(c, "organizationID")
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": service.ErrOrganizationNotFound.Error()})
+		return "", "", false
+	}
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return "", "", false
+	}
+	return orgID, userID, true
+}
+
+func writeOrganizationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOrganizationName),
+		errors.Is(err, service.ErrInvalidOrganizationRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInsufficientOrganizationRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOrganizationNotFound),
+		errors.Is(err, service.ErrOrganizationMemberNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyOrganizationMember),
+		errors.Is(err, service.ErrLastOrganizationOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidToken):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrOrganizationsUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
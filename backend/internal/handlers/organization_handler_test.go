@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_UpdateOrganizationMemberRole(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{name: "success", body: `{"role":"admin"}`, expectedStatus: http.StatusOK},
+		{name: "missing role", body: `{}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid role", body: `{"role":"x"}`, err: service.ErrInvalidOrganizationRole, expectedStatus: http.StatusBadRequest},
+		{name: "owner only", body: `{"role":"owner"}`, err: service.ErrInsufficientOrganizationRole, expectedStatus: http.StatusForbidden},
+		{name: "not a member", body: `{"role":"admin"}`, err: service.ErrOrganizationMemberNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				UpdateOrgMemberRoleFunc: func(orgID, actorID, userID, role string) (*service.OrganizationMemberInfo, error) {
+					if orgID != "7" || actorID != "1" || userID != "2" {
+						t.Errorf("unexpected arguments %q %q %q", orgID, actorID, userID)
+					}
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &service.OrganizationMemberInfo{UserID: userID, Role: role}, nil
+				},
+			}
+			handler := NewAuthHandler(mockService, false)
+
+			c.Set("userID", "1")
+			c.Set("organizationID", "7")
+			c.Params = gin.Params{{Key: "user_id", Value: "2"}}
+			req, _ := http.NewRequest(http.MethodPatch, "/api/orgs/7/members/2", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.UpdateOrganizationMemberRole(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthHandler_RemoveOrganizationMember(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		RemoveOrgMemberFunc: func(orgID, actorID, userID string) error {
+			if orgID != "7" || actorID != "1" || userID != "1" {
+				t.Errorf("unexpected arguments %q %q %q", orgID, actorID, userID)
+			}
+			return service.ErrLastOrganizationOwner
+		},
+	}
+	handler := NewAuthHandler(mockService, false)
+
+	c.Set("userID", "1")
+	c.Set("organizationID", "7")
+	c.Params = gin.Params{{Key: "user_id", Value: "1"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/orgs/7/members/1", nil)
+
+	handler.RemoveOrganizationMember(c)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("expected 409, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAuthHandler_SwitchOrganization(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		SwitchOrganizationFunc: func(sessionID, userID, orgID string) (*service.OrganizationInfo, error) {
+			if sessionID != "session-token" || userID != "1" || orgID != "7" {
+				t.Errorf("unexpected arguments %q %q %q", sessionID, userID, orgID)
+			}
+			return &service.OrganizationInfo{ID: orgID, Name: "Acme", Role: "member"}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService, false)
+
+	c.Set("userID", "1")
+	c.Set("sessionID", "session-token")
+	c.Set("organizationID", "7")
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/orgs/7/switch", nil)
+
+	handler.SwitchOrganization(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body service.OrganizationInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Name != "Acme" {
+		t.Fatalf("expected the active organization, got %+v", body)
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FinishPasskeyRequest carries the browser's answer to a WebAuthn ceremony
+type FinishPasskeyRequest struct {
+	CeremonyToken string          `json:"ceremony_token" binding:"required"`
+	Credential    json.RawMessage `json:"credential"     binding:"required"`
+}
+
+// FinishPasskeyRegistrationRequest carries a new passkey and its label
+type FinishPasskeyRegistrationRequest struct {
+	CeremonyToken string          `json:"ceremony_token" binding:"required"`
+	Credential    json.RawMessage `json:"credential"     binding:"required"`
+	Name          string          `json:"name"           binding:"max=100"`
+}
+
+// BeginPasskeyLogin returns the options for a usernameless passkey login.
+func (h *AuthHandler) BeginPasskeyLogin(c *gin.Context) {
+	options, err := h.authService.BeginPasskeyLogin()
+	if err != nil {
+		writePasskeyError(c, err, "falha ao iniciar login com passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+// FinishPasskeyLogin verifies a passkey assertion and starts a session.
+func (h *AuthHandler) FinishPasskeyLogin(c *gin.Context) {
+	var req FinishPasskeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.FinishPasskeyLogin(req.CeremonyToken, req.Credential, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPasskey),
+			errors.Is(err, service.ErrInvalidCeremony),
+			errors.Is(err, service.ErrUserNotActive),
+			errors.Is(err, service.ErrAccountLocked):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrEmailNotVerified):
+			writeEmailNotVerified(c)
+		default:
+			writePasskeyError(c, err, "falha ao entrar com passkey")
+		}
+		return
+	}
+
+	h.startSession(c, response)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListPasskeys returns the passkeys registered by the authenticated user.
+func (h *AuthHandler) ListPasskeys(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	passkeys, err := h.authService.ListPasskeys(userID)
+	if err != nil {
+		writePasskeyError(c, err, "falha ao listar passkeys")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"passkeys": passkeys})
+}
+
+// BeginPasskeyRegistration returns the options for registering a new passkey.
+func (h *AuthHandler) BeginPasskeyRegistration(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	options, err := h.authService.BeginPasskeyRegistration(userID)
+	if err != nil {
+		writePasskeyError(c, err, "falha ao iniciar cadastro de passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, options)
+}
+
+// FinishPasskeyRegistration verifies and stores a new passkey.
+func (h *AuthHandler) FinishPasskeyRegistration(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req FinishPasskeyRegistrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	passkey, err := h.authService.FinishPasskeyRegistration(userID, req.CeremonyToken, req.Name, req.Credential)
+	if err != nil {
+		writePasskeyError(c, err, "falha ao cadastrar passkey")
+		return
+	}
+
+	c.JSON(http.StatusCreated, passkey)
+}
+
+// DeletePasskey removes one of the authenticated user's passkeys.
+func (h *AuthHandler) DeletePasskey(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	if err := h.authService.DeletePasskey(userID, c.Param("passkey_id")); err != nil {
+		writePasskeyError(c, err, "falha ao remover passkey")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "passkey removida"})
+}
+
+func writePasskeyError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidPasskey),
+		errors.Is(err, service.ErrInvalidCeremony):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPasskeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPasskeysUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
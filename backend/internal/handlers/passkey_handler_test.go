@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthHandler_FinishPasskeyLogin(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+		expectCookie   bool
+	}{
+		{
+			name: "success",
+			body: map[string]any{"ceremony_token": "ceremony", "credential": map[string]any{"id": "abc"}},
+			setupMock: func(m *MockAuthService) {
+				m.FinishPasskeyLoginFunc = func(ceremonyToken string, credential []byte, ip, userAgent string) (*service.LoginResponse, error) {
+					if ceremonyToken != "ceremony" || !strings.Contains(string(credential), `"abc"`) {
+						t.Errorf("unexpected arguments %q %q", ceremonyToken, credential)
+					}
+					return &service.LoginResponse{
+						SessionID: "session-id",
+						ExpiresAt: time.Now().Add(time.Hour),
+						User:      auth.UserData{ID: "1", Identifier: "testuser"},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectCookie:   true,
+		},
+		{
+			name: "invalid assertion",
+			body: map[string]any{"ceremony_token": "ceremony", "credential": map[string]any{"id": "abc"}},
+			setupMock: func(m *MockAuthService) {
+				m.FinishPasskeyLoginFunc = func(ceremonyToken string, credential []byte, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrInvalidPasskey
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "passkeys unavailable",
+			body: map[string]any{"ceremony_token": "ceremony", "credential": map[string]any{"id": "abc"}},
+			setupMock: func(m *MockAuthService) {
+				m.FinishPasskeyLoginFunc = func(ceremonyToken string, credential []byte, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrPasskeysUnavailable
+				}
+			},
+			expectedStatus: http.StatusNotImplemented,
+		},
+		{
+			name:           "missing credential",
+			body:           map[string]any{"ceremony_token": "ceremony"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/webauthn/login/finish", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.FinishPasskeyLogin(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			hasCookie := strings.Contains(w.Header().Get("Set-Cookie"), "session_id=session-id")
+			if hasCookie != tt.expectCookie {
+				t.Fatalf("expected session cookie %v, got %v", tt.expectCookie, hasCookie)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_FinishPasskeyRegistration(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		FinishPasskeyRegFunc: func(userID, ceremonyToken, name string, credential []byte) (*service.PasskeyInfo, error) {
+			return &service.PasskeyInfo{ID: "7", Name: name, CreatedAt: time.Now()}, nil
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Set("userID", "1")
+	body, _ := json.Marshal(map[string]any{
+		"ceremony_token": "ceremony",
+		"credential":     map[string]any{"id": "abc"},
+		"name":           "Notebook",
+	})
+	req, _ := http.NewRequest(http.MethodPost, "/api/account/passkeys/register/finish", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	c.Request = req
+
+	handler.FinishPasskeyRegistration(c)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var passkey service.PasskeyInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &passkey); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if passkey.Name != "Notebook" {
+		t.Fatalf("expected passkey name Notebook, got %q", passkey.Name)
+	}
+}
+
+func TestAuthHandler_DeletePasskey_NotFound(t *testing.T) {
+	c, w := setupTestRouter()
+	mockService := &MockAuthService{
+		DeletePasskeyFunc: func(userID, passkeyID string) error {
+			if passkeyID != "42" {
+				t.Errorf("expected passkey id 42, got %q", passkeyID)
+			}
+			return service.ErrPasskeyNotFound
+		},
+	}
+	handler := NewAuthHandler(mockService)
+
+	c.Set("userID", "1")
+	c.Params = gin.Params{{Key: "passkey_id", Value: "42"}}
+	c.Request, _ = http.NewRequest(http.MethodDelete, "/api/account/passkeys/42", nil)
+
+	handler.DeletePasskey(c)
+
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
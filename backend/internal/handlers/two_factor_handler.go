@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyTwoFactorRequest represents the second step of a two-factor login
+type VerifyTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"            binding:"required"`
+}
+
+// TwoFactorCodeRequest represents a request carrying a TOTP code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTwoFactorRequest represents the request body for disabling 2FA
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// RegenerateRecoveryCodesRequest represents the request body for issuing new recovery codes
+type RegenerateRecoveryCodesRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// VerifyTwoFactor completes a login that is waiting for a second factor.
+func (h *AuthHandler) VerifyTwoFactor(c *gin.Context) {
+	var req VerifyTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.authService.VerifyTwoFactor(req.ChallengeToken, req.Code, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTwoFactorCode),
+			errors.Is(err, service.ErrInvalidChallenge),
+			errors.Is(err, service.ErrUserNotActive),
+			errors.Is(err, service.ErrAccountLocked):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao verificar código"})
+		}
+		return
+	}
+
+	h.startSession(c, response)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetTwoFactorStatus returns the second-factor state of the authenticated user.
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "falha ao obter status da autenticação em dois fatores"})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// SetupTwoFactor starts TOTP enrollment for the authenticated user.
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	setup, err := h.authService.SetupTOTP(userID)
+	if err != nil {
+		writeTwoFactorError(c, err, "falha ao iniciar autenticação em dois fatores")
+		return
+	}
+
+	c.JSON(http.StatusOK, setup)
+}
+
+// ConfirmTwoFactor enables TOTP after verifying the first code and returns the
+// recovery codes, which are not shown again.
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.authService.ConfirmTOTP(userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(c, err, "falha ao ativar autenticação em dois fatores")
+		return
+	}
+
+	// The session now stands for a stronger authentication
+	h.rotateCurrentSession(c)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "autenticação em dois fatores ativada",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after confirming the password.
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(userID, req.Password)
+	if err != nil {
+		writeTwoFactorError(c, err, "falha ao gerar códigos de recuperação")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": recoveryCodes})
+}
+
+// DisableTwoFactor turns TOTP off after confirming the user's password.
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, ok := getContextString(c, "userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "não autenticado"})
+		return
+	}
+
+	var req DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.authService.DisableTOTP(userID, req.Password); err != nil {
+		writeTwoFactorError(c, err, "falha ao desativar autenticação em dois fatores")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "autenticação em dois fatores desativada"})
+}
+
+func writeTwoFactorError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWrongPassword),
+		errors.Is(err, service.ErrAccountLocked),
+		errors.Is(err, service.ErrUserNotActive):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidTwoFactorCode),
+		errors.Is(err, service.ErrTwoFactorSetupRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, service.ErrTwoFactorNotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTwoFactorUnavailable):
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
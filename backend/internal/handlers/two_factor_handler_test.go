@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/service"
+)
+
+func TestAuthHandler_VerifyTwoFactor(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           map[string]any
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+		expectCookie   bool
+	}{
+		{
+			name: "success",
+			body: map[string]any{"challenge_token": "challenge", "code": "123456"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyTwoFactorFunc = func(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error) {
+					return &service.LoginResponse{
+						SessionID: "session-id",
+						ExpiresAt: time.Now().Add(time.Hour),
+						User:      auth.UserData{ID: "1", Identifier: "testuser"},
+					}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectCookie:   true,
+		},
+		{
+			name: "invalid code",
+			body: map[string]any{"challenge_token": "challenge", "code": "000000"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyTwoFactorFunc = func(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrInvalidTwoFactorCode
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "expired challenge",
+			body: map[string]any{"challenge_token": "challenge", "code": "123456"},
+			setupMock: func(m *MockAuthService) {
+				m.VerifyTwoFactorFunc = func(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error) {
+					return nil, service.ErrInvalidChallenge
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "missing code",
+			body:           map[string]any{"challenge_token": "challenge"},
+			setupMock:      func(m *MockAuthService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			body, _ := json.Marshal(tt.body)
+			req, _ := http.NewRequest(http.MethodPost, "/auth/2fa/verify", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.VerifyTwoFactor(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			hasCookie := strings.Contains(w.Header().Get("Set-Cookie"), "session_id=session-id")
+			if hasCookie != tt.expectCookie {
+				t.Fatalf("expected session cookie %v, got %v", tt.expectCookie, hasCookie)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_ConfirmTwoFactor(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(m *MockAuthService) {
+				m.ConfirmTOTPFunc = func(userID, code string) ([]string, error) {
+					return []string{"abcd-efgh"}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "invalid code",
+			setupMock: func(m *MockAuthService) {
+				m.ConfirmTOTPFunc = func(userID, code string) ([]string, error) {
+					return nil, service.ErrInvalidTwoFactorCode
+				}
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "already enabled",
+			setupMock: func(m *MockAuthService) {
+				m.ConfirmTOTPFunc = func(userID, code string) ([]string, error) {
+					return nil, service.ErrTwoFactorAlreadyEnabled
+				}
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			body, _ := json.Marshal(map[string]any{"code": "123456"})
+			req, _ := http.NewRequest(http.MethodPost, "/api/account/2fa/confirm", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.ConfirmTwoFactor(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_DisableTwoFactor_WrongPassword(t *testing.T) {
+	for _, err := range []error{service.ErrWrongPassword, service.ErrAccountLocked} {
+		t.Run(err.Error(), func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{
+				DisableTOTPFunc: func(userID, password string) error {
+					return err
+				},
+			}
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			body, _ := json.Marshal(map[string]any{"password": "wrong"})
+			req, _ := http.NewRequest(http.MethodPost, "/api/account/2fa/disable", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.DisableTwoFactor(c)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected %d, got %d", http.StatusUnauthorized, w.Code)
+			}
+		})
+	}
+}
+
+func TestAuthHandler_SetupTwoFactor_Unauthorized(t *testing.T) {
+	c, w := setupTestRouter()
+	handler := NewAuthHandler(&MockAuthService{})
+	c.Request, _ = http.NewRequest(http.MethodPost, "/api/account/2fa/setup", nil)
+
+	handler.SetupTwoFactor(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAuthHandler_RegenerateRecoveryCodes(t *testing.T) {
+	tests := []struct {
+		name           string
+		setupMock      func(*MockAuthService)
+		expectedStatus int
+	}{
+		{
+			name: "success",
+			setupMock: func(m *MockAuthService) {
+				m.RegenerateCodesFunc = func(userID, password string) ([]string, error) {
+					return []string{"abcd-efgh", "ijkl-mnop"}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "wrong password",
+			setupMock: func(m *MockAuthService) {
+				m.RegenerateCodesFunc = func(userID, password string) ([]string, error) {
+					return nil, service.ErrWrongPassword
+				}
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "two-factor not enabled",
+			setupMock: func(m *MockAuthService) {
+				m.RegenerateCodesFunc = func(userID, password string) ([]string, error) {
+					return nil, service.ErrTwoFactorNotEnabled
+				}
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := setupTestRouter()
+			mockService := &MockAuthService{}
+			tt.setupMock(mockService)
+			handler := NewAuthHandler(mockService)
+
+			c.Set("userID", "1")
+			body, _ := json.Marshal(map[string]any{"password": "password123"})
+			req, _ := http.NewRequest(http.MethodPost, "/api/account/2fa/recovery-codes", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			c.Request = req
+
+			handler.RegenerateRecoveryCodes(c)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package maintenance runs periodic cleanup jobs, such as purging expired
+// sessions and tokens, on exactly one replica at a time.
+package maintenance
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// metrics exposes, per task, how many times it ran, how many records it
+// removed and how many runs failed, as "<task>_runs", "<task>_removed" and
+// "<task>_errors" under the "maintenance" expvar
+var metrics = expvar.NewMap("maintenance")
+
+// MetricsHandler serves the maintenance counters as JSON, in the same
+// {"maintenance": {...}} shape expvar.Handler uses, but without the other
+// process variables such as cmdline and memstats
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		fmt.Fprintf(w, "{\"maintenance\": %s}\n", metrics.String())
+	})
+}
+
+// Task is one cleanup job. Run returns how many records it removed.
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (int64, error)
+}
+
+// Janitor runs its tasks at their intervals while it holds leadership
+type Janitor struct {
+	leader Leader
+	tasks  []Task
+}
+
+// NewJanitor creates a janitor for the tasks. Tasks with a non-positive
+// interval are dropped, which is how a single task is disabled.
+func NewJanitor(leader Leader, tasks ...Task) *Janitor {
+	j := &Janitor{leader: leader}
+	for _, task := range tasks {
+		if task.Interval > 0 {
+			j.tasks = append(j.tasks, task)
+		}
+	}
+	return j
+}
+
+// Run blocks until ctx is done. Every task runs once at start and then every
+// Interval, as long as this replica is the leader; a replica that is not
+// keeps trying to become one at the same pace.
+func (j *Janitor) Run(ctx context.Context) {
+	if len(j.tasks) == 0 {
+		return
+	}
+	defer func() {
+		if err := j.leader.Release(context.Background()); err != nil {
+			slog.Error("maintenance leader release failed", "err", err)
+		}
+	}()
+
+	due := make([]time.Time, len(j.tasks))
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		now := time.Now()
+		leading := j.acquire(ctx)
+		for i, task := range j.tasks {
+			if now.Before(due[i]) {
+				continue
+			}
+			if leading {
+				j.runTask(ctx, task)
+			}
+			due[i] = now.Add(task.Interval)
+		}
+
+		next := due[0]
+		for _, at := range due[1:] {
+			if at.Before(next) {
+				next = at
+			}
+		}
+		timer.Reset(time.Until(next))
+	}
+}
+
+// RunOnce runs every task immediately if this replica is the leader, and
+// reports whether it was
+func (j *Janitor) RunOnce(ctx context.Context) bool {
+	if !j.acquire(ctx) {
+		return false
+	}
+	for _, task := range j.tasks {
+		j.runTask(ctx, task)
+	}
+	return true
+}
+
+func (j *Janitor) acquire(ctx context.Context) bool {
+	leading, err := j.leader.Acquire(ctx)
+	if err != nil {
+		slog.Error("maintenance leader election failed", "err", err)
+		return false
+	}
+	return leading
+}
+
+func (j *Janitor) runTask(ctx context.Context, task Task) {
+	start := time.Now()
+	removed, err := task.Run(ctx)
+	duration := time.Since(start)
+
+	metrics.Add(task.Name+"_runs", 1)
+	if err != nil {
+		metrics.Add(task.Name+"_errors", 1)
+		slog.Error("maintenance task failed", "task", task.Name, "duration", duration, "err", err)
+		return
+	}
+
+	metrics.Add(task.Name+"_removed", removed)
+	slog.Info("maintenance task finished", "task", task.Name, "removed", removed, "duration", duration)
+}
+
+// Step is one cleanup function within a task
+type Step struct {
+	Name string
+	Run  func() (int64, error)
+}
+
+// Steps combines cleanup functions into one task body that runs all of them,
+// adding up what they removed. A failing step does not stop the others; the
+// first error is returned.
+func Steps(steps ...Step) func(ctx context.Context) (int64, error) {
+	return func(ctx context.Context) (int64, error) {
+		var total int64
+		var firstErr error
+		for _, step := range steps {
+			if err := ctx.Err(); err != nil {
+				return total, err
+			}
+
+			removed, err := step.Run()
+			total += removed
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", step.Name, err)
+			}
+		}
+		return total, firstErr
+	}
+}
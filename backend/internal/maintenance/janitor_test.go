@@ -0,0 +1,123 @@
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// followerLeader never becomes the leader
+type followerLeader struct{}
+
+func (followerLeader) Acquire(context.Context) (bool, error) { return false, nil }
+func (followerLeader) Release(context.Context) error         { return nil }
+
+func metric(name string) int64 {
+	value := metrics.Get(name)
+	if value == nil {
+		return 0
+	}
+	return value.(interface{ Value() int64 }).Value()
+}
+
+func TestJanitor_RunOnceRecordsMetrics(t *testing.T) {
+	janitor := NewJanitor(AlwaysLeader{},
+		Task{Name: "test_ok", Interval: time.Hour, Run: func(context.Context) (int64, error) { return 3, nil }},
+		Task{Name: "test_failing", Interval: time.Hour, Run: func(context.Context) (int64, error) { return 0, errors.New("boom") }},
+	)
+
+	require.True(t, janitor.RunOnce(context.Background()))
+
+	assert.Equal(t, int64(1), metric("test_ok_runs"))
+	assert.Equal(t, int64(3), metric("test_ok_removed"))
+	assert.Zero(t, metric("test_ok_errors"))
+	assert.Equal(t, int64(1), metric("test_failing_runs"))
+	assert.Equal(t, int64(1), metric("test_failing_errors"))
+}
+
+func TestJanitor_FollowerDoesNothing(t *testing.T) {
+	var runs atomic.Int32
+	janitor := NewJanitor(followerLeader{},
+		Task{Name: "test_follower", Interval: time.Hour, Run: func(context.Context) (int64, error) {
+			runs.Add(1)
+			return 0, nil
+		}},
+	)
+
+	assert.False(t, janitor.RunOnce(context.Background()))
+	assert.Zero(t, runs.Load())
+}
+
+func TestJanitor_RunRepeatsTasksUntilCancelled(t *testing.T) {
+	var fast, slow atomic.Int32
+	janitor := NewJanitor(AlwaysLeader{},
+		Task{Name: "test_fast", Interval: 10 * time.Millisecond, Run: func(context.Context) (int64, error) {
+			fast.Add(1)
+			return 0, nil
+		}},
+		Task{Name: "test_slow", Interval: time.Hour, Run: func(context.Context) (int64, error) {
+			slow.Add(1)
+			return 0, nil
+		}},
+		Task{Name: "test_disabled", Interval: 0, Run: func(context.Context) (int64, error) {
+			t.Error("disabled task ran")
+			return 0, nil
+		}},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		janitor.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return fast.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+
+	assert.Equal(t, int32(1), slow.Load())
+}
+
+func TestSteps_RunsEveryStepAndReportsFirstError(t *testing.T) {
+	var last bool
+	run := Steps(
+		Step{Name: "first", Run: func() (int64, error) { return 2, nil }},
+		Step{Name: "broken", Run: func() (int64, error) { return 0, errors.New("boom") }},
+		Step{Name: "last", Run: func() (int64, error) {
+			last = true
+			return 5, nil
+		}},
+	)
+
+	removed, err := run(context.Background())
+	assert.Equal(t, int64(7), removed)
+	assert.EqualError(t, err, "broken: boom")
+	assert.True(t, last)
+}
+
+func TestMetricsHandler_ServesOnlyMaintenanceCounters(t *testing.T) {
+	janitor := NewJanitor(AlwaysLeader{},
+		Task{Name: "test_served", Interval: time.Hour, Run: func(context.Context) (int64, error) { return 2, nil }},
+	)
+	require.True(t, janitor.RunOnce(context.Background()))
+
+	w := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var body map[string]map[string]int64
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body, 1)
+	assert.Equal(t, int64(2), body["maintenance"]["test_served_removed"])
+}
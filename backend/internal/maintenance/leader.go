@@ -0,0 +1,101 @@
+package maintenance
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+)
+
+// DefaultLockKey is the advisory lock the janitor's replicas compete for.
+// It spells "gsvk-jan" in ASCII, to stay clear of other users of the lock
+// space.
+const DefaultLockKey int64 = 0x6773766b2d6a616e
+
+// Leader decides which replica runs the maintenance tasks
+type Leader interface {
+	// Acquire reports whether this replica is the leader, becoming it if
+	// nobody else is. It is called before every round of tasks, so it must
+	// also notice when leadership was lost.
+	Acquire(ctx context.Context) (bool, error)
+
+	// Release gives up leadership, if held
+	Release(ctx context.Context) error
+}
+
+// AlwaysLeader is the Leader of a deployment with a single replica
+type AlwaysLeader struct{}
+
+// Acquire always succeeds
+func (AlwaysLeader) Acquire(context.Context) (bool, error) { return true, nil }
+
+// Release does nothing
+func (AlwaysLeader) Release(context.Context) error { return nil }
+
+// AdvisoryLockLeader elects the leader with a PostgreSQL session-level
+// advisory lock. The lock is held on a dedicated connection, so it is
+// released by the database if the replica dies or the connection drops.
+type AdvisoryLockLeader struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLockLeader creates a leader that competes for the advisory lock
+// key with every other replica using the same database
+func NewAdvisoryLockLeader(db *sql.DB, key int64) *AdvisoryLockLeader {
+	return &AdvisoryLockLeader{db: db, key: key}
+}
+
+// Acquire keeps the lock if its connection is still alive, and otherwise
+// tries to take it without waiting
+func (l *AdvisoryLockLeader) Acquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The session and its lock are gone; another replica may hold it now
+		slog.Warn("maintenance leadership lost", "key", l.key)
+		_ = l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return false, err
+	}
+	if !locked {
+		_ = conn.Close()
+		return false, nil
+	}
+
+	slog.Info("maintenance leadership acquired", "key", l.key)
+	l.conn = conn
+	return true, nil
+}
+
+// Release unlocks the advisory lock and returns its connection to the pool
+func (l *AdvisoryLockLeader) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	_ = l.conn.Close()
+	l.conn = nil
+	return err
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// AccessTokenVerifier checks the signed access tokens issued by
+// POST /api/token. Services behind this backend use it to authenticate
+// requests on their own, with only the published JWKS to go on.
+type AccessTokenVerifier struct {
+	verifier *oidc.IDTokenVerifier
+}
+
+// NewAccessTokenVerifier creates a verifier that fetches the signing keys from
+// jwksURL, normally <issuer>/.well-known/jwks.json. The keys are cached and
+// fetched again when a token names a key ID not seen before, so rotation needs
+// no restart. An empty audience accepts tokens for any audience.
+func NewAccessTokenVerifier(ctx context.Context, jwksURL, issuer, audience string) *AccessTokenVerifier {
+	return NewAccessTokenVerifierWithKeySet(oidc.NewRemoteKeySet(ctx, jwksURL), issuer, audience)
+}
+
+// NewAccessTokenVerifierWithKeySet creates a verifier that checks signatures
+// against keySet, e.g. an oidc.StaticKeySet of keys loaded at startup
+func NewAccessTokenVerifierWithKeySet(keySet oidc.KeySet, issuer, audience string) *AccessTokenVerifier {
+	return &AccessTokenVerifier{
+		verifier: oidc.NewVerifier(issuer, keySet, &oidc.Config{
+			ClientID:             audience,
+			SkipClientIDCheck:    audience == "",
+			SupportedSigningAlgs: []string{oidc.EdDSA, oidc.ES256},
+		}),
+	}
+}
+
+// Verify checks the token's signature, type, issuer, audience and expiry and
+// returns its claims. The "typ" header must be auth.AccessTokenType, so an ID
+// token or other JWT signed with the same keys is not taken for an access
+// token.
+func (v *AccessTokenVerifier) Verify(ctx context.Context, token string) (*auth.AccessTokenClaims, error) {
+	idToken, err := v.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := jose.ParseSigned(token, []jose.SignatureAlgorithm{jose.EdDSA, jose.ES256})
+	if err != nil {
+		return nil, err
+	}
+	if typ := signed.Signatures[0].Protected.ExtraHeaders[jose.HeaderType]; typ != auth.AccessTokenType {
+		return nil, errors.New("token is not an access token")
+	}
+
+	var claims auth.AccessTokenClaims
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("access token has no subject")
+	}
+	return &claims, nil
+}
+
+// AccessTokenMiddleware authenticates requests carrying a signed access token
+// in the Authorization header. It sets "userID", "principalType", "role" and
+// "permissions" like AuthMiddleware does, plus "accessToken" with the token's
+// claims, so RoleMiddleware, RequirePrincipal, RequirePermission and
+// RequireScope work the same behind either one.
+func AccessTokenMiddleware(verifier *AccessTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token de acesso ausente"})
+			return
+		}
+
+		claims, err := verifier.Verify(c.Request.Context(), token)
+		if err != nil {
+			message := "token de acesso inválido"
+			var expired *oidc.TokenExpiredError
+			if errors.As(err, &expired) {
+				message = "token de acesso expirado"
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+			return
+		}
+
+		c.Set("userID", claims.Subject)
+		c.Set("principalType", auth.PrincipalUser)
+		c.Set("role", claims.Role)
+		c.Set("permissions", claims.Permissions)
+		c.Set("accessToken", claims)
+
+		c.Next()
+	}
+}
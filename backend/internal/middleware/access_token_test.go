@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccessTokenIssuer = "https://api.example.com"
+
+// newTestAccessTokenSigner creates a signer for the "orders" audience and the
+// key set that verifies its tokens
+func newTestAccessTokenSigner(t *testing.T) (*auth.AccessTokenSigner, oidc.KeySet) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	signer, err := auth.NewAccessTokenSigner(privateKey, nil, auth.AccessTokenSignerConfig{
+		Issuer:   testAccessTokenIssuer,
+		Audience: "orders",
+		TTL:      time.Minute,
+	})
+	require.NoError(t, err)
+
+	return signer, &oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{publicKey}}
+}
+
+func TestAccessTokenVerifier(t *testing.T) {
+	signer, keySet := newTestAccessTokenSigner(t)
+	verifier := NewAccessTokenVerifierWithKeySet(keySet, testAccessTokenIssuer, "orders")
+
+	token, err := signer.Sign(&auth.UserData{ID: "7", Role: "user"}, []string{"read"}, "session-1")
+	require.NoError(t, err)
+
+	claims, err := verifier.Verify(context.Background(), token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "7", claims.Subject)
+	assert.Equal(t, "user", claims.Role)
+	assert.True(t, claims.HasScope("read"))
+
+	// Wrong audience
+	billing := NewAccessTokenVerifierWithKeySet(keySet, testAccessTokenIssuer, "billing")
+	_, err = billing.Verify(context.Background(), token.Token)
+	assert.Error(t, err)
+
+	// Wrong issuer
+	other := NewAccessTokenVerifierWithKeySet(keySet, "https://other.example.com", "")
+	_, err = other.Verify(context.Background(), token.Token)
+	assert.Error(t, err)
+
+	// Signed with a key the verifier does not trust
+	otherSigner, _ := newTestAccessTokenSigner(t)
+	forged, err := otherSigner.Sign(&auth.UserData{ID: "7", Role: "admin"}, []string{"admin"}, "")
+	require.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), forged.Token)
+	assert.Error(t, err)
+}
+
+func TestAccessTokenVerifierRejectsOtherTokenTypes(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	verifier := NewAccessTokenVerifierWithKeySet(
+		&oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{publicKey}}, testAccessTokenIssuer, "orders")
+
+	sign := func(typ string) string {
+		signer, err := jose.NewSigner(
+			jose.SigningKey{Algorithm: jose.EdDSA, Key: privateKey},
+			(&jose.SignerOptions{}).WithType(jose.ContentType(typ)),
+		)
+		require.NoError(t, err)
+		token, err := jwt.Signed(signer).Claims(jwt.Claims{
+			Issuer:   testAccessTokenIssuer,
+			Subject:  "7",
+			Audience: jwt.Audience{"orders"},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		}).Serialize()
+		require.NoError(t, err)
+		return token
+	}
+
+	_, err = verifier.Verify(context.Background(), sign(auth.AccessTokenType))
+	assert.NoError(t, err)
+
+	// An ID token, or any other JWT signed with the same key, is not an access token
+	_, err = verifier.Verify(context.Background(), sign("JWT"))
+	assert.Error(t, err)
+}
+
+func TestAccessTokenMiddleware(t *testing.T) {
+	signer, keySet := newTestAccessTokenSigner(t)
+	verifier := NewAccessTokenVerifierWithKeySet(keySet, testAccessTokenIssuer, "orders")
+
+	r := gin.New()
+	r.Use(AccessTokenMiddleware(verifier))
+	r.GET("/orders", RequirePrincipal(auth.PrincipalUser), RequireScope(auth.APITokenScopeRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("userID"), "role": c.GetString("role")})
+	})
+	r.DELETE("/orders", RequireScope(auth.APITokenScopeWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(method, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/orders", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	token, err := signer.Sign(&auth.UserData{ID: "7", Role: "user"}, []string{auth.APITokenScopeRead}, "")
+	require.NoError(t, err)
+
+	w := serve(http.MethodGet, "Bearer "+token.Token)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"user_id":"7","role":"user"}`, w.Body.String())
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, "Bearer "+token.Token).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "Bearer not-a-jwt").Code)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"maps"
+	"net/http"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit creates a middleware that records the request in the audit log under
+// the given action once the handler succeeded. The route parameters, such as
+// :user_id or :client_id, go into the event's details. The account concerned
+// is the route's :user_id, or the caller's own account when there is none.
+//
+// The actor is the signed-in user, or the admin behind an impersonation
+// session; requests from OAuth clients record the client as actor_client_id
+// in the details. Handlers add what the route does not show, such as the
+// old and new values of a change, with AddAuditDetails.
+func Audit(authManager *auth.AuthManager, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		event := &auth.AuditEvent{
+			Type:      action,
+			ActorID:   c.GetString("userID"),
+			UserID:    c.Param("user_id"),
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			Details:   map[string]string{"route": c.FullPath()},
+		}
+		if event.UserID == "" {
+			event.UserID = event.ActorID
+		}
+		if value, ok := c.Get("session"); ok {
+			if session := value.(*auth.Session); session.Impersonating() {
+				event.ActorID = session.ImpersonatorID
+				event.Details["impersonated_user_id"] = session.UserID
+			}
+		}
+		if clientID := c.GetString("clientID"); clientID != "" {
+			event.Details["actor_client_id"] = clientID
+		}
+
+		for _, param := range c.Params {
+			event.Details[param.Key] = param.Value
+		}
+		if details, ok := c.Get(auditDetailsKey); ok {
+			maps.Copy(event.Details, details.(map[string]string))
+		}
+		// Record the organization itself rather than "current"
+		if orgID := c.GetString("organizationID"); orgID != "" {
+			event.Details["org_id"] = orgID
+		}
+
+		authManager.RecordAuditEvent(event)
+	}
+}
+
+const auditDetailsKey = "auditDetails"
+
+// AddAuditDetails adds details to the event the Audit middleware records for
+// the request. Calling it more than once merges the details.
+func AddAuditDetails(c *gin.Context, details map[string]string) {
+	merged := map[string]string{}
+	if existing, ok := c.Get(auditDetailsKey); ok {
+		maps.Copy(merged, existing.(map[string]string))
+	}
+	maps.Copy(merged, details)
+	c.Set(auditDetailsKey, merged)
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAuditAdapter struct {
+	events []*auth.AuditEvent
+}
+
+func (a *recordingAuditAdapter) RecordAuditEvent(event *auth.AuditEvent) error {
+	a.events = append(a.events, event)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	authManager := auth.NewAuthManager(gormadapter.NewUserAdapter(db), gormadapter.NewSessionAdapter(db), auth.DefaultAuthConfig())
+	adapter := &recordingAuditAdapter{}
+	authManager.SetAuditAdapter(adapter)
+
+	serve := func(status int, session *auth.Session) {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set("userID", session.UserID)
+			c.Set("session", session)
+			c.Next()
+		})
+		r.PATCH("/admin/users/:user_id/role", Audit(authManager, auth.AuditUserRoleChanged), func(c *gin.Context) {
+			AddAuditDetails(c, map[string]string{"previous_role": "user"})
+			AddAuditDetails(c, map[string]string{"role": "admin"})
+			c.Status(status)
+		})
+
+		req := httptest.NewRequest(http.MethodPatch, "/admin/users/7/role", nil)
+		req.Header.Set("User-Agent", "test-agent")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.StatusForbidden, &auth.Session{UserID: "1"})
+	assert.Empty(t, adapter.events, "failed requests are not audited")
+
+	serve(http.StatusOK, &auth.Session{UserID: "1"})
+	require.Len(t, adapter.events, 1)
+	event := adapter.events[0]
+	assert.Equal(t, auth.AuditUserRoleChanged, event.Type)
+	assert.Equal(t, "1", event.ActorID)
+	assert.Equal(t, "7", event.UserID)
+	assert.Equal(t, "test-agent", event.UserAgent)
+	assert.Equal(t, "/admin/users/:user_id/role", event.Details["route"])
+	assert.Equal(t, "7", event.Details["user_id"])
+	assert.Equal(t, "user", event.Details["previous_role"], "handlers add their own details")
+	assert.Equal(t, "admin", event.Details["role"])
+
+	serve(http.StatusOK, &auth.Session{UserID: "3", ImpersonatorID: "1"})
+	require.Len(t, adapter.events, 2)
+	event = adapter.events[1]
+	assert.Equal(t, "1", event.ActorID, "the admin behind an impersonation is the actor")
+	assert.Equal(t, "3", event.Details["impersonated_user_id"])
+}
@@ -4,6 +4,7 @@ package middleware
 import (
 	"errors"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,6 +18,9 @@ const (
 	SessionCookieName = "session_id"
 	// SessionHeaderName is the name of the session header (for API clients)
 	SessionHeaderName = "X-Session-ID"
+	// ImpersonatorCookieName keeps the admin's own session while they
+	// impersonate a user, so that stopping brings it back
+	ImpersonatorCookieName = "impersonator_session_id"
 )
 
 // AuthMiddlewareOptions controls which authentication channels are accepted.
@@ -42,6 +46,17 @@ func DefaultAuthMiddlewareOptions() AuthMiddlewareOptions {
 // 2. The X-Session-ID header
 // 3. A cookie named "session_id"
 //
+// A Bearer credential starting with auth.APITokenPrefix is a personal access
+// token instead; it is accepted whatever the header setting, sets "apiToken"
+// instead of the session keys, and must carry the "read" scope for GET and
+// HEAD requests and the "write" scope for any other.
+//
+// A Bearer credential starting with auth.ClientAccessTokenPrefix was issued to
+// an OAuth client. It follows the same scope rules, but the request acts for
+// a machine rather than a user: "principalType" is auth.PrincipalClient,
+// "clientID" and "role" are set from the client, "permissions" from its role,
+// and "userID" is not set.
+//
 // If validation succeeds, it adds user info to the request context.
 func AuthMiddleware(authManager *auth.AuthManager, options ...AuthMiddlewareOptions) gin.HandlerFunc {
 	authOptions := DefaultAuthMiddlewareOptions()
@@ -55,13 +70,25 @@ func AuthMiddleware(authManager *auth.AuthManager, options ...AuthMiddlewareOpti
 	}
 
 	return func(c *gin.Context) {
-		sessionID := extractSessionID(c, authOptions)
+		switch token := extractBearerToken(c); {
+		case auth.IsAPIToken(token):
+			authenticateAPIToken(c, authManager, token)
+			return
+		case auth.IsClientAccessToken(token):
+			authenticateClientAccessToken(c, authManager, token)
+			return
+		}
+
+		sessionID, channel := extractSessionID(c, authOptions)
 		if sessionID == "" {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "autorização necessária"})
 			return
 		}
 
-		session, user, err := authManager.ValidateSession(sessionID)
+		session, user, err := authManager.ValidateSession(sessionID, auth.SessionMetadata{
+			UserAgent: c.Request.UserAgent(),
+			IP:        c.ClientIP(),
+		})
 		if err != nil {
 			status := http.StatusUnauthorized
 			message := "sessão inválida"
@@ -71,6 +98,8 @@ func AuthMiddleware(authManager *auth.AuthManager, options ...AuthMiddlewareOpti
 				message = "sessão expirada"
 			case errors.Is(err, auth.ErrSessionNotFound):
 				message = "sessão não encontrada"
+			case errors.Is(err, auth.ErrSessionRevoked):
+				message = "sessão revogada"
 			case errors.Is(err, auth.ErrUserNotActive):
 				message = "usuário inativo"
 			}
@@ -83,16 +112,28 @@ func AuthMiddleware(authManager *auth.AuthManager, options ...AuthMiddlewareOpti
 			return
 		}
 
+		// A rotated session comes back under a new token; header clients
+		// read it from the response header, browsers get a new cookie and
+		// never see the token
+		fromHeader := channel == sessionChannelHeader
+		if fromHeader && session.ID != sessionID {
+			c.Header(SessionHeaderName, session.ID)
+		}
+
 		// Store user info in context
+		c.Set("principalType", auth.PrincipalUser)
 		c.Set("userID", user.ID)
 		c.Set("role", user.Role)
+		c.Set("permissions", user.Permissions)
 		c.Set("user", user)
 		c.Set("session", session)
-		c.Set("sessionID", sessionID)
+		c.Set("sessionID", session.ID)
+		c.Set("sessionPublicID", session.PublicID)
+		c.Set("sessionFromHeader", fromHeader)
 
-		// If session was refreshed, update the cookie
+		// If session was refreshed or rotated, update the cookie
 		if authOptions.AllowCookieAuth && session.Fresh && c.Request.Method != http.MethodOptions {
-			SetSessionCookie(c, sessionID, session.ExpiresAt, authOptions.CookieSecure)
+			SetSessionCookie(c, session.ID, session.ExpiresAt, authOptions.CookieSecure)
 		}
 
 		c.Next()
@@ -121,33 +162,279 @@ func RoleMiddleware(roles ...string) gin.HandlerFunc {
 	}
 }
 
-// extractSessionID extracts the session ID from the request.
-// Priority: Authorization header > X-Session-ID header > Cookie
-func extractSessionID(c *gin.Context, options AuthMiddlewareOptions) string {
+// RequirePermission creates a middleware that lets a request through only if
+// its principal holds every one of the permissions.
+//
+// It expects "permissions" to be set in the context by AuthMiddleware or
+// AccessTokenMiddleware: the user's effective permissions, or the role's for
+// OAuth clients.
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get("permissions")
+		if !exists {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "usuário não autenticado"})
+			return
+		}
+
+		held, _ := value.([]string)
+		for _, permission := range permissions {
+			if !slices.Contains(held, permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permissão necessária: " + permission})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// authenticateAPIToken is AuthMiddleware for personal access tokens. They are
+// not sessions: nothing is refreshed and no cookie is set.
+func authenticateAPIToken(c *gin.Context, authManager *auth.AuthManager, token string) {
+	apiToken, user, err := authManager.ValidateAPIToken(token, auth.SessionMetadata{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		message := "token de API inválido"
+		switch {
+		case errors.Is(err, auth.ErrAPITokenExpired):
+			message = "token de API expirado"
+		case errors.Is(err, auth.ErrUserNotActive):
+			message = "usuário inativo"
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+		return
+	}
+
+	if scope := methodScope(c); !apiToken.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "o token de API não tem o escopo " + scope})
+		return
+	}
+
+	c.Set("principalType", auth.PrincipalUser)
+	c.Set("userID", user.ID)
+	c.Set("role", user.Role)
+	c.Set("permissions", user.Permissions)
+	c.Set("user", user)
+	c.Set("apiToken", apiToken)
+
+	c.Next()
+}
+
+// authenticateClientAccessToken is AuthMiddleware for tokens issued to OAuth
+// clients through the client credentials grant
+func authenticateClientAccessToken(c *gin.Context, authManager *auth.AuthManager, token string) {
+	clientToken, client, err := authManager.ValidateClientAccessToken(token)
+	if err != nil {
+		message := "token de cliente inválido"
+		if errors.Is(err, auth.ErrClientAccessTokenExpired) {
+			message = "token de cliente expirado"
+		}
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": message})
+		return
+	}
+
+	if scope := methodScope(c); !clientToken.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "o token de cliente não tem o escopo " + scope})
+		return
+	}
+
+	c.Set("principalType", auth.PrincipalClient)
+	c.Set("clientID", client.ID)
+	c.Set("role", client.Role)
+	c.Set("permissions", auth.RolePermissions(client.Role))
+	c.Set("clientAccessToken", clientToken)
+
+	c.Next()
+}
+
+// methodScope returns the scope a token needs for the request's method
+func methodScope(c *gin.Context) string {
+	if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+		return auth.APITokenScopeRead
+	}
+	return auth.APITokenScopeWrite
+}
+
+// RequirePrincipal creates a middleware that only lets through requests
+// acting for the given principal types, e.g. routes meant for machines only.
+//
+// It expects "principalType" to be set in the context by AuthMiddleware.
+func RequirePrincipal(types ...auth.PrincipalType) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principalType, ok := c.Get("principalType")
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "usuário não autenticado"})
+			return
+		}
+		if !slices.Contains(types, principalType.(auth.PrincipalType)) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "acesso negado"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope creates a middleware that lets personal access tokens, OAuth
+// client tokens and signed access tokens through only if they carry the
+// scope. Sessions are not affected.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiToken, ok := c.Get("apiToken"); ok && !apiToken.(*auth.APIToken).HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "o token de API não tem o escopo " + scope})
+			return
+		}
+		if clientToken, ok := c.Get("clientAccessToken"); ok && !clientToken.(*auth.ClientAccessToken).HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "o token de cliente não tem o escopo " + scope})
+			return
+		}
+		if claims, ok := c.Get("accessToken"); ok && !claims.(*auth.AccessTokenClaims).HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "o token de acesso não tem o escopo " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireSession creates a middleware for routes that manage credentials,
+// which personal access tokens and OAuth clients must not reach even with
+// every scope.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireSession(c) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireSession aborts the request unless AuthMiddleware authenticated it
+// with a session, and reports whether it did
+func requireSession(c *gin.Context) bool {
+	if _, ok := c.Get("apiToken"); ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "não disponível para tokens de API"})
+		return false
+	}
+	if _, ok := c.Get("clientAccessToken"); ok {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "não disponível para clientes OAuth"})
+		return false
+	}
+	if _, ok := c.Get("session"); !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "usuário não autenticado"})
+		return false
+	}
+	return true
+}
+
+// RequireRecentAuth creates a middleware for sensitive actions that demand
+// the user confirmed their password recently ("sudo mode").
+//
+// It expects the session to be set in the context by AuthMiddleware, so
+// personal access tokens are refused like RequireSession does. When the
+// confirmation is missing or too old it responds 403 with
+// "reauthentication_required": true, telling the frontend to ask for the
+// password, POST it to /api/account/reauthenticate and retry.
+func RequireRecentAuth(authManager *auth.AuthManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !requireSession(c) {
+			return
+		}
+
+		session := c.MustGet("session").(*auth.Session)
+		if !authManager.RecentlyAuthenticated(session) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":                     "confirme sua senha para continuar",
+				"reauthentication_required": true,
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireNoImpersonation creates a middleware for actions an admin must not
+// take on the user's behalf while impersonating them, such as changing the
+// password, enrolling a second factor or creating tokens.
+func RequireNoImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if session, ok := c.Get("session"); ok && session.(*auth.Session).Impersonating() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "não disponível durante a personificação"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// sessionChannel is the part of the request a session token came in
+type sessionChannel int
+
+const (
+	sessionChannelNone sessionChannel = iota
+	// sessionChannelHeader is the Authorization or X-Session-ID header
+	sessionChannelHeader
+	// sessionChannelCookie is the HttpOnly session cookie
+	sessionChannelCookie
+)
+
+// extractSessionID extracts the session ID from the request and the channel
+// it came in. Priority: Authorization header > X-Session-ID header > Cookie
+func extractSessionID(c *gin.Context, options AuthMiddlewareOptions) (string, sessionChannel) {
 	if options.AllowHeaderAuth {
 		// Try Authorization header first (for API clients)
 		authHeader := c.GetHeader("Authorization")
 		if authHeader != "" {
 			parts := strings.Split(authHeader, " ")
 			if len(parts) == 2 && parts[0] == "Bearer" {
-				return parts[1]
+				return parts[1], sessionChannelHeader
 			}
 		}
 
 		// Try X-Session-ID header
 		if sessionID := c.GetHeader(SessionHeaderName); sessionID != "" {
-			return sessionID
+			return sessionID, sessionChannelHeader
 		}
 	}
 
 	if options.AllowCookieAuth {
 		// Try cookie
 		if cookie, err := c.Cookie(SessionCookieName); err == nil {
-			return cookie
+			return cookie, sessionChannelCookie
 		}
 	}
 
-	return ""
+	return "", sessionChannelNone
+}
+
+// extractBearerToken returns the Bearer credential in the Authorization
+// header, or "" if the request carries none
+func extractBearerToken(c *gin.Context) string {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// RequestSessionID returns the session token the request carries in any of
+// the channels AuthMiddleware accepts, or "" if there is none. Routes outside
+// AuthMiddleware use it to find a session they are about to replace.
+func RequestSessionID(c *gin.Context) string {
+	sessionID, _ := extractSessionID(c, DefaultAuthMiddlewareOptions())
+	return sessionID
+}
+
+// SetRotatedSession hands a session token that replaced the request's one to
+// the client: in the session cookie and, when AuthMiddleware authenticated
+// the request by header, in the session header. Cookie clients never get the
+// token where scripts can read it.
+func SetRotatedSession(c *gin.Context, session *auth.Session, secure bool) {
+	if c.GetBool("sessionFromHeader") {
+		c.Header(SessionHeaderName, session.ID)
+	}
+	c.Set("sessionID", session.ID)
+	SetSessionCookie(c, session.ID, session.ExpiresAt, secure)
 }
 
 // SetSessionCookie sets the session cookie in the response.
@@ -172,6 +459,21 @@ func SetSessionCookie(c *gin.Context, sessionID string, expiresAt time.Time, sec
 	)
 }
 
+// SetImpersonatorCookie keeps the admin's own session token while they
+// impersonate a user, until the impersonation expires
+func SetImpersonatorCookie(c *gin.Context, sessionID string, expiresAt time.Time, secure bool) {
+	maxAge := int(time.Until(expiresAt).Seconds())
+	if maxAge <= 0 {
+		return
+	}
+	c.SetCookie(ImpersonatorCookieName, sessionID, maxAge, "/", "", secure, true)
+}
+
+// ClearImpersonatorCookie removes the cookie set by SetImpersonatorCookie
+func ClearImpersonatorCookie(c *gin.Context, secure bool) {
+	c.SetCookie(ImpersonatorCookieName, "", -1, "/", "", secure, true)
+}
+
 // ClearSessionCookie removes the session cookie
 func ClearSessionCookie(c *gin.Context, secure ...bool) {
 	cookieSecure := true
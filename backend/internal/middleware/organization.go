@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentOrganization is the :org_id that stands for the session's active
+// organization, as in /api/orgs/current/members
+const CurrentOrganization = "current"
+
+// RequireOrganization creates a middleware for /api/orgs/:org_id routes. It
+// resolves the organization from the route, lets the request through only if
+// the user belongs to it, and sets "organizationID" and "organizationMember"
+// in the context.
+//
+// It expects "userID" to be set by AuthMiddleware, so OAuth clients are
+// refused. An organization the user does not belong to answers 404, like one
+// that does not exist.
+func RequireOrganization(authManager *auth.AuthManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("userID")
+		if userID == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "organizações são apenas para usuários"})
+			return
+		}
+
+		orgID := c.Param("org_id")
+		if orgID == CurrentOrganization {
+			value, _ := c.Get("session")
+			session, ok := value.(*auth.Session)
+			if !ok || session.ActiveOrganizationID == "" {
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "nenhuma organização ativa"})
+				return
+			}
+			orgID = session.ActiveOrganizationID
+		}
+
+		member, err := authManager.OrganizationMember(orgID, userID)
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrOrganizationNotFound):
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "organização não encontrada"})
+			case errors.Is(err, auth.ErrOrganizationsNotSupported):
+				c.AbortWithStatusJSON(http.StatusNotImplemented, gin.H{"error": "organizações indisponíveis"})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro ao carregar a organização"})
+			}
+			return
+		}
+
+		c.Set("organizationID", member.OrganizationID)
+		c.Set("organizationMember", member)
+		c.Next()
+	}
+}
+
+// RequireOrganizationRole creates a middleware that lets a request through
+// only if the user's role in the organization is at least minRole.
+//
+// It expects "organizationMember" to be set by RequireOrganization.
+func RequireOrganizationRole(minRole string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("organizationMember")
+		member, ok := value.(*auth.OrganizationMember)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "organização não encontrada"})
+			return
+		}
+
+		if !auth.OrganizationRoleAtLeast(member.Role, minRole) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "papel necessário na organização: " + minRole})
+			return
+		}
+
+		c.Next()
+	}
+}
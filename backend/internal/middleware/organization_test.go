@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireOrganization(t *testing.T) {
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{}, &models.Organization{}, &models.OrganizationMembership{}, &models.OrganizationInvitation{})
+	authManager := auth.NewAuthManager(gormadapter.NewUserAdapter(db), gormadapter.NewSessionAdapter(db), auth.DefaultAuthConfig())
+	organizationAdapter := gormadapter.NewOrganizationAdapter(db)
+	authManager.SetOrganizationAdapter(organizationAdapter)
+
+	owner := &models.User{Username: "owner", Email: "owner@example.com", DisplayName: "Owner", PasswordHash: "unused", Active: true}
+	member := &models.User{Username: "member", Email: "member@example.com", DisplayName: "Member", PasswordHash: "unused", Active: true}
+	outsider := &models.User{Username: "outsider", Email: "outsider@example.com", DisplayName: "Outsider", PasswordHash: "unused", Active: true}
+	require.NoError(t, db.Create(owner).Error)
+	require.NoError(t, db.Create(member).Error)
+	require.NoError(t, db.Create(outsider).Error)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	memberID := strconv.FormatUint(uint64(member.ID), 10)
+	outsiderID := strconv.FormatUint(uint64(outsider.ID), 10)
+
+	org, err := authManager.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+	require.NoError(t, organizationAdapter.AddOrganizationMember(org.ID, memberID, auth.OrganizationRoleMember))
+
+	serve := func(method, userID, path string, session *auth.Session) *httptest.ResponseRecorder {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if userID != "" {
+				c.Set("userID", userID)
+			}
+			if session != nil {
+				c.Set("session", session)
+			}
+			c.Next()
+		})
+		orgs := r.Group("/orgs/:org_id")
+		orgs.Use(RequireOrganization(authManager))
+		orgs.GET("", func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("organizationID"))
+		})
+		orgs.DELETE("", RequireOrganizationRole(auth.OrganizationRoleOwner), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Member", func(t *testing.T) {
+		w := serve("GET", memberID, "/orgs/"+org.ID, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, org.ID, w.Body.String())
+	})
+
+	t.Run("Outsider Sees Not Found", func(t *testing.T) {
+		w := serve("GET", outsiderID, "/orgs/"+org.ID, nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Unknown Organization", func(t *testing.T) {
+		w := serve("GET", ownerID, "/orgs/999", nil)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("No User", func(t *testing.T) {
+		w := serve("GET", "", "/orgs/"+org.ID, nil)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Current Organization", func(t *testing.T) {
+		w := serve("GET", memberID, "/orgs/current", &auth.Session{UserID: memberID, ActiveOrganizationID: org.ID})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, org.ID, w.Body.String())
+
+		w = serve("GET", memberID, "/orgs/current", &auth.Session{UserID: memberID})
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Role Too Low", func(t *testing.T) {
+		w := serve("DELETE", memberID, "/orgs/"+org.ID, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"error":"papel necessário na organização: owner"}`, w.Body.String())
+
+		w = serve("DELETE", ownerID, "/orgs/"+org.ID, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// APIToken is a personal access token. TokenHash is the SHA-256 of the token,
+// which is shown to the user once and never stored; Hint keeps its last
+// characters. Scopes is a space-separated list.
+type APIToken struct {
+	ID         uint       `json:"id"                     gorm:"primaryKey"`
+	UserID     uint       `json:"user_id"                gorm:"index;not null"`
+	Name       string     `json:"name"                   gorm:"type:varchar(100);not null"`
+	TokenHash  string     `json:"-"                      gorm:"type:varchar(64);uniqueIndex;not null"`
+	Hint       string     `json:"hint"                   gorm:"type:varchar(8);not null"`
+	Scopes     string     `json:"scopes"                 gorm:"type:varchar(255);not null"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty" gorm:"type:varchar(45)"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// AuditEvent records a security-relevant action. ActorID is who acted and
+// UserID whose account it concerns; Details is a JSON object of strings.
+type AuditEvent struct {
+	ID        uint      `json:"id"                   gorm:"primaryKey"`
+	Type      string    `json:"type"                 gorm:"type:varchar(64);index;not null"`
+	ActorID   *uint     `json:"actor_id,omitempty"   gorm:"index"`
+	UserID    *uint     `json:"user_id,omitempty"    gorm:"index"`
+	IP        string    `json:"ip,omitempty"         gorm:"type:varchar(45)"`
+	UserAgent string    `json:"user_agent,omitempty" gorm:"type:varchar(500)"`
+	Details   string    `json:"details,omitempty"    gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"           gorm:"index;not null"`
+}
+
+// TableName specifies the table name for GORM
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
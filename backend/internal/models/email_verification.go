@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerificationToken stores a pending email verification. The ID is the
+// SHA-256 hash of the token emailed to the user and Email is the address it
+// was sent to.
+type EmailVerificationToken struct {
+	ID        string    `json:"-"          gorm:"primaryKey;type:varchar(64)"`
+	UserID    uint      `json:"user_id"    gorm:"index;not null"`
+	Email     string    `json:"email"      gorm:"not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailVerificationToken) TableName() string {
+	return "email_verification_tokens"
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an external OAuth/OIDC provider.
+// Subject is the provider's stable user ID, unique per provider.
+type UserIdentity struct {
+	ID        uint      `json:"id"         gorm:"primaryKey"`
+	UserID    uint      `json:"user_id"    gorm:"index;not null"`
+	Provider  string    `json:"provider"   gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Subject   string    `json:"-"          gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identities_provider_subject"`
+	Email     string    `json:"email"      gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OAuthState stores a social login between the redirect to the provider and
+// its callback. The ID is the SHA-256 hash of the state parameter.
+type OAuthState struct {
+	ID           string    `json:"-"          gorm:"primaryKey;type:varchar(64)"`
+	Provider     string    `json:"provider"   gorm:"type:varchar(50);not null"`
+	CodeVerifier string    `json:"-"          gorm:"type:varchar(128);not null"`
+	Nonce        string    `json:"-"          gorm:"type:varchar(128);not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+// KnownDevice is a device a user has signed in from, identified by the user
+// agent's browser and OS family and the IP address's network. RevokeTokenHash
+// is the SHA-256 of the token emailed when a sign-in from the device was
+// reported as new; it ends SessionPublicID until RevokeExpiresAt.
+type KnownDevice struct {
+	ID              uint      `json:"id"                gorm:"primaryKey"`
+	UserID          uint      `json:"user_id"           gorm:"uniqueIndex:idx_known_devices_user_device;not null"`
+	UserAgentFamily string    `json:"user_agent_family" gorm:"type:varchar(255);uniqueIndex:idx_known_devices_user_device;not null"`
+	IPNetwork       string    `json:"ip_network"        gorm:"type:varchar(64);uniqueIndex:idx_known_devices_user_device;not null"`
+	LastIP          string    `json:"last_ip,omitempty" gorm:"type:varchar(45)"`
+	CreatedAt       time.Time `json:"created_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"      gorm:"index;not null"`
+
+	SessionPublicID string     `json:"-" gorm:"type:varchar(32)"`
+	RevokeTokenHash string     `json:"-" gorm:"index;type:varchar(64)"`
+	RevokeExpiresAt *time.Time `json:"-"`
+}
+
+// TableName specifies the table name for GORM
+func (KnownDevice) TableName() string {
+	return "known_devices"
+}
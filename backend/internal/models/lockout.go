@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// LoginLockout tracks failed login attempts for an identifier so lockouts
+// survive restarts and are shared between replicas
+type LoginLockout struct {
+	Identifier     string    `json:"identifier"      gorm:"primaryKey;type:varchar(255)"`
+	FailedAttempts int       `json:"failed_attempts" gorm:"not null;default:0"`
+	LastFailedAt   time.Time `json:"last_failed_at"  gorm:"not null;index"`
+	LockedUntil    time.Time `json:"locked_until"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (LoginLockout) TableName() string {
+	return "login_lockouts"
+}
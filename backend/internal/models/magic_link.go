@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// MagicLinkToken stores a pending passwordless login link. The ID is the
+// SHA-256 hash of the token emailed to the user.
+type MagicLinkToken struct {
+	ID        string    `json:"-"          gorm:"primaryKey;type:varchar(64)"`
+	UserID    uint      `json:"user_id"    gorm:"index;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (MagicLinkToken) TableName() string {
+	return "magic_link_tokens"
+}
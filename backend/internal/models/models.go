@@ -28,7 +28,7 @@ type User struct {
 
 	// Access control
 	Role        string `json:"role"                  gorm:"default:user"`
-	Permissions string `json:"permissions,omitempty" gorm:"type:text"` // JSON string of permissions
+	Permissions string `json:"permissions,omitempty" gorm:"type:text"` // JSON {"grant": [...], "deny": [...]}, see auth.PermissionOverrides
 
 	// Password reset (kept separate from session management)
 	ResetToken       string    `json:"-"`
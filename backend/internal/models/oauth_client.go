@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// OAuthClient is a machine client using the client credentials grant.
+// SecretHash is the SHA-256 of the secret, which is shown to the admin once
+// and never stored. Scopes is a space-separated list.
+type OAuthClient struct {
+	ID         uint      `json:"id"         gorm:"primaryKey"`
+	ClientID   string    `json:"client_id"  gorm:"type:varchar(64);uniqueIndex;not null"`
+	Name       string    `json:"name"       gorm:"type:varchar(100);not null"`
+	SecretHash string    `json:"-"          gorm:"type:varchar(64);not null"`
+	Role       string    `json:"role"       gorm:"type:varchar(20);not null"`
+	Scopes     string    `json:"scopes"     gorm:"type:varchar(255);not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+// OAuthClientToken is an access token issued to an OAuth client. TokenHash is
+// the SHA-256 of the token.
+type OAuthClientToken struct {
+	ID        uint      `json:"id"         gorm:"primaryKey"`
+	ClientID  string    `json:"client_id"  gorm:"type:varchar(64);index;not null"`
+	TokenHash string    `json:"-"          gorm:"type:varchar(64);uniqueIndex;not null"`
+	Scopes    string    `json:"scopes"     gorm:"type:varchar(255);not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"index;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OAuthClientToken) TableName() string {
+	return "oauth_client_tokens"
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// Organization groups users who share resources.
+type Organization struct {
+	ID        uint      `json:"id"         gorm:"primaryKey"`
+	Name      string    `json:"name"       gorm:"type:varchar(100);index;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// TableName specifies the table name for GORM
+func (Organization) TableName() string {
+	return "organizations"
+}
+
+// OrganizationMembership gives a user a role (owner, admin or member) in an
+// organization.
+type OrganizationMembership struct {
+	ID             uint      `json:"id"              gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_organization_memberships_org_user;not null"`
+	UserID         uint      `json:"user_id"         gorm:"uniqueIndex:idx_organization_memberships_org_user;index;not null"`
+	Role           string    `json:"role"            gorm:"type:varchar(20);not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OrganizationMembership) TableName() string {
+	return "organization_memberships"
+}
+
+// OrganizationInvitation is a pending invitation for an email address to
+// join an organization with a role. Only the hash of the emailed token is
+// stored.
+type OrganizationInvitation struct {
+	ID             uint      `json:"id"              gorm:"primaryKey"`
+	OrganizationID uint      `json:"organization_id" gorm:"uniqueIndex:idx_organization_invitations_org_email;not null"`
+	Email          string    `json:"email"           gorm:"type:varchar(255);uniqueIndex:idx_organization_invitations_org_email;not null"`
+	Role           string    `json:"role"            gorm:"type:varchar(20);not null"`
+	InviterID      uint      `json:"inviter_id"      gorm:"not null"`
+	TokenHash      string    `json:"-"               gorm:"type:varchar(64);uniqueIndex;not null"`
+	ExpiresAt      time.Time `json:"expires_at"      gorm:"index;not null"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (OrganizationInvitation) TableName() string {
+	return "organization_invitations"
+}
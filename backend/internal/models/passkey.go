@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// WebAuthnCredential stores a passkey registered by a user. CredentialID is
+// the base64url-encoded raw ID and Data the serialized credential record.
+type WebAuthnCredential struct {
+	ID           uint       `json:"id"                     gorm:"primaryKey"`
+	UserID       uint       `json:"user_id"                gorm:"index;not null"`
+	CredentialID string     `json:"-"                      gorm:"type:varchar(1400);uniqueIndex;not null"`
+	Name         string     `json:"name"                   gorm:"type:varchar(100);not null"`
+	Data         string     `json:"-"                      gorm:"type:text;not null"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WebAuthnCredential) TableName() string {
+	return "webauthn_credentials"
+}
+
+// WebAuthnCeremony stores a registration or login ceremony between its begin
+// and finish steps. The ID is the SHA-256 hash of the token handed to the client.
+type WebAuthnCeremony struct {
+	ID        string    `json:"-"          gorm:"primaryKey;type:varchar(64)"`
+	UserID    *uint     `json:"user_id"    gorm:"index"`
+	Kind      string    `json:"kind"       gorm:"type:varchar(20);not null"`
+	Data      string    `json:"-"          gorm:"type:text;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WebAuthnCeremony) TableName() string {
+	return "webauthn_ceremonies"
+}
@@ -4,14 +4,33 @@ import (
 	"time"
 )
 
-// Session represents an authentication session stored in the database
+// Session represents an authentication session stored in the database. ID is
+// the SHA-256 of the session token, which is never stored; PublicID names the
+// session in listings and revocation requests. PreviousID is the hash of the
+// token replaced by the last rotation, still accepted until PreviousExpiresAt.
+// ReauthenticatedAt is when the user last re-entered their password in it.
+// ImpersonatorID is set on sessions an admin created to act as the user.
+// ActiveOrganizationID is the organization the user is working in.
 type Session struct {
-	ID        string    `json:"id"                   gorm:"primaryKey;type:varchar(64)"`
-	UserID    uint      `json:"user_id"              gorm:"index;not null"`
-	ExpiresAt time.Time `json:"expires_at"           gorm:"not null;index"`
-	CreatedAt time.Time `json:"created_at"`
-	UserAgent string    `json:"user_agent,omitempty" gorm:"type:varchar(500)"`
-	IP        string    `json:"ip,omitempty"         gorm:"type:varchar(45)"` // Supports IPv6
+	ID         string    `json:"-"                    gorm:"primaryKey;type:varchar(64)"`
+	PublicID   string    `json:"id"                   gorm:"uniqueIndex;not null;type:varchar(32)"`
+	UserID     uint      `json:"user_id"              gorm:"index;not null"`
+	ExpiresAt  time.Time `json:"expires_at"           gorm:"not null;index"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  string    `json:"user_agent,omitempty" gorm:"type:varchar(500)"`
+	IP         string    `json:"ip,omitempty"         gorm:"type:varchar(45)"` // Supports IPv6
+
+	PreviousID        string     `json:"-" gorm:"index;type:varchar(64)"`
+	PreviousExpiresAt *time.Time `json:"-"`
+	RotationPending   bool       `json:"-" gorm:"not null;default:false"`
+	ReportedAnomalies string     `json:"-" gorm:"type:text;not null;default:''"` // space-separated
+	ReauthenticatedAt *time.Time `json:"-"`
+
+	ImpersonatorID      *uint  `json:"-" gorm:"index"`
+	ImpersonationReason string `json:"-" gorm:"type:varchar(255)"`
+
+	ActiveOrganizationID *uint `json:"-"`
 }
 
 // TableName specifies the table name for GORM
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// TOTPCredential stores a user's TOTP second factor
+type TOTPCredential struct {
+	UserID       uint       `json:"user_id"                gorm:"primaryKey;autoIncrement:false"`
+	Secret       string     `json:"-"                      gorm:"type:varchar(64);not null"`
+	Enabled      bool       `json:"enabled"                gorm:"not null;default:false"`
+	LastUsedStep int64      `json:"-"                      gorm:"not null;default:0"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TOTPCredential) TableName() string {
+	return "user_totp_credentials"
+}
+
+// TwoFactorChallenge stores a login that is waiting for its second factor.
+// The ID is the SHA-256 hash of the token handed to the client.
+type TwoFactorChallenge struct {
+	ID        string    `json:"-"          gorm:"primaryKey;type:varchar(64)"`
+	UserID    uint      `json:"user_id"    gorm:"index;not null"`
+	Attempts  int       `json:"attempts"   gorm:"not null;default:0"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TwoFactorChallenge) TableName() string {
+	return "two_factor_challenges"
+}
+
+// RecoveryCode stores the SHA-256 hash of a single-use second-factor
+// recovery code
+type RecoveryCode struct {
+	ID        uint       `json:"id"                gorm:"primaryKey"`
+	UserID    uint       `json:"user_id"           gorm:"not null;uniqueIndex:idx_recovery_codes_user_code"`
+	CodeHash  string     `json:"-"                 gorm:"type:varchar(64);not null;uniqueIndex:idx_recovery_codes_user_code"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (RecoveryCode) TableName() string {
+	return "two_factor_recovery_codes"
+}
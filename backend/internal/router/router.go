@@ -7,6 +7,7 @@ import (
 
 	"gosveltekit/internal/auth"
 	"gosveltekit/internal/handlers"
+	"gosveltekit/internal/maintenance"
 	"gosveltekit/internal/middleware"
 
 	"github.com/gin-gonic/gin"
@@ -55,6 +56,9 @@ func SetupRouter(
 		})
 	})
 
+	// Public keys for the signed access tokens issued by POST /api/token
+	r.GET("/.well-known/jwks.json", authHandler.AccessTokenJWKS)
+
 	// Rate limiter for auth routes (brute force prevention)
 	authLimiter := middleware.NewIPRateLimiter(
 		rate.Limit(authRateLimitPerSecond),
@@ -69,6 +73,21 @@ func SetupRouter(
 	authRoutes.POST("/register", authHandler.Register)
 	authRoutes.POST("/password-reset-request", authHandler.RequestPasswordReset)
 	authRoutes.POST("/password-reset", authHandler.ResetPassword)
+	authRoutes.POST("/2fa/verify", authHandler.VerifyTwoFactor)
+	authRoutes.POST("/webauthn/login/begin", authHandler.BeginPasskeyLogin)
+	authRoutes.POST("/webauthn/login/finish", authHandler.FinishPasskeyLogin)
+	authRoutes.POST("/magic-link", authHandler.RequestMagicLink)
+	authRoutes.POST("/magic-link/consume", authHandler.ConsumeMagicLink)
+	authRoutes.POST("/verify-email", authHandler.VerifyEmail)
+	authRoutes.POST("/verify-email/resend", authHandler.ResendVerificationEmail)
+	authRoutes.POST("/invitations/preview", authHandler.GetInvitation)
+	authRoutes.POST("/revoke-session", authHandler.RevokeSessionByLink)
+	authRoutes.GET("/oauth/providers", authHandler.ListOAuthProviders)
+	authRoutes.GET("/oauth/:provider/start", authHandler.StartOAuthLogin)
+	authRoutes.GET("/oauth/:provider/callback", authHandler.OAuthCallback)
+
+	// OAuth 2.0 token endpoint for machine clients (client credentials grant)
+	r.POST("/oauth/token", middleware.RateLimitMiddleware(authLimiter), authHandler.OAuthToken)
 
 	// Rate limiter for API (more permissive)
 	apiLimiter := middleware.NewIPRateLimiter(
@@ -89,23 +108,97 @@ func SetupRouter(
 	})
 
 	api.GET("/me", authHandler.GetCurrentUser)
-	api.POST("/logout", authHandler.Logout)
 	api.GET("/account/profile", authHandler.GetAccountProfile)
 	api.PATCH("/account/profile", authHandler.UpdateAccountProfile)
-	api.POST("/account/change-password", authHandler.ChangeAccountPassword)
 	api.GET("/account/sessions", authHandler.ListAccountSessions)
-	api.DELETE("/account/sessions/:session_id", authHandler.RevokeAccountSession)
+	api.GET("/account/2fa", authHandler.GetTwoFactorStatus)
+	api.GET("/account/passkeys", authHandler.ListPasskeys)
+	api.GET("/account/identities", authHandler.ListIdentities)
+	api.GET("/account/security-activity", authHandler.ListSecurityActivity)
 	api.GET("/examples/pagination/items", authHandler.ListMockPaginationItems)
 
-	// Admin only routes
+	// Account, organization and admin changes are recorded in the audit log
+	// once they succeed; logins, sessions and passwords are recorded by auth
+	audit := func(action string) gin.HandlerFunc {
+		return middleware.Audit(authManager, action)
+	}
+
+	// Credential management is out of reach of personal access tokens
+	session := api.Group("")
+	session.Use(middleware.RequireSession())
+	session.POST("/logout", authHandler.Logout)
+	session.GET("/account/tokens", authHandler.ListAPITokens)
+	session.POST("/impersonation/stop", authHandler.StopImpersonation)
+
+	// An admin impersonating the user cannot change their credentials
+	credentials := session.Group("")
+	credentials.Use(middleware.RequireNoImpersonation())
+	credentials.POST("/account/reauthenticate", authHandler.Reauthenticate)
+	credentials.DELETE("/account/tokens/:token_id", audit(auth.AuditAPITokenRevoked), authHandler.RevokeAPIToken)
+
+	// Sensitive account actions need a recent password confirmation. That
+	// includes adding a credential, which would otherwise let a stolen
+	// session keep access after the password is changed.
+	sudo := credentials.Group("")
+	sudo.Use(middleware.RequireRecentAuth(authManager))
+	sudo.POST("/account/change-password", authHandler.ChangeAccountPassword)
+	sudo.DELETE("/account/sessions/:session_id", authHandler.RevokeAccountSession)
+	sudo.POST("/account/2fa/setup", authHandler.SetupTwoFactor)
+	sudo.POST("/account/2fa/confirm", audit(auth.AuditTwoFactorEnabled), authHandler.ConfirmTwoFactor)
+	sudo.POST("/account/2fa/disable", audit(auth.AuditTwoFactorDisabled), authHandler.DisableTwoFactor)
+	sudo.POST("/account/2fa/recovery-codes", audit(auth.AuditRecoveryCodesGenerated), authHandler.RegenerateRecoveryCodes)
+	sudo.POST("/account/passkeys/register/begin", authHandler.BeginPasskeyRegistration)
+	sudo.POST("/account/passkeys/register/finish", audit(auth.AuditPasskeyAdded), authHandler.FinishPasskeyRegistration)
+	sudo.DELETE("/account/passkeys/:passkey_id", audit(auth.AuditPasskeyRemoved), authHandler.DeletePasskey)
+	sudo.POST("/account/tokens", audit(auth.AuditAPITokenCreated), authHandler.CreateAPIToken)
+	sudo.POST("/token", authHandler.IssueAccessToken)
+
+	// Organizations the user belongs to. :org_id is resolved and checked for
+	// membership once, "current" standing for the session's active one; routes
+	// that change the organization also need a role in it.
+	api.GET("/orgs", authHandler.ListOrganizations)
+	api.POST("/orgs", audit(auth.AuditOrganizationCreated), authHandler.CreateOrganization)
+	org := api.Group("/orgs/:org_id")
+	org.Use(middleware.RequireOrganization(authManager))
+	org.GET("", authHandler.GetOrganization)
+	org.DELETE("", middleware.RequireOrganizationRole(auth.OrganizationRoleOwner), audit(auth.AuditOrganizationDeleted), authHandler.DeleteOrganization)
+	org.GET("/members", authHandler.ListOrganizationMembers)
+	org.PATCH("/members/:user_id", middleware.RequireOrganizationRole(auth.OrganizationRoleAdmin), audit(auth.AuditOrganizationMemberRole), authHandler.UpdateOrganizationMemberRole)
+	org.DELETE("/members/:user_id", audit(auth.AuditOrganizationMemberRemoved), authHandler.RemoveOrganizationMember) // members may leave on their own
+	org.POST("/switch", middleware.RequireSession(), authHandler.SwitchOrganization)
+	invitations := org.Group("/invitations")
+	invitations.Use(middleware.RequireOrganizationRole(auth.OrganizationRoleAdmin))
+	invitations.GET("", authHandler.ListOrganizationInvitations)
+	invitations.POST("", audit(auth.AuditInvitationCreated), authHandler.InviteOrganizationMember)
+	invitations.POST("/:invitation_id/resend", audit(auth.AuditInvitationResent), authHandler.ResendOrganizationInvitation)
+	invitations.DELETE("/:invitation_id", audit(auth.AuditInvitationRevoked), authHandler.RevokeOrganizationInvitation)
+	api.POST("/invitations/accept", audit(auth.AuditInvitationAccepted), authHandler.AcceptInvitation)
+
+	// Admin routes, each behind the permission it needs
 	admin := api.Group("/admin")
-	admin.Use(middleware.RoleMiddleware("admin"))
+	admin.Use(middleware.RequirePermission(auth.PermissionAdminAccess))
+	admin.Use(middleware.RequireScope(auth.APITokenScopeAdmin))
 	admin.GET("/dashboard", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Admin Dashboard",
 		})
 	})
-	admin.GET("/users", authHandler.ListAdminUsers)
+	admin.GET("/users", middleware.RequirePermission(auth.PermissionUsersRead), authHandler.ListAdminUsers)
+	admin.PATCH("/users/:user_id/role", middleware.RequirePermission(auth.PermissionUsersWrite), audit(auth.AuditUserRoleChanged), authHandler.UpdateAdminUserRole)
+	admin.PUT("/users/:user_id/permissions", middleware.RequirePermission(auth.PermissionUsersWrite), audit(auth.AuditUserPermissionsChanged), authHandler.UpdateAdminUserPermissions)
+	admin.GET("/oauth-clients", middleware.RequirePermission(auth.PermissionOAuthClientsRead), authHandler.ListOAuthClients)
+	admin.GET("/organizations", middleware.RequirePermission(auth.PermissionOrganizationsRead), authHandler.ListAdminOrganizations)
+	admin.GET("/audit", middleware.RequirePermission(auth.PermissionAuditRead), authHandler.ListAuditEvents)
+
+	// Issuing machine credentials and impersonating users need an admin who
+	// recently confirmed their password
+	adminSudo := admin.Group("")
+	adminSudo.Use(middleware.RequireRecentAuth(authManager))
+	adminSudo.POST("/oauth-clients", middleware.RequirePermission(auth.PermissionOAuthClientsWrite), audit(auth.AuditOAuthClientCreated), authHandler.CreateOAuthClient)
+	adminSudo.DELETE("/oauth-clients/:client_id", middleware.RequirePermission(auth.PermissionOAuthClientsWrite), audit(auth.AuditOAuthClientDeleted), authHandler.DeleteOAuthClient)
+	adminSudo.POST("/users/:user_id/impersonate", middleware.RequirePermission(auth.PermissionUsersImpersonate), authHandler.ImpersonateUser)
+	// What the maintenance janitor ran and cleaned up
+	admin.GET("/metrics", middleware.RequirePermission(auth.PermissionMetricsRead), gin.WrapH(maintenance.MetricsHandler()))
 
 	return r
 }
@@ -2,6 +2,7 @@
 package router
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -17,6 +18,8 @@ import (
 	"gosveltekit/internal/testutil"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // MockAuthService implements service.AuthServiceInterface
@@ -33,7 +36,7 @@ func (m *MockAuthService) Login(username, password, ip, userAgent string) (*serv
 	}, nil
 }
 
-func (m *MockAuthService) ValidateSession(sessionID string) (*auth.Session, *auth.UserData, error) {
+func (m *MockAuthService) ValidateSession(sessionID string, metadata auth.SessionMetadata) (*auth.Session, *auth.UserData, error) {
 	return &auth.Session{
 			ID:        sessionID,
 			UserID:    "1",
@@ -57,6 +60,10 @@ func (m *MockAuthService) Register(username, email, password, displayName string
 	return &models.User{}, nil
 }
 
+func (m *MockAuthService) RegisterWithInvitation(token, username, password, displayName string) (*models.User, error) {
+	return &models.User{}, nil
+}
+
 func (m *MockAuthService) RequestPasswordReset(email string) error {
 	return nil
 }
@@ -95,10 +102,106 @@ func (m *MockAuthService) UpdateProfile(
 	}, nil
 }
 
-func (m *MockAuthService) ChangePassword(userID string, input service.ChangePasswordInput) error {
+func (m *MockAuthService) ChangePassword(userID, sessionID string, input service.ChangePasswordInput) (*auth.Session, error) {
+	return &auth.Session{ID: sessionID}, nil
+}
+
+func (m *MockAuthService) RotateSession(sessionID string) (*auth.Session, error) {
+	return &auth.Session{ID: sessionID}, nil
+}
+
+func (m *MockAuthService) Reauthenticate(sessionID, password string) (*service.ReauthenticationStatus, error) {
+	return &service.ReauthenticationStatus{}, nil
+}
+
+func (m *MockAuthService) UpdateUserRole(actorID, userID, role string) (*service.AdminUserUpdate, error) {
+	return &service.AdminUserUpdate{AdminUserRow: service.AdminUserRow{ID: userID, Role: role}}, nil
+}
+
+func (m *MockAuthService) UpdateUserPermissions(actorID, userID string, overrides auth.PermissionOverrides) (*service.AdminUserUpdate, error) {
+	return &service.AdminUserUpdate{AdminUserRow: service.AdminUserRow{ID: userID, PermissionOverrides: overrides}}, nil
+}
+
+func (m *MockAuthService) ImpersonateUser(adminID, targetUserID, reason, ip, userAgent string) (*service.LoginResponse, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) StopImpersonation(sessionID, ip, userAgent string) error {
+	return nil
+}
+
+func (m *MockAuthService) GetImpersonation(session *auth.Session) (*service.ImpersonationInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) ListOrganizations(userID string) ([]service.OrganizationInfo, error) {
+	return []service.OrganizationInfo{}, nil
+}
+
+func (m *MockAuthService) CreateOrganization(userID, name string) (*service.OrganizationInfo, error) {
+	return &service.OrganizationInfo{ID: "1", Name: name, Role: auth.OrganizationRoleOwner}, nil
+}
+
+func (m *MockAuthService) GetOrganization(orgID, userID string) (*service.OrganizationInfo, error) {
+	return &service.OrganizationInfo{ID: orgID}, nil
+}
+
+func (m *MockAuthService) DeleteOrganization(orgID, actorID string) error {
+	return nil
+}
+
+func (m *MockAuthService) ListOrganizationMembers(orgID string) ([]service.OrganizationMemberInfo, error) {
+	return []service.OrganizationMemberInfo{}, nil
+}
+
+func (m *MockAuthService) UpdateOrganizationMemberRole(orgID, actorID, userID, role string) (*service.OrganizationMemberInfo, error) {
+	return &service.OrganizationMemberInfo{UserID: userID, Role: role}, nil
+}
+
+func (m *MockAuthService) RemoveOrganizationMember(orgID, actorID, userID string) error {
+	return nil
+}
+
+func (m *MockAuthService) SwitchOrganization(sessionID, userID, orgID string) (*service.OrganizationInfo, error) {
+	return &service.OrganizationInfo{ID: orgID}, nil
+}
+
+func (m *MockAuthService) ListAdminOrganizations(input service.ListAdminOrganizationsInput) (*pagination.Response[service.AdminOrganizationRow], error) {
+	return &pagination.Response[service.AdminOrganizationRow]{}, nil
+}
+
+func (m *MockAuthService) ListAuditEvents(input service.ListAuditEventsInput) (*pagination.Response[service.AuditEventRow], error) {
+	return &pagination.Response[service.AuditEventRow]{}, nil
+}
+
+func (m *MockAuthService) ListSecurityActivity(userID string, cursor *pagination.CursorQuery) (*pagination.Response[service.AuditEventRow], error) {
+	return &pagination.Response[service.AuditEventRow]{}, nil
+}
+
+func (m *MockAuthService) InviteOrganizationMember(orgID, actorID string, input service.InviteOrganizationMemberInput) (*service.InvitationInfo, error) {
+	return &service.InvitationInfo{Email: input.Email, Role: input.Role}, nil
+}
+
+func (m *MockAuthService) ListOrganizationInvitations(orgID string) ([]service.InvitationInfo, error) {
+	return []service.InvitationInfo{}, nil
+}
+
+func (m *MockAuthService) ResendOrganizationInvitation(orgID, actorID, invitationID string) (*service.InvitationInfo, error) {
+	return &service.InvitationInfo{ID: invitationID}, nil
+}
+
+func (m *MockAuthService) RevokeOrganizationInvitation(orgID, actorID, invitationID string) error {
 	return nil
 }
 
+func (m *MockAuthService) GetInvitation(token string) (*service.InvitationPreview, error) {
+	return &service.InvitationPreview{}, nil
+}
+
+func (m *MockAuthService) AcceptInvitation(token, userID string) (*service.OrganizationInfo, error) {
+	return &service.OrganizationInfo{}, nil
+}
+
 func (m *MockAuthService) ListSessions(userID, currentSessionID string) ([]service.SessionInfo, error) {
 	return []service.SessionInfo{
 		{
@@ -132,6 +235,146 @@ func (m *MockAuthService) ListAdminUsers(
 	}, nil
 }
 
+func (m *MockAuthService) VerifyTwoFactor(challengeToken, code, ip, userAgent string) (*service.LoginResponse, error) {
+	return &service.LoginResponse{
+		SessionID: "mock-session-id",
+		ExpiresAt: time.Now().Add(time.Hour),
+		User: auth.UserData{
+			ID:         "1",
+			Identifier: "testuser",
+		},
+	}, nil
+}
+
+func (m *MockAuthService) GetTwoFactorStatus(userID string) (*service.TwoFactorStatus, error) {
+	return &service.TwoFactorStatus{}, nil
+}
+
+func (m *MockAuthService) SetupTOTP(userID string) (*service.TOTPSetup, error) {
+	return &service.TOTPSetup{}, nil
+}
+
+func (m *MockAuthService) ConfirmTOTP(userID, code string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) DisableTOTP(userID, password string) error {
+	return nil
+}
+
+func (m *MockAuthService) RegenerateRecoveryCodes(userID, password string) ([]string, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) BeginPasskeyLogin() (*service.PasskeyOptions, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) FinishPasskeyLogin(
+	ceremonyToken string,
+	credential []byte,
+	ip, userAgent string,
+) (*service.LoginResponse, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) ListPasskeys(userID string) ([]service.PasskeyInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) BeginPasskeyRegistration(userID string) (*service.PasskeyOptions, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) FinishPasskeyRegistration(
+	userID, ceremonyToken, name string,
+	credential []byte,
+) (*service.PasskeyInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) DeletePasskey(userID, passkeyID string) error {
+	return nil
+}
+
+func (m *MockAuthService) ListAPITokens(userID string) ([]service.APITokenInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) CreateAPIToken(userID string, input service.CreateAPITokenInput) (*service.CreatedAPIToken, error) {
+	return &service.CreatedAPIToken{}, nil
+}
+
+func (m *MockAuthService) RevokeAPIToken(userID, tokenID string) error {
+	return nil
+}
+
+func (m *MockAuthService) IssueAccessToken(userID, sessionPublicID string, scopes []string) (*service.AccessTokenResponse, error) {
+	return &service.AccessTokenResponse{}, nil
+}
+
+func (m *MockAuthService) AccessTokenJWKS() (*jose.JSONWebKeySet, error) {
+	return &jose.JSONWebKeySet{}, nil
+}
+
+func (m *MockAuthService) ListOAuthClients() ([]service.OAuthClientInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) CreateOAuthClient(actorID string, input service.CreateOAuthClientInput) (*service.CreatedOAuthClient, error) {
+	return &service.CreatedOAuthClient{}, nil
+}
+
+func (m *MockAuthService) DeleteOAuthClient(clientID string) error {
+	return nil
+}
+
+func (m *MockAuthService) IssueClientCredentialsToken(
+	clientID, clientSecret string,
+	scopes []string,
+) (*service.AccessTokenResponse, error) {
+	return &service.AccessTokenResponse{}, nil
+}
+
+func (m *MockAuthService) ListOAuthProviders() []string {
+	return nil
+}
+
+func (m *MockAuthService) BeginOAuthLogin(provider string) (*service.OAuthRedirect, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) CompleteOAuthLogin(
+	ctx context.Context,
+	provider, state, code, ip, userAgent string,
+) (*service.LoginResponse, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) ListIdentities(userID string) ([]service.IdentityInfo, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) RequestMagicLink(email string) error {
+	return nil
+}
+
+func (m *MockAuthService) LoginWithMagicLink(token, ip, userAgent string) (*service.LoginResponse, error) {
+	return nil, nil
+}
+
+func (m *MockAuthService) RevokeSessionByLink(token string) error {
+	return nil
+}
+
+func (m *MockAuthService) VerifyEmail(token string) error {
+	return nil
+}
+
+func (m *MockAuthService) ResendVerificationEmail(email string) error {
+	return nil
+}
+
 func NewMockAuthHandler() *handlers.AuthHandler {
 	mockAuthService := &MockAuthService{}
 	return handlers.NewAuthHandler(mockAuthService)
@@ -295,3 +538,73 @@ func TestProtectedRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestCredentialRoutesRequireRecentAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{Username: "testuser", Email: "test@example.com", PasswordHash: string(hash), Active: true, Role: "user"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	authManager := auth.NewAuthManager(gormadapter.NewUserAdapter(db), gormadapter.NewSessionAdapter(db), auth.DefaultAuthConfig())
+	router := SetupRouter(NewMockAuthHandler(), authManager)
+
+	session, _, err := authManager.Login("testuser", "password123", auth.SessionMetadata{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Signed in long enough ago that sudo mode is over
+	if err := db.Model(&models.Session{}).
+		Where("id = ?", auth.HashToken(session.ID)).
+		Update("created_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/account/2fa/setup"},
+		{http.MethodPost, "/api/account/2fa/confirm"},
+		{http.MethodPost, "/api/account/passkeys/register/begin"},
+		{http.MethodPost, "/api/account/passkeys/register/finish"},
+		{http.MethodPost, "/api/account/tokens"},
+		{http.MethodPost, "/api/token"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(route.method, route.path, nil)
+			req.Header.Set("Authorization", "Bearer "+session.ID)
+			router.ServeHTTP(w, req)
+
+			var response map[string]any
+			_ = json.Unmarshal(w.Body.Bytes(), &response)
+			if w.Code != http.StatusForbidden || response["reauthentication_required"] != true {
+				t.Errorf("expected a reauthentication prompt, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// Confirming the password opens them up again
+	if _, err := authManager.Reauthenticate(session.ID, "password123"); err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(route.method, route.path, nil)
+		req.Header.Set("Authorization", "Bearer "+session.ID)
+		router.ServeHTTP(w, req)
+
+		if w.Code == http.StatusForbidden || w.Code == http.StatusUnauthorized {
+			t.Errorf("%s %s: expected the handler to run, got %d: %s", route.method, route.path, w.Code, w.Body.String())
+		}
+	}
+}
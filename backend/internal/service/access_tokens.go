@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+var (
+	ErrAccessTokensUnavailable = errors.New("tokens de acesso indisponíveis")
+	ErrInvalidAccessTokenScope = errors.New("escopos de token de acesso inválidos")
+)
+
+// AccessTokenResponse is a signed access token in the shape of an OAuth 2.0
+// token response
+type AccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// IssueAccessToken exchanges the authenticated user's session for a
+// short-lived signed access token that downstream services verify on their own.
+func (s *AuthService) IssueAccessToken(userID, sessionPublicID string, scopes []string) (*AccessTokenResponse, error) {
+	user, err := s.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := s.authManager.IssueAccessToken(user, sessionPublicID, scopes)
+	if err != nil {
+		return nil, mapAccessTokenError(err)
+	}
+
+	return &AccessTokenResponse{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(token.ExpiresAt).Round(time.Second).Seconds()),
+		Scope:       strings.Join(token.Scopes, " "),
+	}, nil
+}
+
+// AccessTokenJWKS returns the public keys that verify access tokens.
+func (s *AuthService) AccessTokenJWKS() (*jose.JSONWebKeySet, error) {
+	keys, err := s.authManager.AccessTokenJWKS()
+	if err != nil {
+		return nil, mapAccessTokenError(err)
+	}
+	return keys, nil
+}
+
+func mapAccessTokenError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrAccessTokensNotSupported):
+		return ErrAccessTokensUnavailable
+	case errors.Is(err, auth.ErrInvalidAPITokenScope):
+		return ErrInvalidAccessTokenScope
+	case errors.Is(err, auth.ErrUserNotActive):
+		return ErrUserNotActive
+	default:
+		return err
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_IssueAccessToken(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.IssueAccessToken(userID, "session-1", nil)
+	assert.ErrorIs(t, err, ErrAccessTokensUnavailable)
+	_, err = authService.AccessTokenJWKS()
+	assert.ErrorIs(t, err, ErrAccessTokensUnavailable)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := auth.NewAccessTokenSigner(privateKey, nil, auth.AccessTokenSignerConfig{
+		Issuer: "https://api.example.com",
+		TTL:    5 * time.Minute,
+	})
+	require.NoError(t, err)
+	authManager.SetAccessTokenSigner(signer)
+
+	// Without requested scopes the token carries every scope the user may have
+	response, err := authService.IssueAccessToken(userID, "session-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, "read write", response.Scope)
+	assert.Equal(t, 300, response.ExpiresIn)
+
+	verifier := oidc.NewVerifier("https://api.example.com",
+		&oidc.StaticKeySet{PublicKeys: []crypto.PublicKey{publicKey}},
+		&oidc.Config{SkipClientIDCheck: true, SupportedSigningAlgs: []string{oidc.EdDSA}})
+	idToken, err := verifier.Verify(context.Background(), response.AccessToken)
+	require.NoError(t, err)
+	var claims auth.AccessTokenClaims
+	require.NoError(t, idToken.Claims(&claims))
+	assert.Equal(t, userID, claims.Subject)
+	assert.Equal(t, "user", claims.Role)
+	assert.Equal(t, "session-1", claims.SessionID)
+
+	response, err = authService.IssueAccessToken(userID, "session-1", []string{"read"})
+	require.NoError(t, err)
+	assert.Equal(t, "read", response.Scope)
+
+	// Only admins may carry the admin scope
+	_, err = authService.IssueAccessToken(userID, "session-1", []string{"admin"})
+	assert.ErrorIs(t, err, ErrInvalidAccessTokenScope)
+
+	keys, err := authService.AccessTokenJWKS()
+	require.NoError(t, err)
+	require.Len(t, keys.Keys, 1)
+	assert.Equal(t, publicKey, keys.Keys[0].Key)
+}
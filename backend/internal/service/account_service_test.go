@@ -4,6 +4,7 @@ import (
 	"strconv"
 	"testing"
 
+	"gosveltekit/internal/auth"
 	"gosveltekit/internal/validation"
 
 	"github.com/stretchr/testify/assert"
@@ -42,12 +43,26 @@ func TestAuthService_ChangePassword_Success(t *testing.T) {
 	user := createTestUser(t, db)
 	userID := strconv.FormatUint(uint64(user.ID), 10)
 
-	err := authService.ChangePassword(userID, ChangePasswordInput{
+	current, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	other, err := authService.Login("testuser", "password123", "127.0.0.1", "other-agent")
+	require.NoError(t, err)
+
+	rotated, err := authService.ChangePassword(userID, current.SessionID, ChangePasswordInput{
 		CurrentPassword: "password123",
 		NewPassword:     "Secur3!PassA",
 		ConfirmPassword: "Secur3!PassA",
 	})
 	require.NoError(t, err)
+	assert.NotEqual(t, current.SessionID, rotated.ID)
+	assert.True(t, rotated.Fresh)
+
+	// The current session continues under its new token, the others end
+	_, sessionUser, err := authService.ValidateSession(rotated.ID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, sessionUser.ID)
+	_, _, err = authService.ValidateSession(other.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidToken)
 
 	_, err = authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
 	assert.ErrorIs(t, err, ErrInvalidCredentials)
@@ -62,7 +77,7 @@ func TestAuthService_ChangePassword_WrongCurrentPassword(t *testing.T) {
 	user := createTestUser(t, db)
 	userID := strconv.FormatUint(uint64(user.ID), 10)
 
-	err := authService.ChangePassword(userID, ChangePasswordInput{
+	_, err := authService.ChangePassword(userID, "", ChangePasswordInput{
 		CurrentPassword: "invalid-current",
 		NewPassword:     "Secur3!PassA",
 		ConfirmPassword: "Secur3!PassA",
@@ -76,7 +91,7 @@ func TestAuthService_ChangePassword_ValidationError(t *testing.T) {
 	user := createTestUser(t, db)
 	userID := strconv.FormatUint(uint64(user.ID), 10)
 
-	err := authService.ChangePassword(userID, ChangePasswordInput{
+	_, err := authService.ChangePassword(userID, "", ChangePasswordInput{
 		CurrentPassword: "password123",
 		NewPassword:     "short",
 		ConfirmPassword: "short",
@@ -96,25 +111,40 @@ func TestAuthService_ListAndRevokeSessions(t *testing.T) {
 	secondSession, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent-b")
 	require.NoError(t, err)
 
-	sessions, err := authService.ListSessions(userID, secondSession.SessionID)
+	first, _, err := authService.ValidateSession(firstSession.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	second, _, err := authService.ValidateSession(secondSession.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+
+	sessions, err := authService.ListSessions(userID, second.PublicID)
 	require.NoError(t, err)
 	assert.GreaterOrEqual(t, len(sessions), 2)
 
 	var hasCurrent bool
 	for _, session := range sessions {
-		if session.ID == secondSession.SessionID {
+		// Listings never expose session tokens
+		assert.NotEqual(t, firstSession.SessionID, session.ID)
+		assert.NotEqual(t, secondSession.SessionID, session.ID)
+		if session.ID == second.PublicID {
 			hasCurrent = session.IsCurrent
 		}
 	}
 	assert.True(t, hasCurrent)
 
-	err = authService.RevokeSession(userID, firstSession.SessionID, secondSession.SessionID)
+	// A token is not a public ID
+	err = authService.RevokeSession(userID, firstSession.SessionID, second.PublicID)
+	assert.ErrorIs(t, err, ErrAccessDenied)
+
+	err = authService.RevokeSession(userID, first.PublicID, second.PublicID)
 	require.NoError(t, err)
 
-	sessionsAfterRevoke, err := authService.ListSessions(userID, secondSession.SessionID)
+	sessionsAfterRevoke, err := authService.ListSessions(userID, second.PublicID)
 	require.NoError(t, err)
 
 	for _, session := range sessionsAfterRevoke {
-		assert.NotEqual(t, firstSession.SessionID, session.ID)
+		assert.NotEqual(t, first.PublicID, session.ID)
 	}
+
+	_, _, err = authService.ValidateSession(firstSession.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidToken)
 }
@@ -2,12 +2,16 @@ package service
 
 import (
 	"errors"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
+	"gosveltekit/internal/auth"
 	"gosveltekit/internal/models"
 	"gosveltekit/internal/pagination"
+
+	"gorm.io/gorm"
 )
 
 const (
@@ -17,10 +21,16 @@ const (
 )
 
 var (
-	ErrPaginationModeRequired = errors.New("pagination_mode é obrigatório")
-	ErrInvalidPaginationMode  = errors.New("pagination_mode inválido")
-	ErrInvalidAdminUsersQuery = errors.New("parâmetros de listagem inválidos")
-	ErrUnsupportedCursorSort  = errors.New("sort não suportado para paginação cursor")
+	ErrPaginationModeRequired     = errors.New("pagination_mode é obrigatório")
+	ErrInvalidPaginationMode      = errors.New("pagination_mode inválido")
+	ErrInvalidAdminUsersQuery     = errors.New("parâmetros de listagem inválidos")
+	ErrUnsupportedCursorSort      = errors.New("sort não suportado para paginação cursor")
+	ErrInvalidRole                = errors.New("papel inválido")
+	ErrUserNotFound               = errors.New("usuário não encontrado")
+	ErrInvalidPermission          = errors.New("permissão inválida")
+	ErrPermissionNotHeld          = errors.New("não é possível conceder ou retirar uma permissão que você não tem")
+	ErrCannotChangeOwnRole        = errors.New("não é possível alterar o próprio papel")
+	ErrCannotChangeOwnPermissions = errors.New("não é possível alterar as próprias permissões")
 )
 
 type ListAdminUsersInput struct {
@@ -38,6 +48,16 @@ type AdminUserRow struct {
 	Active      bool      `json:"active"`
 	LastLogin   time.Time `json:"last_login"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	Permissions         []string                 `json:"permissions"`          // effective
+	PermissionOverrides auth.PermissionOverrides `json:"permission_overrides"` // per-user grants and denies
+}
+
+// AdminUserUpdate is a user as an admin change left them. Previous holds the
+// user as they were before, for the audit log.
+type AdminUserUpdate struct {
+	AdminUserRow
+	Previous AdminUserRow `json:"-"`
 }
 
 func (s *AuthService) ListAdminUsers(input ListAdminUsersInput) (*pagination.Response[AdminUserRow], error) {
@@ -53,6 +73,130 @@ func (s *AuthService) ListAdminUsers(input ListAdminUsersInput) (*pagination.Res
 	}
 }
 
+// UpdateUserRole changes a user's role. Like a permission grant, the actor
+// must hold every permission of both the user's current role and the new one,
+// and cannot change their own role. The user's sessions get a new token on
+// their next use, so a session obtained before the change cannot be fixed
+// into one with the new privileges.
+func (s *AuthService) UpdateUserRole(actorID, userID, role string) (*AdminUserUpdate, error) {
+	if !auth.IsRole(role) {
+		return nil, ErrInvalidRole
+	}
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnRole
+	}
+
+	actor, err := s.userAdapter.FindUserByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userAdapter.GetUserModel(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	if err := requireHeldPermissions(actor, auth.RolePermissions(user.Role), auth.RolePermissions(role)); err != nil {
+		return nil, err
+	}
+
+	previous := toAdminUserRow(user)
+	if user.Role != role {
+		user.Role = role
+		if err := s.userAdapter.UpdateUser(user); err != nil {
+			return nil, err
+		}
+		if err := s.authManager.RequireSessionRotation(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AdminUserUpdate{AdminUserRow: toAdminUserRow(user), Previous: previous}, nil
+}
+
+// UpdateUserPermissions replaces a user's permission grants and denies. The
+// actor cannot change their own overrides nor those of a user holding a
+// permission they lack, and must hold every permission they grant, deny or
+// take back. Like a role change, the user's sessions get a new token on their
+// next use.
+func (s *AuthService) UpdateUserPermissions(actorID, userID string, overrides auth.PermissionOverrides) (*AdminUserUpdate, error) {
+	for _, permission := range slices.Concat(overrides.Grant, overrides.Deny) {
+		if !auth.IsPermission(permission) {
+			return nil, ErrInvalidPermission
+		}
+	}
+	if actorID == userID {
+		return nil, ErrCannotChangeOwnPermissions
+	}
+
+	actor, err := s.userAdapter.FindUserByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userAdapter.GetUserModel(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	current := auth.ParsePermissionOverrides(user.Permissions)
+	removedGrants := slices.DeleteFunc(slices.Clone(current.Grant), func(permission string) bool {
+		return slices.Contains(overrides.Grant, permission)
+	})
+	if err := requireHeldPermissions(actor,
+		auth.EffectivePermissions(user.Role, current),
+		overrides.Grant,
+		overrides.Deny,
+		removedGrants,
+	); err != nil {
+		return nil, err
+	}
+
+	overrides.Grant = compactPermissions(overrides.Grant)
+	overrides.Deny = compactPermissions(overrides.Deny)
+	encoded, err := overrides.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	previous := toAdminUserRow(user)
+	if user.Permissions != encoded {
+		user.Permissions = encoded
+		if err := s.userAdapter.UpdateUser(user); err != nil {
+			return nil, err
+		}
+		if err := s.authManager.RequireSessionRotation(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &AdminUserUpdate{AdminUserRow: toAdminUserRow(user), Previous: previous}, nil
+}
+
+// requireHeldPermissions fails with ErrPermissionNotHeld unless the actor
+// holds every one of the permissions
+func requireHeldPermissions(actor *auth.UserData, permissions ...[]string) error {
+	for _, permission := range slices.Concat(permissions...) {
+		if !actor.HasPermission(permission) {
+			return ErrPermissionNotHeld
+		}
+	}
+	return nil
+}
+
+// compactPermissions sorts the permissions and drops duplicates
+func compactPermissions(permissions []string) []string {
+	permissions = slices.Clone(permissions)
+	slices.Sort(permissions)
+	return slices.Compact(permissions)
+}
+
 func (s *AuthService) listAdminUsersOffset(
 	input *pagination.OffsetQuery,
 ) (*pagination.Response[AdminUserRow], error) {
@@ -231,6 +375,7 @@ func toAdminUserRows(users []*models.User) []AdminUserRow {
 }
 
 func toAdminUserRow(user *models.User) AdminUserRow {
+	overrides := auth.ParsePermissionOverrides(user.Permissions)
 	return AdminUserRow{
 		ID:          strconv.FormatUint(uint64(user.ID), 10),
 		Identifier:  user.Username,
@@ -240,5 +385,8 @@ func toAdminUserRow(user *models.User) AdminUserRow {
 		Active:      user.Active,
 		LastLogin:   user.LastLogin,
 		CreatedAt:   user.CreatedAt,
+
+		Permissions:         auth.EffectivePermissions(user.Role, overrides),
+		PermissionOverrides: overrides,
 	}
 }
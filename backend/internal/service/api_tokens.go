@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+)
+
+var (
+	ErrAPITokensUnavailable  = errors.New("tokens de API indisponíveis")
+	ErrAPITokenNotFound      = errors.New("token de API não encontrado")
+	ErrInvalidAPITokenName   = errors.New("informe um nome para o token de API")
+	ErrInvalidAPITokenScope  = errors.New("escopos de token de API inválidos")
+	ErrInvalidAPITokenExpiry = errors.New("a expiração do token de API deve estar no futuro")
+)
+
+// CreateAPITokenInput defines payload for creating a personal access token.
+// A nil ExpiresAt creates a token that never expires.
+type CreateAPITokenInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time
+}
+
+// APITokenInfo describes a personal access token to its owner. The token
+// itself is never shown again after creation; Hint holds its last characters.
+type APITokenInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Hint       string     `json:"hint"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+}
+
+// CreatedAPIToken is a new personal access token together with its value,
+// which the user must copy now.
+type CreatedAPIToken struct {
+	APITokenInfo
+	Token string `json:"token"`
+}
+
+// ListAPITokens returns the authenticated user's personal access tokens.
+func (s *AuthService) ListAPITokens(userID string) ([]APITokenInfo, error) {
+	tokens, err := s.authManager.ListAPITokens(userID)
+	if err != nil {
+		return nil, mapAPITokenError(err)
+	}
+
+	result := make([]APITokenInfo, 0, len(tokens))
+	for i := range tokens {
+		result = append(result, toAPITokenInfo(&tokens[i]))
+	}
+	return result, nil
+}
+
+// CreateAPIToken issues a personal access token for the authenticated user.
+func (s *AuthService) CreateAPIToken(userID string, input CreateAPITokenInput) (*CreatedAPIToken, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, ErrInvalidAPITokenName
+	}
+
+	user, err := s.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt time.Time
+	if input.ExpiresAt != nil {
+		expiresAt = *input.ExpiresAt
+	}
+
+	record, token, err := s.authManager.CreateAPIToken(user, input.Name, input.Scopes, expiresAt)
+	if err != nil {
+		return nil, mapAPITokenError(err)
+	}
+
+	return &CreatedAPIToken{APITokenInfo: toAPITokenInfo(record), Token: token}, nil
+}
+
+// RevokeAPIToken deletes one of the authenticated user's personal access tokens.
+func (s *AuthService) RevokeAPIToken(userID, tokenID string) error {
+	return mapAPITokenError(s.authManager.RevokeAPIToken(userID, tokenID))
+}
+
+func toAPITokenInfo(token *auth.APIToken) APITokenInfo {
+	info := APITokenInfo{
+		ID:         token.ID,
+		Name:       token.Name,
+		Hint:       token.Hint,
+		Scopes:     token.Scopes,
+		CreatedAt:  token.CreatedAt,
+		LastUsedIP: token.LastUsedIP,
+	}
+	if !token.ExpiresAt.IsZero() {
+		expiresAt := token.ExpiresAt
+		info.ExpiresAt = &expiresAt
+	}
+	if !token.LastUsedAt.IsZero() {
+		lastUsedAt := token.LastUsedAt
+		info.LastUsedAt = &lastUsedAt
+	}
+	return info
+}
+
+func mapAPITokenError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrAPITokensNotSupported):
+		return ErrAPITokensUnavailable
+	case errors.Is(err, auth.ErrAPITokenNotFound):
+		return ErrAPITokenNotFound
+	case errors.Is(err, auth.ErrInvalidAPITokenScope):
+		return ErrInvalidAPITokenScope
+	case errors.Is(err, auth.ErrAPITokenExpired):
+		return ErrInvalidAPITokenExpiry
+	default:
+		return err
+	}
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_APITokens(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	created, err := authService.CreateAPIToken(userID, CreateAPITokenInput{
+		Name:   "  deploy script  ",
+		Scopes: []string{"write", "read", "read"},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(created.Token, auth.APITokenPrefix))
+	assert.Equal(t, "deploy script", created.Name)
+	assert.Equal(t, []string{"read", "write"}, created.Scopes)
+	assert.Equal(t, created.Token[len(created.Token)-4:], created.Hint)
+	assert.Nil(t, created.ExpiresAt)
+
+	// The token authenticates its owner and records where it was used
+	token, tokenUser, err := authManager.ValidateAPIToken(created.Token, auth.SessionMetadata{IP: "203.0.113.7"})
+	require.NoError(t, err)
+	assert.Equal(t, userID, tokenUser.ID)
+	assert.True(t, token.HasScope(auth.APITokenScopeWrite))
+	assert.False(t, token.HasScope(auth.APITokenScopeAdmin))
+
+	tokens, err := authService.ListAPITokens(userID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, created.ID, tokens[0].ID)
+	assert.Equal(t, "203.0.113.7", tokens[0].LastUsedIP)
+	require.NotNil(t, tokens[0].LastUsedAt)
+
+	// Tokens are not sessions
+	sessions, err := authService.ListSessions(userID, "")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	require.NoError(t, authService.RevokeAPIToken(userID, created.ID))
+	_, _, err = authManager.ValidateAPIToken(created.Token, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, auth.ErrAPITokenNotFound)
+	assert.ErrorIs(t, authService.RevokeAPIToken(userID, created.ID), ErrAPITokenNotFound)
+}
+
+func TestAuthService_CreateAPIToken_Validation(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		input CreateAPITokenInput
+		err   error
+	}{
+		{"blank name", CreateAPITokenInput{Name: " ", Scopes: []string{"read"}}, ErrInvalidAPITokenName},
+		{"no scopes", CreateAPITokenInput{Name: "ci"}, ErrInvalidAPITokenScope},
+		{"unknown scope", CreateAPITokenInput{Name: "ci", Scopes: []string{"read", "root"}}, ErrInvalidAPITokenScope},
+		{"admin scope for non-admin", CreateAPITokenInput{Name: "ci", Scopes: []string{"admin"}}, ErrInvalidAPITokenScope},
+		{"expiry in the past", CreateAPITokenInput{Name: "ci", Scopes: []string{"read"}, ExpiresAt: &past}, ErrInvalidAPITokenExpiry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := authService.CreateAPIToken(userID, tt.input)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestAuthService_APIToken_Expiry(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	expiresAt := time.Now().Add(time.Hour)
+	created, err := authService.CreateAPIToken(userID, CreateAPITokenInput{
+		Name:      "temporary",
+		Scopes:    []string{"read"},
+		ExpiresAt: &expiresAt,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created.ExpiresAt)
+
+	_, _, err = authManager.ValidateAPIToken(created.Token, auth.SessionMetadata{})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec("UPDATE api_tokens SET expires_at = ?", time.Now().Add(-time.Minute)).Error)
+	_, _, err = authManager.ValidateAPIToken(created.Token, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, auth.ErrAPITokenExpired)
+}
+
+func TestAuthService_APIToken_InactiveUser(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	created, err := authService.CreateAPIToken(userID, CreateAPITokenInput{Name: "ci", Scopes: []string{"read"}})
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(user).Update("active", false).Error)
+	_, _, err = authManager.ValidateAPIToken(created.Token, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, auth.ErrUserNotActive)
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/pagination"
+)
+
+var (
+	ErrAuditLogUnavailable = errors.New("registro de auditoria indisponível")
+	ErrInvalidAuditQuery   = errors.New("parâmetros de consulta da auditoria inválidos")
+)
+
+// AuditEventRow is one entry of the audit log. ActorID is who acted and
+// UserID whose account it concerns; either may be empty, e.g. for a failed
+// login with an unknown username.
+type AuditEventRow struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	UserID    string            `json:"user_id,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ListAuditEventsInput filters the audit log; empty fields match every event.
+// The time range includes From and excludes To. Events are sorted by
+// created_at only.
+type ListAuditEventsInput struct {
+	ActorID string
+	UserID  string
+	Type    string
+	From    time.Time
+	To      time.Time
+	Cursor  *pagination.CursorQuery
+}
+
+// SetAuditAdapter enables reading the audit log.
+func (s *AuthService) SetAuditAdapter(adapter *gormadapter.AuditAdapter) {
+	s.auditAdapter = adapter
+}
+
+// ListAuditEvents lists the audit log for the admin area, newest first by
+// default.
+func (s *AuthService) ListAuditEvents(input ListAuditEventsInput) (*pagination.Response[AuditEventRow], error) {
+	if !validAuditUserID(input.ActorID) || !validAuditUserID(input.UserID) {
+		return nil, ErrInvalidAuditQuery
+	}
+	if !input.From.IsZero() && !input.To.IsZero() && !input.From.Before(input.To) {
+		return nil, ErrInvalidAuditQuery
+	}
+
+	return s.listAuditEvents(gormadapter.AuditEventFilter{
+		ActorID: input.ActorID,
+		UserID:  input.UserID,
+		Type:    strings.TrimSpace(input.Type),
+		From:    input.From,
+		To:      input.To,
+	}, input.Cursor)
+}
+
+// ListSecurityActivity lists the audit events that concern the user's own
+// account, such as sign-ins, failed attempts and password changes.
+func (s *AuthService) ListSecurityActivity(userID string, cursor *pagination.CursorQuery) (*pagination.Response[AuditEventRow], error) {
+	if userID == "" || !validAuditUserID(userID) {
+		return nil, ErrInvalidAuditQuery
+	}
+	return s.listAuditEvents(gormadapter.AuditEventFilter{UserID: userID}, cursor)
+}
+
+func (s *AuthService) listAuditEvents(
+	filter gormadapter.AuditEventFilter,
+	cursor *pagination.CursorQuery,
+) (*pagination.Response[AuditEventRow], error) {
+	if s.auditAdapter == nil {
+		return nil, ErrAuditLogUnavailable
+	}
+
+	normalized, err := normalizeAuditCursorInput(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.auditAdapter.ListAuditEventsCursor(filter, normalized)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, ErrInvalidAuditQuery
+		}
+		return nil, err
+	}
+
+	return &pagination.Response[AuditEventRow]{
+		Items:          toAuditEventRows(result.Events),
+		Sort:           pagination.Sort{Field: normalized.Sort, Direction: normalized.Order},
+		PaginationMode: pagination.ModeCursor,
+		Pagination: pagination.CursorMetadata{
+			PageSize:   normalized.PageSize,
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			HasNext:    result.HasNext,
+			HasPrev:    result.HasPrev,
+		},
+	}, nil
+}
+
+func normalizeAuditCursorInput(input *pagination.CursorQuery) (pagination.CursorQuery, error) {
+	if input == nil {
+		input = &pagination.CursorQuery{}
+	}
+
+	if strings.TrimSpace(input.After) != "" && strings.TrimSpace(input.Before) != "" {
+		return pagination.CursorQuery{}, ErrInvalidAuditQuery
+	}
+
+	pageSize := input.PageSize
+	if pageSize == 0 {
+		pageSize = defaultAdminUsersPageSize
+	}
+	if pageSize < 1 || pageSize > maxAdminUsersPageSize {
+		return pagination.CursorQuery{}, ErrInvalidAuditQuery
+	}
+
+	sortField := strings.TrimSpace(strings.ToLower(input.Sort))
+	if sortField == "" {
+		sortField = "created_at"
+	}
+	order := pagination.SortDirection(strings.TrimSpace(strings.ToLower(string(input.Order))))
+	if order == "" {
+		order = pagination.SortDesc
+	}
+	if sortField != "created_at" || (order != pagination.SortAsc && order != pagination.SortDesc) {
+		return pagination.CursorQuery{}, ErrInvalidAuditQuery
+	}
+
+	return pagination.CursorQuery{
+		PageSize: pageSize,
+		Sort:     sortField,
+		Order:    order,
+		After:    strings.TrimSpace(input.After),
+		Before:   strings.TrimSpace(input.Before),
+	}, nil
+}
+
+// validAuditUserID accepts an empty ID or a numeric one
+func validAuditUserID(id string) bool {
+	if id == "" {
+		return true
+	}
+	_, err := strconv.ParseUint(id, 10, 64)
+	return err == nil
+}
+
+func toAuditEventRows(events []*models.AuditEvent) []AuditEventRow {
+	items := make([]AuditEventRow, 0, len(events))
+	for _, event := range events {
+		row := AuditEventRow{
+			ID:        strconv.FormatUint(uint64(event.ID), 10),
+			Type:      event.Type,
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		}
+		if event.ActorID != nil {
+			row.ActorID = strconv.FormatUint(uint64(*event.ActorID), 10)
+		}
+		if event.UserID != nil {
+			row.UserID = strconv.FormatUint(uint64(*event.UserID), 10)
+		}
+		if event.Details != "" {
+			_ = json.Unmarshal([]byte(event.Details), &row.Details)
+		}
+		items = append(items, row)
+	}
+	return items
+}
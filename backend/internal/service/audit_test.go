@@ -0,0 +1,123 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_AuditLogRecordsLogins(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.Login("testuser", "wrongpass", "10.0.0.1", "bad-agent")
+	require.Error(t, err)
+	_, err = authService.Login("testuser", "password123", "10.0.0.2", "good-agent")
+	require.NoError(t, err)
+	_, _ = authService.Login("nobody", "password123", "10.0.0.3", "bad-agent")
+	authManager.Events().Wait()
+
+	activity, err := authService.ListSecurityActivity(userID, nil)
+	require.NoError(t, err)
+	require.Len(t, activity.Items, 2, "the unknown username belongs to no account")
+
+	succeeded := activity.Items[0]
+	assert.Equal(t, auth.EventLoginSucceeded, succeeded.Type)
+	assert.Equal(t, userID, succeeded.ActorID)
+	assert.Equal(t, "10.0.0.2", succeeded.IP)
+	assert.Equal(t, "good-agent", succeeded.UserAgent)
+	assert.Equal(t, auth.LoginMethodPassword, succeeded.Details["method"])
+
+	failed := activity.Items[1]
+	assert.Equal(t, auth.EventLoginFailed, failed.Type)
+	assert.Empty(t, failed.ActorID)
+	assert.Equal(t, userID, failed.UserID)
+	assert.Equal(t, "testuser", failed.Details["identifier"])
+
+	unknown, err := authService.ListAuditEvents(ListAuditEventsInput{Type: auth.EventLoginFailed})
+	require.NoError(t, err)
+	require.Len(t, unknown.Items, 2)
+	assert.Equal(t, "nobody", unknown.Items[0].Details["identifier"])
+	assert.Empty(t, unknown.Items[0].UserID)
+}
+
+func TestAuthService_ListAuditEvents(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	admin := createTestAdmin(t, db)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i, eventType := range []string{auth.AuditUserRoleChanged, auth.AuditUserPermissionsChanged, auth.AuditUserRoleChanged} {
+		authManager.RecordAuditEvent(&auth.AuditEvent{
+			Type:      eventType,
+			ActorID:   adminID,
+			UserID:    userID,
+			Details:   map[string]string{"route": "/api/admin/users/:user_id/role"},
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	authManager.RecordAuditEvent(&auth.AuditEvent{
+		Type:      auth.AuditPasskeyAdded,
+		ActorID:   userID,
+		UserID:    userID,
+		CreatedAt: base,
+	})
+
+	byActor, err := authService.ListAuditEvents(ListAuditEventsInput{ActorID: adminID})
+	require.NoError(t, err)
+	require.Len(t, byActor.Items, 3)
+	assert.Equal(t, base.Add(2*time.Hour), byActor.Items[0].CreatedAt.UTC(), "newest first by default")
+	assert.Equal(t, "/api/admin/users/:user_id/role", byActor.Items[0].Details["route"])
+
+	byType, err := authService.ListAuditEvents(ListAuditEventsInput{Type: auth.AuditUserRoleChanged})
+	require.NoError(t, err)
+	assert.Len(t, byType.Items, 2)
+
+	byTime, err := authService.ListAuditEvents(ListAuditEventsInput{
+		ActorID: adminID,
+		From:    base.Add(time.Hour),
+		To:      base.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, byTime.Items, 1)
+	assert.Equal(t, auth.AuditUserPermissionsChanged, byTime.Items[0].Type)
+
+	first, err := authService.ListAuditEvents(ListAuditEventsInput{
+		UserID: userID,
+		Cursor: &pagination.CursorQuery{PageSize: 3, Order: pagination.SortAsc},
+	})
+	require.NoError(t, err)
+	require.Len(t, first.Items, 3)
+	assert.Equal(t, pagination.ModeCursor, first.PaginationMode)
+	metadata := first.Pagination.(pagination.CursorMetadata)
+	require.True(t, metadata.HasNext)
+	require.NotNil(t, metadata.NextCursor)
+
+	second, err := authService.ListAuditEvents(ListAuditEventsInput{
+		UserID: userID,
+		Cursor: &pagination.CursorQuery{PageSize: 3, Order: pagination.SortAsc, After: *metadata.NextCursor},
+	})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, base.Add(2*time.Hour), second.Items[0].CreatedAt.UTC())
+	assert.False(t, second.Pagination.(pagination.CursorMetadata).HasNext)
+
+	for _, input := range []ListAuditEventsInput{
+		{ActorID: "admin"},
+		{From: base, To: base},
+		{Cursor: &pagination.CursorQuery{Sort: "type"}},
+		{Cursor: &pagination.CursorQuery{After: "not-a-cursor"}},
+	} {
+		_, err = authService.ListAuditEvents(input)
+		assert.ErrorIs(t, err, ErrInvalidAuditQuery)
+	}
+}
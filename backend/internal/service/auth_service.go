@@ -2,6 +2,7 @@
 package service
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"errors"
@@ -17,7 +18,7 @@ import (
 	"gosveltekit/internal/pagination"
 	"gosveltekit/internal/validation"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/go-jose/go-jose/v4"
 	"gorm.io/gorm"
 )
 
@@ -28,6 +29,7 @@ var (
 	ErrExpiredToken       = errors.New("token expirado")
 	ErrAccessDenied       = errors.New("acesso negado")
 	ErrWrongPassword      = errors.New("senha atual incorreta")
+	ErrAccountLocked      = errors.New("conta temporariamente bloqueada, tente novamente mais tarde")
 )
 
 const resetTokenBytesLen = 32
@@ -35,18 +37,73 @@ const resetTokenBytesLen = 32
 // AuthServiceInterface defines the methods that an auth service must implement
 type AuthServiceInterface interface {
 	Login(username, password, ip, userAgent string) (*LoginResponse, error)
-	ValidateSession(sessionID string) (*auth.Session, *auth.UserData, error)
+	ValidateSession(sessionID string, metadata auth.SessionMetadata) (*auth.Session, *auth.UserData, error)
 	Logout(sessionID string) error
 	LogoutAll(userID string) error
 	Register(username, email, password, displayName string) (*models.User, error)
+	RegisterWithInvitation(token, username, password, displayName string) (*models.User, error)
 	RequestPasswordReset(email string) error
 	ResetPassword(token, newPassword string) error
 	GetProfile(userID string) (*AccountProfile, error)
 	UpdateProfile(userID string, input UpdateProfileInput) (*AccountProfile, error)
-	ChangePassword(userID string, input ChangePasswordInput) error
-	ListSessions(userID, currentSessionID string) ([]SessionInfo, error)
-	RevokeSession(userID, sessionID, currentSessionID string) error
+	ChangePassword(userID, sessionID string, input ChangePasswordInput) (*auth.Session, error)
+	RotateSession(sessionID string) (*auth.Session, error)
+	Reauthenticate(sessionID, password string) (*ReauthenticationStatus, error)
+	ListSessions(userID, currentPublicID string) ([]SessionInfo, error)
+	RevokeSession(userID, publicID, currentPublicID string) error
 	ListAdminUsers(input ListAdminUsersInput) (*pagination.Response[AdminUserRow], error)
+	UpdateUserRole(actorID, userID, role string) (*AdminUserUpdate, error)
+	UpdateUserPermissions(actorID, userID string, overrides auth.PermissionOverrides) (*AdminUserUpdate, error)
+	ImpersonateUser(adminID, targetUserID, reason, ip, userAgent string) (*LoginResponse, error)
+	StopImpersonation(sessionID, ip, userAgent string) error
+	GetImpersonation(session *auth.Session) (*ImpersonationInfo, error)
+	ListOrganizations(userID string) ([]OrganizationInfo, error)
+	CreateOrganization(userID, name string) (*OrganizationInfo, error)
+	GetOrganization(orgID, userID string) (*OrganizationInfo, error)
+	DeleteOrganization(orgID, actorID string) error
+	ListOrganizationMembers(orgID string) ([]OrganizationMemberInfo, error)
+	UpdateOrganizationMemberRole(orgID, actorID, userID, role string) (*OrganizationMemberInfo, error)
+	RemoveOrganizationMember(orgID, actorID, userID string) error
+	SwitchOrganization(sessionID, userID, orgID string) (*OrganizationInfo, error)
+	ListAdminOrganizations(input ListAdminOrganizationsInput) (*pagination.Response[AdminOrganizationRow], error)
+	ListAuditEvents(input ListAuditEventsInput) (*pagination.Response[AuditEventRow], error)
+	ListSecurityActivity(userID string, cursor *pagination.CursorQuery) (*pagination.Response[AuditEventRow], error)
+	InviteOrganizationMember(orgID, actorID string, input InviteOrganizationMemberInput) (*InvitationInfo, error)
+	ListOrganizationInvitations(orgID string) ([]InvitationInfo, error)
+	ResendOrganizationInvitation(orgID, actorID, invitationID string) (*InvitationInfo, error)
+	RevokeOrganizationInvitation(orgID, actorID, invitationID string) error
+	GetInvitation(token string) (*InvitationPreview, error)
+	AcceptInvitation(token, userID string) (*OrganizationInfo, error)
+	VerifyTwoFactor(challengeToken, code, ip, userAgent string) (*LoginResponse, error)
+	GetTwoFactorStatus(userID string) (*TwoFactorStatus, error)
+	SetupTOTP(userID string) (*TOTPSetup, error)
+	ConfirmTOTP(userID, code string) ([]string, error)
+	DisableTOTP(userID, password string) error
+	RegenerateRecoveryCodes(userID, password string) ([]string, error)
+	BeginPasskeyLogin() (*PasskeyOptions, error)
+	FinishPasskeyLogin(ceremonyToken string, credential []byte, ip, userAgent string) (*LoginResponse, error)
+	ListPasskeys(userID string) ([]PasskeyInfo, error)
+	BeginPasskeyRegistration(userID string) (*PasskeyOptions, error)
+	FinishPasskeyRegistration(userID, ceremonyToken, name string, credential []byte) (*PasskeyInfo, error)
+	DeletePasskey(userID, passkeyID string) error
+	ListAPITokens(userID string) ([]APITokenInfo, error)
+	CreateAPIToken(userID string, input CreateAPITokenInput) (*CreatedAPIToken, error)
+	RevokeAPIToken(userID, tokenID string) error
+	IssueAccessToken(userID, sessionPublicID string, scopes []string) (*AccessTokenResponse, error)
+	AccessTokenJWKS() (*jose.JSONWebKeySet, error)
+	ListOAuthClients() ([]OAuthClientInfo, error)
+	CreateOAuthClient(actorID string, input CreateOAuthClientInput) (*CreatedOAuthClient, error)
+	DeleteOAuthClient(clientID string) error
+	IssueClientCredentialsToken(clientID, clientSecret string, scopes []string) (*AccessTokenResponse, error)
+	ListOAuthProviders() []string
+	BeginOAuthLogin(provider string) (*OAuthRedirect, error)
+	CompleteOAuthLogin(ctx context.Context, provider, state, code, ip, userAgent string) (*LoginResponse, error)
+	ListIdentities(userID string) ([]IdentityInfo, error)
+	RequestMagicLink(email string) error
+	LoginWithMagicLink(token, ip, userAgent string) (*LoginResponse, error)
+	RevokeSessionByLink(token string) error
+	VerifyEmail(token string) error
+	ResendVerificationEmail(email string) error
 }
 
 // AuthService handles authentication business logic
@@ -55,6 +112,10 @@ type AuthService struct {
 	sessionAdapter *gormadapter.SessionAdapter
 	userAdapter    *gormadapter.UserAdapter
 	emailService   email.EmailServiceInterface
+
+	// Optional, set with SetOrganizationAdapter and SetAuditAdapter
+	organizationAdapter *gormadapter.OrganizationAdapter
+	auditAdapter        *gormadapter.AuditAdapter
 }
 
 // NewAuthService creates a new AuthService instance
@@ -72,11 +133,17 @@ func NewAuthService(
 	}
 }
 
-// LoginResponse represents the response from a successful login
+// LoginResponse represents the response from a successful login.
+//
+// When TwoFactorRequired is set no session was created: ChallengeToken must be
+// sent back with a second-factor code and ExpiresAt refers to the challenge.
 type LoginResponse struct {
 	SessionID string        `json:"session_id"`
 	ExpiresAt time.Time     `json:"expires_at"`
 	User      auth.UserData `json:"user"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
 }
 
 // AccountProfile is the shape returned by account profile endpoints.
@@ -110,12 +177,15 @@ type ChangePasswordInput struct {
 
 // SessionInfo defines account session metadata returned to frontend.
 type SessionInfo struct {
-	ID        string    `json:"id"`
-	CreatedAt time.Time `json:"created_at"`
-	ExpiresAt time.Time `json:"expires_at"`
-	UserAgent string    `json:"user_agent,omitempty"`
-	IP        string    `json:"ip,omitempty"`
-	IsCurrent bool      `json:"is_current"`
+	ID         string    `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IP         string    `json:"ip,omitempty"`
+	IsCurrent  bool      `json:"is_current"`
+
+	Impersonated bool `json:"impersonated,omitempty"` // opened by an admin acting as the user
 }
 
 // Login authenticates a user and creates a session
@@ -127,13 +197,19 @@ func (s *AuthService) Login(username, password, ip, userAgent string) (*LoginRes
 
 	session, user, err := s.authManager.Login(username, password, metadata)
 	if err != nil {
+		if response, ok := twoFactorChallengeResponse(err); ok {
+			return response, nil
+		}
+
 		switch {
 		case errors.Is(err, auth.ErrInvalidCredentials):
 			return nil, ErrInvalidCredentials
 		case errors.Is(err, auth.ErrUserNotActive):
 			return nil, ErrUserNotActive
 		case errors.Is(err, auth.ErrAccountLocked):
-			return nil, errors.New("conta temporariamente bloqueada, tente novamente mais tarde")
+			return nil, ErrAccountLocked
+		case errors.Is(err, auth.ErrEmailNotVerified):
+			return nil, ErrEmailNotVerified
 		default:
 			return nil, err
 		}
@@ -146,12 +222,12 @@ func (s *AuthService) Login(username, password, ip, userAgent string) (*LoginRes
 	}, nil
 }
 
-// ValidateSession validates a session and returns user data
-func (s *AuthService) ValidateSession(sessionID string) (*auth.Session, *auth.UserData, error) {
-	session, user, err := s.authManager.ValidateSession(sessionID)
+// ValidateSession validates a session used by the client described by metadata and returns user data
+func (s *AuthService) ValidateSession(sessionID string, metadata auth.SessionMetadata) (*auth.Session, *auth.UserData, error) {
+	session, user, err := s.authManager.ValidateSession(sessionID, metadata)
 	if err != nil {
 		switch {
-		case errors.Is(err, auth.ErrSessionNotFound):
+		case errors.Is(err, auth.ErrSessionNotFound), errors.Is(err, auth.ErrSessionRevoked):
 			return nil, nil, ErrInvalidToken
 		case errors.Is(err, auth.ErrSessionExpired):
 			return nil, nil, ErrExpiredToken
@@ -176,6 +252,10 @@ func (s *AuthService) LogoutAll(userID string) error {
 
 // Register creates a new user account
 func (s *AuthService) Register(username, emailAddr, password, displayName string) (*models.User, error) {
+	if s.authManager.InviteOnlyRegistration() {
+		return nil, ErrInvitationRequired
+	}
+
 	// Check if username already exists
 	if _, err := s.userAdapter.FindUserByIdentifier(username); err == nil {
 		return nil, errors.New("username already exists")
@@ -196,6 +276,13 @@ func (s *AuthService) Register(username, emailAddr, password, displayName string
 	if err != nil {
 		return nil, err
 	}
+	s.publishUserRegistered(userData, auth.RegistrationPassword)
+
+	// Ask the user to confirm the address; the account exists either way
+	if err := s.sendVerificationEmail(userData); err != nil &&
+		!errors.Is(err, auth.ErrEmailVerificationNotSupported) {
+		slog.Error("failed to issue email verification", "err", err)
+	}
 
 	// Get the actual User model for response
 	user, err := s.userAdapter.GetUserModel(userData.ID)
@@ -271,21 +358,32 @@ func (s *AuthService) ResetPassword(tokenFromUser, newPassword string) error {
 	}
 
 	// Hash new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	hashedPassword, err := s.userAdapter.HashPassword(newPassword)
 	if err != nil {
 		return err
 	}
 
 	// Update password and clear reset token
-	matchedUser.PasswordHash = string(hashedPassword)
+	matchedUser.PasswordHash = hashedPassword
 	matchedUser.ResetToken = ""
 	matchedUser.ResetTokenExpiry = time.Time{}
 
 	// Also invalidate all existing sessions for security
 	userID := strconv.FormatUint(uint64(matchedUser.ID), 10)
-	_ = s.authManager.LogoutAll(userID)
+	if err := s.sessionAdapter.DeleteUserSessions(userID); err == nil {
+		s.authManager.Events().Publish(auth.SessionRevokedEvent{
+			UserID:     userID,
+			Reason:     auth.SessionRevokedPasswordReset,
+			OccurredAt: time.Now(),
+		})
+	}
 
-	return s.userAdapter.UpdateUser(matchedUser)
+	if err := s.userAdapter.UpdateUser(matchedUser); err != nil {
+		return err
+	}
+
+	s.authManager.Events().Publish(auth.PasswordResetEvent{UserID: userID, OccurredAt: time.Now()})
+	return nil
 }
 
 // GetProfile returns profile information for the authenticated user.
@@ -328,35 +426,58 @@ func (s *AuthService) UpdateProfile(userID string, input UpdateProfileInput) (*A
 	return toAccountProfile(user), nil
 }
 
-// ChangePassword updates password for an authenticated user.
-func (s *AuthService) ChangePassword(userID string, input ChangePasswordInput) error {
+// ChangePassword updates password for an authenticated user. Every other
+// session of the user ends and the current one, sessionID, is rotated; the
+// returned session carries its new token.
+func (s *AuthService) ChangePassword(userID, sessionID string, input ChangePasswordInput) (*auth.Session, error) {
 	if input.NewPassword != input.ConfirmPassword {
-		return errors.New("as senhas não coincidem")
+		return nil, errors.New("as senhas não coincidem")
 	}
 
 	user, err := s.userAdapter.GetUserModel(userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.CurrentPassword)); err != nil {
-		return ErrWrongPassword
+	if !s.userAdapter.VerifyPassword(user, input.CurrentPassword) {
+		return nil, ErrWrongPassword
 	}
 
 	if err := validation.ValidatePassword(input.NewPassword, user.Username); err != nil {
-		return err
+		return nil, err
 	}
 
 	if err := s.userAdapter.UpdatePassword(userID, input.NewPassword); err != nil {
-		return err
+		return nil, err
 	}
 
-	// Invalidate all sessions (including current) after password change.
-	return s.authManager.LogoutAll(userID)
+	now := time.Now()
+	s.authManager.Events().Publish(auth.PasswordChangedEvent{UserID: userID, OccurredAt: now})
+
+	if err := s.sessionAdapter.DeleteOtherUserSessions(userID, sessionID); err != nil {
+		return nil, err
+	}
+	s.authManager.Events().Publish(auth.SessionRevokedEvent{
+		UserID:     userID,
+		Reason:     auth.SessionRevokedPasswordChanged,
+		OccurredAt: now,
+	})
+	return s.authManager.RotateSession(sessionID)
+}
+
+// RotateSession gives the session a new token after the user's authentication
+// level changed, e.g. when a second factor was enabled
+func (s *AuthService) RotateSession(sessionID string) (*auth.Session, error) {
+	session, err := s.authManager.RotateSession(sessionID)
+	if errors.Is(err, auth.ErrSessionNotFound) {
+		return nil, ErrInvalidToken
+	}
+	return session, err
 }
 
 // ListSessions returns all sessions for the authenticated user.
-func (s *AuthService) ListSessions(userID, currentSessionID string) ([]SessionInfo, error) {
+// currentPublicID is the public ID of the session making the request.
+func (s *AuthService) ListSessions(userID, currentPublicID string) ([]SessionInfo, error) {
 	sessions, err := s.sessionAdapter.ListUserSessions(userID)
 	if err != nil {
 		return nil, err
@@ -365,32 +486,66 @@ func (s *AuthService) ListSessions(userID, currentSessionID string) ([]SessionIn
 	result := make([]SessionInfo, 0, len(sessions))
 	for _, session := range sessions {
 		result = append(result, SessionInfo{
-			ID:        session.ID,
-			CreatedAt: session.CreatedAt,
-			ExpiresAt: session.ExpiresAt,
-			UserAgent: session.UserAgent,
-			IP:        session.IP,
-			IsCurrent: session.ID == currentSessionID,
+			ID:         session.PublicID,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			LastSeenAt: session.LastSeenAt,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			IsCurrent:  session.PublicID == currentPublicID,
+
+			Impersonated: session.Impersonating(),
 		})
 	}
 
 	return result, nil
 }
 
-// RevokeSession removes one user-owned session.
-func (s *AuthService) RevokeSession(userID, sessionID, _ string) error {
-	if _, err := s.sessionAdapter.GetUserSession(sessionID, userID); err != nil {
+// RevokeSession removes one user-owned session by its public ID.
+func (s *AuthService) RevokeSession(userID, publicID, _ string) error {
+	if err := s.sessionAdapter.DeleteUserSession(publicID, userID); err != nil {
 		if errors.Is(err, auth.ErrSessionNotFound) {
 			return ErrAccessDenied
 		}
 		return err
 	}
 
-	return s.sessionAdapter.DeleteSession(sessionID)
+	s.authManager.Events().Publish(auth.SessionRevokedEvent{
+		UserID:          userID,
+		SessionPublicID: publicID,
+		Reason:          auth.SessionRevokedByUser,
+		OccurredAt:      time.Now(),
+	})
+	return nil
 }
 
 // Helper methods
 
+// twoFactorChallengeResponse turns the error AuthManager returns for an account
+// with a second factor into the pending-challenge LoginResponse
+func twoFactorChallengeResponse(err error) (*LoginResponse, bool) {
+	var challenge *auth.TwoFactorRequiredError
+	if !errors.As(err, &challenge) {
+		return nil, false
+	}
+
+	return &LoginResponse{
+		ExpiresAt:         challenge.ExpiresAt,
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge.ChallengeToken,
+	}, true
+}
+
+func (s *AuthService) publishUserRegistered(user *auth.UserData, method string) {
+	s.authManager.Events().Publish(auth.UserRegisteredEvent{
+		UserID:     user.ID,
+		Identifier: user.Identifier,
+		Email:      user.Email,
+		Method:     method,
+		OccurredAt: time.Now(),
+	})
+}
+
 func (s *AuthService) generateSecureToken(b []byte) (int, error) {
 	return auth.GenerateRandomBytes(b)
 }
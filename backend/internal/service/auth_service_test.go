@@ -20,14 +20,52 @@ import (
 
 // Test helpers
 func setupTest(t *testing.T) (*AuthService, *auth.AuthManager, *gormadapter.UserAdapter, *gormadapter.SessionAdapter, *email.MockEmailService, *gorm.DB) {
-	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	db := testutil.NewSQLiteTestDB(
+		t,
+		&models.User{},
+		&models.Session{},
+		&models.TOTPCredential{},
+		&models.TwoFactorChallenge{},
+		&models.RecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnCeremony{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.MagicLinkToken{},
+		&models.EmailVerificationToken{},
+		&models.LoginLockout{},
+		&models.APIToken{},
+		&models.OAuthClient{},
+		&models.OAuthClientToken{},
+		&models.AuditEvent{},
+		&models.Organization{},
+		&models.OrganizationMembership{},
+		&models.OrganizationInvitation{},
+		&models.KnownDevice{},
+	)
 
 	userAdapter := gormadapter.NewUserAdapter(db)
 	sessionAdapter := gormadapter.NewSessionAdapter(db)
 	authConfig := auth.DefaultAuthConfig()
 	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+	authManager.SetTwoFactorAdapter(gormadapter.NewTwoFactorAdapter(db))
+	require.NoError(t, authManager.SetPasskeyAdapter(gormadapter.NewPasskeyAdapter(db)))
+	authManager.SetIdentityAdapter(gormadapter.NewIdentityAdapter(db))
+	authManager.SetMagicLinkAdapter(gormadapter.NewMagicLinkAdapter(db))
+	authManager.SetEmailVerificationAdapter(gormadapter.NewEmailVerificationAdapter(db))
+	authManager.SetAPITokenAdapter(gormadapter.NewAPITokenAdapter(db))
+	authManager.SetOAuthClientAdapter(gormadapter.NewOAuthClientAdapter(db))
+	auditAdapter := gormadapter.NewAuditAdapter(db)
+	authManager.SetAuditAdapter(auditAdapter)
+	// Let the asynchronous audit writes finish before the database closes
+	t.Cleanup(authManager.Events().Wait)
+	organizationAdapter := gormadapter.NewOrganizationAdapter(db)
+	authManager.SetOrganizationAdapter(organizationAdapter)
+	authManager.SetInvitationAdapter(gormadapter.NewInvitationAdapter(db))
 	mockEmailService := email.NewMockEmailService()
 	authService := NewAuthService(authManager, sessionAdapter, userAdapter, mockEmailService)
+	authService.SetOrganizationAdapter(organizationAdapter)
+	authService.SetAuditAdapter(auditAdapter)
 
 	return authService, authManager, userAdapter, sessionAdapter, mockEmailService, db
 }
@@ -151,7 +189,7 @@ func TestAuthService_ValidateSession_Success(t *testing.T) {
 	require.NoError(t, err)
 
 	// Validate the session
-	session, userData, err := authService.ValidateSession(loginResp.SessionID)
+	session, userData, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
 
 	require.NoError(t, err)
 	assert.NotNil(t, session)
@@ -162,7 +200,7 @@ func TestAuthService_ValidateSession_Success(t *testing.T) {
 func TestAuthService_ValidateSession_Invalid(t *testing.T) {
 	authService, _, _, _, _, _ := setupTest(t)
 
-	session, userData, err := authService.ValidateSession("invalid-session-id")
+	session, userData, err := authService.ValidateSession("invalid-session-id", auth.SessionMetadata{})
 	assert.Nil(t, session)
 	assert.Nil(t, userData)
 	assert.ErrorIs(t, err, ErrInvalidToken)
@@ -181,7 +219,7 @@ func TestAuthService_Logout(t *testing.T) {
 	require.NoError(t, err)
 
 	// Verify session is invalid
-	_, _, err = authService.ValidateSession(loginResp.SessionID)
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
 	assert.Error(t, err)
 }
 
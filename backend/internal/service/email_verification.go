@@ -0,0 +1,86 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"gosveltekit/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailNotVerified             = errors.New("email não verificado")
+	ErrEmailVerificationUnavailable = errors.New("verificação de email indisponível")
+)
+
+// ResendVerificationEmail sends a new verification link. Like
+// RequestPasswordReset it does not reveal whether the address belongs to an
+// account: unknown, inactive and already verified accounts, as well as
+// requests made before the resend interval has passed, silently get no email.
+func (s *AuthService) ResendVerificationEmail(emailAddr string) error {
+	user, err := s.userAdapter.FindByEmail(emailAddr)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.Active {
+		return nil
+	}
+
+	userData, err := s.userAdapter.FindUserByID(strconv.FormatUint(uint64(user.ID), 10))
+	if err != nil {
+		return err
+	}
+
+	err = s.sendVerificationEmail(userData)
+	switch {
+	case err == nil,
+		errors.Is(err, auth.ErrEmailAlreadyVerified),
+		errors.Is(err, auth.ErrEmailVerificationThrottled):
+		return nil
+	case errors.Is(err, auth.ErrEmailVerificationNotSupported):
+		return ErrEmailVerificationUnavailable
+	default:
+		return err
+	}
+}
+
+// VerifyEmail redeems an emailed verification token
+func (s *AuthService) VerifyEmail(token string) error {
+	if _, err := s.authManager.VerifyEmail(token); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrEmailVerificationInvalid):
+			return ErrInvalidToken
+		case errors.Is(err, auth.ErrEmailVerificationExpired):
+			return ErrExpiredToken
+		case errors.Is(err, auth.ErrEmailVerificationNotSupported):
+			return ErrEmailVerificationUnavailable
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// sendVerificationEmail issues a verification token and emails it. Delivery
+// failures are only logged, as for password reset emails.
+func (s *AuthService) sendVerificationEmail(user *auth.UserData) error {
+	verification, err := s.authManager.CreateEmailVerification(user)
+	if err != nil {
+		return err
+	}
+
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.Identifier
+	}
+
+	if err := s.emailService.SendVerificationEmail(user.Email, verification.Token, user.Identifier, displayName); err != nil {
+		slog.Error("failed to send verification email", "err", err)
+	}
+	return nil
+}
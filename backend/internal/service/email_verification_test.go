@@ -0,0 +1,149 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// setupRequiredEmailVerification returns a service that refuses login until
+// the user's email is verified
+func setupRequiredEmailVerification(t *testing.T) (*AuthService, *email.MockEmailService, *gorm.DB) {
+	t.Helper()
+
+	_, _, userAdapter, sessionAdapter, _, db := setupTest(t)
+	authConfig := auth.DefaultAuthConfig()
+	authConfig.RequireEmailVerification = true
+	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+	authManager.SetMagicLinkAdapter(gormadapter.NewMagicLinkAdapter(db))
+	authManager.SetEmailVerificationAdapter(gormadapter.NewEmailVerificationAdapter(db))
+	require.NoError(t, authManager.SetPasskeyAdapter(gormadapter.NewPasskeyAdapter(db)))
+	mockEmail := email.NewMockEmailService()
+
+	return NewAuthService(authManager, sessionAdapter, userAdapter, mockEmail), mockEmail, db
+}
+
+func sentVerificationEmails(mockEmail *email.MockEmailService) []email.MockEmail {
+	var result []email.MockEmail
+	for _, sent := range mockEmail.GetSentEmails() {
+		if sent.Kind == email.MockEmailVerification {
+			result = append(result, sent)
+		}
+	}
+	return result
+}
+
+func TestAuthService_Register_SendsVerificationEmail(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+
+	user, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	require.NoError(t, err)
+	assert.False(t, user.EmailVerified)
+
+	sent := sentVerificationEmails(mockEmail)
+	require.Len(t, sent, 1)
+	assert.Equal(t, "new@example.com", sent[0].To)
+	assert.Equal(t, "New User", sent[0].DisplayName)
+
+	// Only the hash is stored
+	var stored models.EmailVerificationToken
+	require.NoError(t, db.Where("user_id = ?", user.ID).First(&stored).Error)
+	assert.Equal(t, auth.HashToken(sent[0].Token), stored.ID)
+
+	require.NoError(t, authService.VerifyEmail(sent[0].Token))
+
+	var updated models.User
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.True(t, updated.EmailVerified)
+
+	// Single use
+	assert.ErrorIs(t, authService.VerifyEmail(sent[0].Token), ErrInvalidToken)
+}
+
+func TestAuthService_VerifyEmail_Expired(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+
+	user, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	sent := sentVerificationEmails(mockEmail)
+	require.Len(t, sent, 1)
+	assert.ErrorIs(t, authService.VerifyEmail(sent[0].Token), ErrExpiredToken)
+}
+
+func TestAuthService_ResendVerificationEmail_Throttled(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+
+	user, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	require.NoError(t, err)
+	first := sentVerificationEmails(mockEmail)[0].Token
+
+	// Too soon: no error, no email
+	require.NoError(t, authService.ResendVerificationEmail("new@example.com"))
+	assert.Len(t, sentVerificationEmails(mockEmail), 1)
+
+	// Once the interval has passed a new link replaces the old one
+	require.NoError(t, db.Model(&models.EmailVerificationToken{}).
+		Where("user_id = ?", user.ID).
+		Update("created_at", time.Now().Add(-2*time.Minute)).Error)
+	require.NoError(t, authService.ResendVerificationEmail("new@example.com"))
+
+	sent := sentVerificationEmails(mockEmail)
+	require.Len(t, sent, 2)
+	assert.ErrorIs(t, authService.VerifyEmail(first), ErrInvalidToken)
+	assert.NoError(t, authService.VerifyEmail(sent[1].Token))
+
+	// Verified accounts and unknown addresses get nothing
+	mockEmail.ClearSentEmails()
+	require.NoError(t, authService.ResendVerificationEmail("new@example.com"))
+	require.NoError(t, authService.ResendVerificationEmail("nobody@example.com"))
+	assert.Empty(t, mockEmail.GetSentEmails())
+}
+
+func TestAuthService_Login_RequiresVerifiedEmail(t *testing.T) {
+	authService, mockEmail, db := setupRequiredEmailVerification(t)
+
+	_, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	require.NoError(t, err)
+
+	_, err = authService.Login("newuser", "password123", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	sent := sentVerificationEmails(mockEmail)
+	require.Len(t, sent, 1)
+	require.NoError(t, authService.VerifyEmail(sent[0].Token))
+
+	resp, err := authService.Login("newuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.SessionID)
+
+	var user models.User
+	require.NoError(t, db.Where("username = ?", "newuser").First(&user).Error)
+	assert.True(t, user.EmailVerified)
+}
+
+func TestAuthService_MagicLinkLogin_VerifiesEmail(t *testing.T) {
+	authService, mockEmail, db := setupRequiredEmailVerification(t)
+	user := createTestUser(t, db)
+
+	token := requestTestMagicLink(t, authService, mockEmail, user.Email)
+
+	resp, err := authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.SessionID)
+
+	var updated models.User
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.True(t, updated.EmailVerified)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordEvents collects every event the manager publishes
+func recordEvents(authManager *auth.AuthManager) *[]auth.Event {
+	var events []auth.Event
+	authManager.Events().Subscribe(auth.AllEvents, func(e auth.Event) { events = append(events, e) })
+	return &events
+}
+
+func eventTypes(events []auth.Event) []string {
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e.EventType())
+	}
+	return types
+}
+
+func TestAuthService_PublishesSessionLifecycleEvents(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	events := recordEvents(authManager)
+
+	registered, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	require.NoError(t, err)
+	require.Len(t, *events, 1)
+	assert.Equal(t, auth.UserRegisteredEvent{
+		UserID:     strconv.FormatUint(uint64(registered.ID), 10),
+		Identifier: "newuser",
+		Email:      "new@example.com",
+		Method:     auth.RegistrationPassword,
+		OccurredAt: (*events)[0].(auth.UserRegisteredEvent).OccurredAt,
+	}, (*events)[0])
+
+	*events = nil
+	response, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	require.Equal(t, []string{auth.EventSessionCreated, auth.EventLoginSucceeded}, eventTypes(*events))
+	login := (*events)[1].(auth.LoginSucceededEvent)
+	assert.Equal(t, userID, login.UserID)
+	assert.Equal(t, auth.LoginMethodPassword, login.Method)
+	assert.Equal(t, "127.0.0.1", login.IP)
+	assert.NotEmpty(t, login.SessionPublicID)
+
+	*events = nil
+	session, err := authService.ChangePassword(userID, response.SessionID, ChangePasswordInput{
+		CurrentPassword: "password123",
+		NewPassword:     "Padasdasdasdd123!",
+		ConfirmPassword: "Padasdasdasdd123!",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.EventPasswordChanged, auth.EventSessionRevoked, auth.EventSessionRefreshed}, eventTypes(*events))
+	assert.Equal(t, auth.SessionRevokedPasswordChanged, (*events)[1].(auth.SessionRevokedEvent).Reason)
+	assert.True(t, (*events)[2].(auth.SessionRefreshedEvent).Rotated)
+
+	*events = nil
+	require.NoError(t, authService.Logout(session.ID))
+	require.Len(t, *events, 1)
+	revoked := (*events)[0].(auth.SessionRevokedEvent)
+	assert.Equal(t, auth.SessionRevokedLogout, revoked.Reason)
+	assert.Equal(t, login.SessionPublicID, revoked.SessionPublicID)
+}
+
+func TestAuthService_PublishesFailedLoginEvents(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	var failed []auth.LoginFailedEvent
+	auth.On(authManager.Events(), func(e auth.LoginFailedEvent) { failed = append(failed, e) })
+	var locked []auth.AccountLockedEvent
+	auth.On(authManager.Events(), func(e auth.AccountLockedEvent) { locked = append(locked, e) })
+
+	for range 6 {
+		_, _ = authService.Login("testuser", "wrongpass", "127.0.0.1", "test-agent")
+	}
+
+	require.Len(t, failed, 6)
+	assert.ErrorIs(t, failed[0].Err, auth.ErrInvalidCredentials)
+	assert.Equal(t, "testuser", failed[0].Identifier)
+	assert.Equal(t, "test-agent", failed[0].UserAgent)
+	assert.ErrorIs(t, failed[5].Err, auth.ErrAccountLocked)
+
+	require.Len(t, locked, 1, "only the attempt that locks the account reports it")
+	assert.Equal(t, "testuser", locked[0].Identifier)
+	assert.True(t, locked[0].LockedUntil.After(locked[0].OccurredAt))
+}
+
+func TestAuthService_PublishesPasswordResetEvents(t *testing.T) {
+	authService, authManager, _, _, mockEmailService, db := setupTest(t)
+	user := createTestUser(t, db)
+	_, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	require.NoError(t, authService.RequestPasswordReset("test@example.com"))
+	token := mockEmailService.GetSentEmails()[0].Token
+
+	events := recordEvents(authManager)
+	require.NoError(t, authService.ResetPassword(token, "Padasdasdasdd123!"))
+
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	require.Equal(t, []string{auth.EventSessionRevoked, auth.EventPasswordReset}, eventTypes(*events))
+	revoked := (*events)[0].(auth.SessionRevokedEvent)
+	assert.Equal(t, userID, revoked.UserID)
+	assert.Empty(t, revoked.SessionPublicID)
+	assert.Equal(t, auth.SessionRevokedPasswordReset, revoked.Reason)
+	assert.Equal(t, userID, (*events)[1].(auth.PasswordResetEvent).UserID)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrImpersonationNotAllowed     = errors.New("não é possível personificar este usuário")
+	ErrImpersonationReasonRequired = errors.New("informe o motivo da personificação")
+	ErrNotImpersonating            = errors.New("a sessão não é uma personificação")
+)
+
+// ImpersonationInfo tells the frontend that an admin is acting as the user,
+// so that it can say so on every page.
+type ImpersonationInfo struct {
+	ImpersonatorID         string    `json:"impersonator_id"`
+	ImpersonatorIdentifier string    `json:"impersonator_identifier"`
+	ImpersonatorName       string    `json:"impersonator_name"`
+	Reason                 string    `json:"reason"`
+	StartedAt              time.Time `json:"started_at"`
+	ExpiresAt              time.Time `json:"expires_at"`
+}
+
+// ImpersonateUser opens a session as the target user on behalf of an admin.
+func (s *AuthService) ImpersonateUser(adminID, targetUserID, reason, ip, userAgent string) (*LoginResponse, error) {
+	if _, err := s.userAdapter.GetUserModel(targetUserID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	session, user, err := s.authManager.Impersonate(adminID, targetUserID, reason, auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	})
+	if err != nil {
+		return nil, mapImpersonationError(err)
+	}
+
+	return &LoginResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+	}, nil
+}
+
+// StopImpersonation ends the impersonation session.
+func (s *AuthService) StopImpersonation(sessionID, ip, userAgent string) error {
+	err := s.authManager.StopImpersonation(sessionID, auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	})
+	return mapImpersonationError(err)
+}
+
+// GetImpersonation describes who is impersonating the session's user, or
+// returns nil if nobody is.
+func (s *AuthService) GetImpersonation(session *auth.Session) (*ImpersonationInfo, error) {
+	if !session.Impersonating() {
+		return nil, nil
+	}
+
+	impersonator, err := s.userAdapter.FindUserByID(session.ImpersonatorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ImpersonationInfo{
+		ImpersonatorID:         impersonator.ID,
+		ImpersonatorIdentifier: impersonator.Identifier,
+		ImpersonatorName:       impersonator.DisplayName,
+		Reason:                 session.ImpersonationReason,
+		StartedAt:              session.CreatedAt,
+		ExpiresAt:              session.ExpiresAt,
+	}, nil
+}
+
+func mapImpersonationError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrImpersonationNotAllowed):
+		return ErrImpersonationNotAllowed
+	case errors.Is(err, auth.ErrImpersonationReasonRequired):
+		return ErrImpersonationReasonRequired
+	case errors.Is(err, auth.ErrNotImpersonating), errors.Is(err, auth.ErrSessionNotFound):
+		return ErrNotImpersonating
+	case errors.Is(err, auth.ErrUserNotActive):
+		return ErrUserNotActive
+	default:
+		return err
+	}
+}
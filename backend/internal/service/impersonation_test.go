@@ -0,0 +1,128 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func createTestAdmin(t *testing.T, db *gorm.DB) *models.User {
+	admin := &models.User{
+		Username:     "admin",
+		Email:        "admin@example.com",
+		DisplayName:  "Admin",
+		PasswordHash: "unused",
+		Active:       true,
+		Role:         "admin",
+	}
+	require.NoError(t, db.Create(admin).Error)
+	return admin
+}
+
+func TestAuthService_Impersonation(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	admin := createTestAdmin(t, db)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	response, err := authService.ImpersonateUser(adminID, userID, "  ticket #42  ", "10.0.0.1", "Firefox")
+	require.NoError(t, err)
+	assert.Equal(t, userID, response.User.ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), response.ExpiresAt, time.Minute)
+
+	session, current, err := authManager.ValidateSession(response.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, current.ID)
+	assert.True(t, session.Impersonating())
+
+	info, err := authService.GetImpersonation(session)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, adminID, info.ImpersonatorID)
+	assert.Equal(t, "admin", info.ImpersonatorIdentifier)
+	assert.Equal(t, "ticket #42", info.Reason)
+
+	sessions, err := authService.ListSessions(userID, session.PublicID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.True(t, sessions[0].Impersonated)
+
+	require.NoError(t, authService.StopImpersonation(response.SessionID, "10.0.0.1", "Firefox"))
+	_, _, err = authManager.ValidateSession(response.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, auth.ErrSessionNotFound)
+
+	var events []models.AuditEvent
+	require.NoError(t, db.Order("id").Find(&events).Error)
+	require.Len(t, events, 2)
+	assert.Equal(t, auth.AuditImpersonationStarted, events[0].Type)
+	assert.Equal(t, admin.ID, *events[0].ActorID)
+	assert.Equal(t, user.ID, *events[0].UserID)
+	assert.Equal(t, "10.0.0.1", events[0].IP)
+	var details map[string]string
+	require.NoError(t, json.Unmarshal([]byte(events[0].Details), &details))
+	assert.Equal(t, "ticket #42", details["reason"])
+	assert.Equal(t, session.PublicID, details["session_id"])
+	assert.Equal(t, auth.AuditImpersonationStopped, events[1].Type)
+}
+
+func TestAuthService_ImpersonationRules(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	admin := createTestAdmin(t, db)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.ImpersonateUser(adminID, userID, "   ", "", "")
+	assert.ErrorIs(t, err, ErrImpersonationReasonRequired)
+
+	_, err = authService.ImpersonateUser(adminID, "9999", "support", "", "")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// Admins cannot impersonate themselves or other admins
+	_, err = authService.ImpersonateUser(adminID, adminID, "support", "", "")
+	assert.ErrorIs(t, err, ErrImpersonationNotAllowed)
+
+	// Only admins can impersonate
+	_, err = authService.ImpersonateUser(userID, adminID, "support", "", "")
+	assert.ErrorIs(t, err, ErrImpersonationNotAllowed)
+
+	require.NoError(t, db.Model(user).Update("active", false).Error)
+	_, err = authService.ImpersonateUser(adminID, userID, "support", "", "")
+	assert.ErrorIs(t, err, ErrUserNotActive)
+
+	// A regular session cannot be stopped as an impersonation
+	require.NoError(t, db.Model(user).Update("active", true).Error)
+	login, err := authService.Login("testuser", "password123", "", "")
+	require.NoError(t, err)
+	assert.ErrorIs(t, authService.StopImpersonation(login.SessionID, "", ""), ErrNotImpersonating)
+}
+
+func TestAuthService_ImpersonationEndsWhenAdminIsDemoted(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	admin := createTestAdmin(t, db)
+
+	response, err := authService.ImpersonateUser(strconv.FormatUint(uint64(admin.ID), 10), strconv.FormatUint(uint64(user.ID), 10), "support", "", "")
+	require.NoError(t, err)
+
+	other := &models.User{Username: "other-admin", Email: "other-admin@example.com", PasswordHash: "unused", Active: true, Role: "admin"}
+	require.NoError(t, db.Create(other).Error)
+	_, err = authService.UpdateUserRole(strconv.FormatUint(uint64(other.ID), 10), strconv.FormatUint(uint64(admin.ID), 10), "user")
+	require.NoError(t, err)
+
+	_, _, err = authManager.ValidateSession(response.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, auth.ErrSessionRevoked)
+
+	var count int64
+	require.NoError(t, db.Model(&models.AuditEvent{}).Where("type = ?", auth.AuditImpersonationStopped).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
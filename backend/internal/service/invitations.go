@@ -0,0 +1,209 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/validation"
+)
+
+var (
+	ErrInvitationsUnavailable  = errors.New("convites indisponíveis")
+	ErrInvalidInvitationEmail  = errors.New("email do convite inválido")
+	ErrInvitationNotFound      = errors.New("convite não encontrado")
+	ErrInvitationInvalid       = errors.New("convite inválido ou já utilizado")
+	ErrInvitationExpired       = errors.New("convite expirado")
+	ErrInvitationEmailMismatch = errors.New("o convite foi enviado para outro email")
+	ErrInvitationRequired      = errors.New("o cadastro exige um convite")
+	ErrInvitationAccountExists = errors.New("já existe uma conta com este email; entre para aceitar o convite")
+)
+
+// InvitationInfo describes a pending invitation to the organization's admins.
+type InvitationInfo struct {
+	ID          string    `json:"id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	InviterID   string    `json:"inviter_id"`
+	InviterName string    `json:"inviter_name"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Expired     bool      `json:"expired"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InvitationPreview describes an invitation to whoever holds its token, so
+// they can choose between signing up and signing in to accept it.
+type InvitationPreview struct {
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	InviterName      string    `json:"inviter_name"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	HasAccount       bool      `json:"has_account"` // an account with the invited email exists
+}
+
+// InviteOrganizationMemberInput names the email to invite. An empty Role
+// invites them as a member.
+type InviteOrganizationMemberInput struct {
+	Email string
+	Role  string
+}
+
+// InviteOrganizationMember emails an invitation to join the organization.
+func (s *AuthService) InviteOrganizationMember(orgID, actorID string, input InviteOrganizationMemberInput) (*InvitationInfo, error) {
+	emailAddr := strings.TrimSpace(input.Email)
+	if err := validation.ValidateEmail(emailAddr); err != nil {
+		return nil, ErrInvalidInvitationEmail
+	}
+
+	role := input.Role
+	if role == "" {
+		role = auth.OrganizationRoleMember
+	}
+
+	invitation, token, err := s.authManager.CreateInvitation(orgID, actorID, emailAddr, role)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+
+	s.sendInvitationEmail(invitation, token)
+	info := toInvitationInfo(invitation)
+	return &info, nil
+}
+
+// ListOrganizationInvitations returns the organization's pending invitations.
+func (s *AuthService) ListOrganizationInvitations(orgID string) ([]InvitationInfo, error) {
+	invitations, err := s.authManager.ListInvitations(orgID)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+
+	result := make([]InvitationInfo, 0, len(invitations))
+	for i := range invitations {
+		result = append(result, toInvitationInfo(&invitations[i]))
+	}
+	return result, nil
+}
+
+// ResendOrganizationInvitation emails a pending invitation again with a new
+// link and a fresh expiry.
+func (s *AuthService) ResendOrganizationInvitation(orgID, actorID, invitationID string) (*InvitationInfo, error) {
+	invitation, token, err := s.authManager.ResendInvitation(orgID, actorID, invitationID)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+
+	s.sendInvitationEmail(invitation, token)
+	info := toInvitationInfo(invitation)
+	return &info, nil
+}
+
+// RevokeOrganizationInvitation cancels a pending invitation.
+func (s *AuthService) RevokeOrganizationInvitation(orgID, actorID, invitationID string) error {
+	return mapInvitationError(s.authManager.RevokeInvitation(orgID, actorID, invitationID))
+}
+
+// GetInvitation describes the invitation a token belongs to.
+func (s *AuthService) GetInvitation(token string) (*InvitationPreview, error) {
+	invitation, err := s.authManager.PendingInvitation(token)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+
+	_, err = s.userAdapter.FindByEmail(invitation.Email)
+	return &InvitationPreview{
+		OrganizationName: invitation.OrganizationName,
+		Email:            invitation.Email,
+		Role:             invitation.Role,
+		InviterName:      invitation.InviterName,
+		ExpiresAt:        invitation.ExpiresAt,
+		HasAccount:       err == nil,
+	}, nil
+}
+
+// AcceptInvitation adds the signed-in user to the organization they were
+// invited to. The invitation must have been sent to their email.
+func (s *AuthService) AcceptInvitation(token, userID string) (*OrganizationInfo, error) {
+	invitation, err := s.authManager.AcceptInvitation(token, userID)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+	return s.GetOrganization(invitation.OrganizationID, userID)
+}
+
+// RegisterWithInvitation creates an account for the invited email and adds it
+// to the organization. The email needs no confirmation since the invitation
+// link was delivered there.
+func (s *AuthService) RegisterWithInvitation(token, username, password, displayName string) (*models.User, error) {
+	invitation, err := s.authManager.PendingInvitation(token)
+	if err != nil {
+		return nil, mapInvitationError(err)
+	}
+
+	if _, err := s.userAdapter.FindUserByIdentifier(username); err == nil {
+		return nil, errors.New("username already exists")
+	}
+	if _, err := s.userAdapter.FindByEmail(invitation.Email); err == nil {
+		return nil, ErrInvitationAccountExists
+	}
+
+	userData, err := s.userAdapter.CreateUser(auth.CreateUserInput{
+		Identifier:  username,
+		Email:       invitation.Email,
+		Passphrase:  password,
+		DisplayName: displayName,
+		Attributes:  map[string]any{"email_verified": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.publishUserRegistered(userData, auth.RegistrationInvitation)
+
+	// The account stands even if the invitation was accepted concurrently
+	if _, err := s.authManager.AcceptInvitation(token, userData.ID); err != nil {
+		slog.Error("failed to accept invitation after registration", "user_id", userData.ID, "err", err)
+	}
+
+	return s.userAdapter.GetUserModel(userData.ID)
+}
+
+func (s *AuthService) sendInvitationEmail(invitation *auth.Invitation, token string) {
+	if err := s.emailService.SendInvitationEmail(invitation.Email, token, invitation.OrganizationName, invitation.InviterName); err != nil {
+		slog.Error("failed to send invitation email", "err", err)
+	}
+}
+
+func toInvitationInfo(invitation *auth.Invitation) InvitationInfo {
+	return InvitationInfo{
+		ID:          invitation.ID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		InviterID:   invitation.InviterID,
+		InviterName: invitation.InviterName,
+		ExpiresAt:   invitation.ExpiresAt,
+		Expired:     time.Now().After(invitation.ExpiresAt),
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
+
+func mapInvitationError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvitationsNotSupported):
+		return ErrInvitationsUnavailable
+	case errors.Is(err, auth.ErrInvitationNotFound):
+		return ErrInvitationNotFound
+	case errors.Is(err, auth.ErrInvitationInvalid):
+		return ErrInvitationInvalid
+	case errors.Is(err, auth.ErrInvitationExpired):
+		return ErrInvitationExpired
+	case errors.Is(err, auth.ErrInvitationEmailMismatch):
+		return ErrInvitationEmailMismatch
+	case errors.Is(err, auth.ErrRegistrationClosed):
+		return ErrInvitationRequired
+	default:
+		return mapOrganizationError(err)
+	}
+}
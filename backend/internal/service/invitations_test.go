@@ -0,0 +1,174 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_InvitationAcceptedByExistingUser(t *testing.T) {
+	authService, _, _, _, mockEmailService, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	user := createTestUser(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	org, err := authService.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+
+	_, err = authService.InviteOrganizationMember(org.ID, ownerID, InviteOrganizationMemberInput{Email: "not-an-email"})
+	assert.ErrorIs(t, err, ErrInvalidInvitationEmail)
+
+	invitation, err := authService.InviteOrganizationMember(org.ID, ownerID, InviteOrganizationMemberInput{
+		Email: " test@example.com ",
+		Role:  auth.OrganizationRoleAdmin,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", invitation.Email)
+	assert.Equal(t, "Admin", invitation.InviterName)
+
+	sent := mockEmailService.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, email.MockEmailInvitation, sent[0].Kind)
+	assert.Equal(t, "test@example.com", sent[0].To)
+	assert.Equal(t, "Acme", sent[0].OrganizationName)
+	token := sent[0].Token
+
+	preview, err := authService.GetInvitation(token)
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", preview.OrganizationName)
+	assert.True(t, preview.HasAccount)
+
+	// Someone else's account cannot take the invitation
+	_, err = authService.AcceptInvitation(token, ownerID)
+	assert.ErrorIs(t, err, ErrInvitationEmailMismatch)
+
+	joined, err := authService.AcceptInvitation(token, userID)
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, joined.ID)
+	assert.Equal(t, auth.OrganizationRoleAdmin, joined.Role)
+
+	_, err = authService.AcceptInvitation(token, userID)
+	assert.ErrorIs(t, err, ErrInvitationInvalid)
+	invitations, err := authService.ListOrganizationInvitations(org.ID)
+	require.NoError(t, err)
+	assert.Empty(t, invitations)
+
+	// Members are not invited again
+	_, err = authService.InviteOrganizationMember(org.ID, ownerID, InviteOrganizationMemberInput{Email: "test@example.com"})
+	assert.ErrorIs(t, err, ErrAlreadyOrganizationMember)
+}
+
+func TestAuthService_InvitationManagement(t *testing.T) {
+	authService, _, _, _, mockEmailService, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	user := createTestUser(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	org, err := authService.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+	joinOrganization(t, authService, mockEmailService, org.ID, ownerID, user)
+	mockEmailService.ClearSentEmails()
+
+	// Members cannot invite; only owners invite owners
+	_, err = authService.InviteOrganizationMember(org.ID, userID, InviteOrganizationMemberInput{Email: "new@example.com"})
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, ownerID, userID, auth.OrganizationRoleAdmin)
+	require.NoError(t, err)
+	_, err = authService.InviteOrganizationMember(org.ID, userID, InviteOrganizationMemberInput{Email: "new@example.com", Role: auth.OrganizationRoleOwner})
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+
+	invitation, err := authService.InviteOrganizationMember(org.ID, userID, InviteOrganizationMemberInput{Email: "new@example.com"})
+	require.NoError(t, err)
+	firstToken := mockEmailService.GetSentEmails()[0].Token
+
+	resent, err := authService.ResendOrganizationInvitation(org.ID, userID, invitation.ID)
+	require.NoError(t, err)
+	assert.Equal(t, invitation.ID, resent.ID)
+	sent := mockEmailService.GetSentEmails()
+	require.Len(t, sent, 2)
+	assert.NotEqual(t, firstToken, sent[1].Token)
+
+	// The earlier link stops working
+	_, err = authService.GetInvitation(firstToken)
+	assert.ErrorIs(t, err, ErrInvitationInvalid)
+
+	require.NoError(t, db.Model(&models.OrganizationInvitation{}).
+		Where("id = ?", invitation.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = authService.GetInvitation(sent[1].Token)
+	assert.ErrorIs(t, err, ErrInvitationExpired)
+
+	invitations, err := authService.ListOrganizationInvitations(org.ID)
+	require.NoError(t, err)
+	require.Len(t, invitations, 1)
+	assert.True(t, invitations[0].Expired)
+
+	require.NoError(t, authService.RevokeOrganizationInvitation(org.ID, userID, invitation.ID))
+	err = authService.RevokeOrganizationInvitation(org.ID, userID, invitation.ID)
+	assert.ErrorIs(t, err, ErrInvitationNotFound)
+}
+
+func TestAuthService_RegisterWithInvitation(t *testing.T) {
+	authService, _, _, _, mockEmailService, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+
+	org, err := authService.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+	_, err = authService.InviteOrganizationMember(org.ID, ownerID, InviteOrganizationMemberInput{Email: "new@example.com"})
+	require.NoError(t, err)
+	token := mockEmailService.GetSentEmails()[0].Token
+	mockEmailService.ClearSentEmails()
+
+	_, err = authService.RegisterWithInvitation("wrong-token", "newuser", "password123", "New User")
+	assert.ErrorIs(t, err, ErrInvitationInvalid)
+
+	user, err := authService.RegisterWithInvitation(token, "newuser", "password123", "New User")
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.True(t, user.EmailVerified)
+	assert.Empty(t, mockEmailService.GetSentEmails(), "no confirmation email for an invited address")
+
+	organizations, err := authService.ListOrganizations(strconv.FormatUint(uint64(user.ID), 10))
+	require.NoError(t, err)
+	require.Len(t, organizations, 1)
+	assert.Equal(t, auth.OrganizationRoleMember, organizations[0].Role)
+
+	_, err = authService.RegisterWithInvitation(token, "another", "password123", "Another")
+	assert.ErrorIs(t, err, ErrInvitationInvalid)
+}
+
+func TestAuthService_InviteOnlyRegistration(t *testing.T) {
+	_, _, userAdapter, sessionAdapter, mockEmailService, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+
+	authConfig := auth.DefaultAuthConfig()
+	authConfig.InviteOnlyRegistration = true
+	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+	authManager.SetOrganizationAdapter(gormadapter.NewOrganizationAdapter(db))
+	authManager.SetInvitationAdapter(gormadapter.NewInvitationAdapter(db))
+	authService := NewAuthService(authManager, sessionAdapter, userAdapter, mockEmailService)
+
+	_, err := authService.Register("newuser", "new@example.com", "password123", "New User")
+	assert.ErrorIs(t, err, ErrInvitationRequired)
+
+	org, err := authService.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+	_, err = authService.InviteOrganizationMember(org.ID, ownerID, InviteOrganizationMemberInput{Email: "new@example.com"})
+	require.NoError(t, err)
+
+	user, err := authService.RegisterWithInvitation(mockEmailService.GetSentEmails()[0].Token, "newuser", "password123", "New User")
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+}
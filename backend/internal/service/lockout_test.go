@@ -0,0 +1,51 @@
+package service
+
+import (
+	"testing"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/email"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// newReplica returns a service with its own AuthManager, as another backend
+// process would have, sharing the database lockout store
+func newReplica(t *testing.T, db *gorm.DB) *AuthService {
+	t.Helper()
+
+	userAdapter := gormadapter.NewUserAdapter(db)
+	sessionAdapter := gormadapter.NewSessionAdapter(db)
+	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, auth.DefaultAuthConfig())
+	authManager.SetLockoutAdapter(gormadapter.NewLockoutAdapter(db))
+
+	return NewAuthService(authManager, sessionAdapter, userAdapter, email.NewMockEmailService())
+}
+
+func TestAuthService_LockoutSharedBetweenReplicas(t *testing.T) {
+	_, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	first := newReplica(t, db)
+	second := newReplica(t, db)
+
+	// Failures spread over both replicas add up
+	for i := range 5 {
+		replica := first
+		if i%2 == 1 {
+			replica = second
+		}
+		_, err := replica.Login("testuser", "wrongpass", "127.0.0.1", "test-agent")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	_, err := first.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+
+	// A restarted process still sees the lock
+	_, err = newReplica(t, db).Login("testuser", "password123", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+
+	"gosveltekit/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+// ErrMagicLinkUnavailable is returned when passwordless login is not enabled
+var ErrMagicLinkUnavailable = errors.New("login por link indisponível")
+
+// RequestMagicLink emails a single-use login link. Like RequestPasswordReset
+// it does not reveal whether the address belongs to an account, and inactive
+// or locked accounts silently get no email.
+func (s *AuthService) RequestMagicLink(emailAddr string) error {
+	user, err := s.userAdapter.FindByEmail(emailAddr)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	userData, err := s.userAdapter.FindUserByID(strconv.FormatUint(uint64(user.ID), 10))
+	if err != nil {
+		return err
+	}
+
+	link, err := s.authManager.CreateMagicLink(userData)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUserNotActive), errors.Is(err, auth.ErrAccountLocked):
+			return nil
+		case errors.Is(err, auth.ErrMagicLinkNotSupported):
+			return ErrMagicLinkUnavailable
+		default:
+			return err
+		}
+	}
+
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.Username
+	}
+
+	if err := s.emailService.SendMagicLinkEmail(user.Email, link.Token, user.Username, displayName); err != nil {
+		slog.Error("failed to send magic link email", "err", err)
+	}
+
+	return nil
+}
+
+// LoginWithMagicLink redeems an emailed link and creates a session, or a
+// second-factor challenge when the user has one enabled.
+func (s *AuthService) LoginWithMagicLink(token, ip, userAgent string) (*LoginResponse, error) {
+	metadata := auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	session, user, err := s.authManager.LoginWithMagicLink(token, metadata)
+	if err != nil {
+		if response, ok := twoFactorChallengeResponse(err); ok {
+			return response, nil
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrMagicLinkInvalid):
+			return nil, ErrInvalidToken
+		case errors.Is(err, auth.ErrMagicLinkExpired):
+			return nil, ErrExpiredToken
+		case errors.Is(err, auth.ErrMagicLinkNotSupported):
+			return nil, ErrMagicLinkUnavailable
+		case errors.Is(err, auth.ErrUserNotActive):
+			return nil, ErrUserNotActive
+		case errors.Is(err, auth.ErrAccountLocked):
+			return nil, ErrAccountLocked
+		case errors.Is(err, auth.ErrEmailNotVerified):
+			return nil, ErrEmailNotVerified
+		default:
+			return nil, err
+		}
+	}
+
+	return &LoginResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestTestMagicLink asks for a link and returns the token from the sent email
+func requestTestMagicLink(t *testing.T, authService *AuthService, mockEmail *email.MockEmailService, address string) string {
+	t.Helper()
+
+	mockEmail.ClearSentEmails()
+	require.NoError(t, authService.RequestMagicLink(address))
+
+	sent := mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, email.MockEmailMagicLink, sent[0].Kind)
+	assert.Equal(t, address, sent[0].To)
+	return sent[0].Token
+}
+
+func TestAuthService_MagicLinkLogin(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	user := createTestUser(t, db)
+
+	token := requestTestMagicLink(t, authService, mockEmail, user.Email)
+
+	resp, err := authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.SessionID)
+	assert.Equal(t, strconv.FormatUint(uint64(user.ID), 10), resp.User.ID)
+
+	// Single use
+	_, err = authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// The password reset token is left alone
+	var updated models.User
+	require.NoError(t, db.First(&updated, user.ID).Error)
+	assert.Empty(t, updated.ResetToken)
+}
+
+func TestAuthService_MagicLinkLogin_OnlyLatestLinkWorks(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	user := createTestUser(t, db)
+
+	first := requestTestMagicLink(t, authService, mockEmail, user.Email)
+	second := requestTestMagicLink(t, authService, mockEmail, user.Email)
+
+	_, err := authService.LoginWithMagicLink(first, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = authService.LoginWithMagicLink(second, "127.0.0.1", "test-agent")
+	assert.NoError(t, err)
+}
+
+func TestAuthService_MagicLinkLogin_Expired(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	user := createTestUser(t, db)
+
+	token := requestTestMagicLink(t, authService, mockEmail, user.Email)
+	require.NoError(t, db.Model(&models.MagicLinkToken{}).
+		Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err := authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
+
+func TestAuthService_MagicLinkLogin_RespectsAccountState(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	user := createTestUser(t, db)
+
+	// Unknown addresses and locked accounts get no email, without an error
+	require.NoError(t, authService.RequestMagicLink("nobody@example.com"))
+	for range 5 {
+		_, _ = authService.Login("testuser", "wrongpass", "127.0.0.1", "test-agent")
+	}
+	require.NoError(t, authService.RequestMagicLink(user.Email))
+	assert.Empty(t, mockEmail.GetSentEmails())
+
+	// A link issued while active is refused once the account is deactivated
+	authService, _, _, _, mockEmail, db = setupTest(t)
+	user = createTestUser(t, db)
+	token := requestTestMagicLink(t, authService, mockEmail, user.Email)
+	require.NoError(t, db.Model(user).Update("active", false).Error)
+
+	_, err := authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrUserNotActive)
+}
+
+func TestAuthService_MagicLinkLogin_RequiresSecondFactor(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	user := createTestUser(t, db)
+	_ = enableTestUserTOTP(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	token := requestTestMagicLink(t, authService, mockEmail, user.Email)
+
+	resp, err := authService.LoginWithMagicLink(token, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorRequired)
+	assert.NotEmpty(t, resp.ChallengeToken)
+	assert.Empty(t, resp.SessionID)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/email"
+)
+
+// ErrNewDeviceAlertsUnavailable is returned when new-device alerts are not enabled
+var ErrNewDeviceAlertsUnavailable = errors.New("avisos de novo acesso indisponíveis")
+
+// RevokeSessionByLink ends the session named in a new-device alert email. It
+// needs no sign-in: the link itself proves the request comes from the
+// account's inbox. A session that already ended is not an error.
+func (s *AuthService) RevokeSessionByLink(token string) error {
+	device, err := s.authManager.RevokeSessionByLink(token)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrSessionRevokeLinkInvalid):
+			return ErrInvalidToken
+		case errors.Is(err, auth.ErrSessionRevokeLinkExpired):
+			return ErrExpiredToken
+		case errors.Is(err, auth.ErrKnownDevicesNotSupported):
+			return ErrNewDeviceAlertsUnavailable
+		default:
+			return err
+		}
+	}
+
+	if err := s.RevokeSession(device.UserID, device.SessionPublicID, ""); err != nil && !errors.Is(err, ErrAccessDenied) {
+		return err
+	}
+	return nil
+}
+
+// EnableNewDeviceAlerts emails users about the sign-ins from new devices that
+// the auth manager reports, until the returned function is called. Enable it
+// on one service per AuthManager, or every user gets one email per service.
+func (s *AuthService) EnableNewDeviceAlerts() func() {
+	return auth.OnAsync(s.authManager.Events(), s.sendNewSignInEmail)
+}
+
+// sendNewSignInEmail tells the user about a sign-in from a new device. It
+// runs outside the request, so failures are only logged.
+func (s *AuthService) sendNewSignInEmail(e auth.NewDeviceSignInEvent) {
+	user, err := s.userAdapter.FindUserByID(e.UserID)
+	if err != nil {
+		slog.Error("failed to load user for new sign-in email", "user_id", e.UserID, "err", err)
+		return
+	}
+	if user.Email == "" {
+		return
+	}
+
+	displayName := user.DisplayName
+	if displayName == "" {
+		displayName = user.Identifier
+	}
+
+	device := e.UserAgentFamily
+	if device == "" {
+		device = "desconhecido"
+	}
+
+	signIn := email.NewSignIn{Device: device, IP: e.IP, At: e.OccurredAt}
+	if err := s.emailService.SendNewSignInEmail(user.Email, e.RevokeToken, displayName, signIn); err != nil {
+		slog.Error("failed to send new sign-in email", "user_id", e.UserID, "err", err)
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loginAndWait signs in and waits for the new sign-in email, if any, to be sent
+func loginAndWait(t *testing.T, authService *AuthService, authManager *auth.AuthManager, ip, userAgent string) *LoginResponse {
+	t.Helper()
+
+	response, err := authService.Login("testuser", "password123", ip, userAgent)
+	require.NoError(t, err)
+	authManager.Events().Wait()
+	return response
+}
+
+func TestAuthService_NewDeviceSignInEmail(t *testing.T) {
+	authService, authManager, _, _, mockEmail, db := setupTest(t)
+	authManager.SetKnownDeviceAdapter(gormadapter.NewKnownDeviceAdapter(db))
+	t.Cleanup(authService.EnableNewDeviceAlerts())
+	user := createTestUser(t, db)
+
+	// The first device of an account is not news, nor is another address
+	// on the same network
+	first := loginAndWait(t, authService, authManager, "198.51.100.7", firefoxLinux)
+	loginAndWait(t, authService, authManager, "198.51.100.8", firefoxLinux)
+	assert.Empty(t, mockEmail.GetSentEmails())
+
+	before := time.Now()
+	second := loginAndWait(t, authService, authManager, "203.0.113.9", chromeWindows)
+	sent := mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, email.MockEmailNewSignIn, sent[0].Kind)
+	assert.Equal(t, user.Email, sent[0].To)
+	assert.Equal(t, "Chrome/Windows", sent[0].SignIn.Device)
+	assert.Equal(t, "203.0.113.9", sent[0].SignIn.IP)
+	assert.WithinDuration(t, before, sent[0].SignIn.At, time.Minute)
+	token := sent[0].Token
+	require.NotEmpty(t, token)
+
+	// The link ends that session only
+	require.NoError(t, authService.RevokeSessionByLink(token))
+	_, _, err := authService.ValidateSession(second.SessionID, auth.SessionMetadata{})
+	assert.Error(t, err)
+	_, _, err = authService.ValidateSession(first.SessionID, auth.SessionMetadata{})
+	assert.NoError(t, err)
+	assert.ErrorIs(t, authService.RevokeSessionByLink(token), ErrInvalidToken)
+
+	// The revoked device is forgotten, so signing in from it is reported again
+	mockEmail.ClearSentEmails()
+	loginAndWait(t, authService, authManager, "203.0.113.9", chromeWindows)
+	sent = mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+
+	// An expired link is refused
+	require.NoError(t, db.Model(&models.KnownDevice{}).
+		Where("user_agent_family = ?", "Chrome/Windows").
+		Update("revoke_expires_at", time.Now().Add(-time.Minute)).Error)
+	assert.ErrorIs(t, authService.RevokeSessionByLink(sent[0].Token), ErrExpiredToken)
+
+	// A device not seen within KnownDeviceTTL is new again
+	mockEmail.ClearSentEmails()
+	require.NoError(t, db.Model(&models.KnownDevice{}).
+		Where("user_agent_family = ?", "Firefox/Linux").
+		Update("last_seen_at", time.Now().Add(-auth.DefaultAuthConfig().KnownDeviceTTL-time.Hour)).Error)
+	loginAndWait(t, authService, authManager, "198.51.100.7", firefoxLinux)
+	sent = mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "Firefox/Linux", sent[0].SignIn.Device)
+
+	activity, err := authService.ListSecurityActivity(strconv.FormatUint(uint64(user.ID), 10), nil)
+	require.NoError(t, err)
+	var newDevices int
+	for _, event := range activity.Items {
+		if event.Type == auth.EventNewDeviceSignIn {
+			newDevices++
+			assert.NotContains(t, event.Details, "revoke_token")
+		}
+	}
+	assert.Equal(t, 3, newDevices)
+}
+
+func TestAuthService_EnableNewDeviceAlerts(t *testing.T) {
+	authService, authManager, userAdapter, sessionAdapter, mockEmail, db := setupTest(t)
+	authManager.SetKnownDeviceAdapter(gormadapter.NewKnownDeviceAdapter(db))
+	_ = createTestUser(t, db)
+	loginAndWait(t, authService, authManager, "198.51.100.7", firefoxLinux)
+
+	// Services sharing a manager send nothing unless alerts are enabled, and
+	// enabling them on one sends a single email
+	_ = NewAuthService(authManager, sessionAdapter, userAdapter, mockEmail)
+	loginAndWait(t, authService, authManager, "203.0.113.9", chromeWindows)
+	assert.Empty(t, mockEmail.GetSentEmails())
+
+	stop := authService.EnableNewDeviceAlerts()
+	loginAndWait(t, authService, authManager, "192.0.2.4", chromeWindows)
+	assert.Len(t, mockEmail.GetSentEmails(), 1)
+
+	stop()
+	mockEmail.ClearSentEmails()
+	loginAndWait(t, authService, authManager, "192.0.2.200", firefoxLinux)
+	assert.Empty(t, mockEmail.GetSentEmails())
+}
+
+func TestAuthService_RevokeSessionByLinkUnavailable(t *testing.T) {
+	authService, _, _, _, _, _ := setupTest(t)
+
+	assert.ErrorIs(t, authService.RevokeSessionByLink("token"), ErrNewDeviceAlertsUnavailable)
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"gosveltekit/internal/auth"
+)
+
+var (
+	ErrOAuthUnavailable      = errors.New("login social indisponível")
+	ErrOAuthProviderNotFound = errors.New("provedor de login não encontrado")
+	ErrInvalidOAuthState     = errors.New("solicitação de login expirada ou inválida")
+	ErrOAuthFailed           = errors.New("não foi possível confirmar a identidade com o provedor")
+	ErrOAuthEmailNotVerified = errors.New("o provedor não confirmou o email da conta")
+	ErrOAuthAccountConflict  = errors.New("já existe uma conta com este email; entre com a senha para vinculá-la")
+)
+
+// OAuthRedirect is where the browser must go to sign in with a provider.
+// State must be kept by the client and compared on the callback.
+type OAuthRedirect struct {
+	URL       string    `json:"url"`
+	State     string    `json:"-"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// IdentityInfo describes an external account linked to the user.
+type IdentityInfo struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListOAuthProviders returns the names of the enabled social login providers.
+func (s *AuthService) ListOAuthProviders() []string {
+	return s.authManager.OAuthProviders()
+}
+
+// BeginOAuthLogin starts a social login with the given provider.
+func (s *AuthService) BeginOAuthLogin(provider string) (*OAuthRedirect, error) {
+	authorization, err := s.authManager.BeginOAuthLogin(provider)
+	if err != nil {
+		return nil, mapOAuthError(err)
+	}
+
+	return &OAuthRedirect{
+		URL:       authorization.URL,
+		State:     authorization.State,
+		ExpiresAt: authorization.ExpiresAt,
+	}, nil
+}
+
+// CompleteOAuthLogin handles the provider callback and creates a session,
+// or a second-factor challenge when the user has one enabled.
+func (s *AuthService) CompleteOAuthLogin(
+	ctx context.Context,
+	provider, state, code, ip, userAgent string,
+) (*LoginResponse, error) {
+	metadata := auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	session, user, err := s.authManager.CompleteOAuthLogin(ctx, provider, state, code, metadata)
+	if err != nil {
+		if response, ok := twoFactorChallengeResponse(err); ok {
+			return response, nil
+		}
+		if errors.Is(err, auth.ErrOAuthExchangeFailed) {
+			slog.Warn("oauth login rejected", "provider", provider, "ip", ip, "err", err)
+		}
+		return nil, mapOAuthError(err)
+	}
+
+	return &LoginResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+	}, nil
+}
+
+// ListIdentities returns the external accounts linked to the authenticated user.
+func (s *AuthService) ListIdentities(userID string) ([]IdentityInfo, error) {
+	identities, err := s.authManager.ListIdentities(userID)
+	if err != nil {
+		return nil, mapOAuthError(err)
+	}
+
+	result := make([]IdentityInfo, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, IdentityInfo{
+			ID:        identity.ID,
+			Provider:  identity.Provider,
+			Email:     identity.Email,
+			CreatedAt: identity.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+func mapOAuthError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrOAuthNotSupported):
+		return ErrOAuthUnavailable
+	case errors.Is(err, auth.ErrOAuthProviderNotFound):
+		return ErrOAuthProviderNotFound
+	case errors.Is(err, auth.ErrOAuthStateInvalid):
+		return ErrInvalidOAuthState
+	case errors.Is(err, auth.ErrOAuthExchangeFailed):
+		return ErrOAuthFailed
+	case errors.Is(err, auth.ErrOAuthEmailNotVerified):
+		return ErrOAuthEmailNotVerified
+	case errors.Is(err, auth.ErrOAuthAccountConflict):
+		return ErrOAuthAccountConflict
+	case errors.Is(err, auth.ErrRegistrationClosed):
+		return ErrInvitationRequired
+	case errors.Is(err, auth.ErrUserNotActive):
+		return ErrUserNotActive
+	case errors.Is(err, auth.ErrAccountLocked):
+		return ErrAccountLocked
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		return ErrEmailNotVerified
+	default:
+		return err
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+)
+
+var (
+	ErrOAuthClientsUnavailable  = errors.New("clientes OAuth indisponíveis")
+	ErrOAuthClientNotFound      = errors.New("cliente OAuth não encontrado")
+	ErrInvalidOAuthClientName   = errors.New("informe um nome para o cliente OAuth")
+	ErrInvalidOAuthClientScope  = errors.New("escopos de cliente OAuth inválidos")
+	ErrInvalidClientCredentials = errors.New("credenciais do cliente inválidas")
+)
+
+// CreateOAuthClientInput defines payload for registering a machine client.
+// An empty Role registers the client with the "user" role.
+type CreateOAuthClientInput struct {
+	Name   string
+	Role   string
+	Scopes []string
+}
+
+// OAuthClientInfo describes a machine client to admins. The secret is never
+// shown again after the client is created.
+type OAuthClientInfo struct {
+	ClientID  string    `json:"client_id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role"`
+	Scopes    []string  `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreatedOAuthClient is a new machine client together with its secret, which
+// the admin must copy now.
+type CreatedOAuthClient struct {
+	OAuthClientInfo
+	ClientSecret string `json:"client_secret"`
+}
+
+// ListOAuthClients returns every registered machine client.
+func (s *AuthService) ListOAuthClients() ([]OAuthClientInfo, error) {
+	clients, err := s.authManager.ListOAuthClients()
+	if err != nil {
+		return nil, mapOAuthClientError(err)
+	}
+
+	result := make([]OAuthClientInfo, 0, len(clients))
+	for i := range clients {
+		result = append(result, toOAuthClientInfo(&clients[i]))
+	}
+	return result, nil
+}
+
+// CreateOAuthClient registers a machine client. Like a role change, the actor
+// must hold every permission of the client's role, and may only give it
+// scopes they could request for themselves.
+func (s *AuthService) CreateOAuthClient(actorID string, input CreateOAuthClientInput) (*CreatedOAuthClient, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return nil, ErrInvalidOAuthClientName
+	}
+
+	role := input.Role
+	if role == "" {
+		role = "user"
+	}
+	if !auth.IsRole(role) {
+		return nil, ErrInvalidRole
+	}
+
+	actor, err := s.userAdapter.FindUserByID(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireHeldPermissions(actor, auth.RolePermissions(role)); err != nil {
+		return nil, err
+	}
+	for _, scope := range input.Scopes {
+		if slices.Contains(auth.APITokenScopes, scope) && !slices.Contains(auth.AllowedAPITokenScopes(actor.Role), scope) {
+			return nil, ErrPermissionNotHeld
+		}
+	}
+
+	client, secret, err := s.authManager.CreateOAuthClient(input.Name, role, input.Scopes)
+	if err != nil {
+		return nil, mapOAuthClientError(err)
+	}
+
+	return &CreatedOAuthClient{OAuthClientInfo: toOAuthClientInfo(client), ClientSecret: secret}, nil
+}
+
+// DeleteOAuthClient removes a machine client and invalidates its tokens.
+func (s *AuthService) DeleteOAuthClient(clientID string) error {
+	return mapOAuthClientError(s.authManager.DeleteOAuthClient(clientID))
+}
+
+// IssueClientCredentialsToken implements the OAuth 2.0 client credentials
+// grant for machine clients.
+func (s *AuthService) IssueClientCredentialsToken(clientID, clientSecret string, scopes []string) (*AccessTokenResponse, error) {
+	token, err := s.authManager.IssueClientAccessToken(clientID, clientSecret, scopes)
+	if err != nil {
+		return nil, mapOAuthClientError(err)
+	}
+
+	return &AccessTokenResponse{
+		AccessToken: token.Token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(token.ExpiresAt).Round(time.Second).Seconds()),
+		Scope:       strings.Join(token.Scopes, " "),
+	}, nil
+}
+
+func toOAuthClientInfo(client *auth.OAuthClient) OAuthClientInfo {
+	return OAuthClientInfo{
+		ClientID:  client.ID,
+		Name:      client.Name,
+		Role:      client.Role,
+		Scopes:    client.Scopes,
+		CreatedAt: client.CreatedAt,
+	}
+}
+
+func mapOAuthClientError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrOAuthClientsNotSupported):
+		return ErrOAuthClientsUnavailable
+	case errors.Is(err, auth.ErrOAuthClientNotFound):
+		return ErrOAuthClientNotFound
+	case errors.Is(err, auth.ErrInvalidAPITokenScope):
+		return ErrInvalidOAuthClientScope
+	case errors.Is(err, auth.ErrInvalidClientCredentials):
+		return ErrInvalidClientCredentials
+	default:
+		return err
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_OAuthClients(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+
+	created, err := authService.CreateOAuthClient(adminID, CreateOAuthClientInput{
+		Name:   "  nightly export  ",
+		Scopes: []string{"write", "read"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "nightly export", created.Name)
+	assert.Equal(t, "user", created.Role)
+	assert.Equal(t, []string{"read", "write"}, created.Scopes)
+	assert.NotEmpty(t, created.ClientID)
+	assert.NotEmpty(t, created.ClientSecret)
+
+	clients, err := authService.ListOAuthClients()
+	require.NoError(t, err)
+	require.Len(t, clients, 1)
+	assert.Equal(t, created.ClientID, clients[0].ClientID)
+
+	// Without a scope parameter the token carries every scope of the client
+	response, err := authService.IssueClientCredentialsToken(created.ClientID, created.ClientSecret, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(response.AccessToken, auth.ClientAccessTokenPrefix))
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.Equal(t, "read write", response.Scope)
+	assert.Equal(t, int(time.Hour.Seconds()), response.ExpiresIn)
+
+	token, client, err := authManager.ValidateClientAccessToken(response.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, created.ClientID, client.ID)
+	assert.Equal(t, "user", client.Role)
+	assert.True(t, token.HasScope(auth.APITokenScopeWrite))
+
+	response, err = authService.IssueClientCredentialsToken(created.ClientID, created.ClientSecret, []string{"read"})
+	require.NoError(t, err)
+	assert.Equal(t, "read", response.Scope)
+
+	// Scopes the client was not registered with are refused
+	_, err = authService.IssueClientCredentialsToken(created.ClientID, created.ClientSecret, []string{"admin"})
+	assert.ErrorIs(t, err, ErrInvalidOAuthClientScope)
+
+	_, err = authService.IssueClientCredentialsToken(created.ClientID, "wrong", nil)
+	assert.ErrorIs(t, err, ErrInvalidClientCredentials)
+	_, err = authService.IssueClientCredentialsToken("unknown", created.ClientSecret, nil)
+	assert.ErrorIs(t, err, ErrInvalidClientCredentials)
+
+	require.NoError(t, authService.DeleteOAuthClient(created.ClientID))
+	_, _, err = authManager.ValidateClientAccessToken(response.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrClientAccessTokenNotFound)
+	assert.ErrorIs(t, authService.DeleteOAuthClient(created.ClientID), ErrOAuthClientNotFound)
+}
+
+func TestAuthService_CreateOAuthClient_Validation(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+
+	_, err := authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: " ", Scopes: []string{"read"}})
+	assert.ErrorIs(t, err, ErrInvalidOAuthClientName)
+
+	_, err = authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: "job", Role: "root", Scopes: []string{"read"}})
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: "job", Scopes: []string{"delete"}})
+	assert.ErrorIs(t, err, ErrInvalidOAuthClientScope)
+
+	// The admin scope is reserved for clients acting as admins
+	_, err = authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: "job", Scopes: []string{"admin"}})
+	assert.ErrorIs(t, err, ErrInvalidOAuthClientScope)
+
+	created, err := authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: "job", Role: "admin", Scopes: []string{"read", "admin"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "admin"}, created.Scopes)
+}
+
+func TestAuthService_CreateOAuthClient_RequiresActorPermissions(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+
+	// A regular user granted only what the route requires
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	_, err := authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{
+		Grant: []string{auth.PermissionAdminAccess, auth.PermissionOAuthClientsWrite},
+	})
+	require.NoError(t, err)
+
+	_, err = authService.CreateOAuthClient(userID, CreateOAuthClientInput{Name: "job", Role: "admin", Scopes: []string{"read"}})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld, "no admin clients without every admin permission")
+
+	// Holding every permission does not make their scopes admin ones either
+	_, err = authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{Grant: auth.Permissions})
+	require.NoError(t, err)
+	_, err = authService.CreateOAuthClient(userID, CreateOAuthClientInput{Name: "job", Role: "admin", Scopes: []string{"read", "admin"}})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld)
+
+	created, err := authService.CreateOAuthClient(userID, CreateOAuthClientInput{Name: "job", Scopes: []string{"read", "write"}})
+	require.NoError(t, err)
+	assert.Equal(t, "user", created.Role)
+}
+
+func TestAuthService_ClientAccessTokenExpiry(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+
+	created, err := authService.CreateOAuthClient(adminID, CreateOAuthClientInput{Name: "job", Scopes: []string{"read"}})
+	require.NoError(t, err)
+	response, err := authService.IssueClientCredentialsToken(created.ClientID, created.ClientSecret, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Exec("UPDATE oauth_client_tokens SET expires_at = ?", time.Now().Add(-time.Minute)).Error)
+
+	_, _, err = authManager.ValidateClientAccessToken(response.AccessToken)
+	assert.ErrorIs(t, err, auth.ErrClientAccessTokenExpired)
+}
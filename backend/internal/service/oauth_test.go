@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const testOAuthClientID = "gosveltekit-test"
+
+func setupOAuthTest(t *testing.T) (*AuthService, *gorm.DB, *testutil.FakeOIDCProvider) {
+	t.Helper()
+
+	authService, authManager, _, _, _, db := setupTest(t)
+	issuer := testutil.NewFakeOIDCProvider(t, testOAuthClientID)
+
+	provider, err := auth.NewOIDCProvider(context.Background(), "oidc", issuer.Issuer(), auth.OAuthClientConfig{
+		ClientID:     testOAuthClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oauth/oidc/callback",
+	})
+	require.NoError(t, err)
+	authManager.RegisterOAuthProvider(provider)
+
+	return authService, db, issuer
+}
+
+// signInWithOIDC runs the redirect round trip and returns the callback result
+func signInWithOIDC(t *testing.T, authService *AuthService, issuer *testutil.FakeOIDCProvider, user testutil.OIDCUser) (*LoginResponse, error) {
+	t.Helper()
+
+	redirect, err := authService.BeginOAuthLogin("oidc")
+	require.NoError(t, err)
+
+	callback := issuer.Authorize(t, redirect.URL, user)
+	return authService.CompleteOAuthLogin(
+		context.Background(),
+		"oidc",
+		callback.Query().Get("state"),
+		callback.Query().Get("code"),
+		"127.0.0.1",
+		"test-agent",
+	)
+}
+
+func TestAuthService_OAuthLogin_CreatesUserAndReusesIdentity(t *testing.T) {
+	authService, db, issuer := setupOAuthTest(t)
+	oidcUser := testutil.OIDCUser{
+		Subject:       "subject-1",
+		Email:         "new.person@example.com",
+		EmailVerified: true,
+		Name:          "New Person",
+	}
+
+	resp, err := signInWithOIDC(t, authService, issuer, oidcUser)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.SessionID)
+	assert.Equal(t, "new.person", resp.User.Identifier)
+	assert.Equal(t, "New Person", resp.User.DisplayName)
+
+	var created models.User
+	require.NoError(t, db.Where("email = ?", "new.person@example.com").First(&created).Error)
+	assert.True(t, created.EmailVerified)
+
+	// Signing in again resolves the same account through the stored identity
+	resp, err = signInWithOIDC(t, authService, issuer, oidcUser)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(created.ID), 10), resp.User.ID)
+
+	identities, err := authService.ListIdentities(resp.User.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 1)
+	assert.Equal(t, "oidc", identities[0].Provider)
+
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAuthService_OAuthLogin_LinksVerifiedEmail(t *testing.T) {
+	authService, db, issuer := setupOAuthTest(t)
+	user := createTestUser(t, db)
+	require.NoError(t, db.Model(user).Update("email_verified", true).Error)
+
+	resp, err := signInWithOIDC(t, authService, issuer, testutil.OIDCUser{
+		Subject:       "subject-2",
+		Email:         "test@example.com",
+		EmailVerified: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, strconv.FormatUint(uint64(user.ID), 10), resp.User.ID)
+}
+
+func TestAuthService_OAuthLogin_RefusesUnverifiedEmails(t *testing.T) {
+	authService, db, issuer := setupOAuthTest(t)
+	_ = createTestUser(t, db) // local email never verified
+
+	_, err := signInWithOIDC(t, authService, issuer, testutil.OIDCUser{
+		Subject:       "subject-3",
+		Email:         "test@example.com",
+		EmailVerified: true,
+	})
+	assert.ErrorIs(t, err, ErrOAuthAccountConflict)
+
+	_, err = signInWithOIDC(t, authService, issuer, testutil.OIDCUser{
+		Subject: "subject-4",
+		Email:   "someone@example.com",
+	})
+	assert.ErrorIs(t, err, ErrOAuthEmailNotVerified)
+
+	var count int64
+	db.Model(&models.UserIdentity{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestAuthService_OAuthLogin_StateIsSingleUse(t *testing.T) {
+	authService, _, issuer := setupOAuthTest(t)
+
+	redirect, err := authService.BeginOAuthLogin("oidc")
+	require.NoError(t, err)
+	callback := issuer.Authorize(t, redirect.URL, testutil.OIDCUser{
+		Subject:       "subject-5",
+		Email:         "replay@example.com",
+		EmailVerified: true,
+	})
+	state, code := callback.Query().Get("state"), callback.Query().Get("code")
+
+	_, err = authService.CompleteOAuthLogin(context.Background(), "oidc", state, code, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	_, err = authService.CompleteOAuthLogin(context.Background(), "oidc", state, code, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidOAuthState)
+}
+
+func TestAuthService_OAuthLogin_UnknownProvider(t *testing.T) {
+	authService, _, _ := setupOAuthTest(t)
+
+	_, err := authService.BeginOAuthLogin("myspace")
+	assert.ErrorIs(t, err, ErrOAuthProviderNotFound)
+	assert.Equal(t, []string{"oidc"}, authService.ListOAuthProviders())
+}
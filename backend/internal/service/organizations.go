@@ -0,0 +1,383 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/pagination"
+)
+
+var (
+	ErrOrganizationsUnavailable     = errors.New("organizações indisponíveis")
+	ErrOrganizationNotFound         = errors.New("organização não encontrada")
+	ErrInvalidOrganizationName      = errors.New("informe um nome para a organização")
+	ErrInvalidOrganizationRole      = errors.New("papel na organização inválido")
+	ErrOrganizationMemberNotFound   = errors.New("membro da organização não encontrado")
+	ErrAlreadyOrganizationMember    = errors.New("o usuário já é membro da organização")
+	ErrInsufficientOrganizationRole = errors.New("papel na organização insuficiente")
+	ErrLastOrganizationOwner        = errors.New("a organização precisa de ao menos um proprietário")
+)
+
+// OrganizationInfo describes an organization to one of its members.
+type OrganizationInfo struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Role     string    `json:"role"` // the member's role in it
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// OrganizationMemberInfo describes a member to the rest of the organization.
+type OrganizationMemberInfo struct {
+	UserID      string    `json:"user_id"`
+	Identifier  string    `json:"identifier"`
+	DisplayName string    `json:"display_name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	JoinedAt    time.Time `json:"joined_at"`
+}
+
+type ListAdminOrganizationsInput struct {
+	PaginationMode pagination.Mode
+	Offset         *pagination.OffsetQuery
+	Cursor         *pagination.CursorQuery
+}
+
+type AdminOrganizationRow struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	MemberCount int64     `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SetOrganizationAdapter enables the admin listing of organizations.
+func (s *AuthService) SetOrganizationAdapter(adapter *gormadapter.OrganizationAdapter) {
+	s.organizationAdapter = adapter
+}
+
+// ListOrganizations returns the organizations the user belongs to.
+func (s *AuthService) ListOrganizations(userID string) ([]OrganizationInfo, error) {
+	memberships, err := s.authManager.ListUserOrganizations(userID)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+
+	result := make([]OrganizationInfo, 0, len(memberships))
+	for i := range memberships {
+		result = append(result, toOrganizationInfo(&memberships[i]))
+	}
+	return result, nil
+}
+
+// CreateOrganization creates an organization owned by the user.
+func (s *AuthService) CreateOrganization(userID, name string) (*OrganizationInfo, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, ErrInvalidOrganizationName
+	}
+
+	org, err := s.authManager.CreateOrganization(userID, name)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+
+	return &OrganizationInfo{
+		ID:       org.ID,
+		Name:     org.Name,
+		Role:     auth.OrganizationRoleOwner,
+		JoinedAt: org.CreatedAt,
+	}, nil
+}
+
+// GetOrganization returns an organization the user belongs to.
+func (s *AuthService) GetOrganization(orgID, userID string) (*OrganizationInfo, error) {
+	member, err := s.authManager.OrganizationMember(orgID, userID)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+
+	info := toOrganizationInfo(member)
+	return &info, nil
+}
+
+// DeleteOrganization removes an organization the actor owns.
+func (s *AuthService) DeleteOrganization(orgID, actorID string) error {
+	return mapOrganizationError(s.authManager.DeleteOrganization(orgID, actorID))
+}
+
+// ListOrganizationMembers returns an organization's members.
+func (s *AuthService) ListOrganizationMembers(orgID string) ([]OrganizationMemberInfo, error) {
+	members, err := s.authManager.ListOrganizationMembers(orgID)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+
+	result := make([]OrganizationMemberInfo, 0, len(members))
+	for i := range members {
+		result = append(result, toOrganizationMemberInfo(&members[i]))
+	}
+	return result, nil
+}
+
+// UpdateOrganizationMemberRole changes a member's role in the organization.
+func (s *AuthService) UpdateOrganizationMemberRole(orgID, actorID, userID, role string) (*OrganizationMemberInfo, error) {
+	if err := s.authManager.UpdateOrganizationMemberRole(orgID, actorID, userID, role); err != nil {
+		return nil, mapOrganizationError(err)
+	}
+	return s.organizationMemberInfo(orgID, userID)
+}
+
+// RemoveOrganizationMember removes a member from the organization, or lets
+// the actor leave it when userID is their own.
+func (s *AuthService) RemoveOrganizationMember(orgID, actorID, userID string) error {
+	return mapOrganizationError(s.authManager.RemoveOrganizationMember(orgID, actorID, userID))
+}
+
+// SwitchOrganization makes the organization the active one in the session.
+// An empty orgID clears it and returns nil.
+func (s *AuthService) SwitchOrganization(sessionID, userID, orgID string) (*OrganizationInfo, error) {
+	if err := s.authManager.SetActiveOrganization(sessionID, userID, orgID); err != nil {
+		return nil, mapOrganizationError(err)
+	}
+	if orgID == "" {
+		return nil, nil
+	}
+	return s.GetOrganization(orgID, userID)
+}
+
+// ListAdminOrganizations lists every organization for the admin area.
+func (s *AuthService) ListAdminOrganizations(input ListAdminOrganizationsInput) (*pagination.Response[AdminOrganizationRow], error) {
+	if s.organizationAdapter == nil {
+		return nil, ErrOrganizationsUnavailable
+	}
+
+	switch input.PaginationMode {
+	case "":
+		return nil, ErrPaginationModeRequired
+	case pagination.ModeOffset:
+		return s.listAdminOrganizationsOffset(input.Offset)
+	case pagination.ModeCursor:
+		return s.listAdminOrganizationsCursor(input.Cursor)
+	default:
+		return nil, ErrInvalidPaginationMode
+	}
+}
+
+func (s *AuthService) organizationMemberInfo(orgID, userID string) (*OrganizationMemberInfo, error) {
+	member, err := s.authManager.OrganizationMember(orgID, userID)
+	if err != nil {
+		return nil, mapOrganizationError(err)
+	}
+
+	info := toOrganizationMemberInfo(member)
+	return &info, nil
+}
+
+func (s *AuthService) listAdminOrganizationsOffset(
+	input *pagination.OffsetQuery,
+) (*pagination.Response[AdminOrganizationRow], error) {
+	normalized, err := normalizeAdminOrganizationsOffsetInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	organizations, totalItems, err := s.organizationAdapter.ListOrganizationsOffset(normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int((totalItems + int64(normalized.PageSize) - 1) / int64(normalized.PageSize))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	return &pagination.Response[AdminOrganizationRow]{
+		Items:          toAdminOrganizationRows(organizations),
+		Search:         normalized.Search,
+		Sort:           pagination.Sort{Field: normalized.Sort, Direction: normalized.Order},
+		PaginationMode: pagination.ModeOffset,
+		Pagination: pagination.OffsetMetadata{
+			Page:       normalized.Page,
+			PageSize:   normalized.PageSize,
+			TotalItems: totalItems,
+			TotalPages: totalPages,
+		},
+	}, nil
+}
+
+func (s *AuthService) listAdminOrganizationsCursor(
+	input *pagination.CursorQuery,
+) (*pagination.Response[AdminOrganizationRow], error) {
+	normalized, err := normalizeAdminOrganizationsCursorInput(input)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.organizationAdapter.ListOrganizationsCursor(normalized)
+	if err != nil {
+		if errors.Is(err, pagination.ErrInvalidCursor) {
+			return nil, ErrInvalidAdminUsersQuery
+		}
+		return nil, err
+	}
+
+	return &pagination.Response[AdminOrganizationRow]{
+		Items:          toAdminOrganizationRows(result.Organizations),
+		Search:         normalized.Search,
+		Sort:           pagination.Sort{Field: normalized.Sort, Direction: normalized.Order},
+		PaginationMode: pagination.ModeCursor,
+		Pagination: pagination.CursorMetadata{
+			PageSize:   normalized.PageSize,
+			NextCursor: result.NextCursor,
+			PrevCursor: result.PrevCursor,
+			HasNext:    result.HasNext,
+			HasPrev:    result.HasPrev,
+		},
+	}, nil
+}
+
+func normalizeAdminOrganizationsOffsetInput(input *pagination.OffsetQuery) (pagination.OffsetQuery, error) {
+	if input == nil {
+		input = &pagination.OffsetQuery{}
+	}
+
+	page := input.Page
+	if page == 0 {
+		page = defaultAdminUsersPage
+	}
+	if page < 1 {
+		return pagination.OffsetQuery{}, ErrInvalidAdminUsersQuery
+	}
+
+	pageSize := input.PageSize
+	if pageSize == 0 {
+		pageSize = defaultAdminUsersPageSize
+	}
+	if pageSize < 1 || pageSize > maxAdminUsersPageSize {
+		return pagination.OffsetQuery{}, ErrInvalidAdminUsersQuery
+	}
+
+	sortField, order, err := normalizeAdminOrganizationsSort(input.Sort, input.Order)
+	if err != nil {
+		return pagination.OffsetQuery{}, err
+	}
+
+	return pagination.OffsetQuery{
+		Page:     page,
+		PageSize: pageSize,
+		Search:   strings.TrimSpace(input.Search),
+		Sort:     sortField,
+		Order:    order,
+	}, nil
+}
+
+func normalizeAdminOrganizationsCursorInput(input *pagination.CursorQuery) (pagination.CursorQuery, error) {
+	if input == nil {
+		input = &pagination.CursorQuery{}
+	}
+
+	if strings.TrimSpace(input.After) != "" && strings.TrimSpace(input.Before) != "" {
+		return pagination.CursorQuery{}, ErrInvalidAdminUsersQuery
+	}
+
+	pageSize := input.PageSize
+	if pageSize == 0 {
+		pageSize = defaultAdminUsersPageSize
+	}
+	if pageSize < 1 || pageSize > maxAdminUsersPageSize {
+		return pagination.CursorQuery{}, ErrInvalidAdminUsersQuery
+	}
+
+	sortField, order, err := normalizeAdminOrganizationsSort(input.Sort, input.Order)
+	if err != nil {
+		return pagination.CursorQuery{}, err
+	}
+
+	return pagination.CursorQuery{
+		PageSize: pageSize,
+		Search:   strings.TrimSpace(input.Search),
+		Sort:     sortField,
+		Order:    order,
+		After:    strings.TrimSpace(input.After),
+		Before:   strings.TrimSpace(input.Before),
+	}, nil
+}
+
+func normalizeAdminOrganizationsSort(sortField string, order pagination.SortDirection) (string, pagination.SortDirection, error) {
+	normalizedField := strings.TrimSpace(strings.ToLower(sortField))
+	if normalizedField == "" {
+		normalizedField = "created_at"
+	}
+
+	normalizedOrder := pagination.SortDirection(strings.TrimSpace(strings.ToLower(string(order))))
+	if normalizedOrder == "" {
+		normalizedOrder = pagination.SortDesc
+	}
+
+	if normalizedField != "created_at" && normalizedField != "name" {
+		return "", "", ErrInvalidAdminUsersQuery
+	}
+	if normalizedOrder != pagination.SortAsc && normalizedOrder != pagination.SortDesc {
+		return "", "", ErrInvalidAdminUsersQuery
+	}
+
+	return normalizedField, normalizedOrder, nil
+}
+
+func toAdminOrganizationRows(organizations []*gormadapter.AdminOrganization) []AdminOrganizationRow {
+	items := make([]AdminOrganizationRow, 0, len(organizations))
+	for _, organization := range organizations {
+		items = append(items, AdminOrganizationRow{
+			ID:          strconv.FormatUint(uint64(organization.ID), 10),
+			Name:        organization.Name,
+			MemberCount: organization.MemberCount,
+			CreatedAt:   organization.CreatedAt,
+		})
+	}
+	return items
+}
+
+func toOrganizationInfo(member *auth.OrganizationMember) OrganizationInfo {
+	return OrganizationInfo{
+		ID:       member.OrganizationID,
+		Name:     member.OrganizationName,
+		Role:     member.Role,
+		JoinedAt: member.JoinedAt,
+	}
+}
+
+func toOrganizationMemberInfo(member *auth.OrganizationMember) OrganizationMemberInfo {
+	return OrganizationMemberInfo{
+		UserID:      member.UserID,
+		Identifier:  member.Identifier,
+		DisplayName: member.DisplayName,
+		Email:       member.Email,
+		Role:        member.Role,
+		JoinedAt:    member.JoinedAt,
+	}
+}
+
+func mapOrganizationError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrOrganizationsNotSupported):
+		return ErrOrganizationsUnavailable
+	case errors.Is(err, auth.ErrOrganizationNotFound):
+		return ErrOrganizationNotFound
+	case errors.Is(err, auth.ErrOrganizationMemberNotFound):
+		return ErrOrganizationMemberNotFound
+	case errors.Is(err, auth.ErrAlreadyOrganizationMember):
+		return ErrAlreadyOrganizationMember
+	case errors.Is(err, auth.ErrInvalidOrganizationRole):
+		return ErrInvalidOrganizationRole
+	case errors.Is(err, auth.ErrInsufficientOrganizationRole):
+		return ErrInsufficientOrganizationRole
+	case errors.Is(err, auth.ErrLastOrganizationOwner):
+		return ErrLastOrganizationOwner
+	case errors.Is(err, auth.ErrSessionNotFound):
+		return ErrInvalidToken
+	default:
+		return err
+	}
+}
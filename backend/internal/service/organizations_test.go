@@ -0,0 +1,169 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// joinOrganization invites the user to the organization and has them accept
+func joinOrganization(t *testing.T, authService *AuthService, mockEmail *email.MockEmailService, orgID, inviterID string, user *models.User) {
+	t.Helper()
+
+	_, err := authService.InviteOrganizationMember(orgID, inviterID, InviteOrganizationMemberInput{Email: user.Email})
+	require.NoError(t, err)
+	sent := mockEmail.GetSentEmails()
+	require.NotEmpty(t, sent)
+	_, err = authService.AcceptInvitation(sent[len(sent)-1].Token, strconv.FormatUint(uint64(user.ID), 10))
+	require.NoError(t, err)
+}
+
+func TestAuthService_OrganizationMembership(t *testing.T) {
+	authService, _, _, _, mockEmail, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	user := createTestUser(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.CreateOrganization(ownerID, "   ")
+	assert.ErrorIs(t, err, ErrInvalidOrganizationName)
+
+	org, err := authService.CreateOrganization(ownerID, "  Acme  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Acme", org.Name)
+	assert.Equal(t, auth.OrganizationRoleOwner, org.Role)
+
+	// The user does not see an organization they do not belong to
+	_, err = authService.GetOrganization(org.ID, userID)
+	assert.ErrorIs(t, err, ErrOrganizationNotFound)
+
+	joinOrganization(t, authService, mockEmail, org.ID, ownerID, user)
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, ownerID, userID, "superuser")
+	assert.ErrorIs(t, err, ErrInvalidOrganizationRole)
+
+	members, err := authService.ListOrganizationMembers(org.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "admin", members[0].Identifier)
+	assert.Equal(t, "testuser", members[1].Identifier)
+
+	organizations, err := authService.ListOrganizations(userID)
+	require.NoError(t, err)
+	require.Len(t, organizations, 1)
+	assert.Equal(t, "Acme", organizations[0].Name)
+	assert.Equal(t, auth.OrganizationRoleMember, organizations[0].Role)
+
+	// Members cannot manage the organization; admins cannot touch owners
+	err = authService.DeleteOrganization(org.ID, userID)
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, userID, userID, auth.OrganizationRoleAdmin)
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+
+	member, err := authService.UpdateOrganizationMemberRole(org.ID, ownerID, userID, auth.OrganizationRoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, auth.OrganizationRoleAdmin, member.Role)
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, userID, ownerID, auth.OrganizationRoleMember)
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, userID, userID, auth.OrganizationRoleOwner)
+	assert.ErrorIs(t, err, ErrInsufficientOrganizationRole)
+
+	// The last owner can neither step down nor leave
+	_, err = authService.UpdateOrganizationMemberRole(org.ID, ownerID, ownerID, auth.OrganizationRoleAdmin)
+	assert.ErrorIs(t, err, ErrLastOrganizationOwner)
+	err = authService.RemoveOrganizationMember(org.ID, ownerID, ownerID)
+	assert.ErrorIs(t, err, ErrLastOrganizationOwner)
+
+	// Anyone may leave
+	require.NoError(t, authService.RemoveOrganizationMember(org.ID, userID, userID))
+	err = authService.RemoveOrganizationMember(org.ID, ownerID, userID)
+	assert.ErrorIs(t, err, ErrOrganizationMemberNotFound)
+
+	require.NoError(t, authService.DeleteOrganization(org.ID, ownerID))
+	_, err = authService.GetOrganization(org.ID, ownerID)
+	assert.ErrorIs(t, err, ErrOrganizationNotFound)
+}
+
+func TestAuthService_SwitchOrganization(t *testing.T) {
+	authService, authManager, _, _, mockEmail, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	user := createTestUser(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	org, err := authService.CreateOrganization(ownerID, "Acme")
+	require.NoError(t, err)
+	other, err := authService.CreateOrganization(ownerID, "Other")
+	require.NoError(t, err)
+	joinOrganization(t, authService, mockEmail, org.ID, ownerID, user)
+
+	login, err := authService.Login("testuser", "password123", "", "")
+	require.NoError(t, err)
+
+	_, err = authService.SwitchOrganization(login.SessionID, userID, other.ID)
+	assert.ErrorIs(t, err, ErrOrganizationNotFound)
+
+	active, err := authService.SwitchOrganization(login.SessionID, userID, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, active.ID)
+
+	session, _, err := authManager.ValidateSession(login.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, org.ID, session.ActiveOrganizationID)
+
+	// Leaving the organization clears it from the user's sessions
+	require.NoError(t, authService.RemoveOrganizationMember(org.ID, ownerID, userID))
+	session, _, err = authManager.ValidateSession(login.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Empty(t, session.ActiveOrganizationID)
+}
+
+func TestAuthService_ListAdminOrganizations(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	owner := createTestAdmin(t, db)
+	ownerID := strconv.FormatUint(uint64(owner.ID), 10)
+
+	for _, name := range []string{"Charlie", "Alpha", "Bravo"} {
+		_, err := authService.CreateOrganization(ownerID, name)
+		require.NoError(t, err)
+	}
+
+	response, err := authService.ListAdminOrganizations(ListAdminOrganizationsInput{
+		PaginationMode: pagination.ModeOffset,
+		Offset:         &pagination.OffsetQuery{PageSize: 2, Sort: "name", Order: pagination.SortAsc},
+	})
+	require.NoError(t, err)
+	require.Len(t, response.Items, 2)
+	assert.Equal(t, "Alpha", response.Items[0].Name)
+	assert.Equal(t, int64(1), response.Items[0].MemberCount)
+	assert.Equal(t, int64(3), response.Pagination.(pagination.OffsetMetadata).TotalItems)
+
+	first, err := authService.ListAdminOrganizations(ListAdminOrganizationsInput{
+		PaginationMode: pagination.ModeCursor,
+		Cursor:         &pagination.CursorQuery{PageSize: 2, Sort: "name", Order: pagination.SortAsc},
+	})
+	require.NoError(t, err)
+	metadata := first.Pagination.(pagination.CursorMetadata)
+	require.True(t, metadata.HasNext)
+	require.NotNil(t, metadata.NextCursor)
+
+	second, err := authService.ListAdminOrganizations(ListAdminOrganizationsInput{
+		PaginationMode: pagination.ModeCursor,
+		Cursor:         &pagination.CursorQuery{PageSize: 2, Sort: "name", Order: pagination.SortAsc, After: *metadata.NextCursor},
+	})
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, "Charlie", second.Items[0].Name)
+
+	_, err = authService.ListAdminOrganizations(ListAdminOrganizationsInput{
+		PaginationMode: pagination.ModeOffset,
+		Offset:         &pagination.OffsetQuery{Sort: "member_count"},
+	})
+	assert.ErrorIs(t, err, ErrInvalidAdminUsersQuery)
+}
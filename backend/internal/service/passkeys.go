@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"gosveltekit/internal/auth"
+)
+
+var (
+	ErrPasskeysUnavailable = errors.New("login com passkey indisponível")
+	ErrPasskeyNotFound     = errors.New("passkey não encontrada")
+	ErrInvalidPasskey      = errors.New("não foi possível verificar a passkey")
+	ErrInvalidCeremony     = errors.New("cerimônia de passkey inválida ou expirada")
+)
+
+// PasskeyOptions carries the options for navigator.credentials and the token
+// that identifies the ceremony when the browser answers.
+type PasskeyOptions struct {
+	CeremonyToken string    `json:"ceremony_token"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Options       any       `json:"options"`
+}
+
+// PasskeyInfo describes a registered passkey to its owner.
+type PasskeyInfo struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// BeginPasskeyLogin starts a usernameless passkey login.
+func (s *AuthService) BeginPasskeyLogin() (*PasskeyOptions, error) {
+	ceremony, err := s.authManager.BeginPasskeyLogin()
+	if err != nil {
+		return nil, mapPasskeyError(err)
+	}
+	return toPasskeyOptions(ceremony), nil
+}
+
+// FinishPasskeyLogin verifies the browser's assertion and creates a session.
+func (s *AuthService) FinishPasskeyLogin(ceremonyToken string, credential []byte, ip, userAgent string) (*LoginResponse, error) {
+	metadata := auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	session, user, err := s.authManager.FinishPasskeyLogin(ceremonyToken, credential, metadata)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidPasskey) {
+			slog.Warn("passkey login rejected", "ip", ip, "err", err)
+		}
+		return nil, mapPasskeyError(err)
+	}
+
+	return &LoginResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+	}, nil
+}
+
+// ListPasskeys returns the passkeys registered by the authenticated user.
+func (s *AuthService) ListPasskeys(userID string) ([]PasskeyInfo, error) {
+	passkeys, err := s.authManager.ListPasskeys(userID)
+	if err != nil {
+		return nil, mapPasskeyError(err)
+	}
+
+	result := make([]PasskeyInfo, 0, len(passkeys))
+	for i := range passkeys {
+		result = append(result, toPasskeyInfo(&passkeys[i]))
+	}
+	return result, nil
+}
+
+// BeginPasskeyRegistration starts registering a passkey for the authenticated user.
+func (s *AuthService) BeginPasskeyRegistration(userID string) (*PasskeyOptions, error) {
+	user, err := s.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	ceremony, err := s.authManager.BeginPasskeyRegistration(user)
+	if err != nil {
+		return nil, mapPasskeyError(err)
+	}
+	return toPasskeyOptions(ceremony), nil
+}
+
+// FinishPasskeyRegistration verifies the browser's attestation and stores the passkey.
+func (s *AuthService) FinishPasskeyRegistration(userID, ceremonyToken, name string, credential []byte) (*PasskeyInfo, error) {
+	user, err := s.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	passkey, err := s.authManager.FinishPasskeyRegistration(user, ceremonyToken, name, credential)
+	if err != nil {
+		return nil, mapPasskeyError(err)
+	}
+
+	info := toPasskeyInfo(passkey)
+	return &info, nil
+}
+
+// DeletePasskey removes one of the authenticated user's passkeys.
+func (s *AuthService) DeletePasskey(userID, passkeyID string) error {
+	if err := s.authManager.DeletePasskey(userID, passkeyID); err != nil {
+		return mapPasskeyError(err)
+	}
+	return nil
+}
+
+func toPasskeyOptions(ceremony *auth.PasskeyCeremony) *PasskeyOptions {
+	return &PasskeyOptions{
+		CeremonyToken: ceremony.Token,
+		ExpiresAt:     ceremony.ExpiresAt,
+		Options:       ceremony.Options,
+	}
+}
+
+func toPasskeyInfo(passkey *auth.PasskeyCredential) PasskeyInfo {
+	info := PasskeyInfo{
+		ID:        passkey.ID,
+		Name:      passkey.Name,
+		CreatedAt: passkey.CreatedAt,
+	}
+	if !passkey.LastUsedAt.IsZero() {
+		lastUsedAt := passkey.LastUsedAt
+		info.LastUsedAt = &lastUsedAt
+	}
+	return info
+}
+
+func mapPasskeyError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrPasskeysNotSupported):
+		return ErrPasskeysUnavailable
+	case errors.Is(err, auth.ErrInvalidPasskey):
+		// Checked first: a failed assertion may wrap the lookup error
+		return ErrInvalidPasskey
+	case errors.Is(err, auth.ErrPasskeyNotFound):
+		return ErrPasskeyNotFound
+	case errors.Is(err, auth.ErrCeremonyNotFound), errors.Is(err, auth.ErrCeremonyExpired):
+		return ErrInvalidCeremony
+	case errors.Is(err, auth.ErrUserNotActive):
+		return ErrUserNotActive
+	case errors.Is(err, auth.ErrAccountLocked):
+		return ErrAccountLocked
+	case errors.Is(err, auth.ErrEmailNotVerified):
+		return ErrEmailNotVerified
+	default:
+		return err
+	}
+}
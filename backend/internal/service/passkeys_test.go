@@ -0,0 +1,167 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+	"gosveltekit/internal/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebAuthnOrigin = "http://localhost:5173"
+
+// registerTestPasskey registers a software authenticator for the user
+func registerTestPasskey(t *testing.T, authService *AuthService, userID string) (*testutil.SoftAuthenticator, *PasskeyInfo) {
+	t.Helper()
+
+	authenticator := testutil.NewSoftAuthenticator(t, testWebAuthnOrigin)
+
+	options, err := authService.BeginPasskeyRegistration(userID)
+	require.NoError(t, err)
+	require.NotEmpty(t, options.CeremonyToken)
+
+	credential := authenticator.Register(t, options.Options)
+	passkey, err := authService.FinishPasskeyRegistration(userID, options.CeremonyToken, "Notebook", credential)
+	require.NoError(t, err)
+
+	return authenticator, passkey
+}
+
+func TestAuthService_PasskeyRegistrationAndLogin(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	authenticator, passkey := registerTestPasskey(t, authService, userID)
+	assert.Equal(t, "Notebook", passkey.Name)
+	assert.Nil(t, passkey.LastUsedAt)
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	resp, err := authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.SessionID)
+	assert.Equal(t, userID, resp.User.ID)
+
+	// The session is a regular one
+	_, sessionUser, err := authService.ValidateSession(resp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, userID, sessionUser.ID)
+
+	passkeys, err := authService.ListPasskeys(userID)
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	assert.NotNil(t, passkeys[0].LastUsedAt)
+}
+
+func TestAuthService_FinishPasskeyLogin_CeremonyIsSingleUse(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	authenticator, _ := registerTestPasskey(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+	assertion := authenticator.Login(t, options.Options)
+
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, assertion, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, assertion, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidCeremony)
+}
+
+func TestAuthService_FinishPasskeyLogin_RejectsWrongOrigin(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	authenticator, _ := registerTestPasskey(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	authenticator.Origin = "https://phishing.example.com"
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidPasskey)
+}
+
+func TestAuthService_FinishPasskeyLogin_RejectsUnknownCredential(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	authenticator, passkey := registerTestPasskey(t, authService, userID)
+
+	require.NoError(t, authService.DeletePasskey(userID, passkey.ID))
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidPasskey)
+}
+
+func TestAuthService_FinishPasskeyLogin_InactiveUser(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	authenticator, _ := registerTestPasskey(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("active", false).Error)
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrUserNotActive)
+}
+
+func TestAuthService_FinishPasskeyLogin_RequiresVerifiedEmail(t *testing.T) {
+	authService, _, db := setupRequiredEmailVerification(t)
+	user := createTestUser(t, db)
+	authenticator, _ := registerTestPasskey(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	options, err := authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	_, err = authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrEmailNotVerified)
+
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("email_verified", true).Error)
+
+	options, err = authService.BeginPasskeyLogin()
+	require.NoError(t, err)
+
+	result, err := authService.FinishPasskeyLogin(options.CeremonyToken, authenticator.Login(t, options.Options), "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.SessionID)
+}
+
+func TestAuthService_FinishPasskeyRegistration_RejectsOtherUsersCeremony(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	other := &models.User{Username: "other", Email: "other@example.com", DisplayName: "Other", PasswordHash: "x", Active: true}
+	require.NoError(t, db.Create(other).Error)
+
+	options, err := authService.BeginPasskeyRegistration(strconv.FormatUint(uint64(user.ID), 10))
+	require.NoError(t, err)
+
+	authenticator := testutil.NewSoftAuthenticator(t, testWebAuthnOrigin)
+	_, err = authService.FinishPasskeyRegistration(
+		strconv.FormatUint(uint64(other.ID), 10),
+		options.CeremonyToken,
+		"Stolen",
+		authenticator.Register(t, options.Options),
+	)
+	assert.ErrorIs(t, err, ErrInvalidCeremony)
+}
+
+func TestAuthService_DeletePasskey_NotOwned(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	_, passkey := registerTestPasskey(t, authService, strconv.FormatUint(uint64(user.ID), 10))
+
+	err := authService.DeletePasskey("9999", passkey.ID)
+	assert.ErrorIs(t, err, ErrPasskeyNotFound)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_UpdateUserPermissions(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	admin := createTestAdmin(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+
+	login, err := authService.Login("testuser", "password123", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, login.User.Permissions)
+
+	row, err := authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{
+		Grant: []string{auth.PermissionUsersRead, auth.PermissionAdminAccess, auth.PermissionUsersRead},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.PermissionAdminAccess, auth.PermissionUsersRead}, row.Permissions)
+	assert.Equal(t, []string{auth.PermissionAdminAccess, auth.PermissionUsersRead}, row.PermissionOverrides.Grant)
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.JSONEq(t, `{"grant":["admin:access","users:read"]}`, stored.Permissions)
+
+	// The user's sessions pick up the change under a new token
+	session, current, err := authManager.ValidateSession(login.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.NotEqual(t, login.SessionID, session.ID)
+	assert.True(t, current.HasPermission(auth.PermissionUsersRead))
+	assert.False(t, current.HasPermission(auth.PermissionUsersWrite))
+
+	// Denies apply to what the role grants
+	other := &models.User{Username: "other-admin", Email: "other-admin@example.com", PasswordHash: "unused", Active: true, Role: "admin"}
+	require.NoError(t, db.Create(other).Error)
+	row, err = authService.UpdateUserPermissions(adminID, strconv.FormatUint(uint64(other.ID), 10), auth.PermissionOverrides{
+		Deny: []string{auth.PermissionMetricsRead},
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, row.Permissions, auth.PermissionMetricsRead)
+
+	// Empty overrides clear the column
+	row, err = authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.PermissionAdminAccess, auth.PermissionUsersRead}, row.Previous.PermissionOverrides.Grant)
+	assert.Empty(t, row.PermissionOverrides.Grant)
+	require.NoError(t, db.First(&stored, user.ID).Error)
+	assert.Empty(t, stored.Permissions)
+}
+
+func TestAuthService_UpdateUserPermissionsRules(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	admin := createTestAdmin(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+
+	_, err := authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{Grant: []string{"root"}})
+	assert.ErrorIs(t, err, ErrInvalidPermission)
+
+	_, err = authService.UpdateUserPermissions(adminID, "9999", auth.PermissionOverrides{})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = authService.UpdateUserPermissions(adminID, adminID, auth.PermissionOverrides{Deny: []string{auth.PermissionMetricsRead}})
+	assert.ErrorIs(t, err, ErrCannotChangeOwnPermissions)
+
+	// A partial admin holding users:write
+	manager := &models.User{Username: "manager", Email: "manager@example.com", PasswordHash: "unused", Active: true, Role: "user"}
+	require.NoError(t, db.Create(manager).Error)
+	managerID := strconv.FormatUint(uint64(manager.ID), 10)
+	_, err = authService.UpdateUserPermissions(adminID, managerID, auth.PermissionOverrides{
+		Grant: []string{auth.PermissionAdminAccess, auth.PermissionUsersRead, auth.PermissionUsersWrite},
+	})
+	require.NoError(t, err)
+
+	// Nobody can hand out a permission they do not hold
+	_, err = authService.UpdateUserPermissions(managerID, userID, auth.PermissionOverrides{Grant: []string{auth.PermissionAuditRead}})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld)
+
+	// nor deny one, nor touch a user who holds more than they do
+	_, err = authService.UpdateUserPermissions(managerID, userID, auth.PermissionOverrides{Deny: []string{auth.PermissionAuditRead}})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld)
+	_, err = authService.UpdateUserPermissions(managerID, adminID, auth.PermissionOverrides{Deny: []string{auth.PermissionUsersRead}})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld, "cannot lock out a full admin")
+	_, err = authService.UpdateUserPermissions(managerID, managerID, auth.PermissionOverrides{})
+	assert.ErrorIs(t, err, ErrCannotChangeOwnPermissions)
+
+	// nor take back a grant they do not hold
+	_, err = authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{
+		Grant: []string{auth.PermissionAuditRead},
+		Deny:  []string{auth.PermissionAuditRead},
+	})
+	require.NoError(t, err)
+	_, err = authService.UpdateUserPermissions(managerID, userID, auth.PermissionOverrides{})
+	assert.ErrorIs(t, err, ErrPermissionNotHeld)
+
+	// but can manage what they hold themselves
+	_, err = authService.UpdateUserPermissions(adminID, userID, auth.PermissionOverrides{})
+	require.NoError(t, err)
+	row, err := authService.UpdateUserPermissions(managerID, userID, auth.PermissionOverrides{Grant: []string{auth.PermissionUsersRead}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.PermissionUsersRead}, row.Permissions)
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"gosveltekit/internal/auth"
+)
+
+// ReauthenticationStatus tells the frontend how long sensitive actions are
+// allowed without asking for the password again
+type ReauthenticationStatus struct {
+	ReauthenticatedUntil time.Time `json:"reauthenticated_until"`
+}
+
+// Reauthenticate confirms the password of the user signed in with sessionID,
+// allowing sensitive account actions from that session for a while
+func (s *AuthService) Reauthenticate(sessionID, password string) (*ReauthenticationStatus, error) {
+	session, err := s.authManager.Reauthenticate(sessionID, password)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, ErrWrongPassword
+		case errors.Is(err, auth.ErrAccountLocked):
+			return nil, ErrAccountLocked
+		case errors.Is(err, auth.ErrUserNotActive):
+			return nil, ErrUserNotActive
+		case errors.Is(err, auth.ErrSessionNotFound):
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	return &ReauthenticationStatus{ReauthenticatedUntil: s.authManager.ReauthenticatedUntil(session)}, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// ageSession moves the session's creation back so that signing in no longer
+// counts as a recent authentication
+func ageSession(t *testing.T, db *gorm.DB, sessionID string, age time.Duration) {
+	t.Helper()
+
+	require.NoError(t, db.Model(&models.Session{}).
+		Where("id = ?", auth.HashToken(sessionID)).
+		Updates(map[string]any{"created_at": time.Now().Add(-age), "last_seen_at": time.Now()}).Error)
+}
+
+func TestAuthService_Reauthenticate(t *testing.T) {
+	authService, authManager, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Signing in counts as a recent authentication
+	session, _, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.True(t, authManager.RecentlyAuthenticated(session))
+
+	ageSession(t, db, loginResp.SessionID, time.Hour)
+	session, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.False(t, authManager.RecentlyAuthenticated(session))
+
+	_, err = authService.Reauthenticate(loginResp.SessionID, "wrong-password")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	status, err := authService.Reauthenticate(loginResp.SessionID, "password123")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), status.ReauthenticatedUntil, time.Minute)
+
+	session, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.True(t, authManager.RecentlyAuthenticated(session))
+
+	// The confirmation expires with the window
+	require.NoError(t, db.Model(&models.Session{}).
+		Where("id = ?", auth.HashToken(loginResp.SessionID)).
+		Update("reauthenticated_at", time.Now().Add(-11*time.Minute)).Error)
+	session, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.False(t, authManager.RecentlyAuthenticated(session))
+}
+
+func TestAuthService_Reauthenticate_WrongPasswordsLockAccount(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	for i := 0; i < auth.DefaultAuthConfig().MaxFailedAttempts; i++ {
+		_, err = authService.Reauthenticate(loginResp.SessionID, "wrong-password")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	}
+
+	_, err = authService.Reauthenticate(loginResp.SessionID, "password123")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	_, err = authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+}
+
+func TestAuthService_Reauthenticate_UnknownSession(t *testing.T) {
+	authService, _, _, _, _, _ := setupTest(t)
+
+	_, err := authService.Reauthenticate("missing", "password123")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// enableTestUserTOTPWithCodes enrolls TOTP and returns the issued recovery codes
+func enableTestUserTOTPWithCodes(t *testing.T, authService *AuthService, userID string) []string {
+	t.Helper()
+
+	setup, err := authService.SetupTOTP(userID)
+	require.NoError(t, err)
+
+	code, err := auth.GenerateTOTPCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+
+	recoveryCodes, err := authService.ConfirmTOTP(userID, code)
+	require.NoError(t, err)
+	return recoveryCodes
+}
+
+func TestAuthService_ConfirmTOTP_IssuesRecoveryCodes(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	recoveryCodes := enableTestUserTOTPWithCodes(t, authService, userID)
+	assert.Len(t, recoveryCodes, 10)
+
+	// Only hashes are stored
+	var stored []models.RecoveryCode
+	require.NoError(t, db.Where("user_id = ?", user.ID).Find(&stored).Error)
+	require.Len(t, stored, len(recoveryCodes))
+	for _, code := range stored {
+		assert.NotContains(t, recoveryCodes, code.CodeHash)
+		assert.Len(t, code.CodeHash, 64)
+	}
+
+	status, err := authService.GetTwoFactorStatus(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, status.RecoveryCodesRemaining)
+}
+
+func TestAuthService_VerifyTwoFactor_WithRecoveryCode(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	recoveryCodes := enableTestUserTOTPWithCodes(t, authService, userID)
+
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	require.True(t, resp.TwoFactorRequired)
+
+	// Codes are accepted regardless of case and separator
+	typed := strings.ToUpper(strings.ReplaceAll(recoveryCodes[0], "-", " "))
+	verified, err := authService.VerifyTwoFactor(resp.ChallengeToken, typed, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, verified.SessionID)
+
+	status, err := authService.GetTwoFactorStatus(userID)
+	require.NoError(t, err)
+	assert.Equal(t, 9, status.RecoveryCodesRemaining)
+
+	// Each code works only once
+	resp, err = authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, recoveryCodes[0], "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, recoveryCodes[1], "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+}
+
+func TestAuthService_RegenerateRecoveryCodes(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.RegenerateRecoveryCodes(userID, "password123")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+
+	oldCodes := enableTestUserTOTPWithCodes(t, authService, userID)
+
+	_, err = authService.RegenerateRecoveryCodes(userID, "wrong-password")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	newCodes, err := authService.RegenerateRecoveryCodes(userID, "password123")
+	require.NoError(t, err)
+	assert.Len(t, newCodes, 10)
+	assert.NotEqual(t, oldCodes, newCodes)
+
+	// Previously issued codes no longer work
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, oldCodes[0], "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, newCodes[0], "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+}
+
+func TestAuthService_DisableTOTP_RemovesRecoveryCodes(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	_ = enableTestUserTOTPWithCodes(t, authService, userID)
+
+	require.NoError(t, authService.DisableTOTP(userID, "password123"))
+
+	var count int64
+	require.NoError(t, db.Model(&models.RecoveryCode{}).Where("user_id = ?", user.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
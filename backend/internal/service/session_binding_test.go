@@ -0,0 +1,136 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	gormadapter "gosveltekit/internal/auth/adapter/gorm"
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const (
+	firefoxLinux  = "Mozilla/5.0 (X11; Linux x86_64; rv:131.0) Gecko/20100101 Firefox/131.0"
+	firefoxUpdate = "Mozilla/5.0 (X11; Linux x86_64; rv:132.0) Gecko/20100101 Firefox/132.0"
+	chromeWindows = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+)
+
+// newSessionBindingService returns a service with the given binding policies
+// and the anomalies it reports
+func newSessionBindingService(t *testing.T, db *gorm.DB, userAgentPolicy, ipPolicy auth.SessionBindingPolicy) (*AuthService, *[]auth.SessionAnomaly) {
+	t.Helper()
+
+	authConfig := auth.DefaultAuthConfig()
+	authConfig.SessionUserAgentPolicy = userAgentPolicy
+	authConfig.SessionIPPolicy = ipPolicy
+
+	userAdapter := gormadapter.NewUserAdapter(db)
+	sessionAdapter := gormadapter.NewSessionAdapter(db)
+	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+
+	var anomalies []auth.SessionAnomaly
+	authManager.SetSessionAnomalyHandler(func(anomaly auth.SessionAnomaly) {
+		anomalies = append(anomalies, anomaly)
+	})
+
+	return NewAuthService(authManager, sessionAdapter, userAdapter, email.NewMockEmailService()), &anomalies
+}
+
+func TestAuthService_ValidateSession_WarnsOnUserAgentChange(t *testing.T) {
+	_, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	authService, anomalies := newSessionBindingService(t, db, auth.SessionBindingWarn, auth.SessionBindingIgnore)
+
+	loginResp, err := authService.Login("testuser", "password123", "203.0.113.10", firefoxLinux)
+	require.NoError(t, err)
+
+	// A browser update is the same client
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: firefoxUpdate, IP: "203.0.113.10"})
+	require.NoError(t, err)
+	assert.Empty(t, *anomalies)
+
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: chromeWindows, IP: "198.51.100.7"})
+	require.NoError(t, err)
+
+	require.Len(t, *anomalies, 1)
+	anomaly := (*anomalies)[0]
+	assert.Equal(t, auth.SessionAnomalyUserAgent, anomaly.Kind)
+	assert.Equal(t, "Firefox/Linux", anomaly.Expected)
+	assert.Equal(t, "Chrome/Windows", anomaly.Actual)
+	assert.Equal(t, auth.SessionBindingWarn, anomaly.Policy)
+	assert.Equal(t, "198.51.100.7", anomaly.IP)
+	assert.Equal(t, strconv.FormatUint(uint64(user.ID), 10), anomaly.UserID)
+}
+
+func TestAuthService_ValidateSession_ReportsEachAnomalyOnce(t *testing.T) {
+	_, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+	authService, anomalies := newSessionBindingService(t, db, auth.SessionBindingWarn, auth.SessionBindingWarn)
+
+	loginResp, err := authService.Login("testuser", "password123", "203.0.113.10", firefoxLinux)
+	require.NoError(t, err)
+
+	for range 3 {
+		_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: chromeWindows, IP: "203.0.113.10"})
+		require.NoError(t, err)
+	}
+	require.Len(t, *anomalies, 1)
+	assert.Equal(t, auth.SessionAnomalyUserAgent, (*anomalies)[0].Kind)
+
+	// A new mismatch is reported, the known one is not
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: chromeWindows, IP: "198.51.100.7"})
+	require.NoError(t, err)
+	require.Len(t, *anomalies, 2)
+	assert.Equal(t, auth.SessionAnomalyIP, (*anomalies)[1].Kind)
+
+	var session models.Session
+	require.NoError(t, db.Where("id = ?", auth.HashToken(loginResp.SessionID)).First(&session).Error)
+	assert.Equal(t, "user_agent=Chrome/Windows ip=198.51.100.0/24", session.ReportedAnomalies)
+}
+
+func TestAuthService_ValidateSession_RevokesOnIPNetworkChange(t *testing.T) {
+	_, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+	authService, anomalies := newSessionBindingService(t, db, auth.SessionBindingIgnore, auth.SessionBindingRevoke)
+
+	loginResp, err := authService.Login("testuser", "password123", "203.0.113.10", firefoxLinux)
+	require.NoError(t, err)
+
+	// Same /24 network
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: chromeWindows, IP: "203.0.113.200"})
+	require.NoError(t, err)
+	assert.Empty(t, *anomalies)
+
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: firefoxLinux, IP: "198.51.100.7"})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	require.Len(t, *anomalies, 1)
+	assert.Equal(t, auth.SessionAnomalyIP, (*anomalies)[0].Kind)
+	assert.Equal(t, "203.0.113.0/24", (*anomalies)[0].Expected)
+	assert.Equal(t, "198.51.100.0/24", (*anomalies)[0].Actual)
+
+	// The session is gone, even for its original client
+	var count int64
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).Count(&count).Error)
+	assert.Zero(t, count)
+
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: firefoxLinux, IP: "203.0.113.10"})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthService_ValidateSession_IgnoresMissingMetadata(t *testing.T) {
+	_, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+	authService, anomalies := newSessionBindingService(t, db, auth.SessionBindingRevoke, auth.SessionBindingRevoke)
+
+	loginResp, err := authService.Login("testuser", "password123", "", "")
+	require.NoError(t, err)
+
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{UserAgent: chromeWindows, IP: "198.51.100.7"})
+	require.NoError(t, err)
+	assert.Empty(t, *anomalies)
+}
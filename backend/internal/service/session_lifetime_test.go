@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func loadSession(t *testing.T, db *gorm.DB, sessionID string) *models.Session {
+	t.Helper()
+
+	var session models.Session
+	require.NoError(t, db.Where("id = ?", auth.HashToken(sessionID)).First(&session).Error)
+	return &session
+}
+
+func TestAuthService_ValidateSession_IdleTimeout(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), loadSession(t, db, loginResp.SessionID).LastSeenAt, time.Minute)
+
+	// Use within the idle timeout moves last-seen forward
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).
+		Update("last_seen_at", time.Now().Add(-6*24*time.Hour)).Error)
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), loadSession(t, db, loginResp.SessionID).LastSeenAt, time.Minute)
+
+	// Eight idle days end it
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).
+		Update("last_seen_at", time.Now().Add(-8*24*time.Hour)).Error)
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrExpiredToken)
+
+	var count int64
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestAuthService_ValidateSession_AbsoluteLifetimeCapsRefresh(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// An 89-day-old session close to expiry is refreshed only up to day 90
+	createdAt := time.Now().Add(-89 * 24 * time.Hour)
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).Updates(map[string]any{
+		"created_at": createdAt,
+		"expires_at": time.Now().Add(time.Hour),
+	}).Error)
+
+	session, _, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.True(t, session.Fresh)
+	assert.WithinDuration(t, createdAt.Add(90*24*time.Hour), session.ExpiresAt, time.Second)
+	assert.WithinDuration(t, session.ExpiresAt, loadSession(t, db, loginResp.SessionID).ExpiresAt, time.Second)
+
+	// Past the absolute lifetime it ends even if the stored expiry is later
+	require.NoError(t, db.Model(&models.Session{}).Where("id = ?", auth.HashToken(loginResp.SessionID)).Updates(map[string]any{
+		"created_at": time.Now().Add(-91 * 24 * time.Hour),
+		"expires_at": time.Now().Add(24 * time.Hour),
+	}).Error)
+
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrExpiredToken)
+}
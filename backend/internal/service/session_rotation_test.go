@@ -0,0 +1,165 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_UpdateUserRole_RotatesSessionsOnNextUse(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	publicID := loadSession(t, db, loginResp.SessionID).PublicID
+
+	row, err := authService.UpdateUserRole(adminID, userID, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", row.Role)
+
+	// The next request gets the session back under a new token
+	session, sessionUser, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, "admin", sessionUser.Role)
+	assert.NotEqual(t, loginResp.SessionID, session.ID)
+	assert.Equal(t, publicID, session.PublicID)
+	assert.True(t, session.Fresh)
+
+	// Requests already in flight with the old token still pass, without
+	// rotating again
+	stale, _, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.True(t, stale.Superseded)
+	assert.False(t, stale.Fresh)
+
+	again, _, err := authService.ValidateSession(session.ID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, again.ID)
+
+	// Logging out with the replaced token still ends the session
+	require.NoError(t, authService.Logout(loginResp.SessionID))
+	_, _, err = authService.ValidateSession(session.ID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthService_ValidateSession_RotationGraceEnds(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+	user := createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	publicID := loadSession(t, db, loginResp.SessionID).PublicID
+
+	_, err = authService.UpdateUserRole(adminID, strconv.FormatUint(uint64(user.ID), 10), "admin")
+	require.NoError(t, err)
+	rotated, _, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+
+	// After the grace period only the new token works
+	require.NoError(t, db.Model(&models.Session{}).Where("public_id = ?", publicID).
+		Update("previous_expires_at", time.Now().Add(-time.Second)).Error)
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, _, err = authService.ValidateSession(rotated.ID, auth.SessionMetadata{})
+	require.NoError(t, err)
+}
+
+func TestAuthService_UpdateUserRole_SameRoleKeepsSessions(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+	user := createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	_, err = authService.UpdateUserRole(adminID, strconv.FormatUint(uint64(user.ID), 10), "user")
+	require.NoError(t, err)
+
+	session, _, err := authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+	assert.Equal(t, loginResp.SessionID, session.ID)
+}
+
+func TestAuthService_UpdateUserRole_Errors(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	adminID := strconv.FormatUint(uint64(createTestAdmin(t, db).ID), 10)
+	user := createTestUser(t, db)
+
+	_, err := authService.UpdateUserRole(adminID, strconv.FormatUint(uint64(user.ID), 10), "root")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+
+	_, err = authService.UpdateUserRole(adminID, "9999", "admin")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestAuthService_UpdateUserRole_RequiresActorPermissions(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	admin := createTestAdmin(t, db)
+	adminID := strconv.FormatUint(uint64(admin.ID), 10)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	// A partial admin holds enough to reach the endpoint, not every
+	// permission of the admin role
+	partial := &models.User{
+		Username:     "support",
+		Email:        "support@example.com",
+		PasswordHash: "unused",
+		Active:       true,
+		Role:         "user",
+		Permissions:  `{"grant":["admin:access","users:read","users:write"]}`,
+	}
+	require.NoError(t, db.Create(partial).Error)
+	partialID := strconv.FormatUint(uint64(partial.ID), 10)
+
+	_, err := authService.UpdateUserRole(partialID, partialID, "admin")
+	assert.ErrorIs(t, err, ErrCannotChangeOwnRole, "no self-promotion")
+	_, err = authService.UpdateUserRole(adminID, adminID, "user")
+	assert.ErrorIs(t, err, ErrCannotChangeOwnRole)
+
+	_, err = authService.UpdateUserRole(partialID, userID, "admin")
+	assert.ErrorIs(t, err, ErrPermissionNotHeld, "a partial admin cannot promote others")
+	_, err = authService.UpdateUserRole(partialID, adminID, "user")
+	assert.ErrorIs(t, err, ErrPermissionNotHeld, "nor demote a full admin")
+
+	var reloadedUser, reloadedAdmin models.User
+	require.NoError(t, db.First(&reloadedUser, user.ID).Error)
+	assert.Equal(t, "user", reloadedUser.Role)
+	require.NoError(t, db.First(&reloadedAdmin, admin.ID).Error)
+	assert.Equal(t, "admin", reloadedAdmin.Role)
+
+	row, err := authService.UpdateUserRole(adminID, userID, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, "admin", row.Role)
+}
+
+func TestAuthService_RotateSession(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	_ = createTestUser(t, db)
+
+	loginResp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	rotated, err := authService.RotateSession(loginResp.SessionID)
+	require.NoError(t, err)
+	assert.NotEqual(t, loginResp.SessionID, rotated.ID)
+
+	// The replaced token stops working at once
+	_, _, err = authService.ValidateSession(loginResp.SessionID, auth.SessionMetadata{})
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = authService.RotateSession(loginResp.SessionID)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, _, err = authService.ValidateSession(rotated.ID, auth.SessionMetadata{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+
+	"gosveltekit/internal/auth"
+)
+
+var (
+	ErrTwoFactorUnavailable    = errors.New("autenticação em dois fatores indisponível")
+	ErrTwoFactorAlreadyEnabled = errors.New("autenticação em dois fatores já está ativa")
+	ErrTwoFactorNotEnabled     = errors.New("autenticação em dois fatores não está ativa")
+	ErrTwoFactorSetupRequired  = errors.New("inicie a configuração da autenticação em dois fatores primeiro")
+	ErrInvalidTwoFactorCode    = errors.New("código de verificação inválido")
+	ErrInvalidChallenge        = errors.New("desafio de autenticação inválido ou expirado")
+)
+
+// TwoFactorStatus describes the second-factor state of an account.
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"` // setup started but not confirmed yet
+	RecoveryCodesRemaining int  `json:"recovery_codes_remaining"`
+}
+
+// TOTPSetup contains the data needed to register an authenticator app.
+type TOTPSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// VerifyTwoFactor completes a login that is waiting for its second factor.
+func (s *AuthService) VerifyTwoFactor(challengeToken, code, ip, userAgent string) (*LoginResponse, error) {
+	metadata := auth.SessionMetadata{
+		UserAgent: userAgent,
+		IP:        ip,
+	}
+
+	session, user, err := s.authManager.CompleteTwoFactorLogin(challengeToken, code, metadata)
+	if err != nil {
+		return nil, mapTwoFactorError(err)
+	}
+
+	return &LoginResponse{
+		SessionID: session.ID,
+		ExpiresAt: session.ExpiresAt,
+		User:      *user,
+	}, nil
+}
+
+// GetTwoFactorStatus returns the second-factor state for the authenticated user.
+func (s *AuthService) GetTwoFactorStatus(userID string) (*TwoFactorStatus, error) {
+	credential, err := s.authManager.GetTOTPCredential(userID)
+	if err != nil {
+		if errors.Is(err, auth.ErrTwoFactorNotEnrolled) || errors.Is(err, auth.ErrTwoFactorNotSupported) {
+			return &TwoFactorStatus{}, nil
+		}
+		return nil, err
+	}
+
+	status := &TwoFactorStatus{
+		Enabled: credential.Enabled,
+		Pending: !credential.Enabled,
+	}
+	if credential.Enabled {
+		if status.RecoveryCodesRemaining, err = s.authManager.RecoveryCodesRemaining(userID); err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// SetupTOTP starts TOTP enrollment and returns the secret to be scanned.
+func (s *AuthService) SetupTOTP(userID string) (*TOTPSetup, error) {
+	user, err := s.userAdapter.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	enrollment, err := s.authManager.BeginTOTPEnrollment(user)
+	if err != nil {
+		return nil, mapTwoFactorError(err)
+	}
+
+	return &TOTPSetup{
+		Secret:     enrollment.Secret,
+		OTPAuthURI: enrollment.URI,
+	}, nil
+}
+
+// ConfirmTOTP enables TOTP once the first code from the app is verified and
+// returns the account's recovery codes.
+func (s *AuthService) ConfirmTOTP(userID, code string) ([]string, error) {
+	recoveryCodes, err := s.authManager.ConfirmTOTPEnrollment(userID, code)
+	if err != nil {
+		if errors.Is(err, auth.ErrTwoFactorNotEnrolled) {
+			return nil, ErrTwoFactorSetupRequired
+		}
+		return nil, mapTwoFactorError(err)
+	}
+	return recoveryCodes, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after re-checking
+// the password. Previously issued codes stop working.
+func (s *AuthService) RegenerateRecoveryCodes(userID, password string) ([]string, error) {
+	if err := s.checkPassword(userID, password); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.authManager.RegenerateRecoveryCodes(userID)
+	if err != nil {
+		return nil, mapTwoFactorError(err)
+	}
+	return recoveryCodes, nil
+}
+
+// DisableTOTP turns TOTP off after re-checking the user's password.
+func (s *AuthService) DisableTOTP(userID, password string) error {
+	if err := s.checkPassword(userID, password); err != nil {
+		return err
+	}
+
+	if err := s.authManager.DisableTOTP(userID); err != nil {
+		return mapTwoFactorError(err)
+	}
+	return nil
+}
+
+// checkPassword re-verifies the user's password before a sensitive change.
+// Wrong passwords count towards the account lockout like failed logins do.
+func (s *AuthService) checkPassword(userID, password string) error {
+	if err := s.authManager.ConfirmPassword(userID, password); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return ErrWrongPassword
+		case errors.Is(err, auth.ErrAccountLocked):
+			return ErrAccountLocked
+		case errors.Is(err, auth.ErrUserNotActive):
+			return ErrUserNotActive
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func mapTwoFactorError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrTwoFactorNotSupported):
+		return ErrTwoFactorUnavailable
+	case errors.Is(err, auth.ErrTwoFactorAlreadyEnabled):
+		return ErrTwoFactorAlreadyEnabled
+	case errors.Is(err, auth.ErrTwoFactorNotEnrolled):
+		return ErrTwoFactorNotEnabled
+	case errors.Is(err, auth.ErrInvalidTwoFactorCode):
+		return ErrInvalidTwoFactorCode
+	case errors.Is(err, auth.ErrChallengeNotFound), errors.Is(err, auth.ErrChallengeExpired):
+		return ErrInvalidChallenge
+	case errors.Is(err, auth.ErrUserNotActive):
+		return ErrUserNotActive
+	case errors.Is(err, auth.ErrAccountLocked):
+		return ErrAccountLocked
+	default:
+		return err
+	}
+}
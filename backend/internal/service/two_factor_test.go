@@ -0,0 +1,200 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// enableTestUserTOTP enrolls and confirms TOTP for a user, returning the secret
+func enableTestUserTOTP(t *testing.T, authService *AuthService, userID string) string {
+	t.Helper()
+
+	setup, err := authService.SetupTOTP(userID)
+	require.NoError(t, err)
+	require.NotEmpty(t, setup.Secret)
+
+	code, err := auth.GenerateTOTPCode(setup.Secret, time.Now())
+	require.NoError(t, err)
+	recoveryCodes, err := authService.ConfirmTOTP(userID, code)
+	require.NoError(t, err)
+	require.NotEmpty(t, recoveryCodes)
+
+	return setup.Secret
+}
+
+// nextTOTPCode returns a code for the next time step so it is not rejected as a replay
+func nextTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := auth.GenerateTOTPCode(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	return code
+}
+
+func TestAuthService_SetupTOTP(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	setup, err := authService.SetupTOTP(userID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, setup.Secret)
+	assert.Contains(t, setup.OTPAuthURI, "otpauth://totp/")
+	assert.Contains(t, setup.OTPAuthURI, "secret="+setup.Secret)
+
+	status, err := authService.GetTwoFactorStatus(userID)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+	assert.True(t, status.Pending)
+
+	// Login is not affected until the setup is confirmed
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.False(t, resp.TwoFactorRequired)
+	assert.NotEmpty(t, resp.SessionID)
+}
+
+func TestAuthService_ConfirmTOTP_InvalidCode(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+
+	_, err := authService.ConfirmTOTP(userID, "000000")
+	assert.ErrorIs(t, err, ErrTwoFactorSetupRequired)
+
+	_, err = authService.SetupTOTP(userID)
+	require.NoError(t, err)
+
+	_, err = authService.ConfirmTOTP(userID, "abcdef")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestAuthService_TwoFactorLogin(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	secret := enableTestUserTOTP(t, authService, userID)
+
+	_, err := authService.SetupTOTP(userID)
+	assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.True(t, resp.TwoFactorRequired)
+	assert.Empty(t, resp.SessionID)
+	require.NotEmpty(t, resp.ChallengeToken)
+
+	var sessionCount int64
+	require.NoError(t, db.Model(&models.Session{}).Count(&sessionCount).Error)
+	assert.Zero(t, sessionCount)
+
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, "000000", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+
+	code := nextTOTPCode(t, secret)
+	verified, err := authService.VerifyTwoFactor(resp.ChallengeToken, code, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, verified.SessionID)
+	assert.Equal(t, "testuser", verified.User.Identifier)
+
+	_, _, err = authService.ValidateSession(verified.SessionID, auth.SessionMetadata{})
+	require.NoError(t, err)
+
+	// Challenges are single use
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, code, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+
+	// The same code cannot be replayed on a new challenge
+	resp, err = authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, code, "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+}
+
+func TestAuthService_VerifyTwoFactor_ExpiredChallenge(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	secret := enableTestUserTOTP(t, authService, userID)
+
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	require.NoError(t, db.Model(&models.TwoFactorChallenge{}).
+		Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err = authService.VerifyTwoFactor(resp.ChallengeToken, nextTOTPCode(t, secret), "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrInvalidChallenge)
+}
+
+func TestAuthService_VerifyTwoFactor_TooManyAttempts(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	_ = enableTestUserTOTP(t, authService, userID)
+
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	for range auth.DefaultAuthConfig().MaxTwoFactorAttempts {
+		_, err = authService.VerifyTwoFactor(resp.ChallengeToken, "000000", "127.0.0.1", "test-agent")
+		assert.ErrorIs(t, err, ErrInvalidTwoFactorCode)
+	}
+
+	err = db.Where("user_id = ?", user.ID).First(&models.TwoFactorChallenge{}).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func TestAuthService_DisableTOTP(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	_ = enableTestUserTOTP(t, authService, userID)
+
+	err := authService.DisableTOTP(userID, "wrong-password")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	require.NoError(t, authService.DisableTOTP(userID, "password123"))
+
+	status, err := authService.GetTwoFactorStatus(userID)
+	require.NoError(t, err)
+	assert.False(t, status.Enabled)
+
+	resp, err := authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.False(t, resp.TwoFactorRequired)
+	assert.NotEmpty(t, resp.SessionID)
+
+	err = authService.DisableTOTP(userID, "password123")
+	assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+}
+
+func TestAuthService_DisableTOTP_WrongPasswordsLockAccount(t *testing.T) {
+	authService, _, _, _, _, db := setupTest(t)
+	user := createTestUser(t, db)
+	userID := strconv.FormatUint(uint64(user.ID), 10)
+	_ = enableTestUserTOTP(t, authService, userID)
+
+	for range auth.DefaultAuthConfig().MaxFailedAttempts {
+		_, err := authService.RegenerateRecoveryCodes(userID, "wrong-password")
+		assert.ErrorIs(t, err, ErrWrongPassword)
+	}
+
+	err := authService.DisableTOTP(userID, "password123")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+	_, err = authService.Login("testuser", "password123", "127.0.0.1", "test-agent")
+	assert.ErrorIs(t, err, ErrAccountLocked)
+
+	status, err := authService.GetTwoFactorStatus(userID)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+}
@@ -3,15 +3,22 @@ package integration
 
 import (
 	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"gosveltekit/internal/auth"
 	gormadapter "gosveltekit/internal/auth/adapter/gorm"
 	"gosveltekit/internal/email"
 	"gosveltekit/internal/handlers"
+	"gosveltekit/internal/middleware"
 	"gosveltekit/internal/models"
 	"gosveltekit/internal/router"
 	"gosveltekit/internal/service"
@@ -25,7 +32,28 @@ import (
 )
 
 func setupIntegrationTest(t *testing.T) (*gin.Engine, *gorm.DB, *auth.AuthManager, *email.MockEmailService) {
-	db := testutil.NewSQLiteTestDB(t, &models.User{}, &models.Session{})
+	db := testutil.NewSQLiteTestDB(
+		t,
+		&models.User{},
+		&models.Session{},
+		&models.TOTPCredential{},
+		&models.TwoFactorChallenge{},
+		&models.RecoveryCode{},
+		&models.WebAuthnCredential{},
+		&models.WebAuthnCeremony{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.MagicLinkToken{},
+		&models.EmailVerificationToken{},
+		&models.LoginLockout{},
+		&models.APIToken{},
+		&models.OAuthClient{},
+		&models.OAuthClientToken{},
+		&models.AuditEvent{},
+		&models.Organization{},
+		&models.OrganizationMembership{},
+		&models.OrganizationInvitation{},
+	)
 
 	// Setup adapters
 	userAdapter := gormadapter.NewUserAdapter(db)
@@ -34,10 +62,23 @@ func setupIntegrationTest(t *testing.T) (*gin.Engine, *gorm.DB, *auth.AuthManage
 	// Setup auth manager
 	authConfig := auth.DefaultAuthConfig()
 	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+	authManager.SetTwoFactorAdapter(gormadapter.NewTwoFactorAdapter(db))
+	require.NoError(t, authManager.SetPasskeyAdapter(gormadapter.NewPasskeyAdapter(db)))
+	authManager.SetIdentityAdapter(gormadapter.NewIdentityAdapter(db))
+	authManager.SetMagicLinkAdapter(gormadapter.NewMagicLinkAdapter(db))
+	authManager.SetEmailVerificationAdapter(gormadapter.NewEmailVerificationAdapter(db))
+	authManager.SetAPITokenAdapter(gormadapter.NewAPITokenAdapter(db))
+	authManager.SetOAuthClientAdapter(gormadapter.NewOAuthClientAdapter(db))
+	authManager.SetAuditAdapter(gormadapter.NewAuditAdapter(db))
+	authManager.SetLockoutAdapter(gormadapter.NewLockoutAdapter(db))
+	organizationAdapter := gormadapter.NewOrganizationAdapter(db)
+	authManager.SetOrganizationAdapter(organizationAdapter)
+	authManager.SetInvitationAdapter(gormadapter.NewInvitationAdapter(db))
 
 	// Setup services
 	emailService := email.NewMockEmailService()
 	authService := service.NewAuthService(authManager, sessionAdapter, userAdapter, emailService)
+	authService.SetOrganizationAdapter(organizationAdapter)
 	authHandler := handlers.NewAuthHandler(authService)
 
 	// Setup router
@@ -335,14 +376,23 @@ func TestAccountEndpointsFlow(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
+	rotatedSessionID := w.Header().Get(middleware.SessionHeaderName)
+	require.NotEmpty(t, rotatedSessionID)
 
-	// Session should be invalid after password change.
+	// The old token is invalid after password change...
 	w = httptest.NewRecorder()
 	req, _ = http.NewRequest("GET", "/api/account/profile", nil)
 	req.Header.Set("Authorization", "Bearer "+firstSessionID)
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusUnauthorized, w.Code)
 
+	// ...but the session continues under its new one
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+rotatedSessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
 	// 4. Login with new password
 	loginWithNewPassword := map[string]any{
 		"username": "accountuser",
@@ -381,9 +431,20 @@ func TestAccountEndpointsFlow(t *testing.T) {
 	require.NoError(t, err)
 	require.GreaterOrEqual(t, len(sessions), 2)
 
+	// Sessions are listed under public IDs, never their tokens
+	var revokedID string
+	for _, session := range sessions {
+		assert.NotEqual(t, secondSessionID, session["id"])
+		assert.NotEqual(t, thirdSessionID, session["id"])
+		if session["is_current"] != true {
+			revokedID = session["id"].(string)
+		}
+	}
+	require.NotEmpty(t, revokedID)
+
 	// 7. Revoke one non-current session
 	w = httptest.NewRecorder()
-	req, _ = http.NewRequest("DELETE", "/api/account/sessions/"+secondSessionID, nil)
+	req, _ = http.NewRequest("DELETE", "/api/account/sessions/"+revokedID, nil)
 	req.Header.Set("Authorization", "Bearer "+thirdSessionID)
 	r.ServeHTTP(w, req)
 	require.Equal(t, http.StatusOK, w.Code)
@@ -398,8 +459,501 @@ func TestAccountEndpointsFlow(t *testing.T) {
 	err = json.Unmarshal(w.Body.Bytes(), &sessions)
 	require.NoError(t, err)
 	for _, session := range sessions {
-		assert.NotEqual(t, secondSessionID, session["id"])
+		assert.NotEqual(t, revokedID, session["id"])
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/account/profile", nil)
+	req.Header.Set("Authorization", "Bearer "+secondSessionID)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestSudoModeFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, _ := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "sudouser",
+		"email":        "sudo@example.com",
+		"password":     "Test123!@#",
+		"display_name": "Sudo User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"username": "sudouser", "password": "Test123!@#"})
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	// Sign-in was an hour ago, so sensitive actions ask for the password
+	require.NoError(t, db.Model(&models.Session{}).
+		Where("id = ?", auth.HashToken(sessionID)).
+		Update("created_at", time.Now().Add(-time.Hour)).Error)
+
+	changePassword := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		jsonData, _ := json.Marshal(map[string]any{
+			"current_password": "Test123!@#",
+			"new_password":     "ComplexN3w!A",
+			"confirm_password": "ComplexN3w!A",
+		})
+		req, _ := http.NewRequest("POST", "/api/account/change-password", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", "Bearer "+sessionID)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
 	}
+	reauthenticate := func(password string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		jsonData, _ := json.Marshal(map[string]any{"password": password})
+		req, _ := http.NewRequest("POST", "/api/account/reauthenticate", bytes.NewBuffer(jsonData))
+		req.Header.Set("Authorization", "Bearer "+sessionID)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w = changePassword()
+	require.Equal(t, http.StatusForbidden, w.Code)
+	var denied map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &denied))
+	assert.Equal(t, true, denied["reauthentication_required"])
+
+	// Non-sensitive routes are unaffected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/account/sessions", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	require.Equal(t, http.StatusUnauthorized, reauthenticate("wrong-password").Code)
+	require.Equal(t, http.StatusForbidden, changePassword().Code)
+
+	w = reauthenticate("Test123!@#")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "reauthenticated_until")
+
+	require.Equal(t, http.StatusOK, changePassword().Code)
+}
+
+func TestAPITokenFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, _, _ := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "scriptuser",
+		"email":        "script@example.com",
+		"password":     "Test123!@#",
+		"display_name": "Script User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"username": "scriptuser", "password": "Test123!@#"})
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	serve := func(method, path, credential string, body any) *httptest.ResponseRecorder {
+		var reader *bytes.Buffer
+		if body != nil {
+			jsonData, _ := json.Marshal(body)
+			reader = bytes.NewBuffer(jsonData)
+		} else {
+			reader = &bytes.Buffer{}
+		}
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", "Bearer "+credential)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 1. Create a read-only token from the browser session
+	w = serve("POST", "/api/account/tokens", sessionID, map[string]any{"name": "backup script", "scopes": []string{"read"}})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	token := created["token"].(string)
+	tokenID := created["id"].(string)
+
+	// 2. The token reads data but cannot change it or manage credentials
+	w = serve("GET", "/api/me", token, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "scriptuser")
+	assert.Equal(t, http.StatusForbidden, serve("PATCH", "/api/account/profile", token, map[string]any{"display_name": "x"}).Code)
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/api/account/tokens", token, nil).Code)
+
+	// 3. It never shows up as a session, and is listed without its value
+	w = serve("GET", "/api/account/sessions", sessionID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var sessions []map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Len(t, sessions, 1)
+
+	w = serve("GET", "/api/account/tokens", sessionID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), token)
+	assert.Contains(t, w.Body.String(), "backup script")
+	assert.Contains(t, w.Body.String(), "last_used_at")
+
+	// 4. Once revoked it stops working
+	require.Equal(t, http.StatusOK, serve("DELETE", "/api/account/tokens/"+tokenID, sessionID, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/me", token, nil).Code)
+}
+
+func TestAccessTokenFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, authManager, _ := setupIntegrationTest(t)
+
+	// The backend is served over HTTP so the downstream service fetches its JWKS
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := auth.NewAccessTokenSigner(privateKey, nil, auth.AccessTokenSignerConfig{
+		Issuer:   server.URL,
+		Audience: "orders",
+		TTL:      5 * time.Minute,
+	})
+	require.NoError(t, err)
+	authManager.SetAccessTokenSigner(signer)
+
+	registration := map[string]any{
+		"username":     "jwtuser",
+		"email":        "jwt@example.com",
+		"password":     "Test123!@#",
+		"display_name": "JWT User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"username": "jwtuser", "password": "Test123!@#"})
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	// 1. Exchange the session for a read-only access token
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/token", bytes.NewBufferString(`{"scopes":["read"]}`))
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var tokenResponse service.AccessTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokenResponse))
+	assert.Equal(t, "Bearer", tokenResponse.TokenType)
+	assert.Equal(t, "read", tokenResponse.Scope)
+
+	// 2. A downstream service verifies it against the published keys
+	verifier := middleware.NewAccessTokenVerifier(
+		context.Background(), server.URL+"/.well-known/jwks.json", server.URL, "orders",
+	)
+	downstream := gin.New()
+	downstream.Use(middleware.AccessTokenMiddleware(verifier))
+	downstream.GET("/orders", middleware.RequireScope(auth.APITokenScopeRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("userID")})
+	})
+	downstream.POST("/orders", middleware.RequireScope(auth.APITokenScopeWrite), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+	downstream.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/orders", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+	downstream.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 3. The access token is not a session of the backend itself
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestClientCredentialsFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, _ := setupIntegrationTest(t)
+
+	adminHash, err := bcrypt.GenerateFromPassword([]byte("Admin123!@#"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.User{
+		Username:     "clientadmin",
+		Email:        "clientadmin@example.com",
+		PasswordHash: string(adminHash),
+		DisplayName:  "Client Admin",
+		Active:       true,
+		Role:         "admin",
+	}).Error)
+
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(map[string]any{"username": "clientadmin", "password": "Admin123!@#"})
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.41:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	// 1. An admin registers a read-only client acting as an admin
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"name": "reporting job", "role": "admin", "scopes": []string{"read", "admin"}})
+	req, _ = http.NewRequest("POST", "/api/admin/oauth-clients", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created service.CreatedOAuthClient
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	// 2. The job exchanges its credentials for a token
+	requestToken := func(secret string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", "/oauth/token", strings.NewReader("grant_type=client_credentials"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(created.ClientID, secret)
+		req.RemoteAddr = "198.51.100.42:1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, requestToken("wrong").Code)
+
+	w = requestToken(created.ClientSecret)
+	require.Equal(t, http.StatusOK, w.Code)
+	var tokenResponse service.AccessTokenResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokenResponse))
+	assert.Equal(t, "read admin", tokenResponse.Scope)
+
+	serve := func(method, path string) int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+tokenResponse.AccessToken)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 3. The token reaches admin routes through its role, but cannot change
+	// data or manage credentials, and has no user behind it
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/admin/users?pagination_mode=offset"))
+	assert.Equal(t, http.StatusForbidden, serve("DELETE", "/api/admin/oauth-clients/"+created.ClientID))
+	assert.Equal(t, http.StatusForbidden, serve("GET", "/api/account/tokens"))
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/me"))
+
+	// 4. Deleting the client revokes the token
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/admin/oauth-clients/"+created.ClientID, nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/admin/users?pagination_mode=offset"))
+}
+
+func TestImpersonationFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, _ := setupIntegrationTest(t)
+
+	adminHash, err := bcrypt.GenerateFromPassword([]byte("Admin123!@#"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.User{
+		Username:     "supportadmin",
+		Email:        "supportadmin@example.com",
+		PasswordHash: string(adminHash),
+		DisplayName:  "Support Admin",
+		Active:       true,
+		Role:         "admin",
+	}).Error)
+	customer := &models.User{
+		Username:     "customer",
+		Email:        "customer@example.com",
+		PasswordHash: string(adminHash),
+		DisplayName:  "Customer",
+		Active:       true,
+		Role:         "user",
+	}
+	require.NoError(t, db.Create(customer).Error)
+
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(map[string]any{"username": "supportadmin", "password": "Admin123!@#"})
+	req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.51:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	adminSessionID := loginResponse["session_id"].(string)
+
+	// 1. The admin starts impersonating the customer
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"reason": "ticket 42"})
+	req, _ = http.NewRequest("POST", fmt.Sprintf("/api/admin/users/%d/impersonate", customer.ID), bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", "Bearer "+adminSessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var impersonation service.LoginResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &impersonation))
+	assert.Equal(t, fmt.Sprint(customer.ID), impersonation.User.ID)
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+impersonation.SessionID)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 2. /api/me tells the frontend who is behind the session
+	w = serve("GET", "/api/me")
+	require.Equal(t, http.StatusOK, w.Code)
+	var me map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.Equal(t, "customer", me["identifier"])
+	info := me["impersonation"].(map[string]any)
+	assert.Equal(t, "supportadmin", info["impersonator_identifier"])
+	assert.Equal(t, "ticket 42", info["reason"])
+
+	// 3. Credentials cannot be changed while impersonating
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/account/change-password").Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/account/2fa/setup").Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/account/tokens").Code)
+	assert.Equal(t, http.StatusForbidden, serve("POST", "/api/account/reauthenticate").Code)
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/account/profile").Code)
+
+	// 4. Stopping ends the session and both ends are in the audit log
+	assert.Equal(t, http.StatusOK, serve("POST", "/api/impersonation/stop").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("GET", "/api/me").Code)
+
+	var types []string
+	require.NoError(t, db.Model(&models.AuditEvent{}).Where("type LIKE ?", "impersonation.%").Order("id").Pluck("type", &types).Error)
+	assert.Equal(t, []string{auth.AuditImpersonationStarted, auth.AuditImpersonationStopped}, types)
+}
+
+func TestPermissionGrantFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, _ := setupIntegrationTest(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Admin123!@#"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	require.NoError(t, db.Create(&models.User{
+		Username:     "grantadmin",
+		Email:        "grantadmin@example.com",
+		PasswordHash: string(hash),
+		DisplayName:  "Grant Admin",
+		Active:       true,
+		Role:         "admin",
+	}).Error)
+	support := &models.User{
+		Username:     "supportdesk",
+		Email:        "supportdesk@example.com",
+		PasswordHash: string(hash),
+		DisplayName:  "Support Desk",
+		Active:       true,
+		Role:         "user",
+	}
+	require.NoError(t, db.Create(support).Error)
+
+	login := func(username, remoteAddr string) string {
+		w := httptest.NewRecorder()
+		jsonData, _ := json.Marshal(map[string]any{"username": username, "password": "Admin123!@#"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response["session_id"].(string)
+	}
+	serve := func(sessionID, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+sessionID)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	adminSession := login("grantadmin", "198.51.100.61:1234")
+	supportSession := login("supportdesk", "198.51.100.62:1234")
+
+	// 1. A regular user has no permissions and no access to the admin area
+	w := serve(supportSession, "GET", "/api/me", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var me auth.UserData
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.Empty(t, me.Permissions)
+	assert.Equal(t, http.StatusForbidden, serve(supportSession, "GET", "/api/admin/users?pagination_mode=offset", "").Code)
+
+	// 2. The admin grants read access to the user list
+	w = serve(adminSession, "PUT", fmt.Sprintf("/api/admin/users/%d/permissions", support.ID), `{"grant":["admin:access","users:read"]}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 3. The user can now list users, but not change them
+	w = serve(supportSession, "GET", "/api/me", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	assert.Equal(t, []string{auth.PermissionAdminAccess, auth.PermissionUsersRead}, me.Permissions)
+	if rotated := w.Header().Get(middleware.SessionHeaderName); rotated != "" {
+		supportSession = rotated
+	}
+
+	assert.Equal(t, http.StatusOK, serve(supportSession, "GET", "/api/admin/users?pagination_mode=offset", "").Code)
+	w = serve(supportSession, "PATCH", fmt.Sprintf("/api/admin/users/%d/role", support.ID), `{"role":"admin"}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), auth.PermissionUsersWrite)
 }
 
 func TestAdminDashboardAccess(t *testing.T) {
@@ -596,3 +1150,194 @@ func TestAdminUsersListing(t *testing.T) {
 	require.Len(t, searchResponse.Items, 1)
 	assert.Equal(t, "charlie", searchResponse.Items[0]["identifier"])
 }
+
+func TestOrganizationFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, emailService := setupIntegrationTest(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Org123!@#"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	for _, username := range []string{"orgowner", "orgmember", "orgoutsider"} {
+		require.NoError(t, db.Create(&models.User{
+			Username:     username,
+			Email:        username + "@example.com",
+			PasswordHash: string(hash),
+			DisplayName:  username,
+			Active:       true,
+			Role:         "user",
+		}).Error)
+	}
+
+	login := func(username, remoteAddr string) string {
+		w := httptest.NewRecorder()
+		jsonData, _ := json.Marshal(map[string]any{"username": username, "password": "Org123!@#"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response["session_id"].(string)
+	}
+	serve := func(sessionID, method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+sessionID)
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	ownerSession := login("orgowner", "198.51.100.71:1234")
+	memberSession := login("orgmember", "198.51.100.72:1234")
+	outsiderSession := login("orgoutsider", "198.51.100.73:1234")
+
+	// 1. A user creates an organization and becomes its owner
+	w := serve(ownerSession, "POST", "/api/orgs", `{"name":"Acme"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var org service.OrganizationInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &org))
+	assert.Equal(t, auth.OrganizationRoleOwner, org.Role)
+	orgPath := "/api/orgs/" + org.ID
+
+	// 2. The owner invites a member, who accepts; there is no adding users
+	// without their consent. Outsiders cannot even see the organization.
+	assert.Equal(t, http.StatusNotFound, serve(ownerSession, "POST", orgPath+"/members", `{"identifier":"orgoutsider"}`).Code)
+	w = serve(ownerSession, "POST", orgPath+"/invitations", `{"email":"orgmember@example.com"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	sent := emailService.GetSentEmails()
+	require.NotEmpty(t, sent)
+	w = serve(memberSession, "POST", "/api/invitations/accept", fmt.Sprintf(`{"token":%q}`, sent[len(sent)-1].Token))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, http.StatusNotFound, serve(outsiderSession, "GET", orgPath, "").Code)
+
+	// 3. Members can look but not manage
+	w = serve(memberSession, "GET", orgPath+"/members", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var members struct {
+		Members []service.OrganizationMemberInfo `json:"members"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &members))
+	assert.Len(t, members.Members, 2)
+	assert.Equal(t, http.StatusForbidden, serve(memberSession, "POST", orgPath+"/invitations", `{"email":"orgoutsider@example.com"}`).Code)
+	assert.Equal(t, http.StatusForbidden, serve(memberSession, "DELETE", orgPath, "").Code)
+
+	// 4. The member makes it their active organization, reachable as "current"
+	assert.Equal(t, http.StatusNotFound, serve(memberSession, "GET", "/api/orgs/current", "").Code)
+	require.Equal(t, http.StatusOK, serve(memberSession, "POST", orgPath+"/switch", "").Code)
+	w = serve(memberSession, "GET", "/api/me", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var me handlers.CurrentUserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &me))
+	require.NotNil(t, me.ActiveOrganization)
+	assert.Equal(t, org.ID, me.ActiveOrganization.ID)
+	assert.Equal(t, http.StatusOK, serve(memberSession, "GET", "/api/orgs/current", "").Code)
+
+	// 5. The owner cannot leave as the last owner; the member can
+	var owner handlers.CurrentUserResponse
+	require.NoError(t, json.Unmarshal(serve(ownerSession, "GET", "/api/me", "").Body.Bytes(), &owner))
+	assert.Equal(t, http.StatusConflict, serve(ownerSession, "DELETE", fmt.Sprintf("%s/members/%s", orgPath, owner.ID), "").Code)
+	w = serve(memberSession, "DELETE", fmt.Sprintf("%s/members/%s", orgPath, me.ID), "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNotFound, serve(memberSession, "GET", "/api/orgs/current", "").Code)
+
+	// 6. The owner deletes the organization
+	require.Equal(t, http.StatusOK, serve(ownerSession, "DELETE", orgPath, "").Code)
+	assert.Equal(t, http.StatusNotFound, serve(ownerSession, "GET", orgPath, "").Code)
+}
+
+func TestInvitationFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, emailService := setupIntegrationTest(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("Org123!@#"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	for _, username := range []string{"inviteowner", "invitemember"} {
+		require.NoError(t, db.Create(&models.User{
+			Username:     username,
+			Email:        username + "@example.com",
+			PasswordHash: string(hash),
+			DisplayName:  username,
+			Active:       true,
+			Role:         "user",
+		}).Error)
+	}
+
+	post := func(sessionID, path, body, remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		if sessionID != "" {
+			req.Header.Set("Authorization", "Bearer "+sessionID)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		r.ServeHTTP(w, req)
+		return w
+	}
+	login := func(username, password, remoteAddr string) string {
+		w := post("", "/auth/login", fmt.Sprintf(`{"username":%q,"password":%q}`, username, password), remoteAddr)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response["session_id"].(string)
+	}
+	lastInvitationToken := func() string {
+		sent := emailService.GetSentEmails()
+		require.NotEmpty(t, sent)
+		require.Equal(t, email.MockEmailInvitation, sent[len(sent)-1].Kind)
+		return sent[len(sent)-1].Token
+	}
+
+	ownerSession := login("inviteowner", "Org123!@#", "198.51.100.81:1234")
+	w := post(ownerSession, "/api/orgs", `{"name":"Acme"}`, "198.51.100.81:1234")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var org service.OrganizationInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &org))
+	orgPath := "/api/orgs/" + org.ID
+
+	// 1. An existing user accepts an invitation while signed in
+	w = post(ownerSession, orgPath+"/invitations", `{"email":"invitemember@example.com","role":"admin"}`, "198.51.100.81:1234")
+	require.Equal(t, http.StatusCreated, w.Code)
+	memberSession := login("invitemember", "Org123!@#", "198.51.100.82:1234")
+	w = post(memberSession, "/api/invitations/accept", fmt.Sprintf(`{"token":%q}`, lastInvitationToken()), "198.51.100.82:1234")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var joined service.OrganizationInfo
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &joined))
+	assert.Equal(t, auth.OrganizationRoleAdmin, joined.Role)
+
+	// 2. Someone without an account previews the invitation and signs up with it
+	w = post(memberSession, orgPath+"/invitations", `{"email":"newcomer@example.com"}`, "198.51.100.82:1234")
+	require.Equal(t, http.StatusCreated, w.Code)
+	token := lastInvitationToken()
+
+	w = post("", "/auth/invitations/preview", fmt.Sprintf(`{"token":%q}`, token), "198.51.100.83:1234")
+	require.Equal(t, http.StatusOK, w.Code)
+	var preview service.InvitationPreview
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &preview))
+	assert.Equal(t, "Acme", preview.OrganizationName)
+	assert.False(t, preview.HasAccount)
+
+	registration := fmt.Sprintf(`{"username":"newcomer","password":"New123!@#","display_name":"Newcomer","invitation_token":%q}`, token)
+	w = post("", "/auth/register", registration, "198.51.100.84:1234")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	newcomerSession := login("newcomer", "New123!@#", "198.51.100.85:1234")
+	w = httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/orgs", nil)
+	req.Header.Set("Authorization", "Bearer "+newcomerSession)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var organizations struct {
+		Organizations []service.OrganizationInfo `json:"organizations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &organizations))
+	require.Len(t, organizations.Organizations, 1)
+	assert.Equal(t, org.ID, organizations.Organizations[0].ID)
+
+	// 3. The link cannot be used twice
+	w = post("", "/auth/register", strings.Replace(registration, "newcomer", "newcomer2", 1), "198.51.100.86:1234")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosveltekit/internal/email"
+	"gosveltekit/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, db, _, mockEmail := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "verifyuser",
+		"email":        "verify@example.com",
+		"password":     "Test123!@#",
+		"display_name": "Verify User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.80:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 1. Registration sends the verification email
+	sent := mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, email.MockEmailVerification, sent[0].Kind)
+	assert.Equal(t, "verify@example.com", sent[0].To)
+	token := sent[0].Token
+
+	// 2. Resending right away is throttled but answers the same way
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"email": "verify@example.com"})
+	req, _ = http.NewRequest("POST", "/auth/verify-email/resend", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.81:1234"
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, mockEmail.GetSentEmails(), 1)
+
+	// 3. Verify
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"token": token})
+	req, _ = http.NewRequest("POST", "/auth/verify-email", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.82:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var user models.User
+	require.NoError(t, db.Where("username = ?", "verifyuser").First(&user).Error)
+	assert.True(t, user.EmailVerified)
+
+	// 4. The token cannot be reused
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"token": token})
+	req, _ = http.NewRequest("POST", "/auth/verify-email", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.83:1234"
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
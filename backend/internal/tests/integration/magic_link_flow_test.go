@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosveltekit/internal/email"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, _, mockEmail := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "linkuser",
+		"email":        "link@example.com",
+		"password":     "Test123!@#",
+		"display_name": "Link User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.70:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	mockEmail.ClearSentEmails()
+
+	// 1. Request a link
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"email": "link@example.com"})
+	req, _ = http.NewRequest("POST", "/auth/magic-link", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.71:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	sent := mockEmail.GetSentEmails()
+	require.Len(t, sent, 1)
+	assert.Equal(t, email.MockEmailMagicLink, sent[0].Kind)
+	token := sent[0].Token
+
+	// 2. Consume it
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"token": token})
+	req, _ = http.NewRequest("POST", "/auth/magic-link/consume", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.72:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+	assert.NotEmpty(t, sessionID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 3. The link cannot be reused
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"token": token})
+	req, _ = http.NewRequest("POST", "/auth/magic-link/consume", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.73:1234"
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// 4. Unknown addresses get the same answer and no email
+	mockEmail.ClearSentEmails()
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"email": "nobody@example.com"})
+	req, _ = http.NewRequest("POST", "/auth/magic-link", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.74:1234"
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, mockEmail.GetSentEmails())
+}
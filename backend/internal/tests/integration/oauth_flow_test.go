@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosveltekit/internal/auth"
+	"gosveltekit/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, authManager, _ := setupIntegrationTest(t)
+
+	issuer := testutil.NewFakeOIDCProvider(t, "gosveltekit-test")
+	provider, err := auth.NewOIDCProvider(context.Background(), "oidc", issuer.Issuer(), auth.OAuthClientConfig{
+		ClientID:     "gosveltekit-test",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/oauth/oidc/callback",
+	})
+	require.NoError(t, err)
+	authManager.RegisterOAuthProvider(provider)
+
+	// 1. Start: the browser is sent to the provider with a state cookie
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/auth/oauth/oidc/start", nil)
+	req.RemoteAddr = "198.51.100.90:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+
+	var stateCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "oauth_state" {
+			stateCookie = cookie
+		}
+	}
+	require.NotNil(t, stateCookie)
+
+	// 2. The user signs in at the provider and comes back with a code
+	callback := issuer.Authorize(t, w.Header().Get("Location"), testutil.OIDCUser{
+		Subject:       "oidc-user-1",
+		Email:         "social@example.com",
+		EmailVerified: true,
+		Name:          "Social User",
+	})
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", callback.RequestURI(), nil)
+	req.RemoteAddr = "198.51.100.91:1234"
+	req.AddCookie(stateCookie)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/", w.Header().Get("Location"))
+
+	var sessionCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == "session_id" {
+			sessionCookie = cookie
+		}
+	}
+	require.NotNil(t, sessionCookie)
+
+	// 3. The session belongs to the newly created account
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/account/identities", nil)
+	req.AddCookie(sessionCookie)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var identities struct {
+		Identities []struct {
+			Provider string `json:"provider"`
+			Email    string `json:"email"`
+		} `json:"identities"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &identities))
+	require.Len(t, identities.Identities, 1)
+	assert.Equal(t, "oidc", identities.Identities[0].Provider)
+	assert.Equal(t, "social@example.com", identities.Identities[0].Email)
+
+	// 4. Replaying the callback is rejected
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", callback.RequestURI(), nil)
+	req.RemoteAddr = "198.51.100.92:1234"
+	req.AddCookie(stateCookie)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/?oauth_error=invalid_state", w.Header().Get("Location"))
+}
@@ -0,0 +1,146 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gosveltekit/internal/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasskeyFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, _, _ := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "passkeyuser",
+		"email":        "passkey@example.com",
+		"password":     "Test123!@#",
+		"display_name": "Passkey User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.70:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"username": "passkeyuser", "password": "Test123!@#"})
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.71:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	authenticator := testutil.NewSoftAuthenticator(t, "http://localhost:5173")
+
+	// 1. Begin registration
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/account/passkeys/register/begin", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var begin struct {
+		CeremonyToken string          `json:"ceremony_token"`
+		Options       json.RawMessage `json:"options"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &begin))
+	require.NotEmpty(t, begin.CeremonyToken)
+
+	// 2. Finish registration with the authenticator's attestation
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{
+		"ceremony_token": begin.CeremonyToken,
+		"credential":     json.RawMessage(authenticator.Register(t, begin.Options)),
+		"name":           "Security key",
+	})
+	req, _ = http.NewRequest("POST", "/api/account/passkeys/register/finish", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	// 3. List passkeys
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/account/passkeys", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var list struct {
+		Passkeys []map[string]any `json:"passkeys"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Passkeys, 1)
+	assert.Equal(t, "Security key", list.Passkeys[0]["name"])
+	passkeyID := list.Passkeys[0]["id"].(string)
+
+	// 4. Passwordless login
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/auth/webauthn/login/begin", nil)
+	req.RemoteAddr = "198.51.100.72:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &begin))
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{
+		"ceremony_token": begin.CeremonyToken,
+		"credential":     json.RawMessage(authenticator.Login(t, begin.Options)),
+	})
+	req, _ = http.NewRequest("POST", "/auth/webauthn/login/finish", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.73:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "session_id=")
+
+	var passkeyLogin map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &passkeyLogin))
+	passkeySessionID := passkeyLogin["session_id"].(string)
+	assert.NotEqual(t, sessionID, passkeySessionID)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/me", nil)
+	req.Header.Set("Authorization", "Bearer "+passkeySessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 5. Remove the passkey; it can no longer be used
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("DELETE", "/api/account/passkeys/"+passkeyID, nil)
+	req.Header.Set("Authorization", "Bearer "+passkeySessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/auth/webauthn/login/begin", nil)
+	req.RemoteAddr = "198.51.100.74:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &begin))
+
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{
+		"ceremony_token": begin.CeremonyToken,
+		"credential":     json.RawMessage(authenticator.Login(t, begin.Options)),
+	})
+	req, _ = http.NewRequest("POST", "/auth/webauthn/login/finish", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.75:1234"
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
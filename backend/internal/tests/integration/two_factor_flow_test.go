@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gosveltekit/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorFlow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r, _, _, _ := setupIntegrationTest(t)
+
+	registration := map[string]any{
+		"username":     "totpuser",
+		"email":        "totp@example.com",
+		"password":     "Test123!@#",
+		"display_name": "TOTP User",
+	}
+	w := httptest.NewRecorder()
+	jsonData, _ := json.Marshal(registration)
+	req, _ := http.NewRequest("POST", "/auth/register", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.50:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	login := map[string]any{
+		"username": "totpuser",
+		"password": "Test123!@#",
+	}
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(login)
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.51:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var loginResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &loginResponse))
+	sessionID := loginResponse["session_id"].(string)
+
+	// 1. Start enrollment
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/api/account/2fa/setup", nil)
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var setup map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &setup))
+	secret := setup["secret"].(string)
+	assert.Contains(t, setup["otpauth_uri"], "otpauth://totp/")
+
+	// 2. Confirm with the first code
+	code, err := auth.GenerateTOTPCode(secret, time.Now())
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"code": code})
+	req, _ = http.NewRequest("POST", "/api/account/2fa/confirm", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", "Bearer "+sessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var confirm struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &confirm))
+	assert.Len(t, confirm.RecoveryCodes, 10)
+
+	// 3. Password login now returns a challenge instead of a session
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(login)
+	req, _ = http.NewRequest("POST", "/auth/login", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.52:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+	var challengeResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &challengeResponse))
+	assert.Equal(t, true, challengeResponse["two_factor_required"])
+	assert.NotContains(t, challengeResponse, "session_id")
+	challengeToken := challengeResponse["challenge_token"].(string)
+
+	// 4. Complete login with a fresh code
+	code, err = auth.GenerateTOTPCode(secret, time.Now().Add(30*time.Second))
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"challenge_token": challengeToken, "code": code})
+	req, _ = http.NewRequest("POST", "/auth/2fa/verify", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "198.51.100.53:1234"
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), "session_id=")
+
+	var verifyResponse map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &verifyResponse))
+	secondSessionID := verifyResponse["session_id"].(string)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/account/2fa", nil)
+	req.Header.Set("Authorization", "Bearer "+secondSessionID)
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"enabled":true,"pending":false,"recovery_codes_remaining":10}`, w.Body.String())
+
+	// 5. Disable with password
+	w = httptest.NewRecorder()
+	jsonData, _ = json.Marshal(map[string]any{"password": "Test123!@#"})
+	req, _ = http.NewRequest("POST", "/api/account/2fa/disable", bytes.NewBuffer(jsonData))
+	req.Header.Set("Authorization", "Bearer "+secondSessionID)
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+}
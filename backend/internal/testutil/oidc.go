@@ -0,0 +1,228 @@
+package testutil
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+const fakeOIDCKeyID = "test-key"
+
+// OIDCUser is the identity the fake provider asserts for an authorization
+type OIDCUser struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// FakeOIDCProvider is an in-process OpenID Connect issuer for tests. It serves
+// discovery, JWKS and token endpoints, checks the PKCE verifier, and issues
+// RS256-signed ID tokens carrying the nonce of the authorization request.
+type FakeOIDCProvider struct {
+	Server   *httptest.Server
+	ClientID string
+
+	key   *rsa.PrivateKey
+	mu    sync.Mutex
+	codes map[string]fakeOIDCGrant
+}
+
+type fakeOIDCGrant struct {
+	user          OIDCUser
+	codeChallenge string
+	nonce         string
+	redirectURI   string
+}
+
+// NewFakeOIDCProvider starts a provider that accepts the given client ID
+func NewFakeOIDCProvider(t testing.TB, clientID string) *FakeOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate signing key: %v", err)
+	}
+
+	p := &FakeOIDCProvider{
+		ClientID: clientID,
+		key:      key,
+		codes:    make(map[string]fakeOIDCGrant),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/keys", p.handleKeys)
+	mux.HandleFunc("/token", p.handleToken)
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Server.Close)
+
+	return p
+}
+
+// Issuer returns the issuer URL to configure the relying party with
+func (p *FakeOIDCProvider) Issuer() string {
+	return p.Server.URL
+}
+
+// Authorize plays the user signing in at the provider: it reads the
+// authorization URL the relying party redirected to and returns the callback
+// URL the provider would send the browser back to.
+func (p *FakeOIDCProvider) Authorize(t testing.TB, authURL string, user OIDCUser) *url.URL {
+	t.Helper()
+
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("invalid authorization url: %v", err)
+	}
+	query := parsed.Query()
+
+	if query.Get("client_id") != p.ClientID {
+		t.Fatalf("unexpected client_id %q", query.Get("client_id"))
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("authorization request is missing a PKCE challenge")
+	}
+
+	code := rand.Text()
+	p.mu.Lock()
+	p.codes[code] = fakeOIDCGrant{
+		user:          user,
+		codeChallenge: query.Get("code_challenge"),
+		nonce:         query.Get("nonce"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	p.mu.Unlock()
+
+	callback, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil {
+		t.Fatalf("invalid redirect_uri: %v", err)
+	}
+	callbackQuery := callback.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	callback.RawQuery = callbackQuery.Encode()
+	return callback
+}
+
+func (p *FakeOIDCProvider) handleDiscovery(w http.ResponseWriter, _ *http.Request) {
+	writeOIDCJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                p.Issuer(),
+		"authorization_endpoint":                p.Issuer() + "/authorize",
+		"token_endpoint":                        p.Issuer() + "/token",
+		"jwks_uri":                              p.Issuer() + "/keys",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (p *FakeOIDCProvider) handleKeys(w http.ResponseWriter, _ *http.Request) {
+	publicKey := p.key.PublicKey
+	writeOIDCJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": fakeOIDCKeyID,
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func (p *FakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOIDCJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, _, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+	}
+
+	code := r.PostForm.Get("code")
+	p.mu.Lock()
+	grant, found := p.codes[code]
+	delete(p.codes, code)
+	p.mu.Unlock()
+
+	verifierHash := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	switch {
+	case !found, clientID != p.ClientID, r.PostForm.Get("redirect_uri") != grant.redirectURI:
+		writeOIDCJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	case base64.RawURLEncoding.EncodeToString(verifierHash[:]) != grant.codeChallenge:
+		writeOIDCJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":            p.Issuer(),
+		"sub":            grant.user.Subject,
+		"aud":            p.ClientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Hour).Unix(),
+		"email":          grant.user.Email,
+		"email_verified": grant.user.EmailVerified,
+		"name":           grant.user.Name,
+	}
+	if grant.nonce != "" {
+		claims["nonce"] = grant.nonce
+	}
+	if grant.user.PreferredUsername != "" {
+		claims["preferred_username"] = grant.user.PreferredUsername
+	}
+
+	idToken, err := p.signJWT(claims)
+	if err != nil {
+		writeOIDCJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeOIDCJSON(w, http.StatusOK, map[string]any{
+		"access_token": rand.Text(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *FakeOIDCProvider) signJWT(claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": fakeOIDCKeyID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func writeOIDCJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
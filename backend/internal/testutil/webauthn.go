@@ -0,0 +1,198 @@
+package testutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+const (
+	authenticatorFlagUserPresent  = 0x01
+	authenticatorFlagUserVerified = 0x04
+	authenticatorFlagAttestedData = 0x40
+)
+
+// SoftAuthenticator is an in-process WebAuthn authenticator for tests. It
+// holds a single ES256 discoverable credential and answers registration and
+// login options the way a browser would, using "none" attestation.
+type SoftAuthenticator struct {
+	Origin       string
+	CredentialID []byte
+	SignCount    uint32
+
+	key        *ecdsa.PrivateKey
+	userHandle []byte
+}
+
+// webAuthnOptions is the subset of creation/request options the authenticator reads
+type webAuthnOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RPID      string `json:"rpId"`
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+// NewSoftAuthenticator creates an authenticator that reports the given origin
+func NewSoftAuthenticator(t testing.TB, origin string) *SoftAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate authenticator key: %v", err)
+	}
+
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("failed to generate credential id: %v", err)
+	}
+
+	return &SoftAuthenticator{
+		Origin:       origin,
+		CredentialID: credentialID,
+		key:          key,
+	}
+}
+
+// Register answers registration options with an attestation response
+func (a *SoftAuthenticator) Register(t testing.TB, options any) []byte {
+	t.Helper()
+
+	opts := decodeWebAuthnOptions(t, options)
+	userHandle, err := base64.RawURLEncoding.DecodeString(opts.PublicKey.User.ID)
+	if err != nil {
+		t.Fatalf("failed to decode user handle: %v", err)
+	}
+	a.userHandle = userHandle
+
+	coseKey, err := cbor.Marshal(map[int]any{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("failed to encode credential public key: %v", err)
+	}
+
+	attestedData := make([]byte, 16, 16+2+len(a.CredentialID)+len(coseKey)) // zero AAGUID
+	attestedData = binary.BigEndian.AppendUint16(attestedData, uint16(len(a.CredentialID)))
+	attestedData = append(attestedData, a.CredentialID...)
+	attestedData = append(attestedData, coseKey...)
+
+	authData := a.authenticatorData(
+		opts.PublicKey.RP.ID,
+		authenticatorFlagUserPresent|authenticatorFlagUserVerified|authenticatorFlagAttestedData,
+	)
+	authData = append(authData, attestedData...)
+
+	attestationObject, err := cbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode attestation object: %v", err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"clientDataJSON":    a.clientData(t, "webauthn.create", opts.PublicKey.Challenge),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(attestationObject),
+	})
+}
+
+// Login answers request options with a signed assertion
+func (a *SoftAuthenticator) Login(t testing.TB, options any) []byte {
+	t.Helper()
+
+	opts := decodeWebAuthnOptions(t, options)
+	a.SignCount++
+
+	authData := a.authenticatorData(opts.PublicKey.RPID, authenticatorFlagUserPresent|authenticatorFlagUserVerified)
+	clientData := a.clientData(t, "webauthn.get", opts.PublicKey.Challenge)
+	clientDataRaw, _ := base64.RawURLEncoding.DecodeString(clientData)
+	clientDataHash := sha256.Sum256(clientDataRaw)
+
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("failed to sign assertion: %v", err)
+	}
+
+	return a.credentialJSON(t, map[string]any{
+		"clientDataJSON":    clientData,
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature":         base64.RawURLEncoding.EncodeToString(signature),
+		"userHandle":        base64.RawURLEncoding.EncodeToString(a.userHandle),
+	})
+}
+
+func (a *SoftAuthenticator) authenticatorData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, a.SignCount)
+}
+
+func (a *SoftAuthenticator) clientData(t testing.TB, ceremony, challenge string) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]any{
+		"type":        ceremony,
+		"challenge":   challenge,
+		"origin":      a.Origin,
+		"crossOrigin": false,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode client data: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (a *SoftAuthenticator) credentialJSON(t testing.TB, response map[string]any) []byte {
+	t.Helper()
+
+	id := base64.RawURLEncoding.EncodeToString(a.CredentialID)
+	data, err := json.Marshal(map[string]any{
+		"id":       id,
+		"rawId":    id,
+		"type":     "public-key",
+		"response": response,
+	})
+	if err != nil {
+		t.Fatalf("failed to encode credential: %v", err)
+	}
+	return data
+}
+
+func decodeWebAuthnOptions(t testing.TB, options any) *webAuthnOptions {
+	t.Helper()
+
+	raw, ok := options.([]byte)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(options); err != nil {
+			t.Fatalf("failed to encode webauthn options: %v", err)
+		}
+	}
+
+	var opts webAuthnOptions
+	if err := json.Unmarshal(raw, &opts); err != nil {
+		t.Fatalf("failed to decode webauthn options: %v", err)
+	}
+	return &opts
+}
@@ -214,6 +214,24 @@ func ValidateRegistrationRequest(username, email, password, displayName string)
 	return nil
 }
 
+// ValidateInvitedRegistrationRequest validates a registration whose email
+// comes from an invitation
+func ValidateInvitedRegistrationRequest(username, password, displayName string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+
+	if err := ValidatePassword(password, username); err != nil {
+		return err
+	}
+
+	if err := ValidateDisplayName(displayName); err != nil {
+		return fmt.Errorf("nome de exibição inválido: %w", err)
+	}
+
+	return nil
+}
+
 // ValidatePasswordReset validates a password reset request
 func ValidatePasswordReset(token, newPassword, confirmPassword string) error {
 	if err := ValidateResetToken(token); err != nil {
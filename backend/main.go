@@ -2,9 +2,17 @@
 package main
 
 import (
+	"context"
+	"crypto"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"gosveltekit/internal/auth"
 	gormadapter "gosveltekit/internal/auth/adapter/gorm"
@@ -12,13 +20,30 @@ import (
 	"gosveltekit/internal/config"
 	"gosveltekit/internal/email"
 	"gosveltekit/internal/handlers"
+	"gosveltekit/internal/maintenance"
 	"gosveltekit/internal/middleware"
 	"gosveltekit/internal/router"
 	"gosveltekit/internal/service"
 	"gosveltekit/internal/version"
+
+	"gorm.io/gorm"
 )
 
+const oauthDiscoveryTimeout = 10 * time.Second
+
+// auditQueueSize bounds the audit events waiting to be stored; past it new
+// events are dropped (and logged) rather than slowing requests down. Queued
+// events are flushed on SIGINT or SIGTERM, but lost if the process is killed.
+const auditQueueSize = 1024
+
+// shutdownTimeout bounds how long in-flight requests get to finish once the
+// server is asked to stop
+const shutdownTimeout = 30 * time.Second
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		panic("Falha ao carregar as configurações")
@@ -31,9 +56,26 @@ func main() {
 		)
 	}
 
+	passwordHasher, err := bootstrap.NewPasswordHasher(cfg)
+	if err != nil {
+		panic("Configuração inválida de hash de senha: " + err.Error())
+	}
+
 	// Initialize adapters
 	userAdapter := gormadapter.NewUserAdapter(db)
+	userAdapter.SetPasswordHasher(passwordHasher)
 	sessionAdapter := gormadapter.NewSessionAdapter(db)
+	twoFactorAdapter := gormadapter.NewTwoFactorAdapter(db)
+	passkeyAdapter := gormadapter.NewPasskeyAdapter(db)
+	identityAdapter := gormadapter.NewIdentityAdapter(db)
+	magicLinkAdapter := gormadapter.NewMagicLinkAdapter(db)
+	emailVerificationAdapter := gormadapter.NewEmailVerificationAdapter(db)
+	apiTokenAdapter := gormadapter.NewAPITokenAdapter(db)
+	oauthClientAdapter := gormadapter.NewOAuthClientAdapter(db)
+	organizationAdapter := gormadapter.NewOrganizationAdapter(db)
+	invitationAdapter := gormadapter.NewInvitationAdapter(db)
+	auditAdapter := gormadapter.NewAuditAdapter(db)
+	knownDeviceAdapter := gormadapter.NewKnownDeviceAdapter(db)
 
 	// Initialize auth manager from config
 	authConfig := auth.DefaultAuthConfig()
@@ -43,14 +85,111 @@ func main() {
 	if cfg.Auth.RefreshThreshold > 0 {
 		authConfig.RefreshThreshold = cfg.Auth.RefreshThreshold
 	}
+	authConfig.IdleTimeout = cfg.Auth.IdleTimeout
+	authConfig.AbsoluteLifetime = cfg.Auth.AbsoluteLifetime
 	if cfg.Auth.MaxFailedAttempts > 0 {
 		authConfig.MaxFailedAttempts = cfg.Auth.MaxFailedAttempts
 	}
 	if cfg.Auth.LockoutDuration > 0 {
 		authConfig.LockoutDuration = cfg.Auth.LockoutDuration
 	}
+	authConfig.ReauthenticationWindow = cfg.Auth.ReauthenticationWindow
+	if authConfig.SessionUserAgentPolicy, err = auth.ParseSessionBindingPolicy(cfg.Auth.SessionUserAgentPolicy); err != nil {
+		panic("Configuração inválida: auth.session_user_agent_policy deve ser ignore, warn ou revoke")
+	}
+	if authConfig.SessionIPPolicy, err = auth.ParseSessionBindingPolicy(cfg.Auth.SessionIPPolicy); err != nil {
+		panic("Configuração inválida: auth.session_ip_policy deve ser ignore, warn ou revoke")
+	}
+	if cfg.Auth.SessionIPv4Prefix > 0 {
+		authConfig.SessionIPv4Prefix = cfg.Auth.SessionIPv4Prefix
+	}
+	if cfg.Auth.SessionIPv6Prefix > 0 {
+		authConfig.SessionIPv6Prefix = cfg.Auth.SessionIPv6Prefix
+	}
+	if cfg.Auth.TOTPIssuer != "" {
+		authConfig.TOTPIssuer = cfg.Auth.TOTPIssuer
+	}
+	if cfg.Auth.TwoFactorChallengeTTL > 0 {
+		authConfig.TwoFactorChallengeTTL = cfg.Auth.TwoFactorChallengeTTL
+	}
+	if cfg.Auth.WebAuthnRPID != "" {
+		authConfig.WebAuthnRPID = cfg.Auth.WebAuthnRPID
+	}
+	if cfg.Auth.WebAuthnRPDisplayName != "" {
+		authConfig.WebAuthnRPDisplayName = cfg.Auth.WebAuthnRPDisplayName
+	}
+	if len(cfg.Auth.WebAuthnRPOrigins) > 0 {
+		authConfig.WebAuthnRPOrigins = cfg.Auth.WebAuthnRPOrigins
+	}
+	if cfg.Auth.MagicLinkTTL > 0 {
+		authConfig.MagicLinkTTL = cfg.Auth.MagicLinkTTL
+	}
+	authConfig.RequireEmailVerification = cfg.Auth.RequireEmailVerification
+	if cfg.Auth.EmailVerificationTTL > 0 {
+		authConfig.EmailVerificationTTL = cfg.Auth.EmailVerificationTTL
+	}
+	if cfg.Auth.EmailVerificationResendInterval > 0 {
+		authConfig.EmailVerificationResendInterval = cfg.Auth.EmailVerificationResendInterval
+	}
+	if cfg.Auth.ClientAccessTokenTTL > 0 {
+		authConfig.ClientAccessTokenTTL = cfg.Auth.ClientAccessTokenTTL
+	}
+	if cfg.Auth.ImpersonationDuration > 0 {
+		authConfig.ImpersonationDuration = cfg.Auth.ImpersonationDuration
+	}
+	if cfg.Auth.InvitationTTL > 0 {
+		authConfig.InvitationTTL = cfg.Auth.InvitationTTL
+	}
+	authConfig.InviteOnlyRegistration = cfg.Auth.InviteOnlyRegistration
+	authConfig.KnownDeviceTTL = cfg.Auth.KnownDeviceTTL
+	if cfg.Auth.SessionRevokeLinkTTL > 0 {
+		authConfig.SessionRevokeLinkTTL = cfg.Auth.SessionRevokeLinkTTL
+	}
 
 	authManager := auth.NewAuthManager(userAdapter, sessionAdapter, authConfig)
+	authManager.SetTwoFactorAdapter(twoFactorAdapter)
+	if err := authManager.SetPasskeyAdapter(passkeyAdapter); err != nil {
+		panic("Configuração WebAuthn inválida: " + err.Error())
+	}
+	authManager.SetIdentityAdapter(identityAdapter)
+	registerOAuthProviders(authManager, cfg)
+	authManager.SetMagicLinkAdapter(magicLinkAdapter)
+	authManager.SetEmailVerificationAdapter(emailVerificationAdapter)
+	authManager.SetAPITokenAdapter(apiTokenAdapter)
+	authManager.SetOAuthClientAdapter(oauthClientAdapter)
+	authManager.SetOrganizationAdapter(organizationAdapter)
+	authManager.SetInvitationAdapter(invitationAdapter)
+	auditWriter := auth.NewAuditWriter(auditAdapter, auditQueueSize)
+	authManager.SetAuditAdapter(auditWriter)
+	authManager.SetKnownDeviceAdapter(knownDeviceAdapter)
+	if cfg.Auth.AccessTokenKeyFile != "" {
+		signer, err := newAccessTokenSigner(cfg)
+		if err != nil {
+			panic("Configuração inválida de tokens de acesso: " + err.Error())
+		}
+		authManager.SetAccessTokenSigner(signer)
+	}
+	switch cfg.Auth.LockoutStore {
+	case "", "memory":
+		// Default: per-process lockout state
+	case "database":
+		authManager.SetLockoutAdapter(gormadapter.NewLockoutAdapter(db))
+	default:
+		panic("Configuração inválida: auth.lockout_store deve ser memory ou database")
+	}
+	if cfg.Maintenance.Enabled {
+		startJanitor(ctx, cfg, authManager, maintenanceAdapters{
+			db:                db,
+			user:              userAdapter,
+			twoFactor:         twoFactorAdapter,
+			passkey:           passkeyAdapter,
+			identity:          identityAdapter,
+			magicLink:         magicLinkAdapter,
+			emailVerification: emailVerificationAdapter,
+			oauthClient:       oauthClientAdapter,
+			invitation:        invitationAdapter,
+		})
+	}
 	authMiddlewareOptions := middleware.AuthMiddlewareOptions{
 		AllowHeaderAuth: cfg.Auth.AllowHeaderAuth,
 		AllowCookieAuth: cfg.Auth.AllowCookieAuth,
@@ -60,18 +199,178 @@ func main() {
 	// Initialize services
 	emailService := email.NewEmailService(cfg)
 	authService := service.NewAuthService(authManager, sessionAdapter, userAdapter, emailService)
+	authService.SetOrganizationAdapter(organizationAdapter)
+	authService.SetAuditAdapter(auditAdapter)
+	stopNewDeviceAlerts := authService.EnableNewDeviceAlerts()
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService, cfg.Auth.CookieSecure)
+	authHandler.SetOAuthFrontendURL(cfg.Auth.OAuthFrontendURL)
 
 	// Setup router
 	r := router.SetupRouter(authHandler, authManager, authMiddlewareOptions)
 
 	// Start server
-	listenAddr := fmt.Sprintf(":%d", cfg.Server.Port)
-	slog.Info("starting server", "addr", listenAddr, "version", "v"+version.Get())
-	if err := r.Run(listenAddr); err != nil {
+	server := &http.Server{Addr: fmt.Sprintf(":%d", cfg.Server.Port), Handler: r}
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "addr", server.Addr, "version", "v"+version.Get())
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
 		slog.Error("failed to start server", "err", err)
 		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// Let in-flight requests finish, then the emails and audit events they
+	// queued, so that a deploy does not drop them
+	slog.Info("shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to shut down server", "err", err)
+	}
+	stopNewDeviceAlerts()
+	authManager.Events().Wait()
+	auditWriter.Close()
+}
+
+// maintenanceAdapters are the stores the janitor cleans up
+type maintenanceAdapters struct {
+	db                *gorm.DB
+	user              *gormadapter.UserAdapter
+	twoFactor         *gormadapter.TwoFactorAdapter
+	passkey           *gormadapter.PasskeyAdapter
+	identity          *gormadapter.IdentityAdapter
+	magicLink         *gormadapter.MagicLinkAdapter
+	emailVerification *gormadapter.EmailVerificationAdapter
+	oauthClient       *gormadapter.OAuthClientAdapter
+	invitation        *gormadapter.InvitationAdapter
+}
+
+// startJanitor purges expired sessions, tokens, lockouts and stale known
+// devices in the background. Every replica runs it, but only the one holding
+// the PostgreSQL advisory lock does any work.
+func startJanitor(ctx context.Context, cfg *config.Config, authManager *auth.AuthManager, adapters maintenanceAdapters) {
+	sqlDB, err := adapters.db.DB()
+	if err != nil {
+		panic("Falha ao obter a conexão SQL para a manutenção: " + err.Error())
+	}
+
+	tasks := []maintenance.Task{
+		{
+			Name:     "sessions",
+			Interval: cfg.Maintenance.SessionsInterval,
+			Run: maintenance.Steps(
+				maintenance.Step{Name: "sessions", Run: authManager.PurgeExpiredSessions},
+				maintenance.Step{Name: "known_devices", Run: authManager.PurgeStaleKnownDevices},
+			),
+		},
+		{
+			Name:     "tokens",
+			Interval: cfg.Maintenance.TokensInterval,
+			Run: maintenance.Steps(
+				maintenance.Step{Name: "password_reset", Run: adapters.user.ClearExpiredResetTokens},
+				maintenance.Step{Name: "magic_link", Run: adapters.magicLink.DeleteExpiredMagicLinkTokens},
+				maintenance.Step{Name: "email_verification", Run: adapters.emailVerification.DeleteExpiredEmailVerificationTokens},
+				maintenance.Step{Name: "two_factor_challenge", Run: adapters.twoFactor.DeleteExpiredChallenges},
+				maintenance.Step{Name: "passkey_ceremony", Run: adapters.passkey.DeleteExpiredCeremonies},
+				maintenance.Step{Name: "oauth_state", Run: adapters.identity.DeleteExpiredOAuthStates},
+				maintenance.Step{Name: "client_access_token", Run: adapters.oauthClient.DeleteExpiredClientAccessTokens},
+				maintenance.Step{Name: "invitation", Run: adapters.invitation.DeleteExpiredInvitations},
+			),
+		},
+	}
+	// The in-memory store purges itself and is not shared, so only the
+	// database store needs a janitor
+	if cfg.Auth.LockoutStore == "database" {
+		tasks = append(tasks, maintenance.Task{
+			Name:     "lockouts",
+			Interval: cfg.Maintenance.LockoutsInterval,
+			Run:      maintenance.Steps(maintenance.Step{Name: "lockouts", Run: authManager.PurgeLockouts}),
+		})
+	}
+
+	leader := maintenance.NewAdvisoryLockLeader(sqlDB, maintenance.DefaultLockKey)
+	go maintenance.NewJanitor(leader, tasks...).Run(ctx)
+}
+
+// newAccessTokenSigner loads the key that signs access tokens and the
+// previous keys still published for tokens they signed
+func newAccessTokenSigner(cfg *config.Config) (*auth.AccessTokenSigner, error) {
+	data, err := os.ReadFile(cfg.Auth.AccessTokenKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	current, err := auth.ParseAccessTokenSigningKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", cfg.Auth.AccessTokenKeyFile, err)
+	}
+
+	var previous []crypto.PublicKey
+	for _, path := range cfg.Auth.AccessTokenPreviousKeyFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		key, err := auth.ParseAccessTokenVerificationKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		previous = append(previous, key)
+	}
+
+	return auth.NewAccessTokenSigner(current, previous, auth.AccessTokenSignerConfig{
+		Issuer:   cfg.Auth.AccessTokenIssuer,
+		Audience: cfg.Auth.AccessTokenAudience,
+		TTL:      cfg.Auth.AccessTokenTTL,
+	})
+}
+
+// registerOAuthProviders enables the social login providers that have a client
+// ID configured. A provider whose issuer cannot be reached is skipped so the
+// rest of the API still starts.
+func registerOAuthProviders(authManager *auth.AuthManager, cfg *config.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), oauthDiscoveryTimeout)
+	defer cancel()
+
+	for name, providerCfg := range cfg.Auth.OAuthProviders {
+		if providerCfg.ClientID == "" {
+			continue
+		}
+
+		client := auth.OAuthClientConfig{
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  strings.TrimSuffix(cfg.Auth.OAuthRedirectBaseURL, "/") + "/auth/oauth/" + name + "/callback",
+			Scopes:       providerCfg.Scopes,
+		}
+
+		providerType := providerCfg.Type
+		if providerType == "" {
+			providerType = name
+		}
+
+		switch providerType {
+		case "github":
+			authManager.RegisterOAuthProvider(auth.NewGitHubProvider(name, client))
+		case "google", "oidc":
+			issuerURL := providerCfg.IssuerURL
+			if issuerURL == "" && providerType == "google" {
+				issuerURL = auth.GoogleIssuerURL
+			}
+
+			provider, err := auth.NewOIDCProvider(ctx, name, issuerURL, client)
+			if err != nil {
+				slog.Error("failed to set up oauth provider", "provider", name, "err", err)
+				continue
+			}
+			authManager.RegisterOAuthProvider(provider)
+		default:
+			slog.Error("unknown oauth provider type", "provider", name, "type", providerType)
+		}
 	}
 }
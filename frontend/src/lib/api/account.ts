@@ -1,4 +1,6 @@
+import { buildAuditQuery, type AuditCursorParams, type AuditEvent } from './admin'
 import { apiRequest } from './client'
+import type { CursorPaginatedResponse } from './pagination'
 
 export interface AccountProfile {
     id: string
@@ -30,11 +32,16 @@ export interface AccountSession {
     id: string
     created_at: string
     expires_at: string
+    last_seen_at: string
     user_agent?: string
     ip?: string
     is_current: boolean
 }
 
+export interface ReauthenticationStatus {
+    reauthenticated_until: string
+}
+
 interface MessageResponse {
     message: string
 }
@@ -63,6 +70,14 @@ export const accountApi = {
         })
     },
 
+    // A wrong password answers 401 without ending the session
+    reauthenticate: async (password: string): Promise<ReauthenticationStatus> => {
+        return apiRequest<ReauthenticationStatus>('/api/account/reauthenticate', {
+            method: 'POST',
+            body: JSON.stringify({ password })
+        })
+    },
+
     listSessions: async (): Promise<AccountSession[]> => {
         return apiRequest<AccountSession[]>('/api/account/sessions', {
             method: 'GET',
@@ -75,5 +90,18 @@ export const accountApi = {
             method: 'DELETE',
             requiresAuth: true
         })
+    },
+
+    // Sign-ins, failed attempts and other changes to the user's own account
+    listSecurityActivity: async (
+        params: AuditCursorParams = {}
+    ): Promise<CursorPaginatedResponse<AuditEvent>> => {
+        return apiRequest<CursorPaginatedResponse<AuditEvent>>(
+            `/api/account/security-activity${buildAuditQuery(params)}`,
+            {
+                method: 'GET',
+                requiresAuth: true
+            }
+        )
     }
 }
@@ -1,5 +1,20 @@
 import { apiRequest } from './client'
-import type { PaginatedResponse, PaginationMode, SortDirection } from './pagination'
+import type {
+    CursorPaginatedResponse,
+    PaginatedResponse,
+    PaginationMode,
+    SortDirection
+} from './pagination'
+
+export interface ImpersonationResponse {
+    session_id: string
+    expires_at: string
+}
+
+export interface StopImpersonationResponse {
+    message: string
+    restored: boolean // the admin's own session cookie was put back
+}
 
 export interface AdminUserRow {
     id: string
@@ -10,6 +25,14 @@ export interface AdminUserRow {
     active: boolean
     last_login: string
     created_at: string
+    permissions: string[] // effective
+    permission_overrides: PermissionOverrides
+}
+
+// Per-user exceptions to the role's permissions; deny wins over grant
+export interface PermissionOverrides {
+    grant?: string[]
+    deny?: string[]
 }
 
 interface BaseListAdminUsersParams {
@@ -33,6 +56,72 @@ export interface ListAdminUsersCursorParams extends BaseListAdminUsersParams {
 
 export type ListAdminUsersParams = ListAdminUsersOffsetParams | ListAdminUsersCursorParams
 
+// Organizations sort by name or created_at in both pagination modes
+export type ListAdminOrganizationsParams = ListAdminUsersParams
+
+export interface AdminOrganizationRow {
+    id: string
+    name: string
+    member_count: number
+    created_at: string
+}
+
+// One entry of the security audit log; actor_id is who acted, user_id whose account it concerns
+export interface AuditEvent {
+    id: string
+    type: string // e.g. login.succeeded, user.role_changed
+    actor_id?: string
+    user_id?: string
+    ip?: string
+    user_agent?: string
+    details?: Record<string, string>
+    created_at: string
+}
+
+// The audit log only pages by cursor and sorts by created_at (newest first by default)
+export interface AuditCursorParams {
+    page_size?: number
+    order?: SortDirection
+    after?: string
+    before?: string
+}
+
+export interface ListAuditEventsParams extends AuditCursorParams {
+    actor_id?: string
+    user_id?: string
+    action?: string
+    from?: string // RFC 3339, inclusive
+    to?: string // RFC 3339, exclusive
+}
+
+export function buildAuditQuery(params: ListAuditEventsParams = {}) {
+    const query = new URLSearchParams()
+
+    const keys = [
+        'actor_id',
+        'user_id',
+        'action',
+        'from',
+        'to',
+        'order',
+        'after',
+        'before'
+    ] as const
+    for (const key of keys) {
+        const value = params[key]?.trim()
+        if (value) {
+            query.set(key, value)
+        }
+    }
+
+    if (params.page_size) {
+        query.set('page_size', String(params.page_size))
+    }
+
+    const search = query.toString()
+    return search ? `?${search}` : ''
+}
+
 function buildUsersQuery(params: ListAdminUsersParams) {
     const query = new URLSearchParams()
 
@@ -79,5 +168,57 @@ export const adminApi = {
                 requiresAuth: true
             }
         )
+    },
+
+    listOrganizations: async (
+        params: ListAdminOrganizationsParams
+    ): Promise<PaginatedResponse<AdminOrganizationRow>> => {
+        return apiRequest<PaginatedResponse<AdminOrganizationRow>>(
+            `/api/admin/organizations${buildUsersQuery(params)}`,
+            {
+                method: 'GET',
+                requiresAuth: true
+            }
+        )
+    },
+
+    listAuditEvents: async (
+        params: ListAuditEventsParams = {}
+    ): Promise<CursorPaginatedResponse<AuditEvent>> => {
+        return apiRequest<CursorPaginatedResponse<AuditEvent>>(
+            `/api/admin/audit${buildAuditQuery(params)}`,
+            {
+                method: 'GET',
+                requiresAuth: true
+            }
+        )
+    },
+
+    // Replaces the user's grants and denies; only permissions the caller holds can be granted
+    updatePermissions: async (
+        userID: string,
+        overrides: PermissionOverrides
+    ): Promise<AdminUserRow> => {
+        return apiRequest<AdminUserRow>(`/api/admin/users/${userID}/permissions`, {
+            method: 'PUT',
+            body: JSON.stringify(overrides),
+            requiresAuth: true
+        })
+    },
+
+    // Needs a recent password confirmation; the session cookie switches to the user
+    impersonate: async (userID: string, reason: string): Promise<ImpersonationResponse> => {
+        return apiRequest<ImpersonationResponse>(`/api/admin/users/${userID}/impersonate`, {
+            method: 'POST',
+            body: JSON.stringify({ reason }),
+            requiresAuth: true
+        })
+    },
+
+    stopImpersonation: async (): Promise<StopImpersonationResponse> => {
+        return apiRequest<StopImpersonationResponse>('/api/impersonation/stop', {
+            method: 'POST',
+            requiresAuth: true
+        })
     }
 }
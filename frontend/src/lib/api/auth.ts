@@ -9,9 +9,10 @@ export interface LoginRequest {
 
 export interface RegisterRequest {
     username: string
-    email: string
+    email?: string // omitted with an invitation, whose email is used
     password: string
     display_name: string
+    invitation_token?: string
 }
 
 // Updated to match new session-based response from backend
@@ -24,6 +25,7 @@ export interface AuthResponse {
         email: string
         display_name: string
         role: string
+        permissions: string[]
         active: boolean
     }
 }
@@ -21,10 +21,14 @@ let unauthorizedHandler: (() => void) | null = null
 // Error types
 export class ApiError extends Error {
     status: number
+    // Set when a sensitive action needs the password confirmed again through
+    // accountApi.reauthenticate before it is retried
+    reauthenticationRequired: boolean
 
-    constructor(message: string, status: number) {
+    constructor(message: string, status: number, reauthenticationRequired = false) {
         super(message)
         this.status = status
+        this.reauthenticationRequired = reauthenticationRequired
         this.name = 'ApiError'
     }
 }
@@ -66,6 +70,10 @@ export async function apiRequest<T = unknown>(
     }
 }
 
+export function isReauthenticationRequired(error: unknown): boolean {
+    return error instanceof ApiError && error.reauthenticationRequired
+}
+
 // Handle API response
 async function handleResponse<T>(
     response: Response,
@@ -76,6 +84,7 @@ async function handleResponse<T>(
 
     if (!response.ok) {
         const message = data.error || data.message || 'Something went wrong'
+        const reauthenticationRequired = data.reauthentication_required === true
 
         if (response.status === 401 && invalidateAuthOnUnauthorized && browser) {
             unauthorizedHandler?.()
@@ -84,12 +93,12 @@ async function handleResponse<T>(
         if (requiresAuth && browser) {
             if (response.status === 401) {
                 void goto(resolve('/session-expired'))
-            } else if (response.status === 403) {
+            } else if (response.status === 403 && !reauthenticationRequired) {
                 void goto(resolve('/forbidden'))
             }
         }
 
-        throw new ApiError(message, response.status)
+        throw new ApiError(message, response.status, reauthenticationRequired)
     }
 
     return data as T
@@ -0,0 +1,175 @@
+import { apiRequest } from './client'
+
+export type OrganizationRole = 'owner' | 'admin' | 'member'
+
+// An organization as seen by one of its members
+export interface Organization {
+    id: string
+    name: string
+    role: OrganizationRole // the caller's role in it
+    joined_at: string
+}
+
+export interface OrganizationMember {
+    user_id: string
+    identifier: string
+    display_name: string
+    email: string
+    role: OrganizationRole
+    joined_at: string
+}
+
+// A pending invitation, as listed to the organization's admins
+export interface OrganizationInvitation {
+    id: string
+    email: string
+    role: OrganizationRole
+    inviter_id: string
+    inviter_name: string
+    expires_at: string
+    expired: boolean
+    created_at: string
+}
+
+// What the holder of an invitation link is invited to
+export interface InvitationPreview {
+    organization_name: string
+    email: string
+    role: OrganizationRole
+    inviter_name: string
+    expires_at: string
+    has_account: boolean // sign in and accept instead of registering
+}
+
+interface MessageResponse {
+    message: string
+}
+
+// orgID may be 'current' for the session's active organization
+export const organizationsApi = {
+    list: async (): Promise<{ organizations: Organization[] }> => {
+        return apiRequest<{ organizations: Organization[] }>('/api/orgs', {
+            method: 'GET',
+            requiresAuth: true
+        })
+    },
+
+    create: async (name: string): Promise<Organization> => {
+        return apiRequest<Organization>('/api/orgs', {
+            method: 'POST',
+            body: JSON.stringify({ name }),
+            requiresAuth: true
+        })
+    },
+
+    get: async (orgID: string): Promise<Organization> => {
+        return apiRequest<Organization>(`/api/orgs/${orgID}`, {
+            method: 'GET',
+            requiresAuth: true
+        })
+    },
+
+    // Owners only
+    delete: async (orgID: string): Promise<MessageResponse> => {
+        return apiRequest<MessageResponse>(`/api/orgs/${orgID}`, {
+            method: 'DELETE',
+            requiresAuth: true
+        })
+    },
+
+    listMembers: async (orgID: string): Promise<{ members: OrganizationMember[] }> => {
+        return apiRequest<{ members: OrganizationMember[] }>(`/api/orgs/${orgID}/members`, {
+            method: 'GET',
+            requiresAuth: true
+        })
+    },
+
+    updateMemberRole: async (
+        orgID: string,
+        userID: string,
+        role: OrganizationRole
+    ): Promise<OrganizationMember> => {
+        return apiRequest<OrganizationMember>(`/api/orgs/${orgID}/members/${userID}`, {
+            method: 'PATCH',
+            body: JSON.stringify({ role }),
+            requiresAuth: true
+        })
+    },
+
+    // Pass the caller's own ID to leave the organization
+    removeMember: async (orgID: string, userID: string): Promise<MessageResponse> => {
+        return apiRequest<MessageResponse>(`/api/orgs/${orgID}/members/${userID}`, {
+            method: 'DELETE',
+            requiresAuth: true
+        })
+    },
+
+    listInvitations: async (orgID: string): Promise<{ invitations: OrganizationInvitation[] }> => {
+        return apiRequest<{ invitations: OrganizationInvitation[] }>(
+            `/api/orgs/${orgID}/invitations`,
+            {
+                method: 'GET',
+                requiresAuth: true
+            }
+        )
+    },
+
+    // Admins and owners; a pending invitation for the same email is replaced
+    invite: async (
+        orgID: string,
+        email: string,
+        role: OrganizationRole = 'member'
+    ): Promise<OrganizationInvitation> => {
+        return apiRequest<OrganizationInvitation>(`/api/orgs/${orgID}/invitations`, {
+            method: 'POST',
+            body: JSON.stringify({ email, role }),
+            requiresAuth: true
+        })
+    },
+
+    // Sends a new link; the previous one stops working
+    resendInvitation: async (
+        orgID: string,
+        invitationID: string
+    ): Promise<OrganizationInvitation> => {
+        return apiRequest<OrganizationInvitation>(
+            `/api/orgs/${orgID}/invitations/${invitationID}/resend`,
+            {
+                method: 'POST',
+                requiresAuth: true
+            }
+        )
+    },
+
+    revokeInvitation: async (orgID: string, invitationID: string): Promise<MessageResponse> => {
+        return apiRequest<MessageResponse>(`/api/orgs/${orgID}/invitations/${invitationID}`, {
+            method: 'DELETE',
+            requiresAuth: true
+        })
+    },
+
+    // Public; token comes from the emailed link
+    previewInvitation: async (token: string): Promise<InvitationPreview> => {
+        return apiRequest<InvitationPreview>('/auth/invitations/preview', {
+            method: 'POST',
+            body: JSON.stringify({ token })
+        })
+    },
+
+    // The signed-in user's email must be the invited one
+    acceptInvitation: async (token: string): Promise<Organization> => {
+        return apiRequest<Organization>('/api/invitations/accept', {
+            method: 'POST',
+            body: JSON.stringify({ token }),
+            requiresAuth: true
+        })
+    },
+
+    // Makes the organization the session's active one, shown on /api/me
+    switch: async (orgID: string): Promise<Organization> => {
+        return apiRequest<Organization>(`/api/orgs/${orgID}/switch`, {
+            method: 'POST',
+            requiresAuth: true
+        })
+    }
+}
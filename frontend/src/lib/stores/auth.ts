@@ -8,6 +8,17 @@ import { writable } from 'svelte/store'
 import { browser } from '$app/environment'
 import { authApi } from '$lib/api/auth'
 import { setUnauthorizedHandler } from '$lib/api/client'
+import type { Organization } from '$lib/api/organizations'
+
+// Set on /api/me while an admin is acting as the user
+export interface Impersonation {
+    impersonator_id: string
+    impersonator_identifier: string
+    impersonator_name: string
+    reason: string
+    started_at: string
+    expires_at: string
+}
 
 // User interface matching backend response from /api/me
 export interface User {
@@ -16,7 +27,15 @@ export interface User {
     email: string
     display_name: string
     role: string
+    permissions: string[] // effective: the role's, plus grants, minus denies
     active: boolean
+    impersonation?: Impersonation
+    active_organization?: Organization // set with organizationsApi.switch
+}
+
+// Check capabilities rather than role names, e.g. hasPermission(user, 'users:read')
+export function hasPermission(user: User | null, permission: string): boolean {
+    return user?.permissions?.includes(permission) ?? false
 }
 
 interface AuthState {